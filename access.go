@@ -0,0 +1,119 @@
+package lrucache
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// EntryInfo describes a single entry, including its access statistics.
+type EntryInfo[K comparable, V any] struct {
+	Entry[K, V]
+
+	Created  time.Time     // When the entry was stored.
+	LoadCost time.Duration // How long the value took to load; zero if it was stored without a loader.
+
+	// Hits and LastAccess record reads of the entry since it was stored. They're only populated when the cache was
+	// created WithAccessStats. With a non-zero buffer, reads are counted once their promotions have been applied.
+	Hits       uint64
+	LastAccess time.Time // Zero if the entry hasn't been read.
+
+	// Expired is true if the entry has expired, but not yet been purged. Reads treat it as missing.
+	Expired bool
+}
+
+// recordAccess counts a read of n, for EntryInfo, if enabled.
+// Called holding the list lock.
+func (lru *Cache[K, V]) recordAccess(n *node[K, V]) {
+	if !lru.opts.accessStats {
+		return
+	}
+	n.hits++
+	n.accessed = time.Now()
+}
+
+// EntryInfo returns the details of the entry for k, including its access statistics, without affecting its LRU
+// position or counting as a read. Unlike Get, it also returns entries that have expired but are yet to be purged,
+// flagged as Expired, to help explain what the cache is holding.
+func (lru *Cache[K, V]) EntryInfo(k K) (info EntryInfo[K, V], found bool) {
+	lru.readLock(OperationGet)
+	if lru.stopped {
+		lru.lock.RUnlock()
+		return info, false
+	}
+
+	n, found := lru.cache[k]
+	if !found || n == nil || n.negative {
+		lru.lock.RUnlock()
+		return info, false
+	}
+
+	// The statistics are only updated holding the list lock, so are read under it too.
+	lru.runOnEventLoop(func() {
+		info = EntryInfo[K, V]{
+			Entry:      n.entry(),
+			Created:    n.created,
+			LoadCost:   n.cost,
+			Hits:       n.hits,
+			LastAccess: n.accessed,
+			Expired:    lru.isExpired(n, time.Now()),
+		}
+	})
+	lru.lock.RUnlock()
+
+	return info, true
+}
+
+// HottestKeys returns up to n keys of unexpired entries, from the most read to the least. With WithAccessStats,
+// entries are ranked by their number of reads, then by recency; without it, by recency alone.
+func (lru *Cache[K, V]) HottestKeys(n int) []K {
+	return lru.rankedKeys(n, false)
+}
+
+// ColdestKeys returns up to n keys of unexpired entries, from the least read to the most, i.e. the reverse of
+// HottestKeys. Without WithAccessStats, that is the order in which they'd be evicted.
+func (lru *Cache[K, V]) ColdestKeys(n int) []K {
+	return lru.rankedKeys(n, true)
+}
+
+// rankedKeys returns up to n keys, ordered as for HottestKeys, or reversed if coldest is true.
+func (lru *Cache[K, V]) rankedKeys(n int, coldest bool) []K {
+	if n <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	var nodes []*node[K, V]
+
+	lru.readLock(OperationOther)
+	if lru.stopped {
+		lru.lock.RUnlock()
+		return nil
+	}
+	lru.runOnEventLoop(func() {
+		nodes = make([]*node[K, V], 0, len(lru.cache))
+		for e := lru.head.next; e != lru.tail && e != nil; e = e.next {
+			if !e.negative && !lru.isExpired(e, now) {
+				nodes = append(nodes, e)
+			}
+		}
+
+		// The list is in recency order, so a stable sort keeps the most recent first among equal hits.
+		if lru.opts.accessStats {
+			slices.SortStableFunc(nodes, func(a, b *node[K, V]) int {
+				return cmp.Compare(b.hits, a.hits)
+			})
+		}
+	})
+	lru.lock.RUnlock()
+
+	if coldest {
+		slices.Reverse(nodes)
+	}
+
+	keys := make([]K, 0, min(n, len(nodes)))
+	for _, e := range nodes[:min(n, len(nodes))] {
+		keys = append(keys, e.key)
+	}
+	return keys
+}
@@ -0,0 +1,94 @@
+package lrucache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_EntryInfo(t *testing.T) {
+	// Checks that reads are counted per entry, and that EntryInfo itself doesn't count as a read.
+
+	cache := NewCacheWithOptions[string, int](10, WithAccessStats())
+	defer cache.Close()
+
+	before := time.Now()
+	assert.NoError(t, cache.SetWithOptions("a", 1, WithMetadata("source")))
+	assert.NoError(t, cache.Set("b", 2))
+
+	info, found := cache.EntryInfo("a")
+	assert.True(t, found)
+	assert.Equal(t, uint64(0), info.Hits)
+	assert.True(t, info.LastAccess.IsZero())
+	assert.Equal(t, "source", info.Metadata)
+	assert.False(t, info.Created.Before(before))
+
+	cache.Get("a")
+	cache.Get("a")
+	cache.GetMulti([]string{"a", "b"})
+
+	info, _ = cache.EntryInfo("a")
+	assert.Equal(t, uint64(3), info.Hits)
+	assert.False(t, info.LastAccess.Before(info.Created))
+
+	info, _ = cache.EntryInfo("b")
+	assert.Equal(t, uint64(1), info.Hits)
+
+	_, found = cache.EntryInfo("missing")
+	assert.False(t, found)
+}
+
+func TestCache_EntryInfoExpired(t *testing.T) {
+	// Checks that an expired entry which hasn't been purged yet is still described, flagged as expired.
+
+	cache := NewCacheWithOptions[string, int](10, WithPurgeInterval(time.Hour))
+	defer cache.Close()
+
+	assert.NoError(t, cache.SetWithOptions("a", 1, WithTTL(10*time.Millisecond)))
+
+	info, found := cache.EntryInfo("a")
+	assert.True(t, found)
+	assert.False(t, info.Expired)
+
+	time.Sleep(20 * time.Millisecond)
+
+	info, found = cache.EntryInfo("a")
+	assert.True(t, found)
+	assert.True(t, info.Expired)
+	assert.Equal(t, 1, info.Value)
+
+	_, found = cache.Get("a")
+	assert.False(t, found)
+}
+
+func TestCache_HottestAndColdestKeys(t *testing.T) {
+	// Checks that keys are ranked by reads with access stats, and by recency without.
+
+	cache := NewCacheWithOptions[string, int](10, WithAccessStats())
+	defer cache.Close()
+
+	for _, k := range []string{"a", "b", "c", "d"} {
+		assert.NoError(t, cache.Set(k, 0))
+	}
+	for i := 0; i < 3; i++ {
+		cache.Get("b")
+	}
+	cache.Get("a")
+	cache.Get("c")
+
+	assert.Equal(t, []string{"b", "c", "a"}, cache.HottestKeys(3))
+	assert.Equal(t, []string{"d", "a", "c", "b"}, cache.ColdestKeys(10))
+
+	plain := NewCache[string, int](10)
+	defer plain.Close()
+
+	for _, k := range []string{"a", "b", "c"} {
+		assert.NoError(t, plain.Set(k, 0))
+	}
+	plain.Get("a")
+
+	assert.Equal(t, []string{"a", "c"}, plain.HottestKeys(2))
+	assert.Equal(t, []string{"b", "c", "a"}, plain.ColdestKeys(3))
+	assert.Nil(t, plain.ColdestKeys(0))
+}
@@ -0,0 +1,75 @@
+package lrucache
+
+import (
+	"log/slog"
+	"time"
+)
+
+// defaultAdaptiveFactors are the shadow capacities used by WithAdaptiveCapacity, unless WithShadowCapacities is set.
+var defaultAdaptiveFactors = []float64{0.25, 0.5, 0.75, 1}
+
+// WithAdaptiveCapacity adjusts the size the cache is kept within, between min and its capacity, to hold the given
+// target hit ratio with as little memory as possible. Every interval, the hit ratios estimated by the shadow caches
+// of WithShadowCapacities over that interval are compared with target: the cache shrinks to the smallest shadow
+// capacity that met it, so capacity that isn't adding hits is given back, and grows back to its full capacity if
+// none did, e.g. while it's thrashing. Intervals without any lookups leave the limit unchanged.
+//
+// Unless WithShadowCapacities is also set, shadows of 0.25, 0.5, 0.75 and 1 times the capacity are used. The limit
+// in effect is reported as Stats.Limit. It shouldn't be combined with WithMemoryPressure, which sets the same limit.
+func WithAdaptiveCapacity(min uint64, target float64, interval time.Duration) Option {
+	return func(o *options) {
+		o.adaptiveMin = min
+		o.adaptiveTarget = target
+		o.adaptiveInterval = interval
+	}
+}
+
+// adaptCapacity sets the limit every interval from the shadow caches' hit ratios over the interval, as described
+// by WithAdaptiveCapacity, until the cache is closed.
+func (lru *Cache[K, V]) adaptCapacity(min uint64, target float64, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := lru.shadowStats()
+	for {
+		select {
+		case <-lru.done:
+			return
+		case <-ticker.C:
+		}
+
+		current := lru.shadowStats()
+		limit, ok := adaptiveLimit(last, current, target, lru.capacity)
+		last = current
+		if !ok {
+			continue
+		}
+		limit = max(limit, min)
+		lru.unattended(func() {
+			if previous, changed := lru.setLimit(limit); changed {
+				lru.log(slog.LevelDebug, "lrucache: capacity limit adapted to hit ratio", "previous", previous, "limit", limit, "target", target)
+			}
+		})
+	}
+}
+
+// adaptiveLimit returns the smallest capacity among the shadows whose hit ratio between the last and current stats
+// met target, or capacity if none did. ok is false if there were no lookups in between.
+func adaptiveLimit(last, current []ShadowStats, target float64, capacity uint64) (limit uint64, ok bool) {
+	limit = capacity
+	for i, s := range current {
+		// The shadows are cleared if the cache is reset, so their counts can go down.
+		if i < len(last) && last[i].Hits <= s.Hits && last[i].Misses <= s.Misses {
+			s.Hits -= last[i].Hits
+			s.Misses -= last[i].Misses
+		}
+		if s.Hits+s.Misses == 0 {
+			continue
+		}
+		ok = true
+		if s.HitRatio() >= target {
+			limit = min(limit, s.Capacity)
+		}
+	}
+	return limit, ok
+}
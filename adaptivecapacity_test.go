@@ -0,0 +1,61 @@
+package lrucache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdaptiveLimit(t *testing.T) {
+	// Checks the smallest shadow meeting the target over the interval is chosen, or the capacity if none did.
+
+	last := []ShadowStats{
+		{Capacity: 25, Hits: 100, Misses: 100},
+		{Capacity: 50, Hits: 100, Misses: 100},
+		{Capacity: 100, Hits: 100, Misses: 100},
+	}
+	current := []ShadowStats{
+		{Capacity: 25, Hits: 110, Misses: 190},
+		{Capacity: 50, Hits: 190, Misses: 110},
+		{Capacity: 100, Hits: 195, Misses: 105},
+	}
+
+	limit, ok := adaptiveLimit(last, current, 0.8, 100)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(50), limit)
+
+	limit, ok = adaptiveLimit(last, current, 0.99, 100)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(100), limit)
+
+	_, ok = adaptiveLimit(current, current, 0.8, 100)
+	assert.False(t, ok)
+}
+
+func TestCache_AdaptiveCapacity(t *testing.T) {
+	// Checks the cache shrinks while a small working set meets the target, and grows back once it doesn't.
+
+	cache := NewCacheWithOptions[int, int](100, WithAdaptiveCapacity(10, 0.9, 10*time.Millisecond))
+	defer cache.Close()
+
+	read := func(keys int) {
+		for k := 0; k < keys; k++ {
+			if _, found := cache.Get(k); !found {
+				require.NoError(t, cache.Set(k, k))
+			}
+		}
+	}
+
+	assert.Eventually(t, func() bool {
+		read(5)
+		return cache.Stats().Limit == 25
+	}, time.Second, time.Millisecond)
+	assert.LessOrEqual(t, cache.Size(), uint64(25))
+
+	assert.Eventually(t, func() bool {
+		read(80)
+		return cache.Stats().Limit == 100
+	}, time.Second, time.Millisecond)
+}
@@ -0,0 +1,116 @@
+package lrucache
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Audit events.
+const (
+	AuditEventSet    = "set"    // An entry was stored.
+	AuditEventRemove = "remove" // An entry was removed, for the reason given.
+)
+
+// AuditRecord is a line of the audit log written by WithAuditLog, as JSON.
+type AuditRecord[K comparable] struct {
+	Time    time.Time  `json:"time"`  // When the event happened.
+	Event   string     `json:"event"` // AuditEventSet or AuditEventRemove.
+	Key     K          `json:"key"`
+	Size    uint64     `json:"size"`
+	Reason  string     `json:"reason,omitempty"`  // For removals, the EvictionReason, e.g. "expired".
+	Created time.Time  `json:"created"`           // When the entry was stored.
+	Expires *time.Time `json:"expires,omitempty"` // When the entry expires, if it does.
+}
+
+// WithAuditLog writes a record of every entry stored and removed, whether deleted, evicted or expired, to w, as
+// JSON lines of AuditRecord, e.g. to show what data was cached and when it was removed. Records are collected while
+// the cache's lock is held, and written in order, after it's released, by the goroutine that made the change.
+// Errors writing, or encoding keys, are passed to the error handler.
+func WithAuditLog(w io.Writer) Option {
+	return func(o *options) {
+		o.auditLog = w
+	}
+}
+
+// auditLog collects AuditRecords while the cache's lock is held, for them to be written once it's released.
+type auditLog[K comparable] struct {
+	w io.Writer
+
+	lock    sync.Mutex // Protects pending.
+	pending []AuditRecord[K]
+
+	writing sync.Mutex // Held while writing, so concurrent flushes write their records in order.
+	buf     bytes.Buffer
+}
+
+// auditStored records n being stored.
+// Assumes the lock is already acquired.
+func (lru *Cache[K, V]) auditStored(n *node[K, V]) {
+	if lru.audit != nil {
+		lru.audit.add(AuditRecord[K]{Time: time.Now(), Event: AuditEventSet, Key: n.key, Size: n.size, Created: n.created, Expires: auditExpiry(n.expires)})
+	}
+}
+
+// auditRemoved records n being removed for the given reason.
+// Assumes the lock is already acquired.
+func (lru *Cache[K, V]) auditRemoved(n *node[K, V], reason EvictionReason) {
+	if lru.audit != nil {
+		lru.audit.add(AuditRecord[K]{Time: time.Now(), Event: AuditEventRemove, Key: n.key, Size: n.size, Reason: reason.String(), Created: n.created, Expires: auditExpiry(n.expires)})
+	}
+}
+
+// flushAudit writes the pending audit records. It must be called without holding the lock.
+func (lru *Cache[K, V]) flushAudit() {
+	if lru.audit == nil {
+		return
+	}
+	if err := lru.audit.flush(); err != nil {
+		lru.handleError(err)
+	}
+}
+
+// auditExpiry returns expires for an AuditRecord, which is nil if it's zero, so it's omitted.
+func auditExpiry(expires time.Time) *time.Time {
+	if expires.IsZero() {
+		return nil
+	}
+	return &expires
+}
+
+func (a *auditLog[K]) add(r AuditRecord[K]) {
+	a.lock.Lock()
+	a.pending = append(a.pending, r)
+	a.lock.Unlock()
+}
+
+// flush encodes and writes the pending records.
+func (a *auditLog[K]) flush() error {
+	a.writing.Lock()
+	defer a.writing.Unlock()
+
+	a.lock.Lock()
+	pending := a.pending
+	a.pending = nil
+	a.lock.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	a.buf.Reset()
+	enc := json.NewEncoder(&a.buf)
+	var err error
+	for _, r := range pending {
+		if eerr := enc.Encode(r); eerr != nil && err == nil {
+			err = fmt.Errorf("unable to encode audit record for key %v: %w", r.Key, eerr)
+		}
+	}
+	if _, werr := a.w.Write(a.buf.Bytes()); werr != nil {
+		return fmt.Errorf("unable to write audit log: %w", werr)
+	}
+	return err
+}
@@ -0,0 +1,74 @@
+package lrucache
+
+import (
+	"bufio"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// auditRecords decodes the audit records written to log so far.
+func auditRecords(t *testing.T, log *syncBuffer) []AuditRecord[string] {
+	var records []AuditRecord[string]
+	s := bufio.NewScanner(strings.NewReader(log.String()))
+	for s.Scan() {
+		var r AuditRecord[string]
+		require.NoError(t, json.Unmarshal(s.Bytes(), &r))
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestCache_AuditLog(t *testing.T) {
+	log := &syncBuffer{}
+	cache := NewCacheWithOptions[string, int](2, WithAuditLog(log), WithStrictConsistency())
+	defer cache.Close()
+
+	expires := time.Now().Add(time.Hour)
+	require.NoError(t, cache.SetWithExpiry("a", 1, expires))
+	require.NoError(t, cache.Set("b", 2))
+	require.NoError(t, cache.Set("b", 3))
+	require.NoError(t, cache.Set("c", 4))
+	cache.Delete("b")
+
+	type event struct{ event, key, reason string }
+	var events []event
+	for _, r := range auditRecords(t, log) {
+		events = append(events, event{r.Event, r.Key, r.Reason})
+		assert.False(t, r.Time.IsZero())
+		assert.Equal(t, uint64(1), r.Size)
+	}
+	assert.Equal(t, []event{
+		{AuditEventSet, "a", ""},
+		{AuditEventSet, "b", ""},
+		{AuditEventRemove, "b", "replaced"},
+		{AuditEventSet, "b", ""},
+		{AuditEventRemove, "a", "capacity"},
+		{AuditEventSet, "c", ""},
+		{AuditEventRemove, "b", "deleted"},
+	}, events)
+
+	first := auditRecords(t, log)[0]
+	require.NotNil(t, first.Expires)
+	assert.True(t, expires.Equal(*first.Expires))
+	assert.Nil(t, auditRecords(t, log)[1].Expires)
+}
+
+func TestCache_AuditLogExpiry(t *testing.T) {
+	log := &syncBuffer{}
+	cache := NewCacheWithOptions[string, int](10, WithAuditLog(log))
+	defer cache.Close()
+
+	require.NoError(t, cache.SetWithExpiry("a", 1, time.Now().Add(10*time.Millisecond)))
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, 1, cache.DeleteExpired())
+
+	records := auditRecords(t, log)
+	require.Len(t, records, 2)
+	assert.Equal(t, AuditEventRemove, records[1].Event)
+	assert.Equal(t, "expired", records[1].Reason)
+}
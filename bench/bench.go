@@ -0,0 +1,152 @@
+package bench
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Target is a cache driven by a workload. lrucache.Cache[uint64, uint64] and the other caches in lrucache satisfy
+// it, and other implementations can be adapted to compare them.
+type Target interface {
+	Get(k uint64) (uint64, bool)
+	Set(k uint64, v uint64) error
+}
+
+// Workload describes the accesses Run makes to a cache.
+type Workload struct {
+	Keys        NewGenerator // Generates the keys accessed by each worker.
+	Operations  int          // The total number of operations, shared between the workers.
+	WriteRatio  float64      // The fraction of operations that are writes; the rest are reads.
+	Concurrency int          // The number of goroutines making operations; zero means 1.
+	Seed        uint64       // Seeds the keys and the choice of reads and writes; worker i uses Seed+i.
+}
+
+// Result summarises a run of a workload.
+type Result struct {
+	Operations uint64
+	Reads      uint64
+	Hits       uint64 // Reads that found a value.
+	Writes     uint64 // Writes, not counting those filling misses.
+	Errors     uint64 // Writes, including those filling misses, that returned an error.
+	Duration   time.Duration
+}
+
+// HitRatio returns the fraction of reads that found a value, or zero if there were none.
+func (r Result) HitRatio() float64 {
+	if r.Reads == 0 {
+		return 0
+	}
+	return float64(r.Hits) / float64(r.Reads)
+}
+
+// Throughput returns the operations completed per second.
+func (r Result) Throughput() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Operations) / r.Duration.Seconds()
+}
+
+// String summarises the result on a single line.
+func (r Result) String() string {
+	return fmt.Sprintf("%d ops in %s (%.0f ops/s), hit ratio %.4f, %d errors",
+		r.Operations, r.Duration, r.Throughput(), r.HitRatio(), r.Errors)
+}
+
+// Run drives target with the workload, and returns the result. Reads that miss are followed by a write of the key,
+// as a cache-aside client would, so hit ratios reflect the cache's admission and eviction. With a concurrency of 1,
+// the same workload always makes the same operations in the same order.
+func Run(target Target, w Workload) Result {
+	workers := max(w.Concurrency, 1)
+
+	var result Result
+	var reads, hits, writes, errs atomic.Uint64
+	var wg sync.WaitGroup
+
+	start := time.Now()
+	for i := 0; i < workers; i++ {
+		ops := w.Operations / workers
+		if i < w.Operations%workers {
+			ops++
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var c counts
+			drive(target, w.Keys(w.Seed+uint64(i)), newRand(w.Seed+uint64(i)).Float64, w.WriteRatio, ops, &c)
+			reads.Add(c.reads)
+			hits.Add(c.hits)
+			writes.Add(c.writes)
+			errs.Add(c.errors)
+		}()
+	}
+	wg.Wait()
+
+	result.Duration = time.Since(start)
+	result.Reads, result.Hits, result.Writes, result.Errors = reads.Load(), hits.Load(), writes.Load(), errs.Load()
+	result.Operations = result.Reads + result.Writes
+	return result
+}
+
+// Benchmark drives target with the workload from b.RunParallel, for b.N operations in total, reporting the hit
+// ratio alongside the time per operation. The workload's Operations and Concurrency are ignored, in favour of the
+// benchmark's; each parallel goroutine is seeded in turn from the workload's Seed.
+func Benchmark(b *testing.B, target Target, w Workload) {
+	var worker, reads, hits atomic.Uint64
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		seed := w.Seed + worker.Add(1) - 1
+		keys := w.Keys(seed)
+		choose := newRand(seed).Float64
+
+		var c counts
+		for pb.Next() {
+			step(target, keys, choose, w.WriteRatio, &c)
+		}
+		reads.Add(c.reads)
+		hits.Add(c.hits)
+	})
+
+	if r := reads.Load(); r > 0 {
+		b.ReportMetric(float64(hits.Load())/float64(r), "hit-ratio")
+	}
+}
+
+// counts accumulates the operations made by a single worker.
+type counts struct {
+	reads, hits, writes, errors uint64
+}
+
+// drive makes ops operations on target, as step does.
+func drive(target Target, keys Generator, choose func() float64, writeRatio float64, ops int, c *counts) {
+	for range ops {
+		step(target, keys, choose, writeRatio, c)
+	}
+}
+
+// step makes a single operation on target for the next key: a write with probability writeRatio, otherwise a read,
+// followed by a write if it misses.
+func step(target Target, keys Generator, choose func() float64, writeRatio float64, c *counts) {
+	k := keys.Next()
+	if writeRatio > 0 && choose() < writeRatio {
+		c.writes++
+		if target.Set(k, k) != nil {
+			c.errors++
+		}
+		return
+	}
+
+	c.reads++
+	if _, found := target.Get(k); found {
+		c.hits++
+		return
+	}
+	if target.Set(k, k) != nil {
+		c.errors++
+	}
+}
@@ -0,0 +1,69 @@
+package bench
+
+import (
+	"testing"
+
+	"github.com/nsmithuk/lrucache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun_CountsOperations(t *testing.T) {
+	// Checks every operation is counted, as either a read or a write, in the proportions requested.
+
+	cache := lrucache.NewCache[uint64, uint64](100)
+	defer cache.Close()
+
+	result := Run(cache, Workload{Keys: Zipf(1.1, 1000), Operations: 10000, WriteRatio: 0.2, Concurrency: 4, Seed: 1})
+
+	assert.Equal(t, uint64(10000), result.Operations)
+	assert.Equal(t, result.Operations, result.Reads+result.Writes)
+	assert.InDelta(t, 2000, result.Writes, 300)
+	assert.Zero(t, result.Errors)
+	assert.Positive(t, result.Duration)
+	assert.Positive(t, result.Throughput())
+}
+
+func TestRun_HitRatios(t *testing.T) {
+	// Checks the hit ratios of workloads with well known outcomes for an LRU cache.
+
+	run := func(keys NewGenerator) float64 {
+		cache := lrucache.NewCache[uint64, uint64](100)
+		defer cache.Close()
+		return Run(cache, Workload{Keys: keys, Operations: 5000, Seed: 1}).HitRatio()
+	}
+
+	// Every key fits, so only the first access to each misses.
+	assert.InDelta(t, 0.98, run(Loop(100)), 0.001)
+	// A loop just larger than the cache evicts each key before it comes round again.
+	assert.Zero(t, run(Loop(101)))
+	assert.Zero(t, run(Scan()))
+	// A skewed distribution keeps its hot keys cached.
+	assert.Greater(t, run(Zipf(1.2, 10000)), 0.5)
+}
+
+func TestRun_Reproducible(t *testing.T) {
+	// Checks a single worker gets the same result from the same workload.
+
+	run := func() Result {
+		cache := lrucache.NewCache[uint64, uint64](100)
+		defer cache.Close()
+		return Run(cache, Workload{Keys: Zipf(1.1, 1000), Operations: 5000, WriteRatio: 0.1, Seed: 7})
+	}
+
+	first, second := run(), run()
+	assert.Equal(t, first.Hits, second.Hits)
+	assert.Equal(t, first.Writes, second.Writes)
+}
+
+func BenchmarkCache_Zipf(b *testing.B) {
+	cache := lrucache.NewCache[uint64, uint64](1000)
+	defer cache.Close()
+	Benchmark(b, cache, Workload{Keys: Zipf(1.1, 100000), WriteRatio: 0.1})
+}
+
+func BenchmarkCache_ZipfWithScans(b *testing.B) {
+	cache := lrucache.NewCache[uint64, uint64](1000)
+	defer cache.Close()
+	keys := Mix(Weighted{0.9, Zipf(1.1, 100000)}, Weighted{0.1, Offset(Scan(), 100000)})
+	Benchmark(b, cache, Workload{Keys: keys})
+}
@@ -0,0 +1,121 @@
+// Package bench generates reproducible cache workloads, and drives caches with them to measure throughput and hit
+// ratio, so the effect of a cache's configuration, or of a new eviction policy, can be checked by users on their
+// own hardware, with access patterns resembling their own.
+package bench
+
+import (
+	"math/rand/v2"
+)
+
+// Generator produces the sequence of keys accessed by a workload. A Generator isn't safe for concurrent use; Run
+// creates one for each worker.
+type Generator interface {
+	Next() uint64
+}
+
+// GeneratorFunc adapts a function to a Generator.
+type GeneratorFunc func() uint64
+
+// Next returns the result of calling f.
+func (f GeneratorFunc) Next() uint64 {
+	return f()
+}
+
+// NewGenerator returns a new Generator for a workload, seeded with seed, so that the same seed always produces the
+// same sequence of keys.
+type NewGenerator func(seed uint64) Generator
+
+// Zipf returns generators of keys from 0 to keys-1 following a Zipfian distribution with exponent s, which must be
+// greater than 1: key 0 is the most popular, and popularity falls off as a power law, as is typical of web caches.
+// The larger s, the more skewed the distribution.
+func Zipf(s float64, keys uint64) NewGenerator {
+	return func(seed uint64) Generator {
+		z := rand.NewZipf(newRand(seed), s, 1, keys-1)
+		return GeneratorFunc(z.Uint64)
+	}
+}
+
+// Uniform returns generators of keys from 0 to keys-1, each equally likely.
+func Uniform(keys uint64) NewGenerator {
+	return func(seed uint64) Generator {
+		r := newRand(seed)
+		return GeneratorFunc(func() uint64 {
+			return r.Uint64N(keys)
+		})
+	}
+}
+
+// Scan returns generators of keys that are never repeated, counting up from a starting point chosen by the seed,
+// like a sequential scan over a large table. Every access is a compulsory miss.
+func Scan() NewGenerator {
+	return func(seed uint64) Generator {
+		next := seed << 32
+		return GeneratorFunc(func() uint64 {
+			next++
+			return next
+		})
+	}
+}
+
+// Loop returns generators cycling through keys from 0 to keys-1 in order, starting at a key chosen by the seed.
+// With more keys than fit in the cache, an LRU cache misses on every access, while policies that resist scans
+// keep part of the loop.
+func Loop(keys uint64) NewGenerator {
+	return func(seed uint64) Generator {
+		next := newRand(seed).Uint64N(keys)
+		return GeneratorFunc(func() uint64 {
+			k := next
+			next = (next + 1) % keys
+			return k
+		})
+	}
+}
+
+// Weighted is a generator making up a share of a Mix.
+type Weighted struct {
+	Weight    float64
+	Generator NewGenerator
+}
+
+// Mix returns generators that take each key from one of the given generators, chosen at random in proportion to
+// their weights, e.g. a Zipfian hot set interrupted by scans. The generators should use distinct key ranges, unless
+// they're intended to overlap.
+func Mix(generators ...Weighted) NewGenerator {
+	var total float64
+	for _, g := range generators {
+		total += g.Weight
+	}
+	return func(seed uint64) Generator {
+		r := newRand(seed)
+		gens := make([]Generator, len(generators))
+		for i, g := range generators {
+			gens[i] = g.Generator(seed + uint64(i) + 1)
+		}
+		return GeneratorFunc(func() uint64 {
+			pick := r.Float64() * total
+			for i, g := range generators {
+				if pick < g.Weight {
+					return gens[i].Next()
+				}
+				pick -= g.Weight
+			}
+			return gens[len(gens)-1].Next()
+		})
+	}
+}
+
+// Offset returns generators adding offset to every key of gen, so generators can be mixed without their keys
+// overlapping.
+func Offset(gen NewGenerator, offset uint64) NewGenerator {
+	return func(seed uint64) Generator {
+		g := gen(seed)
+		return GeneratorFunc(func() uint64 {
+			return g.Next() + offset
+		})
+	}
+}
+
+// newRand returns a random number generator seeded with seed.
+func newRand(seed uint64) *rand.Rand {
+	return rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))
+}
@@ -0,0 +1,64 @@
+package bench
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func take(g Generator, n int) []uint64 {
+	keys := make([]uint64, n)
+	for i := range keys {
+		keys[i] = g.Next()
+	}
+	return keys
+}
+
+func TestGenerators_Deterministic(t *testing.T) {
+	// Checks each generator produces the same keys from the same seed, and different keys from different seeds.
+
+	generators := map[string]NewGenerator{
+		"zipf":    Zipf(1.2, 1000),
+		"uniform": Uniform(1000),
+		"scan":    Scan(),
+		"loop":    Loop(1000),
+		"mix":     Mix(Weighted{1, Zipf(1.2, 1000)}, Weighted{1, Offset(Scan(), 1000)}),
+	}
+	for name, gen := range generators {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, take(gen(1), 100), take(gen(1), 100))
+			assert.NotEqual(t, take(gen(1), 100), take(gen(2), 100))
+		})
+	}
+}
+
+func TestZipf_Skewed(t *testing.T) {
+	// Checks keys stay in range, and the lowest keys are by far the most popular.
+
+	g := Zipf(1.2, 1000)(1)
+	counts := make(map[uint64]int)
+	for _, k := range take(g, 10000) {
+		assert.Less(t, k, uint64(1000))
+		counts[k]++
+	}
+	assert.Greater(t, counts[0], counts[10])
+	assert.Greater(t, counts[0], 1000)
+}
+
+func TestLoop_Cycles(t *testing.T) {
+	// Checks a loop visits every key in order before repeating.
+
+	keys := take(Loop(3)(1), 6)
+	assert.Equal(t, keys[:3], keys[3:])
+	assert.ElementsMatch(t, []uint64{0, 1, 2}, keys[:3])
+}
+
+func TestScan_NeverRepeats(t *testing.T) {
+	// Checks a scan never returns the same key twice.
+
+	seen := make(map[uint64]struct{})
+	for _, k := range take(Scan()(1), 1000) {
+		assert.NotContains(t, seen, k)
+		seen[k] = struct{}{}
+	}
+}
@@ -0,0 +1,293 @@
+package lrucache
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Entry represents a single key-value pair, along with its size and expiry, for use with bulk operations.
+type Entry[K comparable, V any] struct {
+	Key     K
+	Value   V
+	Size    uint64    // Size of the entry. Zero is treated as the default size of 1.
+	Expires time.Time // Expiry time of the entry; zero value means no expiry.
+
+	Metadata any // Optional user metadata associated with the entry; see WithMetadata.
+
+	Version uint64 // The entry's version, when returned by the cache; see GetVersioned. Ignored when adding entries.
+}
+
+// entry returns the public representation of the node.
+func (n *node[K, V]) entry() Entry[K, V] {
+	return Entry[K, V]{Key: n.key, Value: n.value, Size: n.size, Expires: n.expires, Metadata: n.metadata, Version: n.version}
+}
+
+// Warm adds many entries to the cache under a single lock acquisition, with a single eviction pass.
+// Entries are given in LRU order: the first entry becomes the most recently used, the last the least recently used.
+// If the entries don't all fit within the cache's capacity, the least recently used entries are dropped.
+// If a key appears more than once, only its first (most recent) occurrence is used.
+// Entries are admitted as by Set, with the ExpiryPolicy and tenant quotas applied, with two exceptions: the TTL
+// jitter of WithTTLJitter isn't applied, as restored entries keep their expiries, and the doorkeeper of
+// WithDoorkeeper is bypassed, as warmed entries are known to be wanted and would otherwise all be refused as first
+// sightings. If any entry is invalid, an error is returned and the cache is left unchanged.
+func (lru *Cache[K, V]) Warm(entries []Entry[K, V]) error {
+	nodes := make([]*node[K, V], 0, len(entries))
+	seen := make(map[K]struct{}, len(entries))
+
+	now := time.Now()
+
+	var total uint64
+	full := false
+	for _, e := range entries {
+		size := e.Size
+		if size == 0 {
+			size = 1
+		}
+		size = lru.weigh(e.Key, e.Value, size)
+
+		expires, err := lru.checkNil(e.Value, lru.expiry.ExpireAfterWrite(e.Key, e.Value, e.Expires, now))
+		if err != nil {
+			return fmt.Errorf("unable to warm key %v: %w", e.Key, err)
+		}
+
+		if err := lru.validate(size, expires); err != nil {
+			return fmt.Errorf("unable to warm key %v: %w", e.Key, err)
+		}
+		if err := lru.checkQuota(lru.tenantOf(e.Key, ""), size); err != nil {
+			return fmt.Errorf("unable to warm key %v: %w", e.Key, err)
+		}
+
+		if _, found := seen[e.Key]; found {
+			continue
+		}
+		seen[e.Key] = struct{}{}
+
+		// Once the cache is full, anything less recent than this would be evicted anyway, but is still validated.
+		if full = full || total+size > lru.capacity; full {
+			continue
+		}
+		total += size
+
+		nodes = append(nodes, &node[K, V]{
+			key:      e.Key,
+			value:    e.Value,
+			size:     size,
+			created:  now,
+			expires:  expires,
+			metadata: e.Metadata,
+		})
+	}
+
+	return lru.insertNodes(nodes, nil, "", false, nil)
+}
+
+// SetAll adds all the key-value pairs in values to the cache under a single lock acquisition, performing at most
+// one eviction pass once they have all been added. Every entry is configured by the same EntryOptions.
+// As maps are unordered, the LRU order of the new entries, relative to each other, is undefined.
+// If any entry is invalid, an error is returned and the cache is left unchanged.
+func (lru *Cache[K, V]) SetAll(values map[K]V, opts ...EntryOption) error {
+	eo := entryOptions{size: 1}
+	for _, opt := range opts {
+		opt(&eo)
+	}
+
+	now := time.Now()
+	nodes := make([]*node[K, V], 0, len(values))
+	for k, v := range values {
+		expires, err := lru.checkNil(v, lru.expiry.ExpireAfterWrite(k, v, eo.expires, now))
+		if err != nil {
+			return fmt.Errorf("unable to set key %v: %w", k, err)
+		}
+
+		size := lru.weigh(k, v, eo.size)
+		if err := lru.validate(size, expires); err != nil {
+			return fmt.Errorf("unable to set key %v: %w", k, err)
+		}
+		expires = lru.jitter(expires)
+
+		nodes = append(nodes, &node[K, V]{
+			key:      k,
+			value:    v,
+			size:     size,
+			created:  now,
+			expires:  expires,
+			metadata: eo.metadata,
+		})
+	}
+
+	return lru.insertNodes(nodes, eo.tags, eo.tenant, eo.atTail, nil)
+}
+
+// SetMulti adds all the key-value pairs in values to the cache, each with a size of 1 and no expiry, under a single
+// lock acquisition. It's equivalent to SetAll without any EntryOptions.
+func (lru *Cache[K, V]) SetMulti(values map[K]V) error {
+	return lru.SetAll(values)
+}
+
+// GetMulti returns the values for all the given keys that are in the cache, and not expired, under a single lock
+// acquisition. Missing keys are absent from the returned map. The found entries are moved to the front of the list
+// with a single event, in the order their keys were given, so the last key ends up the most recently used.
+func (lru *Cache[K, V]) GetMulti(keys []K) map[K]V {
+	values := make(map[K]V, len(keys))
+	nodes := make([]*node[K, V], 0, len(keys))
+	var extended []*node[K, V]
+	var expiries []time.Time
+
+	now := time.Now()
+
+	lru.readLock(OperationGet)
+	if lru.stopped {
+		lru.lock.RUnlock()
+		return values
+	}
+	for _, k := range keys {
+		n, found := lru.cache[k]
+		if !found || n == nil || n.negative || lru.isExpired(n, now) {
+			continue
+		}
+		values[k] = n.value
+		if lru.shouldPromote(n) {
+			nodes = append(nodes, n)
+		}
+
+		if expires := lru.expiry.ExpireAfterRead(k, n.value, n.expires, now); !expires.Equal(n.expires) {
+			extended = append(extended, n)
+			expiries = append(expiries, expires)
+		}
+	}
+
+	// Sent while holding the read lock, so the events channel can't be closed first.
+	if len(nodes) > 0 {
+		lru.promote(context.Background(), event[K, V]{a: EventActionRun, fn: func() {
+			for _, n := range nodes {
+				if !n.deleted {
+					lru.recordHitPosition(n)
+					lru.recordAccess(n)
+					lru.addNodeToHead(n)
+					lru.promoteTags(n)
+				}
+			}
+		}})
+	}
+	lru.lock.RUnlock()
+
+	if len(extended) > 0 {
+		lru.extendAfterRead(extended, expiries)
+	}
+
+	if lru.opts.statsRecorder != nil || lru.thrash != nil || len(lru.shadows) > 0 {
+		for _, k := range keys {
+			_, found := values[k]
+			lru.recordLookup(k, found)
+		}
+	}
+
+	return values
+}
+
+// DeleteMulti removes the entries for all the given keys under a single lock acquisition, with a single pass over the
+// list, returning the number of entries removed. Keys without an entry are ignored. This is much cheaper than calling
+// Delete for each key when invalidating many at once.
+func (lru *Cache[K, V]) DeleteMulti(keys ...K) int {
+	for _, k := range keys {
+		lru.supersedeLoad(k)
+	}
+
+	lru.writeLock(OperationDelete)
+	if lru.stopped {
+		lru.lock.Unlock()
+		return 0
+	}
+	deleted := 0
+	lru.runOnEventLoop(func() {
+		for _, k := range keys {
+			if n, found := lru.cache[k]; found {
+				lru.removeNode(n, EvictionReasonDeleted)
+				deleted++
+			}
+		}
+	})
+	removed := lru.takeRemovals()
+	lru.lock.Unlock()
+
+	lru.notifyRemovals(removed)
+
+	return deleted
+}
+
+// insertNodes adds nodes to the cache, replacing any existing entries with the same keys, then evicts from the tail
+// until the cache is within its capacity. nodes are ordered from the most to the least recently used, and must have
+// unique keys. If tags is not empty, every node is given those tags. Every node joins tenant if it's not empty, or
+// otherwise the tenant given by WithTenantFunc. If atTail is true, the nodes are added at the tail of the list, after
+// the existing entries, rather than at the front. If replace is not nil, a node with the same key as an existing
+// entry is only added if replace returns true for them.
+func (lru *Cache[K, V]) insertNodes(nodes []*node[K, V], tags []string, tenant string, atTail bool, replace func(n, existing *node[K, V]) bool) error {
+	for _, n := range nodes {
+		lru.supersedeLoad(n.key)
+	}
+
+	lru.writeLock(OperationSet)
+	if lru.stopped {
+		lru.lock.Unlock()
+		return ErrCacheClosed
+	}
+	lru.runOnEventLoop(func() {
+		if replace != nil {
+			kept := nodes[:0:0]
+			for _, n := range nodes {
+				if existing, found := lru.cache[n.key]; !found || replace(n, existing) {
+					kept = append(kept, n)
+				}
+			}
+			nodes = kept
+		}
+
+		for _, n := range nodes {
+			if existing, found := lru.cache[n.key]; found {
+				lru.removeNode(existing, EvictionReasonReplaced)
+			}
+		}
+
+		// Add from the least recently used to the most recently used, so the most recent ends up at the head or, if
+		// atTail, just after the existing entries.
+		last := lru.tail.previous
+		for i := len(nodes) - 1; i >= 0; i-- {
+			n := nodes[i]
+			if len(tags) > 0 {
+				lru.addTags(n, tags)
+			}
+			lru.addTenant(n, lru.tenantOf(n.key, tenant))
+			lru.version++
+			n.version = lru.version
+			lru.cache[n.key] = n
+			lru.size += n.size
+			lru.updateGauges()
+			if lru.opts.lockFreeReads {
+				lru.index.Store(n.key, n)
+			}
+			lru.sizeCounts[sizeBucket(n.size)]++
+			lru.noteExpiry(n.expires)
+			lru.scanStored(n)
+			lru.auditStored(n)
+			lru.shadowStore(n)
+			lru.waiters.notify(n.key)
+			if atTail {
+				lru.addNodeBetween(n, last, last.next)
+				lru.length++
+			} else {
+				lru.addNodeToHead(n)
+			}
+		}
+
+		// A single eviction pass, once everything has been added.
+		if _, evict := lru.evictionTarget(0); evict {
+			lru.makeSpaceFor(0, 0)
+		}
+	})
+	removed := lru.takeRemovals()
+	lru.lock.Unlock()
+
+	lru.notifyRemovals(removed)
+	return nil
+}
@@ -0,0 +1,165 @@
+package lrucache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_Warm(t *testing.T) {
+	// Warms a cache with more entries than will fit, ensuring order is preserved, the least recently used
+	//entries are dropped, and existing entries are replaced.
+
+	cache := NewCache[int, string](5)
+	defer cache.Close()
+
+	require.NoError(t, cache.Set(100, "old"))
+	require.NoError(t, cache.Set(1, "replaced"))
+
+	entries := make([]Entry[int, string], 0, 10)
+	for i := 1; i <= 10; i++ {
+		entries = append(entries, Entry[int, string]{Key: i, Value: fmt.Sprintf("value-%d", i)})
+	}
+
+	require.NoError(t, cache.Warm(entries))
+
+	assert.Equal(t, uint64(5), cache.EntryCount())
+	assert.Equal(t, uint64(5), cache.Size())
+
+	cache.runOnEventLoop(func() {
+		head := cache.head
+		for i := 1; i <= 5; i++ {
+			head = head.next
+			assert.Equal(t, i, head.key)
+			assert.Equal(t, fmt.Sprintf("value-%d", i), head.value)
+		}
+		assert.Equal(t, cache.tail, head.next)
+	})
+}
+
+func TestCache_WarmInvalid(t *testing.T) {
+	// Ensures an invalid entry results in an error, without any entries being added.
+
+	cache := NewCache[int, string](5)
+	defer cache.Close()
+
+	err := cache.Warm([]Entry[int, string]{
+		{Key: 1, Value: "value-1"},
+		{Key: 2, Value: "value-2", Expires: time.Now().Add(-time.Second)},
+	})
+	assert.ErrorIs(t, err, ErrPastExpiry)
+	assert.Equal(t, uint64(0), cache.EntryCount())
+
+	err = cache.Warm([]Entry[int, string]{{Key: 1, Value: "value-1", Size: 6}})
+	assert.ErrorIs(t, err, ErrItemTooBig)
+
+	// Entries beyond the capacity are validated too, even though they'd be dropped.
+	entries := make([]Entry[int, string], 0, 6)
+	for i := 1; i <= 5; i++ {
+		entries = append(entries, Entry[int, string]{Key: i, Value: fmt.Sprintf("value-%d", i)})
+	}
+	entries = append(entries, Entry[int, string]{Key: 6, Value: "value-6", Expires: time.Now().Add(-time.Second)})
+	err = cache.Warm(entries)
+	assert.ErrorIs(t, err, ErrPastExpiry)
+	assert.Equal(t, uint64(0), cache.EntryCount())
+}
+
+func TestCache_WarmAdmission(t *testing.T) {
+	// Checks warmed entries are given the expiry policy's TTL and are subject to tenant quotas, but bypass the
+	// doorkeeper.
+
+	cache := NewCacheWithOptions[string, int](10,
+		WithExpiryPolicy(ExpireAfterWrite[string, int](time.Minute)),
+		WithTenantFunc(func(k string) string { return k }),
+		WithTenantQuota("c", 1),
+		WithDoorkeeper(100),
+	)
+	defer cache.Close()
+
+	require.NoError(t, cache.Warm([]Entry[string, int]{{Key: "a", Value: 1}}))
+	a, found := cache.Entry("a")
+	require.True(t, found)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), a.Expires, time.Second)
+
+	err := cache.Warm([]Entry[string, int]{{Key: "b", Value: 2}, {Key: "c", Value: 3, Size: 2}})
+	assert.ErrorIs(t, err, ErrItemTooBig)
+	assert.Equal(t, uint64(1), cache.EntryCount())
+}
+
+func TestCache_SetAll(t *testing.T) {
+	// Validates SetAll adds every entry with the given options, replacing existing keys, and evicts in one pass.
+
+	cache := NewCache[string, int](10)
+	defer cache.Close()
+
+	require.NoError(t, cache.Set("old", 0))
+	require.NoError(t, cache.Set("a", 0))
+
+	values := map[string]int{"a": 1, "b": 2, "c": 3}
+	require.NoError(t, cache.SetAll(values, WithSize(3), WithTags("config")))
+
+	for k, v := range values {
+		got, found := cache.Get(k)
+		assert.True(t, found)
+		assert.Equal(t, v, got)
+	}
+	assert.Equal(t, uint64(10), cache.Size())
+	assert.Equal(t, 3, cache.TagCount("config"))
+	assert.True(t, cache.Contains("old"))
+
+	// Over capacity, so the old entry, at the tail, is evicted.
+	require.NoError(t, cache.SetAll(map[string]int{"d": 4}, WithSize(1)))
+	assert.False(t, cache.Contains("old"))
+	assert.Equal(t, uint64(10), cache.Size())
+
+	err := cache.SetAll(map[string]int{"e": 5, "f": 6}, WithSize(11))
+	assert.ErrorIs(t, err, ErrItemTooBig)
+	assert.False(t, cache.Contains("e"))
+}
+
+func TestCache_GetMultiSetMulti(t *testing.T) {
+	// Checks GetMulti returns only the keys present, and promotes them in the order given.
+
+	cache := NewCache[string, int](4)
+	defer cache.Close()
+
+	require.NoError(t, cache.SetMulti(map[string]int{"a": 1, "b": 2, "c": 3}))
+	require.NoError(t, cache.SetWithExpiry("expired", 4, time.Now().Add(time.Millisecond)))
+	time.Sleep(5 * time.Millisecond)
+
+	values := cache.GetMulti([]string{"c", "missing", "a", "expired"})
+	assert.Equal(t, map[string]int{"a": 1, "c": 3}, values)
+
+	var order []string
+	cache.runOnEventLoop(func() {
+		for n := cache.head.next; n != cache.tail; n = n.next {
+			order = append(order, n.key)
+		}
+	})
+	assert.Equal(t, []string{"a", "c", "expired", "b"}, order)
+
+	assert.Empty(t, cache.GetMulti(nil))
+}
+
+func TestCache_DeleteMulti(t *testing.T) {
+	// Checks the given keys are removed, ignoring missing and repeated keys, with the callback run for each.
+
+	var evicted []int
+	cache := NewCacheWithOptions[int, int](10, WithOnEvict(func(k, _ int, reason EvictionReason) {
+		assert.Equal(t, EvictionReasonDeleted, reason)
+		evicted = append(evicted, k)
+	}))
+	defer cache.Close()
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, cache.Set(i, i))
+	}
+
+	assert.Equal(t, 2, cache.DeleteMulti(1, 3, 3, 7))
+	assert.ElementsMatch(t, []int{1, 3}, evicted)
+	assert.Equal(t, []int{4, 2, 0}, cache.OrderedKeys())
+	assert.Equal(t, uint64(3), cache.Size())
+}
@@ -0,0 +1,188 @@
+package lrucache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// BulkLoader loads the values for many keys that are missing from the cache in a single call, e.g. using a
+// backend's multi-get, such as Redis MGET or DynamoDB BatchGetItem. It returns entries for the keys that have a
+// value; keys without an entry are treated as not found. Each entry's Size and Expires are honoured as by SetAll.
+type BulkLoader[K comparable, V any] func(ctx context.Context, keys []K) ([]Entry[K, V], error)
+
+// GetMultiOrLoad returns the values for all the given keys, as GetMulti, calling loader once for all those missing.
+// Keys already being loaded, by GetOrLoad or another GetMultiOrLoad, share that load rather than being requested
+// again. Keys the loader has no value for are absent from the returned map, and are cached as misses if
+// WithNegativeCaching is set, as are keys with a cached miss. If the loader fails, its error is returned, along with
+// the values that were found.
+func (lru *Cache[K, V]) GetMultiOrLoad(ctx context.Context, keys []K, loader BulkLoader[K, V]) (map[K]V, error) {
+	values := lru.GetMulti(keys)
+
+	missing := lru.missingKeys(keys, values)
+	if len(missing) == 0 {
+		return values, nil
+	}
+
+	owned := make(map[K]*load[V], len(missing))
+	waiting := make(map[K]*load[V])
+	for _, k := range missing {
+		if l, owner := lru.startLoad(k); owner {
+			owned[k] = l
+		} else {
+			waiting[k] = l
+		}
+	}
+
+	err := lru.loadAll(ctx, owned, loader)
+
+	for k, l := range owned {
+		if l.err == nil {
+			values[k] = l.value
+		}
+	}
+	for k, l := range waiting {
+		v, _, werr := lru.waitForLoad(ctx, k, l, false)
+		switch {
+		case werr == nil:
+			values[k] = v
+		case !errors.Is(werr, ErrNotFound) && err == nil:
+			err = werr
+		}
+	}
+
+	return values, err
+}
+
+// missingKeys returns the keys, without duplicates, that aren't in values, and don't have a cached miss.
+func (lru *Cache[K, V]) missingKeys(keys []K, values map[K]V) []K {
+	now := time.Now()
+	seen := make(map[K]struct{}, len(keys)-len(values))
+	missing := make([]K, 0, len(keys)-len(values))
+
+	lru.readLock(OperationGet)
+	defer lru.lock.RUnlock()
+
+	for _, k := range keys {
+		if _, found := values[k]; found {
+			continue
+		}
+		if _, found := seen[k]; found {
+			continue
+		}
+		seen[k] = struct{}{}
+		if n, found := lru.cache[k]; found && n.negative && !lru.isExpired(n, now) {
+			continue
+		}
+		missing = append(missing, k)
+	}
+	return missing
+}
+
+// loadAll calls loader for the keys of the loads owned, subject to the concurrent load limit, storing and recording
+// the results on each load, which it then finishes. Keys with a cached error, from WithErrorCaching, aren't requested.
+func (lru *Cache[K, V]) loadAll(ctx context.Context, owned map[K]*load[V], loader BulkLoader[K, V]) (err error) {
+	defer func() {
+		for k, l := range owned {
+			lru.finishLoad(k, l)
+		}
+	}()
+
+	keys := make([]K, 0, len(owned))
+	for k, l := range owned {
+		if ferr := lru.cachedFailure(k); ferr != nil {
+			l.outcome, l.err = LoadOutcomeError, ferr
+			err = ferr
+			continue
+		}
+		keys = append(keys, k)
+	}
+	if len(keys) == 0 {
+		return err
+	}
+
+	fail := func(lerr error) error {
+		for _, k := range keys {
+			owned[k].outcome, owned[k].err = LoadOutcomeError, lerr
+		}
+		return lerr
+	}
+
+	if lerr := lru.loaders.limiter.acquire(ctx); lerr != nil {
+		return fail(lerr)
+	}
+
+	var entries []Entry[K, V]
+	var lerr error
+	start := time.Now()
+	func() {
+		defer lru.loaders.limiter.release()
+
+		loadCtx := ctx
+		if timeout := lru.opts.loadTimeout; timeout > 0 {
+			var cancel context.CancelFunc
+			loadCtx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		if perr := lru.safely("BulkLoader", func() {
+			entries, lerr = loader(loadCtx, keys)
+		}); perr != nil {
+			lerr = perr
+		}
+	}()
+	lru.recordLoad(start, lerr)
+
+	// The load time is shared among the keys requested, for WithCostAwareEviction.
+	cost := time.Since(start) / time.Duration(len(keys))
+
+	if lerr != nil {
+		if lru.opts.errorTTL > 0 && ctx.Err() == nil {
+			for _, k := range keys {
+				lru.cacheFailure(k, lerr)
+			}
+		}
+		return fail(lerr)
+	}
+
+	loaded := make(map[K]struct{}, len(entries))
+	for _, e := range entries {
+		l, found := owned[e.Key]
+		if !found {
+			continue
+		}
+		if _, found := loaded[e.Key]; found {
+			continue
+		}
+		loaded[e.Key] = struct{}{}
+
+		size := e.Size
+		if size == 0 {
+			size = 1
+		}
+		outcome, serr := lru.storeLoaded(e.Key, l, e.Value, entryOptions{size: size, expires: e.Expires, cost: cost})
+		if serr != nil {
+			l.outcome, l.err = LoadOutcomeError, fmt.Errorf("unable to cache loaded value: %w", serr)
+			err = l.err
+			continue
+		}
+		l.value, l.outcome, l.err = e.Value, outcome, nil
+	}
+
+	for _, k := range keys {
+		if _, found := loaded[k]; found {
+			continue
+		}
+		l := owned[k]
+		l.outcome, l.err = LoadOutcomeError, fmt.Errorf("%w: key %v", ErrNotFound, k)
+		if ttl := lru.opts.negativeTTL; ttl > 0 {
+			eo := entryOptions{size: 1, expires: time.Now().Add(ttl), negative: true}
+			if _, serr := lru.storeLoaded(k, l, lru.emptyV, eo); serr != nil {
+				lru.handleError(fmt.Errorf("unable to cache miss for key %v: %w", k, serr))
+			}
+		}
+	}
+
+	return err
+}
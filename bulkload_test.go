@@ -0,0 +1,126 @@
+package lrucache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_GetMultiOrLoad(t *testing.T) {
+	// Checks only the missing keys are loaded, in a single call, and that keys without a value are cached as misses.
+
+	cache := NewCacheWithOptions[int, string](10, WithNegativeCaching(time.Minute))
+	defer cache.Close()
+	require.NoError(t, cache.Set(1, "cached-1"))
+
+	var calls [][]int
+	loader := func(ctx context.Context, keys []int) ([]Entry[int, string], error) {
+		calls = append(calls, append([]int(nil), keys...))
+		var entries []Entry[int, string]
+		for _, k := range keys {
+			if k%2 == 0 {
+				entries = append(entries, Entry[int, string]{Key: k, Value: fmt.Sprintf("loaded-%d", k)})
+			}
+		}
+		return entries, nil
+	}
+
+	values, err := cache.GetMultiOrLoad(context.Background(), []int{1, 2, 3, 4, 2}, loader)
+	require.NoError(t, err)
+	assert.Equal(t, map[int]string{1: "cached-1", 2: "loaded-2", 4: "loaded-4"}, values)
+	require.Len(t, calls, 1)
+	assert.ElementsMatch(t, []int{2, 3, 4}, calls[0])
+
+	// Everything is now cached, including the miss for 3.
+	values, err = cache.GetMultiOrLoad(context.Background(), []int{1, 2, 3, 4}, loader)
+	require.NoError(t, err)
+	assert.Len(t, values, 3)
+	assert.Len(t, calls, 1)
+}
+
+func TestCache_GetMultiOrLoadError(t *testing.T) {
+	cache := NewCache[int, int](10)
+	defer cache.Close()
+	require.NoError(t, cache.Set(1, 1))
+
+	failure := errors.New("backend unavailable")
+	values, err := cache.GetMultiOrLoad(context.Background(), []int{1, 2}, func(ctx context.Context, keys []int) ([]Entry[int, int], error) {
+		return nil, failure
+	})
+	assert.ErrorIs(t, err, failure)
+	assert.Equal(t, map[int]int{1: 1}, values)
+	assert.False(t, cache.Contains(2))
+}
+
+func TestCache_GetMultiOrLoadSharesInFlightLoads(t *testing.T) {
+	// A key already being loaded by GetOrLoad isn't requested again.
+
+	cache := NewCache[int, int](10)
+	defer cache.Close()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, _ = cache.GetOrLoad(context.Background(), 1, func(ctx context.Context, k int) (int, time.Time, error) {
+			close(started)
+			<-release
+			return 10, time.Time{}, nil
+		})
+	}()
+	<-started
+
+	var requested []int
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+	}()
+	values, err := cache.GetMultiOrLoad(context.Background(), []int{1, 2}, func(ctx context.Context, keys []int) ([]Entry[int, int], error) {
+		requested = keys
+		return []Entry[int, int]{{Key: 2, Value: 20}}, nil
+	})
+	wg.Wait()
+
+	require.NoError(t, err)
+	assert.Equal(t, map[int]int{1: 10, 2: 20}, values)
+	assert.Equal(t, []int{2}, requested)
+}
+
+func TestLoadingCache_GetAll(t *testing.T) {
+	single := func(ctx context.Context, k int) (int, time.Time, error) {
+		if k < 0 {
+			return 0, time.Time{}, ErrNotFound
+		}
+		return k * 10, time.Time{}, nil
+	}
+	var bulkCalls int
+	bulk := func(ctx context.Context, keys []int) ([]Entry[int, int], error) {
+		bulkCalls++
+		entries := make([]Entry[int, int], 0, len(keys))
+		for _, k := range keys {
+			if k >= 0 {
+				entries = append(entries, Entry[int, int]{Key: k, Value: k * 10})
+			}
+		}
+		return entries, nil
+	}
+
+	for _, cache := range []*LoadingCache[int, int]{
+		NewLoadingCache[int, int](10, single),
+		NewBulkLoadingCache[int, int](10, single, bulk),
+	} {
+		values, err := cache.GetAll(context.Background(), []int{1, 2, -1})
+		require.NoError(t, err)
+		assert.Equal(t, map[int]int{1: 10, 2: 20}, values)
+		cache.Close()
+	}
+	assert.Equal(t, 1, bulkCalls)
+}
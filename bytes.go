@@ -0,0 +1,34 @@
+package lrucache
+
+import (
+	"bytes"
+	"hash/maphash"
+)
+
+// BytesCache is a HashedCache keyed by byte slices, such as raw protobuf keys. Lookups hash the slice directly,
+// so unlike a Cache[string, V], there's no allocation converting each key to a string.
+// Keys are copied when stored, so callers may reuse their buffers.
+type BytesCache[V any] struct {
+	*HashedCache[[]byte, V]
+}
+
+// NewBytesCache creates a new BytesCache with the specified capacity, configured by the given Options, as for
+// NewHashedCache.
+func NewBytesCache[V any](capacity uint64, opts ...Option) *BytesCache[V] {
+	seed := maphash.MakeSeed()
+	hash := func(k []byte) uint64 {
+		return maphash.Bytes(seed, k)
+	}
+	return &BytesCache[V]{HashedCache: NewHashedCache[[]byte, V](capacity, hash, bytes.Equal, opts...)}
+}
+
+// Set adds a key-value pair to the cache with a size of 1 and no expiry, replacing any existing value for k.
+func (c *BytesCache[V]) Set(k []byte, v V) error {
+	return c.SetWithOptions(k, v)
+}
+
+// SetWithOptions adds a key-value pair to the cache, configured by the given EntryOptions; see
+// HashedCache.SetWithOptions.
+func (c *BytesCache[V]) SetWithOptions(k []byte, v V, opts ...EntryOption) error {
+	return c.HashedCache.SetWithOptions(bytes.Clone(k), v, opts...)
+}
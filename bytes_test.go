@@ -0,0 +1,33 @@
+package lrucache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBytesCache(t *testing.T) {
+	// Checks that keys are copied when stored, and that lookups don't allocate.
+
+	cache := NewBytesCache[int](10)
+	defer cache.Close()
+
+	buf := []byte("key-1")
+	assert.NoError(t, cache.Set(buf, 1))
+
+	// Reusing the buffer doesn't affect the stored key.
+	copy(buf, "key-2")
+	assert.NoError(t, cache.Set(buf, 2))
+
+	v, found := cache.Get([]byte("key-1"))
+	assert.True(t, found)
+	assert.Equal(t, 1, v)
+	v, _ = cache.Get([]byte("key-2"))
+	assert.Equal(t, 2, v)
+
+	key := []byte("key-1")
+	allocs := testing.AllocsPerRun(100, func() {
+		cache.Get(key)
+	})
+	assert.Zero(t, allocs)
+}
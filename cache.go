@@ -1,8 +1,11 @@
 package lrucache
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -38,6 +41,14 @@ var (
 type Cache[K comparable, V any] struct {
 	size     uint64 // Current total size of all items in the cache.
 	capacity uint64 // Maximum allowed size of the cache.
+	limit    uint64 // The size entries are evicted down to; less than capacity under memory pressure. Protected by the lock.
+
+	// Copies of size and len(cache), updated with them, so Size and EntryCount needn't contend for the lock.
+	sizeGauge  atomic.Uint64
+	entryGauge atomic.Uint64
+
+	sizeCounts [SizeBuckets]uint64 // Entries by size, for Stats.SizeCounts; protected by the lock.
+	version    uint64              // The version given to the most recently stored entry; protected by the lock.
 
 	cache map[K]*node[K, V] // Map for fast key-based lookup of nodes.
 
@@ -46,10 +57,94 @@ type Cache[K comparable, V any] struct {
 
 	lock   AssertRWLock     // Lock for synchronising read/write operations.
 	events chan event[K, V] // Channel for handling cache events asynchronously.
-	done   chan bool        // Channel for signalling cache shutdown.
+	done   chan struct{}    // Closed to signal cache shutdown.
 	close  sync.Once        // Ensures Close method runs only once.
 
+	processed    chan struct{} // Closed when the event goroutine exits, once the events channel is closed.
+	shutdownDone chan struct{} // Closed once shutdown has completed.
+	shrink       chan struct{} // Wakes the background eviction of WithSoftCapacity.
+
+	lifecycle  sync.Mutex     // Protects closed, and adding to background.
+	closed     bool           // True once Close has been called.
+	stopped    bool           // True once the events channel has been closed; protected by lock.
+	background sync.WaitGroup // Background loops that must stop before the events channel is closed.
+
+	list      sync.Mutex     // Held while handling events, in place or on the event goroutine, so they're never concurrent.
+	queued    atomic.Uint64  // Promotions sent to the events channel.
+	handled   atomic.Uint64  // Promotions taken from the events channel and applied; updated holding the list lock.
+	maxQueue  atomic.Uint64  // The most promotions that have been waiting to be applied at once.
+	dropped   atomic.Uint64  // Promotions skipped while the buffer was full; see WithOverflowPolicy.
+	coalesced atomic.Uint64  // Promotions merged with one already set aside; see OverflowCoalesce.
+	overflow  overflow[K, V] // Promotions set aside while the buffer was full, for OverflowCoalesce.
+
 	purgeInterval time.Duration
+	nextPurge     atomic.Int64  // Unix nanoseconds of the purge scheduled by WithPurgeAtExpiry; zero if none.
+	purgeCursor   *node[K, V]   // Where the next pass resumes, with WithPurgeMaxEntries; only accessed holding the list lock.
+	purgeSequence uint64        // The purgeCursor's sequence when it was chosen, to detect it being moved.
+	purgeStats    purgeCounters // Summarises purge passes, for Stats.
+	lastUsed      atomic.Int64  // Unix nanoseconds of the last read or write, with WithWeakCapacity's idle trimming.
+	reschedule    chan struct{} // Wakes the purge goroutine of WithPurgeAtExpiry, when nextPurge is brought forward.
+
+	opts options // Optional behaviour, configured at construction.
+
+	loaders loaders[K, V] // In-flight loads, for GetOrLoad.
+
+	onEvict      func(K, V, EvictionReason)            // Optional callback for removed entries.
+	onEvictEntry func(Entry[K, V], EvictionReason)     // Optional callback for removed entries, with all their details.
+	evictHook    func(K, V, time.Time, EvictionReason) // Internal callback for removed entries, e.g. for demotion.
+	removed      []removal[K, V]                       // Removals awaiting the onEvict callback, protected by the lock.
+	evicted      int                                   // Capacity evictions since the last takeEvicted, protected by the lock.
+
+	onCapacityPressure func(CapacityPressure[K]) // Optional callback for rejections and large evictions.
+	expired            *expiryBatcher[K]         // Batches expired keys for the OnExpiredBatch callback; nil unless set.
+	expiredEntries     chan ExpiredEntry[K, V]   // Receives expired entries; see WithExpiredChannel. Nil unless set.
+
+	equal func(a, b V) bool // Compares values for CompareAndSwap.
+
+	evictionLess func(a, b EvictionCandidate[K, V]) bool // Chooses eviction victims; see WithEvictionOrder.
+	inflation    float64                                 // Baseline credit for WithCostAwareEviction; only accessed holding the list lock.
+	weigher      func(K, V) uint64                       // Gives the size of entries; see WithWeigher.
+
+	audit *auditLog[K] // Records to write to the WithAuditLog writer; nil without it.
+
+	scan *scanIndex[K, V] // Nodes in the order they were stored, for Scan; nil until Scan is first called.
+
+	shadows []*shadowCache[K] // Simulated caches of other capacities; see WithShadowCapacities.
+
+	waiters keyWaiters[K] // Goroutines waiting in WaitFor.
+
+	expiry ExpiryPolicy[K, V] // Decides when entries expire; see WithExpiryPolicy.
+
+	logger    *slog.Logger // Optional logger for notable events; see WithLogger.
+	saturated atomic.Bool  // True while the events channel is full, so saturation is only logged once.
+
+	doorkeeper *doorkeeper  // Refuses keys on their first sighting; nil unless enabled.
+	hasher     keyHasher[K] // Hashes keys for the doorkeeper.
+
+	lockWait *[operationCount]lockWaitCounter // Time spent waiting for the lock; nil unless enabled.
+	latency  *latencyCounters                 // Latency histograms; nil unless enabled by WithLatencyStats.
+
+	hitPositions *[HitPositionBuckets]atomic.Uint64 // Hits by approximate list position; nil unless enabled.
+	length       int                                // Number of nodes in the list, only accessed holding the list lock.
+	sequence     atomic.Uint64                      // Count of nodes moved to the front, only changed holding the list lock.
+
+	tags    map[string]*tagList[K, V] // Per-tag LRU lists, only accessed holding the list lock.
+	tenants map[string]*tagList[K, V] // Per-tenant LRU lists, for tenants with quotas; as for tags.
+
+	tenantFunc func(K) string // Derives an entry's tenant from its key; see WithTenantFunc.
+
+	epoch     atomic.Uint64 // Entries with a version up to this were invalidated by BumpEpoch; written holding the lock.
+	tagEpochs atomic.Bool   // True once BumpTagEpoch has been used, so reads must take the lock to check tags.
+
+	// For WithLockFreeReads: an index of the entries, updated with the map; buffers of promotions; a channel to
+	// wake the goroutine applying them; and a flag mirroring stopped.
+	index      sync.Map
+	reads      [readStripes]readBuffer[K, V]
+	drainReads chan struct{}
+	halted     atomic.Bool
+
+	thrash      *thrashMonitor      // Counts evictions and reads for WithThrashAlert; nil unless enabled.
+	utilization *utilizationTracker // Tracks the thresholds crossed for WithOnUtilization; nil unless enabled.
 
 	emptyK K // Zero value for the key type, used for default returns.
 	emptyV V // Zero value for the value type, used for default returns.
@@ -58,13 +153,29 @@ type Cache[K comparable, V any] struct {
 // node represents an individual entry in the LRU cache.
 // Ordered to try and reduce padding.
 type node[K comparable, V any] struct {
-	expires  time.Time   // Expiry time of the entry; zero value means no expiry.
-	size     uint64      // Size of the entry in the cache.
-	previous *node[K, V] // Pointer to the previous node in the linked list.
-	next     *node[K, V] // Pointer to the next node in the linked list.
-	key      K           // Key associated with the cache entry.
-	value    V           // Value stored in the cache entry.
+	created  time.Time          // Time the entry was added to the cache.
+	expires  time.Time          // Expiry time of the entry; zero value means no expiry.
+	accessed time.Time          // Time the entry was last read, with WithAccessStats; only accessed holding the list lock.
+	pinned   time.Time          // Time until which the entry is exempt from eviction; see PinUntil.
+	size     uint64             // Size of the entry in the cache.
+	sequence atomic.Uint64      // The cache's sequence when the node was last moved to the front; see recordHitPosition.
+	version  uint64             // The entry's version, unique within the cache; see GetVersioned.
+	hits     uint64             // Number of reads, with WithAccessStats; only accessed holding the list lock.
+	previous *node[K, V]        // Pointer to the previous node in the linked list.
+	next     *node[K, V]        // Pointer to the next node in the linked list.
+	tags     []*tagMember[K, V] // The node's membership of each of its tags' lists, if any.
+	tenant   *tagMember[K, V]   // The node's membership of its tenant's list, if it has a quota.
+	metadata any                // Optional user metadata associated with the entry.
+	key      K                  // Key associated with the cache entry.
+	value    V                  // Value stored in the cache entry.
 	deleted  bool
+	negative bool // True if this is a negative-cache entry, recording that the loader found no value.
+
+	cost   time.Duration // How long the value took to load; zero if it was Set directly.
+	credit float64       // Eviction priority for WithCostAwareEviction; only accessed holding the list lock.
+
+	refs          atomic.Int64   // Outstanding handles from Acquire, plus refRemoved once removed.
+	releaseReason EvictionReason // Why the node was removed, for callbacks deferred until its handles are released.
 }
 
 func NewCache[K comparable, V any](capacity uint64) *Cache[K, V] {
@@ -84,31 +195,267 @@ func NewCacheWithInterval[K comparable, V any](capacity uint64, interval time.Du
 // - buffer: Buffer size for the event channel.
 // - buffer: Duration between purging expired nodes.
 func NewCacheWithBufferAndInterval[K comparable, V any](capacity uint64, buffer uint16, interval time.Duration) *Cache[K, V] {
+	return NewCacheWithOptions[K, V](capacity, WithBuffer(buffer), WithPurgeInterval(interval))
+}
+
+// NewCacheWithOptions creates a new LRU cache with the specified capacity, configured by the given Options.
+func NewCacheWithOptions[K comparable, V any](capacity uint64, opts ...Option) *Cache[K, V] {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	cache := &Cache[K, V]{
 		capacity: capacity,
+		limit:    capacity,
 		cache:    make(map[K]*node[K, V]),
+		logger:   o.logger,
+		tags:     make(map[string]*tagList[K, V]),
+		tenants:  make(map[string]*tagList[K, V]),
 
 		head: &node[K, V]{},
 		tail: &node[K, V]{},
 
-		done:   make(chan bool),
-		events: make(chan event[K, V], buffer),
+		done:   make(chan struct{}),
+		events: make(chan event[K, V], o.buffer),
+
+		processed:    make(chan struct{}),
+		shutdownDone: make(chan struct{}),
+		shrink:       make(chan struct{}, 1),
+		reschedule:   make(chan struct{}, 1),
+		drainReads:   make(chan struct{}, 1),
+
+		purgeInterval: o.purgeInterval,
 
-		purgeInterval: interval,
+		opts: o,
+
+		loaders: loaders[K, V]{
+			inFlight: make(map[K]*load[V]),
+			limiter:  newLoadLimiter(o.maxConcurrentLoads, o.loadQueueSize, o.loadQueueTimeout),
+		},
+	}
+
+	if o.lockContentionStats {
+		cache.lockWait = &[operationCount]lockWaitCounter{}
+	}
+
+	if o.latencyStats {
+		cache.latency = &latencyCounters{}
+	}
+
+	if o.hitPositionStats {
+		cache.hitPositions = &[HitPositionBuckets]atomic.Uint64{}
+	}
+
+	if o.onUtilization != nil && len(o.utilizationThresholds) > 0 {
+		cache.utilization = newUtilizationTracker(o.utilizationThresholds)
+	}
+
+	if o.onThrash != nil && o.thrashWindow > 0 {
+		cache.thrash = &thrashMonitor{}
+	}
+
+	if o.doorkeeperWindow > 0 {
+		cache.doorkeeper = newDoorkeeper(o.doorkeeperWindow)
+		cache.hasher = newKeyHasher[K]()
+	}
+
+	if o.onEvict != nil {
+		fn, ok := o.onEvict.(func(K, V, EvictionReason))
+		if !ok {
+			panic(fmt.Sprintf("lrucache: OnEvict callback has type %T, which does not match the cache", o.onEvict))
+		}
+		cache.onEvict = fn
+	}
+
+	if o.onEvictEntry != nil {
+		fn, ok := o.onEvictEntry.(func(Entry[K, V], EvictionReason))
+		if !ok {
+			panic(fmt.Sprintf("lrucache: OnEvictEntry callback has type %T, which does not match the cache", o.onEvictEntry))
+		}
+		cache.onEvictEntry = fn
+	}
+
+	cache.equal = func(a, b V) bool {
+		return any(a) == any(b)
+	}
+	if o.equal != nil {
+		fn, ok := o.equal.(func(V, V) bool)
+		if !ok {
+			panic(fmt.Sprintf("lrucache: Equal function has type %T, which does not match the cache", o.equal))
+		}
+		cache.equal = fn
+	}
+
+	cache.expiry = AbsoluteExpiry[K, V]()
+	if o.expiryPolicy != nil {
+		policy, ok := o.expiryPolicy.(ExpiryPolicy[K, V])
+		if !ok {
+			panic(fmt.Sprintf("lrucache: ExpiryPolicy has type %T, which does not match the cache", o.expiryPolicy))
+		}
+		cache.expiry = policy
+	}
+
+	if o.onCapacityPressure != nil {
+		fn, ok := o.onCapacityPressure.(func(CapacityPressure[K]))
+		if !ok {
+			panic(fmt.Sprintf("lrucache: OnCapacityPressure callback has type %T, which does not match the cache", o.onCapacityPressure))
+		}
+		cache.onCapacityPressure = fn
+	}
+
+	if o.onExpiredBatch != nil {
+		fn, ok := o.onExpiredBatch.(func([]K))
+		if !ok {
+			panic(fmt.Sprintf("lrucache: OnExpiredBatch callback has type %T, which does not match the cache", o.onExpiredBatch))
+		}
+		delay := o.expiredBatchDelay
+		if o.synchronous {
+			delay = 0
+		}
+		cache.expired = newExpiryBatcher(fn, o.expiredBatchSize, delay, cache.safely, cache.unattended)
+	}
+
+	if o.expiredChannel {
+		cache.expiredEntries = make(chan ExpiredEntry[K, V], max(o.expiredBuffer, 0))
+	}
+
+	if o.adaptiveInterval > 0 && len(o.shadowFactors) == 0 {
+		o.shadowFactors = defaultAdaptiveFactors
+	}
+	if len(o.shadowFactors) > 0 {
+		cache.shadows = newShadowCaches[K](capacity, o.shadowFactors)
+	}
+
+	if o.auditLog != nil {
+		cache.audit = &auditLog[K]{w: o.auditLog}
+	}
+
+	if o.weigher != nil {
+		fn, ok := o.weigher.(func(K, V) uint64)
+		if !ok {
+			panic(fmt.Sprintf("lrucache: Weigher function has type %T, which does not match the cache", o.weigher))
+		}
+		cache.weigher = fn
+	}
+
+	if o.evictionLess != nil {
+		fn, ok := o.evictionLess.(func(EvictionCandidate[K, V], EvictionCandidate[K, V]) bool)
+		if !ok {
+			panic(fmt.Sprintf("lrucache: EvictionOrder function has type %T, which does not match the cache", o.evictionLess))
+		}
+		cache.evictionLess = fn
+	}
+
+	if o.tenantFunc != nil {
+		fn, ok := o.tenantFunc.(func(K) string)
+		if !ok {
+			panic(fmt.Sprintf("lrucache: TenantFunc function has type %T, which does not match the cache", o.tenantFunc))
+		}
+		cache.tenantFunc = fn
+	}
+
+	if o.evictHook != nil {
+		cache.evictHook = o.evictHook.(func(K, V, time.Time, EvictionReason))
 	}
 
 	// Initialise the linked list with the head and tail nodes.
 	cache.head.next = cache.tail
 	cache.tail.previous = cache.head
 
-	// Start background goroutines for processing events and purging expired items.
-	go cache.processEvents()
+	cache.start()
+
+	return cache
+}
+
+// start starts the background goroutines for processing events and purging expired items.
+func (lru *Cache[K, V]) start() {
+	if lru.opts.synchronous {
+		return
+	}
 
-	if interval > 0 {
-		go cache.purgeExpired(interval)
+	if !lru.opts.strictConsistency {
+		go lru.processEvents()
 	}
 
-	return cache
+	if lru.lockFree() {
+		lru.background.Add(1)
+		go func() {
+			defer lru.background.Done()
+			lru.applyReadsInBackground()
+		}()
+	}
+
+	if lru.opts.pressure != nil {
+		lru.background.Add(1)
+		go func() {
+			defer lru.background.Done()
+			lru.watchPressure(lru.opts.pressure, lru.opts.pressureInterval)
+		}()
+	}
+
+	if lru.opts.softCapacity > 0 {
+		lru.background.Add(1)
+		go func() {
+			defer lru.background.Done()
+			lru.shrinkInBackground()
+		}()
+	}
+
+	if interval := lru.opts.adaptiveInterval; interval > 0 {
+		lru.background.Add(1)
+		go func() {
+			defer lru.background.Done()
+			lru.adaptCapacity(lru.opts.adaptiveMin, lru.opts.adaptiveTarget, interval)
+		}()
+	}
+
+	if interval := lru.opts.reweighInterval; lru.weigher != nil && interval > 0 {
+		lru.background.Add(1)
+		go func() {
+			defer lru.background.Done()
+			lru.reweighInBackground(interval)
+		}()
+	}
+
+	if path := lru.opts.hotKeyPath; path != "" && lru.opts.hotKeyCount > 0 && lru.opts.hotKeyInterval > 0 {
+		lru.background.Add(1)
+		go func() {
+			defer lru.background.Done()
+			lru.checkpointHotKeys(path, lru.opts.hotKeyCount, lru.opts.hotKeyInterval)
+		}()
+	}
+
+	if idle := lru.opts.weakIdle; lru.opts.weakCapacity && idle > 0 {
+		lru.touch()
+		lru.background.Add(1)
+		go func() {
+			defer lru.background.Done()
+			lru.trimWhenIdle(idle)
+		}()
+	}
+
+	if lru.thrash != nil {
+		lru.background.Add(1)
+		go func() {
+			defer lru.background.Done()
+			lru.watchThrash()
+		}()
+	}
+
+	if lru.opts.purgeAtExpiry && !lru.opts.externalRun {
+		lru.background.Add(1)
+		go func() {
+			defer lru.background.Done()
+			lru.purgeAtExpiry(context.Background())
+		}()
+	} else if lru.purgeInterval > 0 && !lru.opts.externalRun {
+		lru.background.Add(1)
+		go func() {
+			defer lru.background.Done()
+			lru.purgeExpired(context.Background(), lru.purgeInterval)
+		}()
+	}
 }
 
 // Capacity returns the maximum capacity of the cache.
@@ -116,31 +463,166 @@ func (lru *Cache[K, V]) Capacity() uint64 {
 	return lru.capacity
 }
 
-// Size returns the current total size of all entries in the cache.
+// Size returns the current total size of all entries in the cache. It doesn't take the lock, so is cheap to call
+// frequently, e.g. for metrics.
 func (lru *Cache[K, V]) Size() uint64 {
-	lru.lock.RLock()
-	s := lru.size
-	lru.lock.RUnlock()
-	return s
+	return lru.sizeGauge.Load()
 }
 
-// EntryCount returns the number of entries currently stored in the cache.
+// EntryCount returns the number of entries currently stored in the cache. It doesn't take the lock, so is cheap to
+// call frequently, e.g. for metrics.
 func (lru *Cache[K, V]) EntryCount() uint64 {
-	lru.lock.RLock()
-	l := len(lru.cache)
+	return lru.entryGauge.Load()
+}
+
+// updateGauges copies the cache's size and entry count for Size and EntryCount, once they've changed.
+// Assumes the lock is already acquired.
+func (lru *Cache[K, V]) updateGauges() {
+	lru.sizeGauge.Store(lru.size)
+	lru.entryGauge.Store(uint64(len(lru.cache)))
+}
+
+// Sync blocks until all queued events, such as the promotions of recently read entries, have been applied to the
+// list. With a non-zero buffer, the LRU order is otherwise only eventually consistent with the operations made.
+// It returns immediately if the cache is closed.
+func (lru *Cache[K, V]) Sync() {
+	lru.readLock(OperationOther)
+	if !lru.stopped {
+		// Events are processed in order, so once this has run, everything sent before it has too.
+		lru.runOnEventLoop(func() {})
+	}
 	lru.lock.RUnlock()
-	return uint64(l)
 }
 
 // Close gracefully shuts down the cache, stopping background operations.
 func (lru *Cache[K, V]) Close() {
+	_ = lru.CloseCtx(context.Background())
+}
+
+// CloseCtx shuts down the cache as for Close, stopping the periodic purge, applying any queued events and waiting
+// for the event goroutine to exit. If ctx is done first, its error is returned and the shutdown carries on in the
+// background; a later call to CloseCtx or Close waits for it to finish.
+func (lru *Cache[K, V]) CloseCtx(ctx context.Context) error {
 	lru.close.Do(func() {
-		if lru.purgeInterval > 0 {
-			// We need this to block so we don't close the channel until the purge is done.
-			lru.done <- true
+		lru.spawn(lru.shutdown)
+	})
+
+	select {
+	case <-lru.shutdownDone:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// shutdown stops the cache's background work and closes the events channel, then closes shutdownDone once the event
+// goroutine has drained the channel and exited.
+func (lru *Cache[K, V]) shutdown() {
+	defer close(lru.shutdownDone)
+
+	lru.lifecycle.Lock()
+	lru.closed = true
+	lru.lifecycle.Unlock()
+	lru.halted.Store(true)
+
+	// We need this to block so we don't close the channel until the purge is done.
+	close(lru.done)
+	lru.background.Wait()
+
+	// Operations check stopped after taking the lock, so none can send on the channel after it's closed.
+	lru.writeLock(OperationOther)
+	lru.stopped = true
+	close(lru.events)
+	lru.lock.Unlock()
+
+	// Woken once stopped, so they see the cache is closed.
+	lru.waiters.notifyAll()
+
+	if !lru.opts.strictConsistency {
+		<-lru.processed
+	}
+
+	if lru.expired != nil {
+		lru.expired.close()
+	}
+}
+
+// Reset removes every entry from the cache, with EvictionReasonDeleted. If the cache has been closed, it's reopened
+// instead: any entries left are discarded without callbacks, and its background goroutines are restarted, so that
+// long-lived components can recycle the same instance. Reopening must not run concurrently with Close or Run.
+func (lru *Cache[K, V]) Reset() {
+	lru.lifecycle.Lock()
+	closed := lru.closed
+	lru.lifecycle.Unlock()
+
+	if closed {
+		lru.reopen()
+		return
+	}
+
+	lru.writeLock(OperationDelete)
+	if lru.stopped {
+		lru.lock.Unlock()
+		return
+	}
+	lru.runOnEventLoop(func() {
+		for _, n := range lru.cache {
+			lru.removeNode(n, EvictionReasonDeleted)
 		}
-		close(lru.events)
 	})
+	removed := lru.takeRemovals()
+	lru.lock.Unlock()
+
+	lru.notifyRemovals(removed)
+}
+
+// reopen returns a closed cache to its initial, empty state, once its shutdown has completed, and restarts it.
+func (lru *Cache[K, V]) reopen() {
+	<-lru.shutdownDone
+
+	lru.writeLock(OperationOther)
+	defer lru.lock.Unlock()
+
+	lru.cache = make(map[K]*node[K, V])
+	lru.tags = make(map[string]*tagList[K, V])
+	lru.tenants = make(map[string]*tagList[K, V])
+	lru.head.next = lru.tail
+	lru.tail.previous = lru.head
+	lru.size = 0
+	lru.updateGauges()
+	lru.limit = lru.capacity
+	lru.nextPurge.Store(0)
+	lru.purgeCursor = nil
+	lru.scan = nil
+	lru.overflow.take()
+	lru.forgetFailures()
+	lru.index.Clear()
+	for _, s := range lru.shadows {
+		s.clear()
+	}
+	for i := range lru.reads {
+		lru.reads[i].nodes = nil
+	}
+	lru.sizeCounts = [SizeBuckets]uint64{}
+	lru.length = 0
+
+	lru.done = make(chan struct{})
+	lru.events = make(chan event[K, V], lru.opts.buffer)
+	lru.processed = make(chan struct{})
+	lru.shutdownDone = make(chan struct{})
+	lru.close = sync.Once{}
+
+	if lru.expired != nil {
+		lru.expired = newExpiryBatcher(lru.expired.fn, lru.expired.max, lru.expired.delay, lru.expired.safely, lru.expired.unattended)
+	}
+
+	lru.lifecycle.Lock()
+	lru.closed = false
+	lru.lifecycle.Unlock()
+
+	lru.stopped = false
+	lru.halted.Store(false)
+	lru.start()
 }
 
 // Set adds a key-value pair to the cache with a default size of 1 and no expiry.
@@ -162,85 +644,416 @@ func (lru *Cache[K, V]) SetWithExpiry(k K, v V, expires time.Time) error {
 // SetWithSizeAndExpiry adds a key-value pair to the cache with a specified size and expiry time.
 // If the size exceeds the cache's capacity or the expiry time is in the past, an error is returned.
 func (lru *Cache[K, V]) SetWithSizeAndExpiry(k K, v V, size uint64, expires time.Time) error {
+	return lru.set(context.Background(), k, v, entryOptions{size: size, expires: expires})
+}
+
+// SetWithOptions adds a key-value pair to the cache, configured by the given EntryOptions.
+// Without any options, the entry has a size of 1 and no expiry.
+func (lru *Cache[K, V]) SetWithOptions(k K, v V, opts ...EntryOption) error {
+	eo := entryOptions{size: 1}
+	for _, opt := range opts {
+		opt(&eo)
+	}
+	return lru.set(context.Background(), k, v, eo)
+}
+
+// set adds a key-value pair to the cache, as configured by eo.
+// If ctx is done before the lock is acquired, ctx's error is returned; once acquired, the set always completes.
+func (lru *Cache[K, V]) set(ctx context.Context, k K, v V, eo entryOptions) error {
+	_, _, err := lru.swap(ctx, k, v, eo)
+	return err
+}
+
+// swap adds a key-value pair to the cache as set does, returning the node it replaced, if any and not invalidated by
+// an epoch, and the node stored. stored is nil if the entry was refused by the doorkeeper or, with eo.ifAbsent, if an unexpired entry exists, in
+// which case it's returned as existing.
+func (lru *Cache[K, V]) swap(ctx context.Context, k K, v V, eo entryOptions) (existing, stored *node[K, V], err error) {
+	if lru.latency != nil {
+		defer lru.latency.set.since(time.Now())
+	}
+	lru.touch()
 
-	if size == 0 {
-		return fmt.Errorf("%w: item size = %d", ErrItemTooSmall, size)
+	size := eo.size
+	if !eo.negative {
+		size = lru.weigh(k, v, size)
 	}
+	now := time.Now()
 
-	if size > lru.capacity {
-		return fmt.Errorf("%w: item size = %d. cache capacity = %d", ErrItemTooBig, size, lru.capacity)
+	// Negative entries always hold the zero value, so are exempt from the expiry policy and nil checks.
+	expires := eo.expires
+	if !eo.negative {
+		expires = lru.expiry.ExpireAfterWrite(k, v, expires, now)
+		if expires, err = lru.checkNil(v, expires); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := lru.validate(size, expires); err != nil {
+		lru.log(slog.LevelDebug, "lrucache: rejected entry", "key", k, "error", err)
+		lru.notifyRejected(k, size, err)
+		return nil, nil, err
+	}
+	expires = lru.jitter(expires)
+
+	tenant := lru.tenantOf(k, eo.tenant)
+	if err := lru.checkQuota(tenant, size); err != nil {
+		lru.notifyRejected(k, size, err)
+		return nil, nil, err
 	}
 
-	if !expires.IsZero() && expires.Before(time.Now()) {
-		return fmt.Errorf("%w. expires is set to %s, but the current time is %s", ErrPastExpiry, expires.Format(DateTime), time.Now().Format(DateTime))
+	if !eo.fromLoad {
+		lru.supersedeLoad(k)
 	}
 
 	n := &node[K, V]{
-		key:     k,
-		value:   v,
-		size:    size,
-		expires: expires,
+		key:      k,
+		value:    v,
+		size:     size,
+		created:  now,
+		expires:  expires,
+		metadata: eo.metadata,
+		negative: eo.negative,
+		cost:     eo.cost,
+	}
+
+	// Hashed before taking the lock, as it may be slow for some key types.
+	var h uint64
+	if lru.doorkeeper != nil {
+		h = lru.hasher.hash(k)
+	}
+
+	if err := lru.writeLockCtx(ctx, OperationSet); err != nil {
+		return nil, nil, err
+	}
+	if lru.stopped {
+		lru.lock.Unlock()
+		return nil, nil, fmt.Errorf("%w: unable to set key %v", ErrCacheClosed, k)
+	}
+	lru.takeEvicted()
+
+	if lru.doorkeeper != nil {
+		if _, found := lru.cache[k]; !found && !lru.doorkeeper.admit(h) {
+			lru.lock.Unlock()
+			lru.log(slog.LevelDebug, "lrucache: entry refused by doorkeeper", "key", k)
+			return nil, nil, nil
+		}
 	}
 
-	lru.lock.Lock()
+	// Make space in batches first, releasing the lock between them so other operations aren't stalled.
+	if lru.opts.evictionYield {
+		for !lru.evictBatch(n) {
+			removed := lru.takeRemovals()
+			lru.lock.Unlock()
+
+			lru.notifyRemovals(removed)
+
+			lru.writeLock(OperationSet)
+			if lru.stopped {
+				lru.lock.Unlock()
+				return nil, nil, fmt.Errorf("%w: unable to set key %v", ErrCacheClosed, k)
+			}
+		}
+	}
 
+	if eo.ifAbsent {
+		if e, found := lru.cache[k]; found && !e.negative && !lru.isExpired(e, now) {
+			if lru.shouldPromote(e) {
+				lru.dispatch(event[K, V]{a: EventActionAddToFront, n: e, hit: true})
+			}
+			lru.lock.Unlock()
+			return e, nil, nil
+		}
+	}
+
+	if lru.opts.keepExpiryOnUpdate && eo.expires.IsZero() && !eo.negative {
+		if e, found := lru.cache[k]; found && !e.negative && !lru.isExpired(e, now) {
+			n.expires = e.expires
+		}
+	}
+
+	// Checked last, as the lock may have been released while making space.
+	if eo.checkVersion {
+		if current := lru.versionLocked(k); current != eo.version {
+			lru.lock.Unlock()
+			return nil, nil, fmt.Errorf("%w: expected version %d, but the current version is %d", ErrVersionConflict, eo.version, current)
+		}
+	}
+
+	// Invalidated entries are reported as missing, as by Get; checked first, as replacing the entry drops its tags.
+	stale := false
+	if e, found := lru.cache[k]; found {
+		stale = lru.isStale(e)
+	}
+	existing = lru.insertLocked(n, eo.tags, tenant)
+	if stale {
+		existing = nil
+	}
+
+	// Move the new node to the front of the list, or the tail with WithInsertAtTail.
+	if eo.atTail {
+		lru.runOnEventLoop(func() {
+			lru.addNodeToTail(n)
+			lru.creditCost(n)
+		})
+	} else {
+		lru.dispatch(event[K, V]{a: EventActionAddToFront, n: n})
+	}
+
+	removed := lru.takeRemovals()
+	evicted := lru.takeEvicted()
+	lru.lock.Unlock()
+
+	lru.notifyRemovals(removed)
+	lru.notifyEvicted(k, size, evicted)
+
+	return existing, n, nil
+}
+
+// insertLocked adds n to the map, replacing and returning any existing node for its key, and making space for it
+// by evicting from the tail. It's given the tags, if any, and joins the tenant, if it has a quota, and must then be
+// sent to the front of the list.
+// Assumes the lock is already acquired.
+func (lru *Cache[K, V]) insertLocked(n *node[K, V], tags []string, tenant string) *node[K, V] {
 	// Remove the old entry if it exists.
-	if existing, found := lru.cache[k]; found {
-		lru.deleteNode(existing)
+	existing, found := lru.cache[n.key]
+	if found {
+		lru.deleteNode(existing, EvictionReasonReplaced)
 	}
 
-	spaceAvailable := lru.capacity - lru.size
-	if spaceAvailable < size {
+	// Tagging may evict other entries with the same tag or tenant, so is done before checking for space.
+	if len(tags) > 0 || lru.hasQuota(tenant) {
+		lru.runOnEventLoop(func() {
+			lru.addTags(n, tags)
+			lru.addTenant(n, tenant)
+		})
+	}
+
+	if _, evict := lru.evictionTarget(n.size); evict {
 		if PurgeExpiredEventsWhenCacheIsFull {
-			lru.events <- event[K, V]{a: EventActionRemoveExpired}
+			lru.dispatch(event[K, V]{a: EventActionRemoveExpired})
 		}
 
-		wg := &sync.WaitGroup{}
-		wg.Add(1)
-		lru.events <- event[K, V]{a: EventActionMakeSpaceFor, n: n, finished: wg}
-		wg.Wait()
+		if lru.opts.evictionBatch > 0 {
+			// Each batch is a separate event, so queued events can be processed between them.
+			for !lru.evictBatch(n) {
+			}
+		} else {
+			lru.dispatch(event[K, V]{a: EventActionMakeSpaceFor, n: n})
+		}
 	}
 
 	// Add the new node to the cache and update the size.
-	lru.cache[k] = n
+	lru.version++
+	n.version = lru.version
+	lru.cache[n.key] = n
 	lru.size = lru.size + n.size
+	lru.updateGauges()
+	if lru.opts.lockFreeReads {
+		lru.index.Store(n.key, n)
+	}
+	lru.sizeCounts[sizeBucket(n.size)]++
+	lru.noteExpiry(n.expires)
+	lru.scanStored(n)
+	lru.auditStored(n)
+	lru.shadowStore(n)
+	lru.waiters.notify(n.key)
+	return existing
+}
 
-	lru.lock.Unlock()
-
-	// Move the new node to the front of the list.
-	lru.events <- event[K, V]{a: EventActionAddToFront, n: n}
-	return nil
+// Swap adds a key-value pair to the cache with a default size of 1 and no expiry, returning the value it replaced.
+// existed is false if there was no unexpired entry for the key. The replacement is atomic, so no other write can
+// happen between reading the old value and storing the new one.
+func (lru *Cache[K, V]) Swap(k K, v V) (old V, existed bool, err error) {
+	n, _, err := lru.swap(context.Background(), k, v, entryOptions{size: 1})
+	if err != nil || n == nil || n.negative || n.isExpired(time.Now()) {
+		return lru.emptyV, false, err
+	}
+	return n.value, true, nil
 }
 
 // Get retrieves the value associated with the given key from the cache.
 // If the key does not exist or has expired, the zero value for the value type is returned.
+// The returned bool reports whether the key was found, so a stored zero value can be told apart from a missing key.
 func (lru *Cache[K, V]) Get(k K) (V, bool) {
-	lru.lock.RLock()
+	n, found, _ := lru.get(context.Background(), k)
+	if !found || n.negative {
+		return lru.emptyV, false
+	}
+	return n.value, true
+}
+
+// Lookup retrieves the value associated with the given key, as Get does, but returns an error instead of a bool.
+// The error wraps ErrKeyNotFound if there's no unexpired entry for the key, or ErrCacheClosed if the cache is closed.
+func (lru *Cache[K, V]) Lookup(k K) (V, error) {
+	n, found, err := lru.get(context.Background(), k)
+	if err != nil {
+		return lru.emptyV, err
+	}
+	if !found || n.negative {
+		return lru.emptyV, fmt.Errorf("%w: key %v", ErrKeyNotFound, k)
+	}
+	return n.value, nil
+}
+
+// GetWithMetadata retrieves the value associated with the given key, as Get does, along with the metadata attached to
+// the entry by WithMetadata, if any, so wrappers needn't wrap every value in their own struct to carry it.
+func (lru *Cache[K, V]) GetWithMetadata(k K) (V, any, bool) {
+	n, found, _ := lru.get(context.Background(), k)
+	if !found || n.negative {
+		return lru.emptyV, nil, false
+	}
+	return n.value, n.metadata, true
+}
+
+// get returns the unexpired node for the given key, moving it to the front of the list.
+// The node may be a negative-cache entry. An error is only returned if ctx is done before the lock is acquired,
+// or the cache is closed.
+func (lru *Cache[K, V]) get(ctx context.Context, k K) (*node[K, V], bool, error) {
+	return lru.getWithPromotion(ctx, k, PromoteDefault)
+}
+
+// getWithPromotion returns the unexpired node for the given key, as get does, moving it to the front of the list
+// as decided by p.
+func (lru *Cache[K, V]) getWithPromotion(ctx context.Context, k K, p Promotion) (*node[K, V], bool, error) {
+	if lru.latency != nil {
+		defer lru.latency.get.since(time.Now())
+	}
+	lru.touch()
+
+	if lru.lockFree() {
+		if n, found := lru.getLockFree(k, p); found {
+			return n, true, nil
+		}
+	}
+
+	if err := lru.readLockCtx(ctx, OperationGet); err != nil {
+		return nil, false, err
+	}
+	if lru.stopped {
+		lru.lock.RUnlock()
+		return nil, false, fmt.Errorf("%w: unable to get key %v", ErrCacheClosed, k)
+	}
 	n, found := lru.cache[k]
-	lru.lock.RUnlock()
 
 	if !found || n == nil {
-		return lru.emptyV, false
+		lru.lock.RUnlock()
+		lru.recordLookup(k, false)
+		return nil, false, nil
 	}
 
 	// Check if the node has expired.
-	if !n.expires.IsZero() && n.expires.Before(time.Now()) {
+	now := time.Now()
+	if lru.isExpired(n, now) {
+		lru.lock.RUnlock()
 		// We'll opt to not remove the expired node here in returning for a quicker return.
 		// We say found is false as we treat expired nodes as if they don't exist from the caller's perspective.
-		return lru.emptyV, false
+		lru.recordLookup(k, false)
+		return nil, false, nil
 	}
 
-	// Move the accessed node to the front of the list.
-	lru.events <- event[K, V]{a: EventActionAddToFront, n: n}
-	return n.value, true
+	// Move the accessed node to the front of the list, unless reads leave the order unchanged.
+	if lru.promoteRead(n, p) {
+		lru.promote(ctx, event[K, V]{a: EventActionAddToFront, n: n, hit: true})
+	}
+
+	// Compared while holding the read lock, as the expiry may be changed by a concurrent read.
+	expires := lru.expiry.ExpireAfterRead(n.key, n.value, n.expires, now)
+	extend := !expires.Equal(n.expires)
+	lru.lock.RUnlock()
+
+	if extend {
+		lru.extendAfterRead([]*node[K, V]{n}, []time.Time{expires})
+	}
+	lru.recordLookup(k, true)
+
+	return n, true, nil
+}
+
+// Contains reports whether an unexpired entry exists for the given key, without affecting its LRU position.
+func (lru *Cache[K, V]) Contains(k K) bool {
+	lru.readLock(OperationGet)
+	defer lru.lock.RUnlock()
+
+	n, found := lru.cache[k]
+	return found && n != nil && !n.negative && !lru.isExpired(n, time.Now())
+}
+
+// Entry returns the unexpired entry for the given key, including its size and expiry, without affecting its
+// LRU position.
+func (lru *Cache[K, V]) Entry(k K) (Entry[K, V], bool) {
+	lru.readLock(OperationGet)
+	defer lru.lock.RUnlock()
+
+	n, found := lru.cache[k]
+	if !found || n == nil || n.negative || lru.isExpired(n, time.Now()) {
+		return Entry[K, V]{}, false
+	}
+
+	return n.entry(), true
+}
+
+// GetExpired returns the entry for k even if it has expired, or been invalidated, flagging it as expired, e.g. so
+// error handling can report the last known value when the origin is unavailable. An expired entry is only
+// available until it's purged. The entry isn't made current again, and its LRU position and the stats are
+// unaffected.
+func (lru *Cache[K, V]) GetExpired(k K) (e Entry[K, V], expired bool, found bool) {
+	lru.readLock(OperationGet)
+	defer lru.lock.RUnlock()
+
+	n, found := lru.cache[k]
+	if !found || n == nil || n.negative {
+		return Entry[K, V]{}, false, false
+	}
+
+	return n.entry(), lru.isExpired(n, time.Now()), true
 }
 
 // Delete removes the entry associated with the given key from the cache if it exists.
 func (lru *Cache[K, V]) Delete(k K) {
-	lru.lock.Lock()
+	_, _ = lru.delete(context.Background(), k)
+}
+
+// GetAndDelete removes the entry for the given key, returning its value, in a single locked operation.
+// Of concurrent calls for the same key, only one will find the entry, so a value can be consumed exactly once.
+// found is false if there was no unexpired entry for the key; an expired entry is still removed.
+func (lru *Cache[K, V]) GetAndDelete(k K) (V, bool) {
+	n, _ := lru.delete(context.Background(), k)
+	if n == nil || n.negative || n.isExpired(time.Now()) {
+		return lru.emptyV, false
+	}
+	return n.value, true
+}
+
+// DeleteExpired removes all expired entries from the cache, as the periodic purge does, returning the number removed.
+// This lets applications without a purge interval reclaim space from expired entries at convenient times, such as
+// between bursts of requests. It makes a full pass over the cache, holding the lock.
+func (lru *Cache[K, V]) DeleteExpired() int {
+	return lru.purge().removed
+}
+
+// delete removes the entry for k, returning the removed node, if any and not invalidated by an epoch, unless ctx is
+// done before the lock is acquired.
+func (lru *Cache[K, V]) delete(ctx context.Context, k K) (*node[K, V], error) {
+	lru.supersedeLoad(k)
+
+	if err := lru.writeLockCtx(ctx, OperationDelete); err != nil {
+		return nil, err
+	}
+	if lru.stopped {
+		lru.lock.Unlock()
+		return nil, fmt.Errorf("%w: unable to delete key %v", ErrCacheClosed, k)
+	}
 	n, found := lru.cache[k]
 	if found {
-		lru.deleteNode(n)
+		stale := lru.isStale(n)
+		lru.deleteNode(n, EvictionReasonDeleted)
+		if stale {
+			n = nil
+		}
 	}
+	removed := lru.takeRemovals()
 	lru.lock.Unlock()
+
+	lru.notifyRemovals(removed)
+	return n, nil
 }
@@ -1,12 +1,16 @@
 package lrucache
 
 import (
+	"context"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"io"
+	"math"
 	"math/rand"
 	"slices"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -71,6 +75,34 @@ func TestCache_SetAndGetBasic(t *testing.T) {
 	assert.False(t, nonExistentFound)
 }
 
+func TestCache_ZeroValuesAreFound(t *testing.T) {
+	// Ensures that a stored zero value is reported as found, and can be told apart from a missing key.
+
+	cache := NewCache[string, int](10)
+	defer cache.Close()
+
+	err := cache.Set("zero", 0)
+	assert.NoError(t, err)
+
+	value, found := cache.Get("zero")
+	assert.Equal(t, 0, value)
+	assert.True(t, found)
+	assert.True(t, cache.Contains("zero"))
+
+	value, found = cache.Get("missing")
+	assert.Equal(t, 0, value)
+	assert.False(t, found)
+	assert.False(t, cache.Contains("missing"))
+
+	structs := NewCache[string, struct{}](10)
+	defer structs.Close()
+
+	err = structs.Set("present", struct{}{})
+	assert.NoError(t, err)
+	assert.True(t, structs.Contains("present"))
+	assert.False(t, structs.Contains("missing"))
+}
+
 func TestCache_EvictsOldEntries(t *testing.T) {
 	// Tests the cache's eviction policy by filling the cache beyond its capacity and verifying that the oldest
 	//entries are evicted while the most recent entries are retained.
@@ -235,6 +267,37 @@ func TestCache_GetExpiredEntry(t *testing.T) {
 	assert.False(t, found)
 }
 
+func TestCache_GetExpired(t *testing.T) {
+	// Checks an expired entry can still be retrieved, flagged as expired, without being made current again.
+
+	cache := NewCache[string, int](3)
+	defer cache.Close()
+
+	require.NoError(t, cache.SetWithExpiry("a", 1, time.Now().Add(20*time.Millisecond)))
+	require.NoError(t, cache.Set("b", 2))
+
+	e, expired, found := cache.GetExpired("a")
+	assert.True(t, found)
+	assert.False(t, expired)
+	assert.Equal(t, 1, e.Value)
+
+	time.Sleep(40 * time.Millisecond)
+
+	e, expired, found = cache.GetExpired("a")
+	assert.True(t, found)
+	assert.True(t, expired)
+	assert.Equal(t, 1, e.Value)
+	assert.False(t, cache.Contains("a"))
+
+	_, _, found = cache.GetExpired("missing")
+	assert.False(t, found)
+
+	// Once purged, it's gone.
+	cache.DeleteExpired()
+	_, _, found = cache.GetExpired("a")
+	assert.False(t, found)
+}
+
 func TestCache_SetWithBuffer(t *testing.T) {
 	//Tests the functionality of setting values with an internal buffer size and ensures MRU ordering
 	//is maintained for recently accessed entries.
@@ -459,3 +522,529 @@ func TestCache_Something(t *testing.T) {
 	defer cache.Close()
 
 }
+
+func TestCache_NilValues(t *testing.T) {
+	// Checks that nil values can be rejected, or given a short TTL, while non-nil values are unaffected.
+
+	cache := NewCacheWithOptions[int, *string](10, WithRejectNilValues())
+	defer cache.Close()
+
+	err := cache.Set(1, nil)
+	assert.ErrorIs(t, err, ErrNilValue)
+	assert.False(t, cache.Contains(1))
+
+	v := "value"
+	err = cache.Set(1, &v)
+	assert.NoError(t, err)
+	assert.True(t, cache.Contains(1))
+
+	//---
+
+	negative := NewCacheWithOptions[int, []byte](10, WithNilValueTTL(50*time.Millisecond))
+	defer negative.Close()
+
+	assert.NoError(t, negative.Set(1, nil))
+	assert.NoError(t, negative.Set(2, []byte{}))
+	assert.True(t, negative.Contains(1))
+
+	time.Sleep(100 * time.Millisecond)
+
+	assert.False(t, negative.Contains(1))
+	assert.True(t, negative.Contains(2))
+}
+
+func TestCache_Swap(t *testing.T) {
+	// Checks that Swap returns the value it replaced, treating expired entries as absent.
+
+	cache := NewCache[string, int](10)
+	defer cache.Close()
+
+	old, existed, err := cache.Swap("a", 1)
+	assert.NoError(t, err)
+	assert.False(t, existed)
+	assert.Equal(t, 0, old)
+
+	old, existed, err = cache.Swap("a", 2)
+	assert.NoError(t, err)
+	assert.True(t, existed)
+	assert.Equal(t, 1, old)
+
+	v, _ := cache.Get("a")
+	assert.Equal(t, 2, v)
+	assert.Equal(t, uint64(1), cache.Size())
+
+	assert.NoError(t, cache.SetWithExpiry("b", 1, time.Now().Add(time.Millisecond)))
+	time.Sleep(5 * time.Millisecond)
+
+	_, existed, err = cache.Swap("b", 2)
+	assert.NoError(t, err)
+	assert.False(t, existed)
+}
+
+func TestCache_GetAndDelete(t *testing.T) {
+	// Ensures that of many concurrent GetAndDelete calls for a key, exactly one receives its value.
+
+	cache := NewCache[string, int](10)
+	defer cache.Close()
+
+	assert.NoError(t, cache.Set("token", 42))
+
+	var wg sync.WaitGroup
+	var taken atomic.Int32
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, found := cache.GetAndDelete("token"); found {
+				assert.Equal(t, 42, v)
+				taken.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), taken.Load())
+	assert.False(t, cache.Contains("token"))
+	assert.Equal(t, uint64(0), cache.Size())
+}
+
+func TestCache_Closed(t *testing.T) {
+	// Checks that operations after Close fail gracefully, rather than panicking on the closed events channel.
+
+	cache := NewCache[string, int](10)
+	assert.NoError(t, cache.Set("a", 1))
+	cache.Close()
+	cache.Close()
+
+	assert.ErrorIs(t, cache.Set("b", 2), ErrCacheClosed)
+	assert.ErrorIs(t, cache.SetWithOptions("b", 2, WithTags("t")), ErrCacheClosed)
+	assert.ErrorIs(t, cache.SetAll(map[string]int{"b": 2}), ErrCacheClosed)
+	assert.ErrorIs(t, cache.SaveTo(io.Discard), ErrCacheClosed)
+
+	_, found := cache.Get("a")
+	assert.False(t, found)
+	assert.Empty(t, cache.GetMulti([]string{"a"}))
+
+	_, _, err := cache.GetCtx(context.Background(), "a")
+	assert.ErrorIs(t, err, ErrCacheClosed)
+
+	_, err = cache.GetOrLoad(context.Background(), "a", func(ctx context.Context, k string) (int, time.Time, error) {
+		return 1, time.Time{}, nil
+	})
+	assert.ErrorIs(t, err, ErrCacheClosed)
+
+	_, err = cache.Compute("a", func(old int, found bool) (int, bool) { return old + 1, true })
+	assert.ErrorIs(t, err, ErrCacheClosed)
+	assert.False(t, cache.CompareAndSwap("a", 1, 2))
+
+	_, _, found = cache.RemoveOldest()
+	assert.False(t, found)
+	_, found = cache.PeekOldest()
+	assert.False(t, found)
+	assert.Equal(t, 0, cache.DeleteTag("t"))
+
+	cache.Delete("a")
+	_, found = cache.GetAndDelete("a")
+	assert.False(t, found)
+}
+
+func TestCache_Sync(t *testing.T) {
+	// Checks that after Sync, promotions queued in the event buffer have been applied to the list.
+
+	cache := NewCacheWithOptions[int, string](10, WithBuffer(100))
+
+	for i := 1; i <= 3; i++ {
+		assert.NoError(t, cache.Set(i, fmt.Sprintf("value-%d", i)))
+	}
+	cache.Get(1)
+
+	cache.Sync()
+	assert.Equal(t, 1, cache.head.next.key)
+	assert.Equal(t, 2, cache.tail.previous.key)
+
+	// Returns immediately once closed.
+	cache.Close()
+	cache.Sync()
+}
+
+func TestCache_StrictConsistency(t *testing.T) {
+	// Checks that with strict consistency, the list is updated before each operation returns, including under
+	// concurrent access, and that the cache works without being closed.
+
+	cache := NewCacheWithOptions[int, string](10, WithStrictConsistency())
+
+	wg := &sync.WaitGroup{}
+	for i := 1; i <= 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, cache.SetWithOptions(i, fmt.Sprintf("value-%d", i), WithTags("all")))
+			cache.Get(i)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, uint64(10), cache.EntryCount())
+	assert.Equal(t, 10, cache.TagCount("all"))
+
+	assert.NoError(t, cache.Set(1000, "a"))
+	assert.NoError(t, cache.Set(1001, "b"))
+	cache.Get(1000)
+	assert.Equal(t, 1000, cache.head.next.key)
+
+	oldest, found := cache.PeekOldest()
+	assert.True(t, found)
+	assert.NotEqual(t, 1000, oldest.Key)
+	assert.NotEqual(t, 1001, oldest.Key)
+}
+
+func TestCache_CloseCtx(t *testing.T) {
+	// Checks that CloseCtx reports a timeout if the shutdown can't complete in time, and carries on in the background.
+
+	cache := NewCacheWithOptions[int, string](10, WithPurgeInterval(time.Millisecond))
+	assert.NoError(t, cache.Set(1, "a"))
+
+	// Holding the lock stops shutdown from closing the events channel.
+	cache.lock.Lock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, cache.CloseCtx(ctx), context.DeadlineExceeded)
+
+	cache.lock.Unlock()
+
+	// Waits for the shutdown that's already underway.
+	assert.NoError(t, cache.CloseCtx(context.Background()))
+	assert.ErrorIs(t, cache.Set(2, "b"), ErrCacheClosed)
+}
+
+func TestCache_Reset(t *testing.T) {
+	// Checks that Reset empties an open cache, notifying callbacks, and reopens a closed one.
+
+	var lock sync.Mutex
+	var evicted []int
+	cache := NewCacheWithOptions[int, string](10, WithPurgeInterval(time.Millisecond), WithOnEvict(func(k int, v string, reason EvictionReason) {
+		lock.Lock()
+		defer lock.Unlock()
+		if reason == EvictionReasonDeleted {
+			evicted = append(evicted, k)
+		}
+	}))
+
+	assert.NoError(t, cache.Set(1, "a"))
+	assert.NoError(t, cache.Set(2, "b"))
+
+	cache.Reset()
+	assert.ElementsMatch(t, []int{1, 2}, evicted)
+	assert.Equal(t, uint64(0), cache.Size())
+	assert.False(t, cache.Contains(1))
+
+	assert.NoError(t, cache.Set(3, "c"))
+	cache.Close()
+	assert.ErrorIs(t, cache.Set(4, "d"), ErrCacheClosed)
+
+	cache.Reset()
+	assert.Len(t, evicted, 2)
+	assert.Equal(t, uint64(0), cache.EntryCount())
+
+	assert.NoError(t, cache.SetWithExpiry(4, "d", time.Now().Add(time.Millisecond)))
+	assert.NoError(t, cache.Set(5, "e"))
+	v, found := cache.Get(5)
+	assert.True(t, found)
+	assert.Equal(t, "e", v)
+
+	// The purge has restarted.
+	assert.Eventually(t, func() bool { return cache.EntryCount() == 1 }, time.Second, time.Millisecond)
+
+	cache.Close()
+}
+
+func TestCache_MaxEntrySize(t *testing.T) {
+	// Checks that entries above the maximum entry size are rejected, leaving the existing entries in place.
+
+	cache := NewCacheWithOptions[int, string](100, WithMaxEntrySize(10))
+	defer cache.Close()
+
+	for i := 1; i <= 9; i++ {
+		assert.NoError(t, cache.SetWithSize(i, "small", 10))
+	}
+
+	err := cache.SetWithSize(100, "huge", 50)
+	assert.ErrorIs(t, err, ErrItemTooBig)
+	assert.Equal(t, uint64(9), cache.EntryCount())
+
+	assert.ErrorIs(t, cache.SetAll(map[int]string{101: "huge"}, WithSize(11)), ErrItemTooBig)
+	assert.NoError(t, cache.SetWithSize(102, "limit", 10))
+}
+
+func TestCache_MaxEntryFraction(t *testing.T) {
+	// Checks that entries above the fraction of the capacity are rejected.
+
+	cache := NewCacheWithOptions[int, string](200, WithMaxEntryFraction(0.1))
+	defer cache.Close()
+
+	assert.NoError(t, cache.SetWithSize(1, "limit", 20))
+	assert.ErrorIs(t, cache.SetWithSize(2, "huge", 21), ErrItemTooBig)
+	assert.Equal(t, uint64(1), cache.EntryCount())
+}
+
+func TestCache_EvictionWatermarks(t *testing.T) {
+	// Checks that exceeding the high watermark evicts down to the low watermark in a single batch.
+
+	var evicted atomic.Int32
+	cache := NewCacheWithOptions[int, string](10, WithEvictionWatermarks(0.9, 0.5), WithOnEvict(func(int, string, EvictionReason) {
+		evicted.Add(1)
+	}))
+	defer cache.Close()
+
+	for i := 1; i <= 9; i++ {
+		assert.NoError(t, cache.Set(i, fmt.Sprintf("value-%d", i)))
+	}
+	assert.Equal(t, uint64(9), cache.Size())
+	assert.Equal(t, int32(0), evicted.Load())
+
+	// Taking the size to 10 passes the high watermark, so it's trimmed to 5 including the new entry.
+	assert.NoError(t, cache.Set(10, "value-10"))
+	assert.Equal(t, uint64(5), cache.Size())
+	assert.Equal(t, int32(5), evicted.Load())
+	assert.False(t, cache.Contains(5))
+	assert.True(t, cache.Contains(6))
+	assert.True(t, cache.Contains(10))
+}
+
+func TestCache_EvictionWatermarksInvalid(t *testing.T) {
+	// Checks watermarks that are out of range, or the wrong way round, are ignored, so eviction frees just enough
+	// space for each entry.
+
+	for _, marks := range [][2]float64{{0.5, 0.9}, {1.5, 0.5}, {0.9, -0.5}, {0.9, 0}, {math.NaN(), 0.5}} {
+		cache := NewCacheWithOptions[int, string](10, WithEvictionWatermarks(marks[0], marks[1]))
+
+		for i := 1; i <= 11; i++ {
+			assert.NoError(t, cache.Set(i, fmt.Sprintf("value-%d", i)))
+		}
+		assert.Equal(t, uint64(10), cache.Size(), marks)
+		assert.False(t, cache.Contains(1), marks)
+		assert.True(t, cache.Contains(2), marks)
+		cache.Close()
+	}
+}
+
+func TestCache_EvictionBatch(t *testing.T) {
+	// Checks that batched eviction, with and without yielding the lock, still makes enough space for a large entry.
+
+	for _, yield := range []bool{false, true} {
+		cache := NewCacheWithOptions[int, string](100, WithEvictionBatch(7, yield))
+
+		for i := 1; i <= 100; i++ {
+			assert.NoError(t, cache.Set(i, fmt.Sprintf("value-%d", i)))
+		}
+
+		assert.NoError(t, cache.SetWithSize(1000, "large", 50))
+		assert.Equal(t, uint64(100), cache.Size())
+		assert.Equal(t, uint64(51), cache.EntryCount())
+		assert.False(t, cache.Contains(50))
+		assert.True(t, cache.Contains(51))
+
+		// Replacing an entry takes its own size into account.
+		assert.NoError(t, cache.SetWithSize(1000, "larger", 60))
+		assert.Equal(t, uint64(100), cache.Size())
+		assert.Equal(t, uint64(41), cache.EntryCount())
+
+		cache.Close()
+	}
+}
+
+func TestCache_TTLJitter(t *testing.T) {
+	// Checks that jittered expiries are spread within the configured fraction of the TTL.
+
+	cache := NewCacheWithOptions[int, string](100, WithTTLJitter(0.2))
+	defer cache.Close()
+
+	now := time.Now()
+	for i := 1; i <= 100; i++ {
+		assert.NoError(t, cache.SetWithOptions(i, "value", WithTTL(100*time.Second)))
+	}
+
+	distinct := make(map[time.Time]struct{})
+	for i := 1; i <= 100; i++ {
+		e, found := cache.Entry(i)
+		assert.True(t, found)
+		assert.WithinRange(t, e.Expires, now.Add(80*time.Second), time.Now().Add(120*time.Second))
+		distinct[e.Expires] = struct{}{}
+	}
+	assert.Greater(t, len(distinct), 1)
+
+	// Entries without an expiry are unaffected.
+	assert.NoError(t, cache.Set(0, "forever"))
+	e, _ := cache.Entry(0)
+	assert.True(t, e.Expires.IsZero())
+}
+
+func TestCache_TTLJitterInvalid(t *testing.T) {
+	// Checks fractions that could give a zero or negative TTL are ignored, leaving TTLs unjittered.
+
+	for _, fraction := range []float64{-0.5, 1, 2, math.NaN()} {
+		cache := NewCacheWithOptions[int, string](10, WithTTLJitter(fraction))
+
+		expires := time.Now().Add(time.Minute)
+		assert.NoError(t, cache.SetWithExpiry(1, "value", expires))
+		e, found := cache.Entry(1)
+		assert.True(t, found, fraction)
+		assert.Equal(t, expires, e.Expires, fraction)
+		cache.Close()
+	}
+}
+
+func TestCache_Lookup(t *testing.T) {
+	// Checks Lookup's errors can be told apart with errors.Is, and carry the key.
+
+	cache := NewCache[string, int](10)
+
+	require.NoError(t, cache.Set("a", 1))
+
+	v, err := cache.Lookup("a")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+
+	_, err = cache.Lookup("missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	assert.Contains(t, err.Error(), "missing")
+
+	cache.Close()
+
+	_, err = cache.Lookup("a")
+	assert.ErrorIs(t, err, ErrCacheClosed)
+	assert.Contains(t, err.Error(), "a")
+
+	err = cache.Set("b", 2)
+	assert.ErrorIs(t, err, ErrCacheClosed)
+	assert.Contains(t, err.Error(), "b")
+}
+
+func TestCache_NoCapacity(t *testing.T) {
+	// Checks a zero capacity, or a zero tenant quota, is reported with ErrNoCapacity rather than ErrItemTooBig.
+
+	empty := NewCache[string, int](0)
+	defer empty.Close()
+	assert.ErrorIs(t, empty.Set("a", 1), ErrNoCapacity)
+
+	cache := NewCacheWithOptions[string, int](10, WithTenantQuota("none", 0))
+	defer cache.Close()
+	assert.ErrorIs(t, cache.SetWithOptions("a", 1, WithTenant("none")), ErrNoCapacity)
+	assert.NoError(t, cache.Set("a", 1))
+}
+
+func TestCache_MaxEntries(t *testing.T) {
+	// Checks entries are evicted when either the entry limit or the capacity would be exceeded.
+
+	cache := NewCacheWithOptions[int, int](100, WithMaxEntries(3))
+	defer cache.Close()
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, cache.Set(i, i))
+	}
+	assert.Equal(t, uint64(3), cache.EntryCount())
+	assert.Equal(t, []int{4, 3, 2}, cache.OrderedKeys())
+
+	// Replacing an entry doesn't need room for another.
+	require.NoError(t, cache.Set(2, 20))
+	assert.Equal(t, []int{2, 4, 3}, cache.OrderedKeys())
+
+	// Size is still limited too.
+	require.NoError(t, cache.SetWithSize(5, 5, 100))
+	assert.Equal(t, []int{5}, cache.OrderedKeys())
+
+	require.NoError(t, cache.SetMulti(map[int]int{10: 0, 11: 0, 12: 0, 13: 0}))
+	assert.Equal(t, uint64(3), cache.EntryCount())
+}
+
+func TestCache_KeepExpiryOnUpdate(t *testing.T) {
+	// Checks a Set without an expiry keeps the replaced entry's, while a Set with one replaces it.
+
+	cache := NewCacheWithOptions[string, int](10, WithKeepExpiryOnUpdate())
+	defer cache.Close()
+
+	expires := time.Now().Add(time.Minute)
+	require.NoError(t, cache.SetWithExpiry("a", 1, expires))
+	require.NoError(t, cache.Set("a", 2))
+
+	e, found := cache.Entry("a")
+	require.True(t, found)
+	assert.Equal(t, 2, e.Value)
+	assert.True(t, expires.Equal(e.Expires))
+
+	later := time.Now().Add(time.Hour)
+	require.NoError(t, cache.SetWithExpiry("a", 3, later))
+	e, _ = cache.Entry("a")
+	assert.True(t, later.Equal(e.Expires))
+
+	// New keys are unaffected.
+	require.NoError(t, cache.Set("b", 1))
+	e, _ = cache.Entry("b")
+	assert.True(t, e.Expires.IsZero())
+}
+
+func TestCache_RenewExpiryOnUpdate(t *testing.T) {
+	// Checks CompareAndSwap and Compute restart an entry's expiry from the policy's TTL, and that the option
+	// overrides an earlier WithKeepExpiryOnUpdate.
+
+	cache := NewCacheWithOptions[string, int](10,
+		WithKeepExpiryOnUpdate(),
+		WithRenewExpiryOnUpdate(),
+		WithExpiryPolicy(ExpireAfterWrite[string, int](time.Hour)),
+	)
+	defer cache.Close()
+
+	soon := time.Now().Add(time.Minute)
+	require.NoError(t, cache.SetWithExpiry("a", 1, soon))
+
+	require.True(t, cache.CompareAndSwap("a", 1, 2))
+	e, found := cache.Entry("a")
+	require.True(t, found)
+	assert.True(t, e.Expires.After(soon.Add(30*time.Minute)))
+
+	require.NoError(t, cache.SetWithExpiry("b", 1, soon))
+	_, err := cache.Compute("b", func(old int, found bool) (int, bool) { return old + 1, true })
+	require.NoError(t, err)
+	e, _ = cache.Entry("b")
+	assert.True(t, e.Expires.After(soon.Add(30*time.Minute)))
+
+	// A plain Set renews it too, rather than keeping it.
+	require.NoError(t, cache.SetWithExpiry("c", 1, soon))
+	require.NoError(t, cache.Set("c", 2))
+	e, _ = cache.Entry("c")
+	assert.True(t, e.Expires.After(soon.Add(30*time.Minute)))
+}
+
+func TestCache_DeleteExpired(t *testing.T) {
+	// Checks only expired entries are removed, and counted.
+
+	cache := NewCache[string, int](10)
+	defer cache.Close()
+
+	require.NoError(t, cache.SetWithExpiry("a", 1, time.Now().Add(5*time.Millisecond)))
+	require.NoError(t, cache.SetWithExpiry("b", 2, time.Now().Add(5*time.Millisecond)))
+	require.NoError(t, cache.SetWithExpiry("c", 3, time.Now().Add(time.Hour)))
+	require.NoError(t, cache.Set("d", 4))
+	time.Sleep(10 * time.Millisecond)
+
+	assert.Equal(t, 2, cache.DeleteExpired())
+	assert.Equal(t, uint64(2), cache.EntryCount())
+	assert.Equal(t, 0, cache.DeleteExpired())
+}
+
+func TestCache_SizeWithoutLock(t *testing.T) {
+	// Checks Size and EntryCount don't wait for the lock, and track writes and removals.
+
+	cache := NewCache[int, string](100)
+	defer cache.Close()
+
+	require.NoError(t, cache.SetWithSize(1, "a", 10))
+	require.NoError(t, cache.SetWithSize(2, "b", 5))
+	cache.Delete(1)
+
+	cache.lock.Lock()
+	assert.Equal(t, uint64(5), cache.Size())
+	assert.Equal(t, uint64(1), cache.EntryCount())
+	cache.lock.Unlock()
+}
@@ -0,0 +1,56 @@
+package lrucache
+
+import "time"
+
+// Cacher is the common interface of Cache and its alternative implementations, such as RotatingCache, so that
+// consumers can take the cache as a dependency, and substitute NopCache to disable caching.
+type Cacher[K comparable, V any] interface {
+	// Get returns the value for k. found is false if there's no unexpired entry for it.
+	Get(k K) (v V, found bool)
+
+	// Set stores the value for k, with no expiry.
+	Set(k K, v V) error
+
+	// SetWithExpiry stores the value for k, expiring at expires (the zero value meaning no expiry).
+	SetWithExpiry(k K, v V, expires time.Time) error
+
+	// Contains reports whether an unexpired entry exists for k.
+	Contains(k K) bool
+
+	// Delete removes the entry for k, if it exists.
+	Delete(k K)
+
+	// Close stops any background work.
+	Close()
+}
+
+// NopCache is a Cacher that stores nothing, so every Get misses. It can be injected in place of a cache to disable
+// caching, in tests or by configuration, without the consumer needing to check.
+type NopCache[K comparable, V any] struct{}
+
+// Get always returns the zero value, and false.
+func (NopCache[K, V]) Get(K) (V, bool) {
+	var empty V
+	return empty, false
+}
+
+// Set discards the value.
+func (NopCache[K, V]) Set(K, V) error {
+	return nil
+}
+
+// SetWithExpiry discards the value.
+func (NopCache[K, V]) SetWithExpiry(K, V, time.Time) error {
+	return nil
+}
+
+// Contains always returns false.
+func (NopCache[K, V]) Contains(K) bool {
+	return false
+}
+
+// Delete does nothing.
+func (NopCache[K, V]) Delete(K) {}
+
+// Close does nothing.
+func (NopCache[K, V]) Close() {}
@@ -0,0 +1,38 @@
+package lrucache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	_ Cacher[string, int] = (*Cache[string, int])(nil)
+	_ Cacher[string, int] = (*ChainedCache[string, int])(nil)
+	_ Cacher[string, int] = (*ClockCache[string, int])(nil)
+	_ Cacher[string, int] = (*EncodedCache[string, int])(nil)
+	_ Cacher[string, int] = (*RotatingCache[string, int])(nil)
+	_ Cacher[string, int] = (*SampledCache[string, int])(nil)
+	_ Cacher[string, int] = (*ShardedCache[string, int])(nil)
+	_ Cacher[string, int] = NopCache[string, int]{}
+
+	_ Cacher[string, []byte] = (*SlabCache)(nil)
+)
+
+func TestNopCache_AlwaysMisses(t *testing.T) {
+	// Checks values given to a NopCache are discarded.
+
+	var cache Cacher[string, int] = NopCache[string, int]{}
+	defer cache.Close()
+
+	assert.NoError(t, cache.Set("a", 1))
+	assert.NoError(t, cache.SetWithExpiry("b", 2, time.Now().Add(time.Minute)))
+
+	v, found := cache.Get("a")
+	assert.False(t, found)
+	assert.Zero(t, v)
+	assert.False(t, cache.Contains("b"))
+
+	cache.Delete("a")
+}
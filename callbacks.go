@@ -0,0 +1,243 @@
+package lrucache
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+)
+
+// EvictionReason describes why an entry was removed from the cache.
+type EvictionReason uint8
+
+const (
+	EvictionReasonCapacity    EvictionReason = iota // Removed from the tail to make space for another entry.
+	EvictionReasonExpired                           // Removed because its expiry time passed.
+	EvictionReasonDeleted                           // Removed by a call to Delete.
+	EvictionReasonReplaced                          // Replaced by a new value for the same key.
+	EvictionReasonTagLimit                          // Removed to keep a tag within WithMaxEntriesPerTag.
+	EvictionReasonQuota                             // Removed to keep its tenant within its quota; see WithTenantQuota.
+	EvictionReasonInvalidated                       // Removed after being invalidated by BumpEpoch or BumpTagEpoch.
+	EvictionReasonRenamed                           // Moved to another key by Rename.
+)
+
+// String returns a human-readable name for the reason.
+func (r EvictionReason) String() string {
+	switch r {
+	case EvictionReasonCapacity:
+		return "capacity"
+	case EvictionReasonExpired:
+		return "expired"
+	case EvictionReasonDeleted:
+		return "deleted"
+	case EvictionReasonReplaced:
+		return "replaced"
+	case EvictionReasonTagLimit:
+		return "tag limit"
+	case EvictionReasonQuota:
+		return "quota"
+	case EvictionReasonInvalidated:
+		return "invalidated"
+	case EvictionReasonRenamed:
+		return "renamed"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(r))
+	}
+}
+
+// CapacityPressureReason describes why the WithOnCapacityPressure callback was called.
+type CapacityPressureReason uint8
+
+const (
+	CapacityPressureRejected  CapacityPressureReason = iota // An entry was rejected with ErrItemTooBig.
+	CapacityPressureEvictions                               // Storing an entry evicted more than the threshold number of others.
+)
+
+// String returns a human-readable name for the reason.
+func (r CapacityPressureReason) String() string {
+	switch r {
+	case CapacityPressureRejected:
+		return "rejected"
+	case CapacityPressureEvictions:
+		return "evictions"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(r))
+	}
+}
+
+// CapacityPressure is passed to the WithOnCapacityPressure callback when the cache shows signs of being too small.
+type CapacityPressure[K comparable] struct {
+	Reason  CapacityPressureReason
+	Key     K      // The key of the entry being stored.
+	Size    uint64 // The size of the entry being stored.
+	Evicted int    // The number of entries evicted to make space for it; zero if it was rejected.
+	Err     error  // The error it was rejected with; nil unless it was rejected.
+}
+
+// PanicError is passed to the error handler when a user-supplied callback panics.
+// It wraps ErrCallbackPanic.
+type PanicError struct {
+	Callback string // The name of the callback that panicked, e.g. "OnEvict".
+	Value    any    // The value passed to panic.
+	Stack    []byte // The stack trace at the point of the panic.
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%s: %s: %v", ErrCallbackPanic, e.Callback, e.Value)
+}
+
+func (e *PanicError) Unwrap() error {
+	return ErrCallbackPanic
+}
+
+// removal records a node removed while the lock was held, so callbacks can be run once it's released.
+type removal[K comparable, V any] struct {
+	n      *node[K, V]
+	reason EvictionReason
+}
+
+// recordRemoval queues the node for the OnEvict callback, if one is configured, and counts capacity evictions for
+// WithThrashAlert.
+// Assumes the lock is already acquired.
+func (lru *Cache[K, V]) recordRemoval(n *node[K, V], reason EvictionReason) {
+	if lru.thrash != nil && reason == EvictionReasonCapacity {
+		lru.thrash.evictions.Add(1)
+	}
+	lru.auditRemoved(n, reason)
+	lru.shadowRemove(n.key, reason)
+	if lru.onEvict != nil || lru.onEvictEntry != nil || lru.evictHook != nil || lru.opts.statsRecorder != nil || ((lru.expired != nil || lru.expiredEntries != nil) && reason == EvictionReasonExpired) {
+		lru.removed = append(lru.removed, removal[K, V]{n: n, reason: reason})
+	}
+}
+
+// takeRemovals returns, and clears, the removals recorded since the last call.
+// Assumes the lock is already acquired.
+func (lru *Cache[K, V]) takeRemovals() []removal[K, V] {
+	removed := lru.removed
+	lru.removed = nil
+	return removed
+}
+
+// notifyRemovals runs the OnEvict callbacks for each removal, and the OnUtilization callback for any thresholds
+// crossed, and writes the audit log. It's called after every write, and must be called without holding the lock.
+func (lru *Cache[K, V]) notifyRemovals(removed []removal[K, V]) {
+	lru.flushAudit()
+	lru.checkUtilization()
+
+	if lru.expired != nil {
+		var keys []K
+		for _, r := range removed {
+			if r.reason == EvictionReasonExpired {
+				keys = append(keys, r.n.key)
+			}
+		}
+		if len(keys) > 0 {
+			lru.expired.add(keys)
+		}
+	}
+
+	if lru.expiredEntries != nil {
+		lru.sendExpired(removed)
+	}
+
+	for _, r := range removed {
+		if recorder := lru.opts.statsRecorder; recorder != nil {
+			_ = lru.safely("StatsRecorder", func() {
+				recorder.RecordEviction(r.reason)
+			})
+		}
+		if lru.evictHook != nil {
+			_ = lru.safely("EvictHook", func() {
+				lru.evictHook(r.n.key, r.n.value, r.n.expires, r.reason)
+			})
+		}
+		if (lru.onEvict != nil || lru.onEvictEntry != nil) && !lru.deferEvictCallbacks(r.n, r.reason) {
+			lru.evictCallbacks(r.n, r.reason)
+		}
+	}
+}
+
+// evictCallbacks runs the OnEvict and OnEvictEntry callbacks for n. It must be called without holding the lock.
+func (lru *Cache[K, V]) evictCallbacks(n *node[K, V], reason EvictionReason) {
+	if lru.onEvict != nil {
+		_ = lru.safely("OnEvict", func() {
+			lru.onEvict(n.key, n.value, reason)
+		})
+	}
+	if lru.onEvictEntry != nil {
+		_ = lru.safely("OnEvictEntry", func() {
+			lru.onEvictEntry(n.entry(), reason)
+		})
+	}
+}
+
+// takeEvicted returns, and clears, the number of entries evicted for capacity since the last call.
+// Assumes the lock is already acquired.
+func (lru *Cache[K, V]) takeEvicted() int {
+	evicted := lru.evicted
+	lru.evicted = 0
+	return evicted
+}
+
+// notifyEvicted runs the OnCapacityPressure callback if storing k evicted more than the configured threshold of
+// entries. It must be called without holding the lock.
+func (lru *Cache[K, V]) notifyEvicted(k K, size uint64, evicted int) {
+	if lru.onCapacityPressure == nil || evicted == 0 || evicted <= lru.opts.capacityPressureThreshold {
+		return
+	}
+	_ = lru.safely("OnCapacityPressure", func() {
+		lru.onCapacityPressure(CapacityPressure[K]{Reason: CapacityPressureEvictions, Key: k, Size: size, Evicted: evicted})
+	})
+}
+
+// notifyRejected runs the OnCapacityPressure callback if err shows k was rejected as too big.
+// It must be called without holding the lock.
+func (lru *Cache[K, V]) notifyRejected(k K, size uint64, err error) {
+	if lru.onCapacityPressure == nil || !errors.Is(err, ErrItemTooBig) {
+		return
+	}
+	_ = lru.safely("OnCapacityPressure", func() {
+		lru.onCapacityPressure(CapacityPressure[K]{Reason: CapacityPressureRejected, Key: k, Size: size, Err: err})
+	})
+}
+
+// safely runs a user-supplied callback, recovering from any panic so it can't take down the cache.
+// A recovered panic is returned as a *PanicError and passed to the error handler. If no error handler is
+// configured, the panic is re-raised, unless the callback was run by background work; see unattended.
+func (lru *Cache[K, V]) safely(callback string, fn func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Callback: callback, Value: r, Stack: debug.Stack()}
+			if lru.opts.errorHandler == nil {
+				panic(err)
+			}
+			lru.opts.errorHandler(err)
+		}
+	}()
+	fn()
+	return nil
+}
+
+// unattended runs fn, part of the cache's background work, where a panic re-raised by safely has no caller to
+// reach and would crash the process. Such panics are logged and returned instead; any other panic is a bug in the
+// cache, so it's re-raised.
+func (lru *Cache[K, V]) unattended(fn func()) (perr *PanicError) {
+	defer func() {
+		if r := recover(); r != nil {
+			var ok bool
+			if perr, ok = r.(*PanicError); !ok {
+				panic(r)
+			}
+			lru.log(slog.LevelError, "lrucache: callback panicked in the background", "callback", perr.Callback, "panic", perr.Value, "stack", string(perr.Stack))
+		}
+	}()
+	fn()
+	return nil
+}
+
+// handleError passes err to the error handler, if one is configured.
+func (lru *Cache[K, V]) handleError(err error) {
+	if lru.opts.errorHandler != nil {
+		lru.opts.errorHandler(err)
+	}
+}
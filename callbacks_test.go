@@ -0,0 +1,193 @@
+package lrucache
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_OnEvict(t *testing.T) {
+	// Checks that the OnEvict callback is run, with the correct reason, for each way an entry can be removed.
+
+	type evicted struct {
+		k      int
+		reason EvictionReason
+	}
+
+	mu := sync.Mutex{}
+	var seen []evicted
+	cache := NewCacheWithOptions[int, string](2, WithOnEvict(func(k int, v string, reason EvictionReason) {
+		mu.Lock()
+		seen = append(seen, evicted{k, reason})
+		mu.Unlock()
+	}))
+	defer cache.Close()
+
+	require.NoError(t, cache.Set(1, "value-1"))
+	require.NoError(t, cache.Set(1, "value-1b"))
+	require.NoError(t, cache.Set(2, "value-2"))
+	require.NoError(t, cache.Set(3, "value-3"))
+	cache.Delete(2)
+
+	assert.Equal(t, []evicted{
+		{1, EvictionReasonReplaced},
+		{1, EvictionReasonCapacity},
+		{2, EvictionReasonDeleted},
+	}, seen)
+}
+
+func TestCache_PanicRecovery(t *testing.T) {
+	// Ensures panics in callbacks are routed to the error handler, and don't stop the cache from working.
+
+	var errs []error
+	cache := NewCacheWithOptions[int, string](1,
+		WithErrorHandler(func(err error) { errs = append(errs, err) }),
+		WithOnEvict(func(k int, v string, reason EvictionReason) { panic("evict") }),
+	)
+	defer cache.Close()
+
+	require.NoError(t, cache.Set(1, "value-1"))
+	require.NoError(t, cache.Set(2, "value-2"))
+
+	_, err := cache.GetOrLoad(context.Background(), 3, func(ctx context.Context, k int) (string, time.Time, error) {
+		panic("load")
+	})
+	assert.ErrorIs(t, err, ErrCallbackPanic)
+
+	require.Len(t, errs, 2)
+	var perr *PanicError
+	assert.True(t, errors.As(errs[0], &perr))
+	assert.Equal(t, "OnEvict", perr.Callback)
+	assert.True(t, errors.As(errs[1], &perr))
+	assert.Equal(t, "Loader", perr.Callback)
+
+	// The cache should still be usable.
+	require.NoError(t, cache.Set(4, "value-4"))
+	v, found := cache.Get(4)
+	assert.True(t, found)
+	assert.Equal(t, "value-4", v)
+}
+
+func TestCache_BackgroundPanic(t *testing.T) {
+	// Checks a callback panicking in the background, with no error handler, is logged rather than crashing the
+	// process, and the cache keeps purging.
+
+	var out syncBuffer
+	var expired atomic.Int32
+	cache := NewCacheWithOptions[int, string](10,
+		WithLogger(slog.New(slog.NewTextHandler(&out, nil))),
+		WithPurgeInterval(time.Millisecond),
+		WithOnEvict(func(k int, v string, reason EvictionReason) {
+			expired.Add(1)
+			panic("evict")
+		}),
+	)
+	defer cache.Close()
+
+	require.NoError(t, cache.SetWithOptions(1, "value-1", WithTTL(time.Millisecond)))
+	assert.Eventually(t, func() bool { return expired.Load() == 1 }, time.Second, time.Millisecond)
+	require.NoError(t, cache.SetWithOptions(2, "value-2", WithTTL(time.Millisecond)))
+	assert.Eventually(t, func() bool { return expired.Load() == 2 }, time.Second, time.Millisecond)
+
+	assert.Contains(t, out.String(), "callback panicked in the background")
+	assert.Contains(t, out.String(), "callback=OnEvict")
+}
+
+func TestCache_OnEvictTypeMismatch(t *testing.T) {
+	assert.Panics(t, func() {
+		NewCacheWithOptions[int, string](1, WithOnEvict(func(k string, v string, reason EvictionReason) {}))
+	})
+}
+
+func TestCache_Metadata(t *testing.T) {
+	// Checks metadata attached at Set is returned by Entry, and passed to the OnEvictEntry callback.
+
+	type source struct {
+		URL string
+	}
+
+	var evicted []Entry[int, string]
+	cache := NewCacheWithOptions[int, string](1, WithOnEvictEntry(func(e Entry[int, string], reason EvictionReason) {
+		evicted = append(evicted, e)
+	}))
+	defer cache.Close()
+
+	require.NoError(t, cache.SetWithOptions(1, "value-1", WithMetadata(source{URL: "https://example.com/1"}), WithSize(1)))
+
+	e, found := cache.Entry(1)
+	assert.True(t, found)
+	assert.Equal(t, source{URL: "https://example.com/1"}, e.Metadata)
+	assert.Equal(t, uint64(1), e.Size)
+
+	require.NoError(t, cache.Set(2, "value-2"))
+	require.Len(t, evicted, 1)
+	assert.Equal(t, 1, evicted[0].Key)
+	assert.Equal(t, source{URL: "https://example.com/1"}, evicted[0].Metadata)
+
+	e, _ = cache.Entry(2)
+	assert.Nil(t, e.Metadata)
+}
+
+func TestCache_GetWithMetadata(t *testing.T) {
+	// Checks metadata is returned alongside the value by a read.
+
+	cache := NewCache[string, int](10)
+	defer cache.Close()
+
+	require.NoError(t, cache.SetWithOptions("a", 1, WithMetadata("etag-1")))
+	require.NoError(t, cache.Set("b", 2))
+
+	v, metadata, found := cache.GetWithMetadata("a")
+	assert.True(t, found)
+	assert.Equal(t, 1, v)
+	assert.Equal(t, "etag-1", metadata)
+	assert.Equal(t, []string{"a", "b"}, cache.OrderedKeys())
+
+	_, metadata, found = cache.GetWithMetadata("b")
+	assert.True(t, found)
+	assert.Nil(t, metadata)
+
+	_, _, found = cache.GetWithMetadata("c")
+	assert.False(t, found)
+}
+
+func TestCache_OnCapacityPressure(t *testing.T) {
+	// Checks the callback is run for rejected entries, and for sets evicting more than the threshold.
+
+	var events []CapacityPressure[string]
+	cache := NewCacheWithOptions[string, int](5, WithOnCapacityPressure(func(p CapacityPressure[string]) {
+		events = append(events, p)
+	}, 2))
+	defer cache.Close()
+
+	err := cache.SetWithSize("huge", 0, 6)
+	assert.ErrorIs(t, err, ErrItemTooBig)
+	require.Len(t, events, 1)
+	assert.Equal(t, CapacityPressureRejected, events[0].Reason)
+	assert.Equal(t, "huge", events[0].Key)
+	assert.Equal(t, uint64(6), events[0].Size)
+	assert.ErrorIs(t, events[0].Err, ErrItemTooBig)
+
+	for _, k := range []string{"a", "b", "c", "d", "e"} {
+		require.NoError(t, cache.Set(k, 0))
+	}
+	assert.Len(t, events, 1)
+
+	// Evicting two is within the threshold.
+	require.NoError(t, cache.SetWithSize("f", 0, 2))
+	assert.Len(t, events, 1)
+
+	require.NoError(t, cache.SetWithSize("g", 0, 4))
+	require.Len(t, events, 2)
+	assert.Equal(t, CapacityPressureEvictions, events[1].Reason)
+	assert.Equal(t, "g", events[1].Key)
+	assert.Equal(t, 4, events[1].Evicted)
+	assert.NoError(t, events[1].Err)
+}
@@ -0,0 +1,85 @@
+package lrucache
+
+import (
+	"errors"
+	"time"
+)
+
+// ChainedCache combines several caches into levels, e.g. a small L1 in front of a larger L2, all in-process. Get
+// queries each level in order, backfilling the earlier levels on a hit, and writes go through to every level.
+type ChainedCache[K comparable, V any] struct {
+	levels []Cacher[K, V]
+}
+
+// Chain returns a ChainedCache querying caches in the order given, so the first should be the smallest and fastest.
+func Chain[K comparable, V any](caches ...Cacher[K, V]) *ChainedCache[K, V] {
+	return &ChainedCache[K, V]{levels: caches}
+}
+
+// Levels returns the caches in the chain, in the order they're queried.
+func (c *ChainedCache[K, V]) Levels() []Cacher[K, V] {
+	return c.levels
+}
+
+// Get returns the value for k from the first level that has it, storing it in each earlier level. The levels
+// backfilled keep the entry's expiry if the level it was found in is a Cache, or another Cacher with an Entry
+// method; otherwise it's stored without one. Failures to backfill are ignored, as the value is still returned.
+func (c *ChainedCache[K, V]) Get(k K) (v V, found bool) {
+	for i, level := range c.levels {
+		if v, found = level.Get(k); !found {
+			continue
+		}
+		var expires time.Time
+		if el, ok := level.(interface{ Entry(K) (Entry[K, V], bool) }); ok {
+			if e, ok := el.Entry(k); ok {
+				expires = e.Expires
+			}
+		}
+		for _, earlier := range c.levels[:i] {
+			_ = earlier.SetWithExpiry(k, v, expires)
+		}
+		return v, true
+	}
+	return v, false
+}
+
+// Set stores the value for k in every level, with no expiry. The errors from any levels that fail are joined.
+func (c *ChainedCache[K, V]) Set(k K, v V) error {
+	return c.SetWithExpiry(k, v, time.Time{})
+}
+
+// SetWithExpiry stores the value for k in every level, expiring at expires (the zero value meaning no expiry). The
+// errors from any levels that fail are joined.
+func (c *ChainedCache[K, V]) SetWithExpiry(k K, v V, expires time.Time) error {
+	var errs []error
+	for _, level := range c.levels {
+		if err := level.SetWithExpiry(k, v, expires); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Contains reports whether any level has an unexpired entry for k.
+func (c *ChainedCache[K, V]) Contains(k K) bool {
+	for _, level := range c.levels {
+		if level.Contains(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// Delete removes the entry for k from every level.
+func (c *ChainedCache[K, V]) Delete(k K) {
+	for _, level := range c.levels {
+		level.Delete(k)
+	}
+}
+
+// Close closes every level.
+func (c *ChainedCache[K, V]) Close() {
+	for _, level := range c.levels {
+		level.Close()
+	}
+}
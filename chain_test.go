@@ -0,0 +1,60 @@
+package lrucache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainedCache_GetBackfills(t *testing.T) {
+	// Checks a hit in a later level is copied, with its expiry, into the earlier levels.
+
+	l1 := NewCache[string, int](2)
+	l2 := NewCache[string, int](10)
+	cache := Chain[string, int](l1, l2)
+	defer cache.Close()
+
+	expires := time.Now().Add(time.Hour)
+	require.NoError(t, l2.SetWithExpiry("a", 1, expires))
+
+	v, found := cache.Get("a")
+	assert.True(t, found)
+	assert.Equal(t, 1, v)
+
+	e, found := l1.Entry("a")
+	require.True(t, found)
+	assert.Equal(t, 1, e.Value)
+	assert.Equal(t, expires, e.Expires)
+
+	_, found = cache.Get("missing")
+	assert.False(t, found)
+}
+
+func TestChainedCache_WritesThrough(t *testing.T) {
+	// Checks writes and deletes apply to every level, and errors from any level are returned.
+
+	l1 := NewCache[string, int](2)
+	l2 := NewCache[string, int](10)
+	cache := Chain[string, int](l1, l2)
+	defer cache.Close()
+
+	require.NoError(t, cache.Set("a", 1))
+	assert.True(t, l1.Contains("a"))
+	assert.True(t, l2.Contains("a"))
+
+	// Evicted from the small L1, but still found in L2.
+	require.NoError(t, cache.Set("b", 2))
+	require.NoError(t, cache.Set("c", 3))
+	assert.False(t, l1.Contains("a"))
+	assert.True(t, cache.Contains("a"))
+
+	cache.Delete("a")
+	assert.False(t, cache.Contains("a"))
+
+	l1.Close()
+	err := cache.Set("d", 4)
+	assert.ErrorIs(t, err, ErrCacheClosed)
+	assert.True(t, l2.Contains("d"))
+}
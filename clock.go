@@ -0,0 +1,164 @@
+package lrucache
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// clockSlot is a slot in a ClockCache's ring.
+type clockSlot[K comparable, V any] struct {
+	key        K
+	value      V
+	expires    time.Time   // Zero means none.
+	used       bool        // False if the slot is free.
+	referenced atomic.Bool // Set by reads, and cleared as the hand passes, giving the entry a second chance.
+}
+
+// ClockCache is a cache approximating LRU with the CLOCK, or second chance, algorithm. Its entries are held in a
+// fixed ring of slots, each with a reference bit that's set when the entry is read. To make space, a hand sweeps
+// the ring, clearing the bits it finds set, and evicting the first entry whose bit was already clear, or that has
+// expired. Entries read since the hand last passed them survive another sweep.
+//
+// There's no list to reorder on reads, which only take a read lock and set a bit, so reads are nearly free and
+// don't contend with each other, suiting large, read-heavy caches. Entries have no size; the capacity is in entries.
+type ClockCache[K comparable, V any] struct {
+	lock  sync.RWMutex
+	index map[K]int         // The slot holding each key.
+	slots []clockSlot[K, V] // The ring.
+	free  []int             // Slots freed by Delete, reused before evicting.
+	hand  int               // The next slot to consider for eviction.
+}
+
+// NewClockCache creates a ClockCache holding up to capacity entries.
+func NewClockCache[K comparable, V any](capacity int) *ClockCache[K, V] {
+	capacity = max(capacity, 1)
+	return &ClockCache[K, V]{
+		index: make(map[K]int, capacity),
+		slots: make([]clockSlot[K, V], capacity),
+	}
+}
+
+// Capacity returns the maximum number of entries the cache can hold.
+func (c *ClockCache[K, V]) Capacity() uint64 {
+	return uint64(len(c.slots))
+}
+
+// EntryCount returns the number of entries in the cache, including any that have expired but not yet been swept.
+func (c *ClockCache[K, V]) EntryCount() uint64 {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return uint64(len(c.index))
+}
+
+// Close does nothing, as a ClockCache has no background work; it's present to satisfy Cacher.
+func (c *ClockCache[K, V]) Close() {}
+
+// Set adds a key-value pair to the cache, with no expiry.
+func (c *ClockCache[K, V]) Set(k K, v V) error {
+	return c.SetWithExpiry(k, v, time.Time{})
+}
+
+// SetWithExpiry adds a key-value pair to the cache, expiring at the given time; the zero value means no expiry.
+// Replacing an entry counts as a reference to it. A new entry takes a free slot if there is one, or else evicts
+// the next entry the hand finds unreferenced.
+func (c *ClockCache[K, V]) SetWithExpiry(k K, v V, expires time.Time) error {
+	now := time.Now()
+	if !expires.IsZero() && expires.Before(now) {
+		return fmt.Errorf("%w. expires is set to %s, but the current time is %s", ErrPastExpiry, expires.Format(DateTime), now.Format(DateTime))
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if i, found := c.index[k]; found {
+		s := &c.slots[i]
+		s.value, s.expires = v, expires
+		s.referenced.Store(true)
+		return nil
+	}
+
+	i := c.claim(now)
+	s := &c.slots[i]
+	s.key, s.value, s.expires, s.used = k, v, expires, true
+	s.referenced.Store(false)
+	c.index[k] = i
+	return nil
+}
+
+// claim returns a free slot, evicting an entry to make one if needed.
+// Assumes the lock is already acquired for writing.
+func (c *ClockCache[K, V]) claim(now time.Time) int {
+	if n := len(c.free); n > 0 {
+		i := c.free[n-1]
+		c.free = c.free[:n-1]
+		return i
+	}
+
+	for {
+		i := c.hand
+		c.hand = (c.hand + 1) % len(c.slots)
+
+		s := &c.slots[i]
+		if !s.used {
+			return i
+		}
+		expired := !s.expires.IsZero() && s.expires.Before(now)
+		if !expired && s.referenced.Swap(false) {
+			continue
+		}
+		c.clear(i)
+		return i
+	}
+}
+
+// clear frees slot i. Assumes the lock is already acquired for writing.
+func (c *ClockCache[K, V]) clear(i int) {
+	delete(c.index, c.slots[i].key)
+	c.slots[i] = clockSlot[K, V]{}
+}
+
+// Get retrieves the value for k, marking it as referenced.
+func (c *ClockCache[K, V]) Get(k K) (V, bool) {
+	now := time.Now()
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if i, found := c.index[k]; found {
+		s := &c.slots[i]
+		if s.expires.IsZero() || !s.expires.Before(now) {
+			// Checked first, so reads of hot entries don't keep writing to the same cache line.
+			if !s.referenced.Load() {
+				s.referenced.Store(true)
+			}
+			return s.value, true
+		}
+	}
+
+	var empty V
+	return empty, false
+}
+
+// Contains reports whether an unexpired entry exists for k, without marking it as referenced.
+func (c *ClockCache[K, V]) Contains(k K) bool {
+	now := time.Now()
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	i, found := c.index[k]
+	return found && (c.slots[i].expires.IsZero() || !c.slots[i].expires.Before(now))
+}
+
+// Delete removes the entry for k, if it exists.
+func (c *ClockCache[K, V]) Delete(k K) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if i, found := c.index[k]; found {
+		c.clear(i)
+		c.free = append(c.free, i)
+	}
+}
@@ -0,0 +1,64 @@
+package lrucache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClockCache_SecondChance(t *testing.T) {
+	// Checks entries read since the hand last passed survive, and unreferenced ones are evicted in ring order.
+
+	cache := NewClockCache[int, int](3)
+	defer cache.Close()
+
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, cache.Set(i, i))
+	}
+
+	// 1 is referenced, so the hand clears its bit and evicts 2 instead.
+	v, found := cache.Get(1)
+	assert.True(t, found)
+	assert.Equal(t, 1, v)
+	require.NoError(t, cache.Set(4, 4))
+	assert.False(t, cache.Contains(2))
+
+	// The hand continues from 3, which is unreferenced.
+	require.NoError(t, cache.Set(5, 5))
+	assert.False(t, cache.Contains(3))
+
+	// 1 has had its second chance, so goes next.
+	require.NoError(t, cache.Set(6, 6))
+	assert.False(t, cache.Contains(1))
+	assert.True(t, cache.Contains(4))
+	assert.True(t, cache.Contains(5))
+	assert.True(t, cache.Contains(6))
+	assert.Equal(t, uint64(3), cache.EntryCount())
+	assert.Equal(t, uint64(3), cache.Capacity())
+}
+
+func TestClockCache_DeleteAndExpiry(t *testing.T) {
+	cache := NewClockCache[string, int](2)
+	defer cache.Close()
+
+	require.NoError(t, cache.Set("a", 1))
+	require.NoError(t, cache.SetWithExpiry("b", 2, time.Now().Add(5*time.Millisecond)))
+	assert.ErrorIs(t, cache.SetWithExpiry("c", 3, time.Now().Add(-time.Second)), ErrPastExpiry)
+
+	// Deleting frees a slot, which is reused without evicting.
+	cache.Delete("a")
+	require.NoError(t, cache.Set("c", 3))
+	assert.True(t, cache.Contains("b"))
+
+	// Expired entries are reported missing, and evicted first even if referenced.
+	cache.Get("b")
+	cache.Get("c")
+	time.Sleep(10 * time.Millisecond)
+	_, found := cache.Get("b")
+	assert.False(t, found)
+	require.NoError(t, cache.Set("d", 4))
+	assert.True(t, cache.Contains("c"))
+	assert.True(t, cache.Contains("d"))
+}
@@ -0,0 +1,214 @@
+// Package cluster spreads keys across several remote cache nodes, from the client side, using consistent hashing
+// with virtual nodes, so adding or removing a node only moves the keys it owns.
+//
+// Nodes are reached through the small Node interface, which any client of the remote protocol can implement.
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/nsmithuk/lrucache/peer"
+)
+
+// ErrNoNodes is returned when a key is requested from a Router with no nodes.
+var ErrNoNodes = errors.New("cluster: no nodes available")
+
+// Node is a connection to one remote cache instance.
+type Node interface {
+	// Get returns the value stored at key. found is false if the key doesn't exist.
+	Get(ctx context.Context, key string) (value []byte, found bool, err error)
+
+	// Set stores value at key. A ttl of zero means the key doesn't expire.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Del removes key.
+	Del(ctx context.Context, key string) error
+}
+
+// Dialer connects to the node at addr. If the Node returned implements io.Closer, it's closed when the node is
+// removed from the Router, or the Router is closed.
+type Dialer func(addr string) (Node, error)
+
+// Router sends each key to the node that owns it. It's safe for concurrent use, including while nodes are being
+// added and removed.
+type Router struct {
+	dial     Dialer
+	replicas int
+	hash     peer.Hash
+
+	lock  sync.RWMutex
+	ring  *peer.Ring
+	nodes map[string]Node
+}
+
+// Option configures a Router.
+type Option func(*Router)
+
+// WithReplicas sets the number of virtual nodes each node is given on the ring. The default is
+// peer.DefaultReplicas; more give a more even distribution, at the cost of memory.
+func WithReplicas(replicas int) Option {
+	return func(r *Router) {
+		r.replicas = replicas
+	}
+}
+
+// WithHash sets the function placing keys and nodes on the ring. The default is crc32.ChecksumIEEE.
+func WithHash(hash peer.Hash) Option {
+	return func(r *Router) {
+		r.hash = hash
+	}
+}
+
+// NewRouter returns a Router with no nodes, connecting to those added with dial.
+func NewRouter(dial Dialer, opts ...Option) *Router {
+	r := &Router{
+		dial:  dial,
+		nodes: make(map[string]Node),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	r.ring = peer.NewRing(r.replicas, r.hash)
+	return r
+}
+
+// Add connects to, and starts routing keys to, the nodes at the given addresses. Addresses already added are
+// ignored. If any can't be dialled, none are added.
+func (r *Router) Add(addrs ...string) error {
+	dialled := make(map[string]Node, len(addrs))
+	for _, addr := range addrs {
+		if r.Has(addr) {
+			continue
+		}
+		if _, found := dialled[addr]; found {
+			continue
+		}
+		node, err := r.dial(addr)
+		if err != nil {
+			closeNodes(dialled)
+			return fmt.Errorf("cluster: unable to dial node %s: %w", addr, err)
+		}
+		dialled[addr] = node
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for addr, node := range dialled {
+		// Another Add may have raced with dialling.
+		if _, found := r.nodes[addr]; found {
+			closeNodes(map[string]Node{addr: node})
+			continue
+		}
+		r.nodes[addr] = node
+		r.ring.Add(addr)
+	}
+	return nil
+}
+
+// Remove stops routing keys to, and disconnects from, the nodes at the given addresses. Their keys move to the
+// remaining nodes; no other keys move.
+func (r *Router) Remove(addrs ...string) {
+	removed := make(map[string]Node, len(addrs))
+
+	r.lock.Lock()
+	for _, addr := range addrs {
+		if node, found := r.nodes[addr]; found {
+			removed[addr] = node
+			delete(r.nodes, addr)
+			r.ring.Remove(addr)
+		}
+	}
+	r.lock.Unlock()
+
+	closeNodes(removed)
+}
+
+// Has returns true if the node at addr has been added.
+func (r *Router) Has(addr string) bool {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	_, found := r.nodes[addr]
+	return found
+}
+
+// Nodes returns the addresses of the nodes, in no particular order.
+func (r *Router) Nodes() []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	addrs := make([]string, 0, len(r.nodes))
+	for addr := range r.nodes {
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
+// Owner returns the address of the node that owns key, or an empty string if there are no nodes.
+func (r *Router) Owner(key string) string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.ring.Get(key)
+}
+
+// Get returns the value for key from the node that owns it.
+func (r *Router) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	node, err := r.node(key)
+	if err != nil {
+		return nil, false, err
+	}
+	return node.Get(ctx, key)
+}
+
+// Set stores the value for key on the node that owns it. A ttl of zero means the key doesn't expire.
+func (r *Router) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	node, err := r.node(key)
+	if err != nil {
+		return err
+	}
+	return node.Set(ctx, key, value, ttl)
+}
+
+// Del removes key from the node that owns it.
+func (r *Router) Del(ctx context.Context, key string) error {
+	node, err := r.node(key)
+	if err != nil {
+		return err
+	}
+	return node.Del(ctx, key)
+}
+
+// Close disconnects from all the nodes, leaving the Router empty.
+func (r *Router) Close() {
+	r.lock.Lock()
+	nodes := r.nodes
+	r.nodes = make(map[string]Node)
+	r.ring = peer.NewRing(r.replicas, r.hash)
+	r.lock.Unlock()
+
+	closeNodes(nodes)
+}
+
+// node returns the node that owns key.
+func (r *Router) node(key string) (Node, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	addr := r.ring.Get(key)
+	if addr == "" {
+		return nil, ErrNoNodes
+	}
+	return r.nodes[addr], nil
+}
+
+// closeNodes closes the nodes that implement io.Closer.
+func closeNodes(nodes map[string]Node) {
+	for _, node := range nodes {
+		if c, ok := node.(io.Closer); ok {
+			_ = c.Close()
+		}
+	}
+}
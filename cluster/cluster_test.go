@@ -0,0 +1,138 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNode is an in-memory Node, for testing.
+type fakeNode struct {
+	lock   sync.Mutex
+	data   map[string][]byte
+	closed bool
+}
+
+func (f *fakeNode) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	b, found := f.data[key]
+	return b, found, nil
+}
+
+func (f *fakeNode) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.data[key] = value
+	return nil
+}
+
+func (f *fakeNode) Del(ctx context.Context, key string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	delete(f.data, key)
+	return nil
+}
+
+func (f *fakeNode) Close() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.closed = true
+	return nil
+}
+
+// newFakeDialer returns a Dialer creating fakeNodes, and the nodes it has created, by address.
+func newFakeDialer() (Dialer, map[string]*fakeNode) {
+	nodes := make(map[string]*fakeNode)
+	return func(addr string) (Node, error) {
+		if addr == "unreachable" {
+			return nil, errors.New("connection refused")
+		}
+		n := &fakeNode{data: make(map[string][]byte)}
+		nodes[addr] = n
+		return n, nil
+	}, nodes
+}
+
+func TestRouter_RoutesToOwner(t *testing.T) {
+	dial, nodes := newFakeDialer()
+	r := NewRouter(dial)
+	defer r.Close()
+
+	ctx := context.Background()
+	_, _, err := r.Get(ctx, "a")
+	assert.ErrorIs(t, err, ErrNoNodes)
+
+	require.NoError(t, r.Add("n1", "n2", "n3", "n1"))
+	assert.ElementsMatch(t, []string{"n1", "n2", "n3"}, r.Nodes())
+
+	for i := 0; i < 300; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		require.NoError(t, r.Set(ctx, key, []byte(key), 0))
+		_, found, _ := nodes[r.Owner(key)].Get(ctx, key)
+		assert.True(t, found)
+	}
+	for addr, n := range nodes {
+		assert.NotEmpty(t, n.data, "node %s has no keys", addr)
+	}
+
+	v, found, err := r.Get(ctx, "key-1")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []byte("key-1"), v)
+
+	require.NoError(t, r.Del(ctx, "key-1"))
+	_, found, _ = r.Get(ctx, "key-1")
+	assert.False(t, found)
+}
+
+func TestRouter_MinimalMovement(t *testing.T) {
+	// Checks adding a node only moves keys to it, and removing it moves them back.
+
+	dial, nodes := newFakeDialer()
+	r := NewRouter(dial)
+	defer r.Close()
+	require.NoError(t, r.Add("n1", "n2", "n3"))
+
+	before := make(map[string]string)
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		before[key] = r.Owner(key)
+	}
+
+	require.NoError(t, r.Add("n4"))
+	moved := 0
+	for key, owner := range before {
+		if now := r.Owner(key); now != owner {
+			assert.Equal(t, "n4", now)
+			moved++
+		}
+	}
+	assert.Greater(t, moved, 0)
+	assert.Less(t, moved, 500)
+
+	r.Remove("n4")
+	assert.True(t, nodes["n4"].closed)
+	assert.False(t, r.Has("n4"))
+	for key, owner := range before {
+		assert.Equal(t, owner, r.Owner(key))
+	}
+}
+
+func TestRouter_DialFailure(t *testing.T) {
+	dial, nodes := newFakeDialer()
+	r := NewRouter(dial)
+	defer r.Close()
+
+	assert.Error(t, r.Add("n1", "unreachable"))
+	assert.Empty(t, r.Nodes())
+	if n, found := nodes["n1"]; found {
+		assert.True(t, n.closed)
+	}
+}
@@ -0,0 +1,156 @@
+package lrucache
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrDecryption is returned by AESGCMCodec when a value can't be decrypted, e.g. as it was encrypted with another
+// key, or has been tampered with.
+var ErrDecryption = errors.New("the value could not be decrypted")
+
+// ValueCodec converts values to and from the bytes stored by an EncodedCache.
+type ValueCodec[V any] interface {
+	Encode(v V) ([]byte, error)
+	Decode(b []byte) (V, error)
+}
+
+// BytesCodec is a ValueCodec for byte slices, which stores them as they are. It's the innermost codec for an
+// AESGCMCodec of []byte values.
+type BytesCodec struct{}
+
+func (BytesCodec) Encode(v []byte) ([]byte, error) {
+	return v, nil
+}
+
+func (BytesCodec) Decode(b []byte) ([]byte, error) {
+	return b, nil
+}
+
+// AESGCMCodec is a ValueCodec that encrypts the bytes from another codec with AES-GCM, so values are never held
+// in plaintext by the cache, e.g. in case of a memory dump. Each value is encrypted with a random nonce, which is
+// stored before it, and is authenticated, so a tampered value fails to decode.
+type AESGCMCodec[V any] struct {
+	aead  cipher.AEAD
+	inner ValueCodec[V]
+}
+
+// NewAESGCMCodec returns an AESGCMCodec encrypting the output of inner with key, which must be 16, 24 or 32 bytes
+// long, for AES-128, AES-192 or AES-256.
+func NewAESGCMCodec[V any](key []byte, inner ValueCodec[V]) (*AESGCMCodec[V], error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &AESGCMCodec[V]{aead: aead, inner: inner}, nil
+}
+
+// Encode encodes v with the inner codec, and encrypts the result.
+func (c *AESGCMCodec[V]) Encode(v V) ([]byte, error) {
+	plaintext, err := c.inner.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(plaintext)+c.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return c.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// Decode decrypts b, and decodes the result with the inner codec.
+func (c *AESGCMCodec[V]) Decode(b []byte) (V, error) {
+	var empty V
+	n := c.aead.NonceSize()
+	if len(b) < n {
+		return empty, ErrDecryption
+	}
+	plaintext, err := c.aead.Open(nil, b[:n], b[n:], nil)
+	if err != nil {
+		return empty, ErrDecryption
+	}
+	return c.inner.Decode(plaintext)
+}
+
+// EncodedCache is a Cacher holding its values encoded by a ValueCodec, e.g. encrypted by an AESGCMCodec. Values
+// are encoded on Set, outside the cache's lock, and decoded on every Get. Each entry's size is the length of its
+// encoded value, so the capacity is in bytes.
+type EncodedCache[K comparable, V any] struct {
+	cache *Cache[K, []byte]
+	codec ValueCodec[V]
+}
+
+// NewEncodedCache creates an EncodedCache with the specified capacity, in bytes, configured by the given Options.
+// Callbacks given by Options, such as WithOnEvict, see the encoded values.
+func NewEncodedCache[K comparable, V any](capacity uint64, codec ValueCodec[V], opts ...Option) *EncodedCache[K, V] {
+	return &EncodedCache[K, V]{
+		cache: NewCacheWithOptions[K, []byte](capacity, opts...),
+		codec: codec,
+	}
+}
+
+// Cache returns the underlying cache, holding the encoded values.
+func (c *EncodedCache[K, V]) Cache() *Cache[K, []byte] {
+	return c.cache
+}
+
+// Get returns the decoded value for k. A value that fails to decode is treated as missing, and the error is passed
+// to the cache's error handler.
+func (c *EncodedCache[K, V]) Get(k K) (V, bool) {
+	v, found, err := c.GetWithError(k)
+	if err != nil {
+		c.cache.handleError(err)
+	}
+	return v, found && err == nil
+}
+
+// GetWithError returns the decoded value for k, and whether it was found, or an error if it fails to decode.
+func (c *EncodedCache[K, V]) GetWithError(k K) (V, bool, error) {
+	var empty V
+	b, found := c.cache.Get(k)
+	if !found {
+		return empty, false, nil
+	}
+	v, err := c.codec.Decode(b)
+	if err != nil {
+		return empty, true, fmt.Errorf("unable to decode value for key %v: %w", k, err)
+	}
+	return v, true, nil
+}
+
+// Set encodes v, and stores it for k with no expiry.
+func (c *EncodedCache[K, V]) Set(k K, v V) error {
+	return c.SetWithExpiry(k, v, time.Time{})
+}
+
+// SetWithExpiry encodes v, and stores it for k, expiring at expires (the zero value meaning no expiry).
+func (c *EncodedCache[K, V]) SetWithExpiry(k K, v V, expires time.Time) error {
+	b, err := c.codec.Encode(v)
+	if err != nil {
+		return fmt.Errorf("unable to encode value for key %v: %w", k, err)
+	}
+	return c.cache.SetWithSizeAndExpiry(k, b, uint64(max(len(b), 1)), expires)
+}
+
+// Contains reports whether an unexpired entry exists for k, without decoding it.
+func (c *EncodedCache[K, V]) Contains(k K) bool {
+	return c.cache.Contains(k)
+}
+
+// Delete removes the entry for k, if it exists.
+func (c *EncodedCache[K, V]) Delete(k K) {
+	c.cache.Delete(k)
+}
+
+// Close closes the underlying cache.
+func (c *EncodedCache[K, V]) Close() {
+	c.cache.Close()
+}
@@ -0,0 +1,69 @@
+package lrucache
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodedCache_AESGCM(t *testing.T) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	codec, err := NewAESGCMCodec[[]byte](key, BytesCodec{})
+	require.NoError(t, err)
+
+	cache := NewEncodedCache[string, []byte](1024, codec)
+	defer cache.Close()
+
+	secret := []byte("card number 4111 1111 1111 1111")
+	require.NoError(t, cache.SetWithExpiry("a", secret, time.Now().Add(time.Minute)))
+
+	v, found := cache.Get("a")
+	assert.True(t, found)
+	assert.Equal(t, secret, v)
+
+	// The stored value is encrypted, and its size is that of the ciphertext.
+	stored, found := cache.Cache().Get("a")
+	require.True(t, found)
+	assert.False(t, bytes.Contains(stored, []byte("4111")))
+	assert.Equal(t, uint64(len(stored)), cache.Cache().Size())
+	assert.Greater(t, len(stored), len(secret))
+
+	// The same value encrypts differently each time.
+	require.NoError(t, cache.Set("b", secret))
+	other, _ := cache.Cache().Get("b")
+	assert.NotEqual(t, stored, other)
+}
+
+func TestEncodedCache_TamperedValue(t *testing.T) {
+	codec, err := NewAESGCMCodec[[]byte](make([]byte, 16), BytesCodec{})
+	require.NoError(t, err)
+
+	var handled []error
+	cache := NewEncodedCache[string, []byte](1024, codec, WithErrorHandler(func(err error) { handled = append(handled, err) }))
+	defer cache.Close()
+
+	require.NoError(t, cache.Set("a", []byte("value")))
+	stored, _ := cache.Cache().Get("a")
+	tampered := bytes.Clone(stored)
+	tampered[len(tampered)-1] ^= 1
+	require.NoError(t, cache.Cache().Set("a", tampered))
+
+	_, found := cache.Get("a")
+	assert.False(t, found)
+	require.Len(t, handled, 1)
+	assert.ErrorIs(t, handled[0], ErrDecryption)
+
+	_, _, err = cache.GetWithError("a")
+	assert.ErrorIs(t, err, ErrDecryption)
+}
+
+func TestNewAESGCMCodec_InvalidKey(t *testing.T) {
+	_, err := NewAESGCMCodec[[]byte](make([]byte, 10), BytesCodec{})
+	assert.Error(t, err)
+}
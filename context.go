@@ -0,0 +1,30 @@
+package lrucache
+
+import "context"
+
+// GetCtx behaves like Get, but returns ctx's error if ctx is done while waiting to acquire the cache's lock.
+// If ctx is done while waiting to move the entry to the front of the list, the value is still returned, but its
+// LRU position isn't updated.
+func (lru *Cache[K, V]) GetCtx(ctx context.Context, k K) (V, bool, error) {
+	n, found, err := lru.get(ctx, k)
+	if err != nil || !found || n.negative {
+		return lru.emptyV, false, err
+	}
+	return n.value, true, nil
+}
+
+// SetCtx behaves like SetWithOptions, but returns ctx's error if ctx is done while waiting to acquire the cache's
+// lock. Once the lock is acquired, the Set always completes, including making space for the entry.
+func (lru *Cache[K, V]) SetCtx(ctx context.Context, k K, v V, opts ...EntryOption) error {
+	eo := entryOptions{size: 1}
+	for _, opt := range opts {
+		opt(&eo)
+	}
+	return lru.set(ctx, k, v, eo)
+}
+
+// DeleteCtx behaves like Delete, but returns ctx's error if ctx is done while waiting to acquire the cache's lock.
+func (lru *Cache[K, V]) DeleteCtx(ctx context.Context, k K) error {
+	_, err := lru.delete(ctx, k)
+	return err
+}
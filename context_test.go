@@ -0,0 +1,70 @@
+package lrucache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_ContextOperations(t *testing.T) {
+	// Checks the context-aware operations behave like their plain counterparts when the context isn't done.
+
+	cache := NewCache[string, int](10)
+	defer cache.Close()
+
+	ctx := context.Background()
+
+	require.NoError(t, cache.SetCtx(ctx, "a", 1, WithSize(2)))
+
+	v, found, err := cache.GetCtx(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 1, v)
+	assert.Equal(t, uint64(2), cache.Size())
+
+	require.NoError(t, cache.DeleteCtx(ctx, "a"))
+
+	_, found, err = cache.GetCtx(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestCache_ContextCanceledWhileLocked(t *testing.T) {
+	// Ensures operations waiting for the lock return when their context is done, and that the lock is still usable
+	// afterwards.
+
+	cache := NewCache[string, int](10)
+	defer cache.Close()
+
+	require.NoError(t, cache.Set("a", 1))
+
+	// Hold the write lock, as a long-running operation would.
+	cache.lock.Lock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, _, err := cache.GetCtx(ctx, "a")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	assert.ErrorIs(t, cache.SetCtx(ctx, "b", 2), context.DeadlineExceeded)
+	assert.ErrorIs(t, cache.DeleteCtx(ctx, "a"), context.DeadlineExceeded)
+
+	_, err = cache.GetOrLoad(ctx, "c", func(ctx context.Context, k string) (int, time.Time, error) {
+		t.Fatal("loader should not be called")
+		return 0, time.Time{}, nil
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	cache.lock.Unlock()
+
+	// Abandoned lock attempts must not leave the lock held.
+	v, found := cache.Get("a")
+	assert.True(t, found)
+	assert.Equal(t, 1, v)
+	require.NoError(t, cache.Set("b", 2))
+	assert.Equal(t, uint64(2), cache.EntryCount())
+}
@@ -0,0 +1,54 @@
+package lrucache
+
+import "time"
+
+// WithCostAwareEviction evicts the entries that are cheapest to recompute first, rather than always the least
+// recently used, using a sampled form of the GreedyDual-Size policy. Each entry is credited with the time its value
+// took to load, divided by its size, on top of a baseline that rises to the credit of each entry evicted. Reading an
+// entry renews its credit, so entries that are expensive to load are kept for longer, but not forever once they stop
+// being read. Up to sample unpinned entries are taken from the tail of the list, and the one with the least credit
+// is evicted; a sample of zero means DefaultEvictionSample.
+//
+// Load times are recorded by GetOrLoad, GetMultiOrLoad and the loading caches; entries Set directly have no cost, so
+// are evicted before loaded ones of the same recency. It has no effect if WithEvictionOrder is set.
+func WithCostAwareEviction(sample int) Option {
+	return func(o *options) {
+		o.costAware = true
+		o.costSample = sample
+	}
+}
+
+// creditCost renews n's credit for WithCostAwareEviction, as it's added to the front of the list.
+// Assumes the list lock is held.
+func (lru *Cache[K, V]) creditCost(n *node[K, V]) {
+	if !lru.opts.costAware {
+		return
+	}
+	n.credit = lru.inflation + float64(n.cost)/float64(max(n.size, 1))
+}
+
+// cheapestVictim returns the node with the least credit, as set by creditCost, among a sample from the tail of the
+// list, skipping any that are pinned, or nil if there's none. The baseline credit is raised to the victim's.
+// Assumes the lock is already acquired, and the list lock is held.
+func (lru *Cache[K, V]) cheapestVictim(now time.Time) *node[K, V] {
+	sample := lru.opts.costSample
+	if sample <= 0 {
+		sample = DefaultEvictionSample
+	}
+
+	var victim *node[K, V]
+	taken := 0
+	for n := lru.tail.previous; n != lru.head && taken < sample; n = n.previous {
+		if n.isPinned(now) {
+			continue
+		}
+		if victim == nil || n.credit < victim.credit {
+			victim = n
+		}
+		taken++
+	}
+	if victim != nil {
+		lru.inflation = max(lru.inflation, victim.credit)
+	}
+	return victim
+}
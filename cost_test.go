@@ -0,0 +1,64 @@
+package lrucache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_CostAwareEviction(t *testing.T) {
+	// Checks entries that were slow to load are kept in preference to cheaper ones that are more recently used.
+
+	cache := NewCacheWithOptions[string, int](3, WithCostAwareEviction(0))
+	defer cache.Close()
+
+	slow := func(ctx context.Context, k string) (int, time.Time, error) {
+		time.Sleep(10 * time.Millisecond)
+		return 1, time.Time{}, nil
+	}
+	fast := func(ctx context.Context, k string) (int, time.Time, error) {
+		return 2, time.Time{}, nil
+	}
+
+	_, err := cache.GetOrLoad(context.Background(), "slow", slow)
+	require.NoError(t, err)
+	_, err = cache.GetOrLoad(context.Background(), "fast", fast)
+	require.NoError(t, err)
+	require.NoError(t, cache.Set("set", 3))
+
+	info, found := cache.EntryInfo("slow")
+	require.True(t, found)
+	assert.GreaterOrEqual(t, info.LoadCost, 10*time.Millisecond)
+	info, _ = cache.EntryInfo("set")
+	assert.Zero(t, info.LoadCost)
+
+	require.NoError(t, cache.Set("new", 4))
+	assert.True(t, cache.Contains("slow"))
+	assert.True(t, cache.Contains("fast"))
+	assert.False(t, cache.Contains("set"))
+
+	require.NoError(t, cache.Set("newer", 5))
+	assert.True(t, cache.Contains("slow"))
+	assert.False(t, cache.Contains("new"))
+}
+
+func TestCache_CostAwareEvictionSample(t *testing.T) {
+	// Checks only the sampled entries are considered, so a sample of 1 evicts the least recently used.
+
+	cache := NewCacheWithOptions[string, int](2, WithCostAwareEviction(1))
+	defer cache.Close()
+
+	_, err := cache.GetOrLoad(context.Background(), "slow", func(ctx context.Context, k string) (int, time.Time, error) {
+		time.Sleep(5 * time.Millisecond)
+		return 1, time.Time{}, nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, cache.Set("a", 2))
+	require.NoError(t, cache.Set("b", 3))
+
+	assert.False(t, cache.Contains("slow"))
+	assert.True(t, cache.Contains("a"))
+}
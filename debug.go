@@ -0,0 +1,136 @@
+package lrucache
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Dump writes a human-readable description of the cache's internal state to w, for diagnosing ordering problems:
+// its total size and map count, followed by every node in the list from the most to the least recently used, with
+// its key, size, expiry and flags. The list is read consistently, after any queued events have been applied.
+func (lru *Cache[K, V]) Dump(w io.Writer) error {
+	type dumpedNode struct {
+		key      K
+		size     uint64
+		expires  time.Time
+		deleted  bool
+		negative bool
+	}
+	var nodes []dumpedNode
+	var size, capacity uint64
+	var entries int
+
+	lru.readLock(OperationOther)
+	if lru.stopped {
+		lru.lock.RUnlock()
+		return ErrCacheClosed
+	}
+	lru.runOnEventLoop(func() {
+		size, capacity, entries = lru.size, lru.capacity, len(lru.cache)
+		for n := lru.head.next; n != lru.tail && n != nil; n = n.next {
+			nodes = append(nodes, dumpedNode{n.key, n.size, n.expires, n.deleted, n.negative})
+		}
+	})
+	lru.lock.RUnlock()
+
+	// Written outside the lock, so slow writers don't block the cache.
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "size=%d capacity=%d entries=%d listed=%d\n", size, capacity, entries, len(nodes))
+	for i, n := range nodes {
+		expires := "never"
+		if !n.expires.IsZero() {
+			expires = n.expires.Format(DateTime)
+		}
+		fmt.Fprintf(bw, "%d: key=%v size=%d expires=%s", i, n.key, n.size, expires)
+		if n.deleted {
+			fmt.Fprint(bw, " deleted")
+		}
+		if n.negative {
+			fmt.Fprint(bw, " negative")
+		}
+		fmt.Fprintln(bw)
+	}
+	return bw.Flush()
+}
+
+// CheckIntegrity verifies the cache's structural invariants: the head and tail sentinels are intact, the list is
+// correctly linked in both directions, it holds exactly the nodes in the map and none marked as deleted, and the
+// recorded total size and counts match the nodes. Every violation found is returned, joined, each wrapping
+// ErrCorrupted. It's O(n), and blocks writes while it runs.
+func (lru *Cache[K, V]) CheckIntegrity() error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: %s", ErrCorrupted, fmt.Sprintf(format, args...)))
+	}
+
+	lru.readLock(OperationOther)
+	if lru.stopped {
+		lru.lock.RUnlock()
+		return ErrCacheClosed
+	}
+	lru.runOnEventLoop(func() {
+		if lru.head.previous != nil {
+			fail("head sentinel has a previous node")
+		}
+		if lru.tail.next != nil {
+			fail("tail sentinel has a next node")
+		}
+
+		var size uint64
+		var sizeCounts [SizeBuckets]uint64
+		seen := make(map[*node[K, V]]struct{}, len(lru.cache))
+
+		previous := lru.head
+		for n := lru.head.next; n != lru.tail; n = n.next {
+			if n == nil {
+				fail("list is broken after key %v", previous.key)
+				break
+			}
+			if _, found := seen[n]; found {
+				fail("list has a cycle at key %v", n.key)
+				break
+			}
+			seen[n] = struct{}{}
+
+			if n.previous != previous {
+				fail("key %v is not linked back to its previous node", n.key)
+			}
+			if n.deleted {
+				fail("key %v is marked as deleted but still linked", n.key)
+			}
+			if m, found := lru.cache[n.key]; !found {
+				fail("key %v is in the list but not the map", n.key)
+			} else if m != n {
+				fail("key %v is in the list more than once, or the map holds a different node", n.key)
+			}
+
+			size += n.size
+			if n.size > 0 {
+				sizeCounts[sizeBucket(n.size)]++
+			}
+			previous = n
+		}
+		if lru.tail.previous != previous {
+			fail("tail sentinel is not linked back to the last node")
+		}
+
+		if len(seen) != len(lru.cache) {
+			fail("list holds %d nodes, but the map holds %d", len(seen), len(lru.cache))
+		}
+		if len(seen) != lru.length {
+			fail("list holds %d nodes, but its recorded length is %d", len(seen), lru.length)
+		}
+		if size != lru.size {
+			fail("nodes total a size of %d, but the recorded size is %d", size, lru.size)
+		}
+		if sizeCounts != lru.sizeCounts {
+			fail("nodes by size don't match the recorded size counts")
+		}
+	})
+	lru.lock.RUnlock()
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,54 @@
+package lrucache
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Dump(t *testing.T) {
+	// Checks that Dump lists the nodes from the most to the least recently used.
+
+	cache := NewCache[string, int](10)
+
+	expires := time.Now().Add(time.Hour)
+	assert.NoError(t, cache.Set("a", 1))
+	assert.NoError(t, cache.SetWithSizeAndExpiry("b", 2, 3, expires))
+	cache.Get("a")
+
+	var buf bytes.Buffer
+	assert.NoError(t, cache.Dump(&buf))
+	assert.Equal(t, "size=4 capacity=10 entries=2 listed=2\n"+
+		"0: key=a size=1 expires=never\n"+
+		"1: key=b size=3 expires="+expires.Format(DateTime)+"\n", buf.String())
+
+	cache.Close()
+	assert.ErrorIs(t, cache.Dump(&buf), ErrCacheClosed)
+}
+
+func TestCache_CheckIntegrity(t *testing.T) {
+	// Checks that a healthy cache passes, and that corruption is reported.
+
+	cache := NewCacheWithBuffer[int, string](5, 10)
+	defer cache.Close()
+
+	for i := 1; i <= 10; i++ {
+		assert.NoError(t, cache.SetWithSize(i, "value", uint64(i%2+1)))
+		cache.Get(i - 1)
+	}
+	cache.Delete(9)
+	assert.NoError(t, cache.CheckIntegrity())
+
+	// Corrupt the recorded size, and mark a linked node as deleted.
+	cache.lock.Lock()
+	cache.size++
+	cache.head.next.deleted = true
+	cache.lock.Unlock()
+
+	err := cache.CheckIntegrity()
+	assert.ErrorIs(t, err, ErrCorrupted)
+	assert.ErrorContains(t, err, "recorded size")
+	assert.ErrorContains(t, err, "marked as deleted")
+}
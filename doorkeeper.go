@@ -0,0 +1,60 @@
+package lrucache
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// doorkeeperHashes is the number of bits set per key. With ten bits per key, this gives a false positive rate of
+// around 1%.
+const doorkeeperHashes = 7
+
+// doorkeeper is a bloom filter of keys that have been seen, so new keys are only admitted to the cache on their
+// second sighting. It's cleared once it has recorded window keys, so sightings only count within that window.
+type doorkeeper struct {
+	lock   sync.Mutex
+	bits   []uint64
+	count  int
+	window int
+
+	rejected atomic.Uint64 // Number of keys refused admission, for Stats.
+}
+
+func newDoorkeeper(window int) *doorkeeper {
+	return &doorkeeper{
+		bits:   make([]uint64, (window*10+63)/64),
+		window: window,
+	}
+}
+
+// admit reports whether the key with hash h has been seen before within the window, recording it if not.
+func (d *doorkeeper) admit(h uint64) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	// Double hashing, deriving each bit's index from the two halves of h.
+	h1, h2 := uint32(h), uint32(h>>32)
+	m := uint32(len(d.bits) * 64)
+
+	seen := true
+	for i := uint32(0); i < doorkeeperHashes; i++ {
+		bit := (h1 + i*h2) % m
+		word, mask := bit/64, uint64(1)<<(bit%64)
+		if d.bits[word]&mask == 0 {
+			seen = false
+			d.bits[word] |= mask
+		}
+	}
+	if seen {
+		return true
+	}
+
+	d.count++
+	if d.count >= d.window {
+		clear(d.bits)
+		d.count = 0
+	}
+
+	d.rejected.Add(1)
+	return false
+}
@@ -0,0 +1,51 @@
+package lrucache
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Doorkeeper(t *testing.T) {
+	// Checks that new keys are only stored on their second sighting, and existing keys can always be replaced.
+
+	cache := NewCacheWithOptions[string, int](10, WithDoorkeeper(100))
+	defer cache.Close()
+
+	assert.NoError(t, cache.Set("a", 1))
+	assert.False(t, cache.Contains("a"))
+
+	assert.NoError(t, cache.Set("a", 2))
+	v, found := cache.Get("a")
+	assert.True(t, found)
+	assert.Equal(t, 2, v)
+
+	assert.NoError(t, cache.Set("a", 3))
+	v, _ = cache.Get("a")
+	assert.Equal(t, 3, v)
+
+	assert.Equal(t, uint64(1), cache.Stats().DoorkeeperRejections)
+
+	// One-hit keys don't displace the working set.
+	for i := 0; i < 50; i++ {
+		assert.NoError(t, cache.Set(fmt.Sprintf("once-%d", i), i))
+	}
+	assert.True(t, cache.Contains("a"))
+	assert.Less(t, cache.EntryCount(), uint64(5))
+}
+
+func TestDoorkeeper_Window(t *testing.T) {
+	// Checks that sightings are forgotten once the window has passed.
+
+	d := newDoorkeeper(3)
+	h := newKeyHasher[int]()
+
+	assert.False(t, d.admit(h.hash(1)))
+	assert.True(t, d.admit(h.hash(1)))
+
+	assert.False(t, d.admit(h.hash(2)))
+	assert.False(t, d.admit(h.hash(3))) // Ends the window.
+
+	assert.False(t, d.admit(h.hash(1)))
+}
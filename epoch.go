@@ -0,0 +1,46 @@
+package lrucache
+
+import "time"
+
+// BumpEpoch invalidates every entry in the cache in constant time, however many there are. Invalidated entries are
+// treated as missing by reads, as if expired, but are only removed lazily: by the periodic purge, DeleteExpired, or
+// as they reach the tail of the list. Until then they still count towards the cache's size and entry count, and
+// are reported to eviction callbacks with EvictionReasonInvalidated. Entries stored afterwards aren't affected.
+// For a huge cache, this is much cheaper than Reset.
+func (lru *Cache[K, V]) BumpEpoch() {
+	lru.writeLock(OperationDelete)
+	lru.epoch.Store(lru.version)
+	lru.lock.Unlock()
+}
+
+// BumpTagEpoch invalidates every entry with the given tag in constant time, as BumpEpoch does for all entries.
+// It's a cheaper, lazy, alternative to DeleteTag.
+func (lru *Cache[K, V]) BumpTagEpoch(tag string) {
+	lru.writeLock(OperationDelete)
+	if l, found := lru.tags[tag]; found {
+		l.epoch = lru.version
+		lru.tagEpochs.Store(true)
+	}
+	lru.lock.Unlock()
+}
+
+// isStale returns true if n was invalidated by BumpEpoch or BumpTagEpoch. Each entry's version is greater than
+// those of the entries stored before it, so entries stored before the bump are those with a version up to its epoch.
+// Assumes the lock is already acquired.
+func (lru *Cache[K, V]) isStale(n *node[K, V]) bool {
+	if n.version <= lru.epoch.Load() {
+		return true
+	}
+	for _, m := range n.tags {
+		if n.version <= m.list.epoch {
+			return true
+		}
+	}
+	return false
+}
+
+// isExpired returns true if n has expired at now, or been invalidated by an epoch, so should be treated as missing.
+// Assumes the lock is already acquired.
+func (lru *Cache[K, V]) isExpired(n *node[K, V], now time.Time) bool {
+	return n.isExpired(now) || lru.isStale(n)
+}
@@ -0,0 +1,63 @@
+package lrucache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_BumpEpoch(t *testing.T) {
+	var reasons []EvictionReason
+	cache := NewCacheWithOptions[string, int](10, WithStrictConsistency(), WithOnEvict(func(_ string, _ int, reason EvictionReason) {
+		reasons = append(reasons, reason)
+	}))
+	defer cache.Close()
+
+	require.NoError(t, cache.Set("a", 1))
+	require.NoError(t, cache.Set("b", 2))
+	cache.BumpEpoch()
+	require.NoError(t, cache.Set("c", 3))
+
+	_, found := cache.Get("a")
+	assert.False(t, found)
+	assert.False(t, cache.Contains("b"))
+	assert.True(t, cache.Contains("c"))
+
+	// Replacing or removing an invalidated entry reports it as missing.
+	_, existed, err := cache.Swap("a", 10)
+	require.NoError(t, err)
+	assert.False(t, existed)
+	assert.True(t, cache.Contains("a"))
+	_, found = cache.GetAndDelete("b")
+	assert.False(t, found)
+	assert.Equal(t, []EvictionReason{EvictionReasonReplaced, EvictionReasonDeleted}, reasons)
+
+	// Invalidated entries are removed lazily.
+	require.NoError(t, cache.Set("d", 4))
+	cache.BumpEpoch()
+	assert.Equal(t, uint64(3), cache.EntryCount())
+	assert.Equal(t, 3, cache.DeleteExpired())
+	assert.Equal(t, uint64(0), cache.EntryCount())
+	assert.Equal(t, EvictionReasonInvalidated, reasons[len(reasons)-1])
+	assert.NoError(t, cache.CheckIntegrity())
+}
+
+func TestCache_BumpTagEpoch(t *testing.T) {
+	cache := NewCacheWithOptions[string, int](10, WithStrictConsistency())
+	defer cache.Close()
+
+	require.NoError(t, cache.SetWithOptions("a", 1, WithTags("x")))
+	require.NoError(t, cache.SetWithOptions("b", 2, WithTags("x", "y")))
+	require.NoError(t, cache.SetWithOptions("c", 3, WithTags("y")))
+
+	cache.BumpTagEpoch("x")
+	cache.BumpTagEpoch("missing")
+	require.NoError(t, cache.SetWithOptions("d", 4, WithTags("x")))
+
+	assert.False(t, cache.Contains("a"))
+	assert.False(t, cache.Contains("b"))
+	assert.True(t, cache.Contains("c"))
+	assert.True(t, cache.Contains("d"))
+	assert.Equal(t, []string{"d", "c"}, cache.OrderedKeys())
+}
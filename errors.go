@@ -6,4 +6,42 @@ var (
 	ErrPastExpiry   = errors.New("the expiry date cannot be in the past")
 	ErrItemTooSmall = errors.New("the item size much the greater than or equal to 1")
 	ErrItemTooBig   = errors.New("the item is too big to fit in the cache")
+
+	// ErrNilValue is returned when a nil value is set in a cache created with WithRejectNilValues.
+	ErrNilValue = errors.New("nil values cannot be added to the cache")
+
+	// ErrNoCapacity is returned when an item is set in a cache, or for a tenant, with a capacity of zero.
+	ErrNoCapacity = errors.New("the cache has no capacity")
+
+	// ErrKeyNotFound is returned by Lookup when there's no unexpired entry for the key.
+	ErrKeyNotFound = errors.New("the key was not found in the cache")
+
+	// ErrTypeMismatch is returned by a View when the cached value isn't of the view's type.
+	ErrTypeMismatch = errors.New("the cached value is not of the expected type")
+
+	// ErrLoadQueueFull is returned by a load when the queue set by WithLoadQueue is already full.
+	ErrLoadQueueFull = errors.New("too many callers are waiting to load values")
+
+	// ErrLoadTimeout is returned by a load that waited for a slot longer than the timeout set by WithLoadQueue.
+	ErrLoadTimeout = errors.New("timed out waiting to load value")
+
+	// ErrCacheClosed is returned by operations on a cache after Close has been called.
+	ErrCacheClosed = errors.New("the cache has been closed")
+
+	// ErrCallbackPanic is wrapped by the PanicError describing a user-supplied callback that panicked, and returned
+	// by operations whose callback did, so can be matched with errors.Is; use errors.As for the PanicError's details.
+	ErrCallbackPanic = errors.New("a user-supplied callback panicked")
+
+	// ErrVersionConflict is returned by SetIfVersion when the entry's version isn't the one expected.
+	ErrVersionConflict = errors.New("the entry has been changed")
+
+	// ErrNotModified is returned by GetIfChanged when the entry still has the version the caller already has.
+	ErrNotModified = errors.New("the entry has not been changed")
+
+	// ErrCorrupted is wrapped by the errors returned from CheckIntegrity.
+	ErrCorrupted = errors.New("the cache's internal state is inconsistent")
+
+	// ErrNotFound should be returned (or wrapped) by a Loader when no value exists for the key.
+	// It's returned by GetOrLoad both for fresh and negatively cached misses.
+	ErrNotFound = errors.New("no value exists for the key")
 )
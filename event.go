@@ -1,7 +1,5 @@
 package lrucache
 
-import "sync"
-
 // action represents the type of operation or event to be processed in the cache.
 type action uint8
 
@@ -11,13 +9,16 @@ const (
 	EventActionRemove                      // Remove a specific node from the cache.
 	EventActionMakeSpaceFor                // Make space for a new entry by evicting older ones.
 	EventActionRemoveExpired               // Remove all expired entries from the cache.
+	EventActionRun                         // Run a function with sole access to the list, e.g. to read it consistently.
 )
 
-// event represents a specific operation to be performed on the cache.
-// It is used in the asynchronous event channel for managing the linked list and cache state.
+// event represents a specific operation to be performed on the linked list.
+// Writes handle their events in place; reads send their promotions through the asynchronous event channel.
 // Ordered to try and reduce padding.
 type event[K comparable, V any] struct {
-	finished *sync.WaitGroup // Optional wait group to signal completion of the event.
-	n        *node[K, V]     // The node involved in the action, if applicable.
-	a        action          // The type of action to be performed (e.g., add, remove, etc.).
+	n      *node[K, V]    // The node involved in the action, if applicable.
+	fn     func()         // The function to run, for EventActionRun.
+	a      action         // The type of action to be performed (e.g., add, remove, etc.).
+	reason EvictionReason // Why the node is being removed, for EventActionRemove.
+	hit    bool           // True if an EventActionAddToFront is for a Get, rather than a Set.
 }
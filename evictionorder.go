@@ -0,0 +1,52 @@
+package lrucache
+
+import "time"
+
+// DefaultEvictionSample is the number of candidates WithEvictionOrder compares when given a sample of zero.
+const DefaultEvictionSample = 8
+
+// EvictionCandidate is an entry being considered for eviction by the function set with WithEvictionOrder.
+type EvictionCandidate[K comparable, V any] struct {
+	Entry[K, V]
+	Rank int // Position from the tail of the list, ignoring pinned entries; 0 is the least recently used.
+}
+
+// WithEvictionOrder sets a function choosing which entry to evict when space is needed, rather than always the least
+// recently used. Up to sample unpinned entries are taken from the tail of the list, and the one ranked first by less
+// is evicted, so an application can prefer evicting large, low priority (e.g. as held in their metadata) or cold
+// entries, while keeping ones that are expensive to recompute. less returns true if a should be evicted before b.
+// A sample of zero means DefaultEvictionSample; a sample of 1 is plain LRU.
+//
+// less is called while holding the cache's lock, so must be quick, and must not call the cache. Its types must
+// match the cache's.
+func WithEvictionOrder[K comparable, V any](less func(a, b EvictionCandidate[K, V]) bool, sample int) Option {
+	return func(o *options) {
+		o.evictionLess = less
+		o.evictionSample = sample
+	}
+}
+
+// orderedVictim returns the node ranked first for eviction by the WithEvictionOrder function, among a sample from
+// the tail of the list, skipping any that are pinned, or nil if there's none.
+// Assumes the lock is already acquired, and the list lock is held.
+func (lru *Cache[K, V]) orderedVictim(now time.Time) *node[K, V] {
+	sample := lru.opts.evictionSample
+	if sample <= 0 {
+		sample = DefaultEvictionSample
+	}
+
+	var victim *node[K, V]
+	var best EvictionCandidate[K, V]
+	rank := 0
+	for n := lru.tail.previous; n != lru.head && rank < sample; n = n.previous {
+		if n.isPinned(now) {
+			continue
+		}
+		c := EvictionCandidate[K, V]{Entry: n.entry(), Rank: rank}
+		if victim == nil || lru.evictionLess(c, best) {
+			victim, best = n, c
+		}
+		rank++
+	}
+	return victim
+}
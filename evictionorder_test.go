@@ -0,0 +1,53 @@
+package lrucache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_EvictionOrder(t *testing.T) {
+	// Prefers evicting low priority entries, as held in their metadata, then the least recently used.
+	less := func(a, b EvictionCandidate[string, int]) bool {
+		pa, pb := a.Metadata.(int), b.Metadata.(int)
+		if pa != pb {
+			return pa < pb
+		}
+		return a.Rank < b.Rank
+	}
+
+	cache := NewCacheWithOptions[string, int](3, WithStrictConsistency(), WithEvictionOrder(less, 3))
+	defer cache.Close()
+
+	require.NoError(t, cache.SetWithOptions("a", 1, WithMetadata(5)))
+	require.NoError(t, cache.SetWithOptions("b", 2, WithMetadata(1)))
+	require.NoError(t, cache.SetWithOptions("c", 3, WithMetadata(5)))
+
+	// a is the least recently used, but b has the lowest priority.
+	require.NoError(t, cache.SetWithOptions("d", 4, WithMetadata(5)))
+	assert.False(t, cache.Contains("b"))
+	assert.Equal(t, []string{"d", "c", "a"}, cache.OrderedKeys())
+
+	// With equal priorities, it falls back to the least recently used.
+	require.NoError(t, cache.SetWithOptions("e", 5, WithMetadata(5)))
+	assert.False(t, cache.Contains("a"))
+}
+
+func TestCache_EvictionOrderSampleOfOne(t *testing.T) {
+	// A sample of one only considers the tail, so is plain LRU whatever the function.
+	cache := NewCacheWithOptions[int, int](2, WithStrictConsistency(),
+		WithEvictionOrder(func(a, b EvictionCandidate[int, int]) bool { return a.Size > b.Size }, 1))
+	defer cache.Close()
+
+	require.NoError(t, cache.SetWithSize(1, 1, 1))
+	require.NoError(t, cache.SetWithSize(2, 2, 1))
+	require.NoError(t, cache.SetWithSize(3, 3, 1))
+	assert.Equal(t, []int{3, 2}, cache.OrderedKeys())
+}
+
+func TestCache_EvictionOrderTypeMismatch(t *testing.T) {
+	assert.Panics(t, func() {
+		NewCacheWithOptions[string, int](3, WithEvictionOrder(func(a, b EvictionCandidate[int, int]) bool { return false }, 0))
+	})
+}
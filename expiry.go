@@ -0,0 +1,123 @@
+package lrucache
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ExpiredEntry is sent to the channel returned by Expired when an expired entry is removed.
+type ExpiredEntry[K comparable, V any] struct {
+	Entry[K, V]
+	Removed time.Time // When the entry was removed, which may be a while after it expired.
+}
+
+// Expired returns the channel enabled by WithExpiredChannel, which receives entries as they're removed once
+// expired, so applications can follow up on them, e.g. to recompute or persist them. It's nil if the option isn't
+// set. The channel isn't closed when the cache is, as a closed cache may be reopened by Reset.
+func (lru *Cache[K, V]) Expired() <-chan ExpiredEntry[K, V] {
+	return lru.expiredEntries
+}
+
+// sendExpired sends the expired entries among removed to the Expired channel, dropping any that don't fit.
+func (lru *Cache[K, V]) sendExpired(removed []removal[K, V]) {
+	now := time.Now()
+	for _, r := range removed {
+		if r.reason != EvictionReasonExpired {
+			continue
+		}
+		select {
+		case lru.expiredEntries <- ExpiredEntry[K, V]{Entry: r.n.entry(), Removed: now}:
+		default:
+			lru.log(slog.LevelWarn, "lrucache: expired channel is full; dropping entry", "key", r.n.key)
+		}
+	}
+}
+
+// expiryBatcher collects the keys of expired entries, passing them to the OnExpiredBatch callback in batches.
+type expiryBatcher[K comparable] struct {
+	fn     func([]K)
+	max    int           // Flush once this many keys are pending; zero means no limit.
+	delay  time.Duration // Flush this long after the first pending key was added; zero means flush immediately.
+	safely func(string, func()) error
+
+	// unattended runs the flushes made by the timer, which are background work; see Cache.unattended.
+	unattended func(func()) *PanicError
+
+	lock    sync.Mutex
+	pending []K
+	timer   *time.Timer
+	closed  bool
+
+	flushing sync.Mutex // Held while calling fn, so calls are never concurrent.
+}
+
+func newExpiryBatcher[K comparable](fn func([]K), max int, delay time.Duration, safely func(string, func()) error, unattended func(func()) *PanicError) *expiryBatcher[K] {
+	return &expiryBatcher[K]{fn: fn, max: max, delay: delay, safely: safely, unattended: unattended}
+}
+
+// add queues keys, flushing any full batches, or everything if there's no delay.
+func (b *expiryBatcher[K]) add(keys []K) {
+	b.lock.Lock()
+	b.pending = append(b.pending, keys...)
+	all := b.closed || b.delay <= 0
+	full := b.max > 0 && len(b.pending) >= b.max
+	b.lock.Unlock()
+
+	switch {
+	case all:
+		b.flush(true)
+	case full:
+		b.flush(false)
+	}
+
+	b.lock.Lock()
+	if len(b.pending) > 0 && b.timer == nil && !b.closed {
+		b.timer = time.AfterFunc(b.delay, func() {
+			b.unattended(func() { b.flush(true) })
+		})
+	}
+	b.lock.Unlock()
+}
+
+// flush passes pending keys to fn, in batches of at most max keys. Unless all is true, only full batches are
+// passed on, leaving the remainder pending.
+func (b *expiryBatcher[K]) flush(all bool) {
+	b.flushing.Lock()
+	defer b.flushing.Unlock()
+
+	b.lock.Lock()
+	pending := b.pending
+	if !all && b.max > 0 {
+		n := len(pending) - len(pending)%b.max
+		pending, b.pending = pending[:n], append([]K(nil), pending[n:]...)
+	} else {
+		b.pending = nil
+	}
+	if b.timer != nil && (all || len(b.pending) == 0) {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	b.lock.Unlock()
+
+	for len(pending) > 0 {
+		batch := pending
+		if b.max > 0 && len(batch) > b.max {
+			batch = batch[:b.max]
+		}
+		pending = pending[len(batch):]
+
+		_ = b.safely("OnExpiredBatch", func() {
+			b.fn(batch)
+		})
+	}
+}
+
+// close flushes any pending keys. Keys added afterwards are flushed immediately.
+func (b *expiryBatcher[K]) close() {
+	b.lock.Lock()
+	b.closed = true
+	b.lock.Unlock()
+
+	b.flush(true)
+}
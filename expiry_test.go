@@ -0,0 +1,117 @@
+package lrucache
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchRecorder collects the batches passed to an OnExpiredBatch callback.
+type batchRecorder struct {
+	lock    sync.Mutex
+	batches [][]string
+}
+
+func (r *batchRecorder) record(keys []string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.batches = append(r.batches, append([]string(nil), keys...))
+}
+
+func (r *batchRecorder) keys() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var keys []string
+	for _, b := range r.batches {
+		keys = append(keys, b...)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestCache_OnExpiredBatch(t *testing.T) {
+	// Checks the keys of expired entries are delivered in batches no larger than the limit.
+
+	r := &batchRecorder{}
+	cache := NewCacheWithOptions[string, int](10,
+		WithPurgeInterval(10*time.Millisecond),
+		WithOnExpiredBatch(r.record, 2, time.Hour),
+	)
+
+	for _, k := range []string{"a", "b", "c", "d", "e"} {
+		require.NoError(t, cache.SetWithExpiry(k, 1, time.Now().Add(5*time.Millisecond)))
+	}
+	require.NoError(t, cache.Set("f", 1))
+
+	// Full batches are delivered without waiting for the delay.
+	assert.Eventually(t, func() bool {
+		return len(r.keys()) == 4
+	}, time.Second, 5*time.Millisecond)
+
+	// The remaining key is delivered on Close.
+	cache.Close()
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, r.keys())
+	for _, b := range r.batches {
+		assert.LessOrEqual(t, len(b), 2)
+	}
+}
+
+func TestCache_OnExpiredBatchDelay(t *testing.T) {
+	// Ensures a partial batch is delivered once the delay has passed, and that deletions aren't reported.
+
+	r := &batchRecorder{}
+	cache := NewCacheWithOptions[string, int](10,
+		WithPurgeInterval(10*time.Millisecond),
+		WithOnExpiredBatch(r.record, 0, 30*time.Millisecond),
+	)
+	defer cache.Close()
+
+	require.NoError(t, cache.SetWithExpiry("a", 1, time.Now().Add(5*time.Millisecond)))
+	require.NoError(t, cache.SetWithExpiry("b", 1, time.Now().Add(5*time.Millisecond)))
+	cache.Delete("b")
+
+	assert.Eventually(t, func() bool {
+		return len(r.keys()) == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"a"}, r.keys())
+}
+
+func TestCache_OnExpiredBatchTypeMismatch(t *testing.T) {
+	// Verifies a callback with the wrong key type is rejected at construction.
+
+	assert.Panics(t, func() {
+		NewCacheWithOptions[string, int](10, WithOnExpiredBatch(func(keys []int) {}, 0, 0))
+	})
+}
+
+func TestCache_ExpiredChannel(t *testing.T) {
+	// Checks expired entries are sent to the channel when purged, and dropped once its buffer is full.
+
+	cache := NewCacheWithOptions[string, int](10, WithPurgeInterval(0), WithExpiredChannel(2))
+	defer cache.Close()
+
+	for i, k := range []string{"a", "b", "c"} {
+		require.NoError(t, cache.SetWithExpiry(k, i, time.Now().Add(5*time.Millisecond)))
+	}
+	require.NoError(t, cache.Set("d", 3))
+	time.Sleep(10 * time.Millisecond)
+
+	assert.Equal(t, 3, cache.DeleteExpired())
+
+	var keys []string
+	for len(cache.Expired()) > 0 {
+		e := <-cache.Expired()
+		keys = append(keys, e.Key)
+		assert.Equal(t, e.Value, map[string]int{"a": 0, "b": 1, "c": 2}[e.Key])
+		assert.True(t, e.Removed.After(e.Expires))
+	}
+	assert.Len(t, keys, 2)
+
+	plain := NewCache[string, int](1)
+	defer plain.Close()
+	assert.Nil(t, plain.Expired())
+}
@@ -0,0 +1,82 @@
+package lrucache
+
+import "time"
+
+// ExpiryPolicy decides when entries expire. A cache's policy is chosen at construction, with WithExpiryPolicy;
+// the default, AbsoluteExpiry, uses the expiry each entry was set with.
+//
+// Both methods are called while holding the cache's lock, so must be quick, and must not call the cache.
+// A zero time means the entry doesn't expire.
+type ExpiryPolicy[K comparable, V any] interface {
+	// ExpireAfterWrite returns the expiry for an entry being stored by Set, SetAll, Compute or a loader.
+	// requested is the expiry it was set with, e.g. by WithExpiry or WithTTL, or zero if it wasn't given one.
+	ExpireAfterWrite(k K, v V, requested, now time.Time) time.Time
+
+	// ExpireAfterRead returns the expiry for an entry that has just been read by Get, GetMulti or a loading get,
+	// given its current expiry. Returning anything other than current takes the cache's write lock to update it.
+	ExpireAfterRead(k K, v V, current, now time.Time) time.Time
+}
+
+// absoluteExpiry is the ExpiryPolicy returned by AbsoluteExpiry.
+type absoluteExpiry[K comparable, V any] struct{}
+
+// AbsoluteExpiry returns the default ExpiryPolicy: entries expire at the time they were set with, if any, and reads
+// don't affect it.
+func AbsoluteExpiry[K comparable, V any]() ExpiryPolicy[K, V] {
+	return absoluteExpiry[K, V]{}
+}
+
+func (absoluteExpiry[K, V]) ExpireAfterWrite(_ K, _ V, requested, _ time.Time) time.Time {
+	return requested
+}
+
+func (absoluteExpiry[K, V]) ExpireAfterRead(_ K, _ V, current, _ time.Time) time.Time {
+	return current
+}
+
+// ttlExpiry is the ExpiryPolicy returned by ExpireAfterWrite and ExpireAfterAccess.
+type ttlExpiry[K comparable, V any] struct {
+	ttl    time.Duration
+	access bool // True if reads restart the TTL.
+}
+
+// ExpireAfterWrite returns an ExpiryPolicy under which entries expire ttl after they were stored, unless they were
+// set with an expiry of their own.
+func ExpireAfterWrite[K comparable, V any](ttl time.Duration) ExpiryPolicy[K, V] {
+	return ttlExpiry[K, V]{ttl: ttl}
+}
+
+// ExpireAfterAccess returns an ExpiryPolicy under which entries expire ttl after they were last stored or read,
+// unless they were set with an expiry of their own, which then only applies until they're first read.
+func ExpireAfterAccess[K comparable, V any](ttl time.Duration) ExpiryPolicy[K, V] {
+	return ttlExpiry[K, V]{ttl: ttl, access: true}
+}
+
+func (p ttlExpiry[K, V]) ExpireAfterWrite(_ K, _ V, requested, now time.Time) time.Time {
+	if !requested.IsZero() {
+		return requested
+	}
+	return now.Add(p.ttl)
+}
+
+func (p ttlExpiry[K, V]) ExpireAfterRead(_ K, _ V, current, now time.Time) time.Time {
+	if !p.access {
+		return current
+	}
+	return now.Add(p.ttl)
+}
+
+// extendAfterRead updates the expiry of each of nodes, which have just been read, to the corresponding one of
+// expiries, as given by the ExpiryPolicy. Nodes that have since been removed or replaced are skipped.
+func (lru *Cache[K, V]) extendAfterRead(nodes []*node[K, V], expiries []time.Time) {
+	lru.writeLock(OperationGet)
+	if !lru.stopped {
+		for i, n := range nodes {
+			if lru.cache[n.key] == n {
+				n.expires = expiries[i]
+				lru.noteExpiry(n.expires)
+			}
+		}
+	}
+	lru.lock.Unlock()
+}
@@ -0,0 +1,82 @@
+package lrucache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_ExpireAfterWrite(t *testing.T) {
+	// Checks entries without an expiry of their own are given the policy's TTL, and reads don't extend it.
+
+	cache := NewCacheWithOptions[string, int](10, WithExpiryPolicy(ExpireAfterWrite[string, int](time.Minute)))
+	defer cache.Close()
+
+	require.NoError(t, cache.Set("a", 1))
+	require.NoError(t, cache.SetWithOptions("b", 2, WithTTL(time.Hour)))
+
+	a, _ := cache.Entry("a")
+	assert.WithinDuration(t, time.Now().Add(time.Minute), a.Expires, time.Second)
+
+	b, _ := cache.Entry("b")
+	assert.WithinDuration(t, time.Now().Add(time.Hour), b.Expires, time.Second)
+
+	cache.Get("a")
+	after, _ := cache.Entry("a")
+	assert.Equal(t, a.Expires, after.Expires)
+}
+
+func TestCache_ExpireAfterAccess(t *testing.T) {
+	// Checks that reads by Get and GetMulti restart an entry's TTL, so frequently read entries are kept.
+
+	cache := NewCacheWithOptions[string, int](10,
+		WithExpiryPolicy(ExpireAfterAccess[string, int](30*time.Millisecond)),
+		WithPurgeInterval(time.Hour),
+	)
+	defer cache.Close()
+
+	require.NoError(t, cache.Set("a", 1))
+	require.NoError(t, cache.Set("b", 2))
+	require.NoError(t, cache.Set("c", 3))
+
+	for i := 0; i < 4; i++ {
+		time.Sleep(15 * time.Millisecond)
+		_, found := cache.Get("a")
+		require.True(t, found)
+		require.Len(t, cache.GetMulti([]string{"b"}), 1)
+	}
+
+	assert.True(t, cache.Contains("a"))
+	assert.True(t, cache.Contains("b"))
+	assert.False(t, cache.Contains("c"))
+}
+
+// valueExpiry is an ExpiryPolicy that gives each entry a TTL of its value, in milliseconds.
+type valueExpiry struct{}
+
+func (valueExpiry) ExpireAfterWrite(_ string, v int, _, now time.Time) time.Time {
+	return now.Add(time.Duration(v) * time.Millisecond)
+}
+
+func (valueExpiry) ExpireAfterRead(_ string, _ int, current, _ time.Time) time.Time {
+	return current
+}
+
+func TestCache_CustomExpiryPolicy(t *testing.T) {
+	// Checks a user-defined policy can derive each entry's expiry from its value.
+
+	cache := NewCacheWithOptions[string, int](10, WithExpiryPolicy[string, int](valueExpiry{}))
+	defer cache.Close()
+
+	require.NoError(t, cache.SetMulti(map[string]int{"short": 10, "long": 60000}))
+	time.Sleep(20 * time.Millisecond)
+
+	assert.False(t, cache.Contains("short"))
+	assert.True(t, cache.Contains("long"))
+
+	assert.Panics(t, func() {
+		NewCacheWithOptions[string, string](10, WithExpiryPolicy[string, int](valueExpiry{}))
+	})
+}
@@ -0,0 +1,226 @@
+// Package flagcache caches feature flag and configuration lookups, keeping them fresh with a background poller
+// and notifying callers when a value changes.
+//
+//	flags := flagcache.New(fetchFlag, flagcache.WithPollInterval[bool](30*time.Second))
+//	defer flags.Close()
+//
+//	checkout := flags.Scope("checkout")
+//	enabled, err := checkout.Get(ctx, "new-flow") // Fetches "checkout/new-flow" on first use.
+package flagcache
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/nsmithuk/lrucache"
+)
+
+// Fetcher fetches the current value of a flag from its source of truth.
+type Fetcher[V any] func(ctx context.Context, key string) (V, error)
+
+// Option configures a Cache.
+type Option[V any] func(*Cache[V])
+
+// WithCapacity sets the maximum number of flags held. The default is 10,000.
+func WithCapacity[V any](capacity uint64) Option[V] {
+	return func(c *Cache[V]) {
+		c.capacity = capacity
+	}
+}
+
+// WithPollInterval sets how often every cached flag is re-fetched. The default is 1 minute.
+func WithPollInterval[V any](interval time.Duration) Option[V] {
+	return func(c *Cache[V]) {
+		c.interval = interval
+	}
+}
+
+// WithTTL sets how long a value may be served without being successfully re-fetched. If polling fails for
+// longer, the flag expires and the next Get fetches it directly. The default is ten poll intervals.
+func WithTTL[V any](ttl time.Duration) Option[V] {
+	return func(c *Cache[V]) {
+		c.ttl = ttl
+	}
+}
+
+// WithOnChange sets a callback run when polling finds that a flag's value has changed.
+func WithOnChange[V any](fn func(key string, old, new V)) Option[V] {
+	return func(c *Cache[V]) {
+		c.onChange = fn
+	}
+}
+
+// WithEqual sets the function used to detect changed values. The default is reflect.DeepEqual.
+func WithEqual[V any](fn func(a, b V) bool) Option[V] {
+	return func(c *Cache[V]) {
+		c.equal = fn
+	}
+}
+
+// WithErrorHandler sets a function to receive errors from background polling.
+func WithErrorHandler[V any](fn func(error)) Option[V] {
+	return func(c *Cache[V]) {
+		c.errorHandler = fn
+	}
+}
+
+// Cache caches flag values fetched by a Fetcher, re-fetching all cached flags every poll interval.
+type Cache[V any] struct {
+	cache *lrucache.Cache[string, V]
+	fetch Fetcher[V]
+
+	capacity     uint64
+	interval     time.Duration
+	ttl          time.Duration
+	onChange     func(key string, old, new V)
+	equal        func(a, b V) bool
+	errorHandler func(error)
+
+	lock sync.Mutex
+	keys map[string]struct{} // The keys that have been fetched, for polling.
+
+	refreshing sync.Mutex // Serialises refreshes, so each change is reported once.
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a new Cache, fetching values with fetch, and starts its background poller.
+func New[V any](fetch Fetcher[V], opts ...Option[V]) *Cache[V] {
+	c := &Cache[V]{
+		fetch:    fetch,
+		capacity: 10000,
+		interval: time.Minute,
+		equal: func(a, b V) bool {
+			return reflect.DeepEqual(a, b)
+		},
+		keys: make(map[string]struct{}),
+		done: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.ttl <= 0 {
+		c.ttl = 10 * c.interval
+	}
+
+	cacheOpts := []lrucache.Option{
+		lrucache.WithOnEvict(func(k string, _ V, reason lrucache.EvictionReason) {
+			if reason == lrucache.EvictionReasonReplaced {
+				return
+			}
+			// Evicted flags are no longer polled, unless they're fetched again.
+			c.lock.Lock()
+			delete(c.keys, k)
+			c.lock.Unlock()
+		}),
+	}
+	if c.errorHandler != nil {
+		cacheOpts = append(cacheOpts, lrucache.WithErrorHandler(c.errorHandler))
+	}
+	c.cache = lrucache.NewCacheWithOptions[string, V](c.capacity, cacheOpts...)
+
+	c.wg.Add(1)
+	go c.poll()
+
+	return c
+}
+
+// Get returns the value of the flag, fetching it if it isn't cached. Once fetched, the flag is kept up to date
+// by the poller.
+func (c *Cache[V]) Get(ctx context.Context, key string) (V, error) {
+	return c.cache.GetOrLoad(ctx, key, func(ctx context.Context, key string) (V, time.Time, error) {
+		v, err := c.fetch(ctx, key)
+		if err != nil {
+			return v, time.Time{}, err
+		}
+		c.lock.Lock()
+		c.keys[key] = struct{}{}
+		c.lock.Unlock()
+		return v, time.Now().Add(c.ttl), nil
+	})
+}
+
+// Scope returns a view of the cache whose keys are prefixed with name and a slash.
+func (c *Cache[V]) Scope(name string) *Scope[V] {
+	return &Scope[V]{cache: c, prefix: name + "/"}
+}
+
+// Refresh re-fetches every cached flag immediately, returning the first error. It's also run every poll interval.
+func (c *Cache[V]) Refresh(ctx context.Context) error {
+	c.lock.Lock()
+	keys := make([]string, 0, len(c.keys))
+	for k := range c.keys {
+		keys = append(keys, k)
+	}
+	c.lock.Unlock()
+
+	var first error
+	for _, k := range keys {
+		if err := c.refresh(ctx, k); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Close stops the poller and closes the underlying cache.
+func (c *Cache[V]) Close() {
+	close(c.done)
+	c.wg.Wait()
+	c.cache.Close()
+}
+
+// refresh re-fetches a single flag, storing it and running the change callback if its value has changed.
+func (c *Cache[V]) refresh(ctx context.Context, key string) error {
+	v, err := c.fetch(ctx, key)
+	if err != nil {
+		return fmt.Errorf("unable to refresh flag %s: %w", key, err)
+	}
+
+	c.refreshing.Lock()
+	defer c.refreshing.Unlock()
+
+	old, existed := c.cache.Entry(key)
+	if err := c.cache.SetWithExpiry(key, v, time.Now().Add(c.ttl)); err != nil {
+		return fmt.Errorf("unable to store flag %s: %w", key, err)
+	}
+
+	if existed && c.onChange != nil && !c.equal(old.Value, v) {
+		c.onChange(key, old.Value, v)
+	}
+	return nil
+}
+
+// poll refreshes all cached flags every poll interval, until the cache is closed.
+func (c *Cache[V]) poll() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			if err := c.Refresh(context.Background()); err != nil && c.errorHandler != nil {
+				c.errorHandler(err)
+			}
+		}
+	}
+}
+
+// Scope is a view of a Cache where every key is prefixed, so independent components can share a single cache.
+type Scope[V any] struct {
+	cache  *Cache[V]
+	prefix string
+}
+
+// Get returns the value of the flag with the given key, within the scope.
+func (s *Scope[V]) Get(ctx context.Context, key string) (V, error) {
+	return s.cache.Get(ctx, s.prefix+key)
+}
@@ -0,0 +1,89 @@
+package flagcache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSource is an in-memory source of flags, for testing.
+type fakeSource struct {
+	lock   sync.Mutex
+	values map[string]bool
+	err    error
+	calls  int
+}
+
+func (f *fakeSource) set(key string, v bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.values[key] = v
+}
+
+func (f *fakeSource) fetch(ctx context.Context, key string) (bool, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.calls++
+	return f.values[key], f.err
+}
+
+func TestCache_GetAndPoll(t *testing.T) {
+	source := &fakeSource{values: map[string]bool{"checkout/new-flow": false}}
+
+	type change struct {
+		key      string
+		old, new bool
+	}
+	changes := make(chan change, 10)
+
+	flags := New(source.fetch,
+		WithPollInterval[bool](10*time.Millisecond),
+		WithOnChange(func(key string, old, new bool) {
+			changes <- change{key, old, new}
+		}),
+	)
+	defer flags.Close()
+
+	enabled, err := flags.Scope("checkout").Get(context.Background(), "new-flow")
+	require.NoError(t, err)
+	assert.False(t, enabled)
+
+	source.set("checkout/new-flow", true)
+
+	select {
+	case c := <-changes:
+		assert.Equal(t, change{"checkout/new-flow", false, true}, c)
+	case <-time.After(time.Second):
+		t.Fatal("change wasn't reported")
+	}
+
+	enabled, err = flags.Get(context.Background(), "checkout/new-flow")
+	require.NoError(t, err)
+	assert.True(t, enabled)
+}
+
+func TestCache_RefreshFailureKeepsValue(t *testing.T) {
+	source := &fakeSource{values: map[string]bool{"flag": true}}
+
+	flags := New(source.fetch, WithPollInterval[bool](time.Hour))
+	defer flags.Close()
+
+	_, err := flags.Get(context.Background(), "flag")
+	require.NoError(t, err)
+
+	source.lock.Lock()
+	source.err = errors.New("source unavailable")
+	source.lock.Unlock()
+
+	// The stale value is served until its TTL passes.
+	assert.ErrorIs(t, flags.Refresh(context.Background()), source.err)
+	enabled, err := flags.Get(context.Background(), "flag")
+	require.NoError(t, err)
+	assert.True(t, enabled)
+	assert.Equal(t, 2, source.calls)
+}
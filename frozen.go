@@ -0,0 +1,79 @@
+package lrucache
+
+import (
+	"iter"
+	"time"
+)
+
+// Snapshot is an immutable copy of a cache's unexpired entries, taken at a single point in time by
+// Cache.Snapshot. It can be iterated or exported at leisure, without holding the cache's lock, and without
+// observing changes, such as evictions, made to the cache since it was taken.
+type Snapshot[K comparable, V any] struct {
+	taken   time.Time
+	entries []Entry[K, V] // From the most recently used to the least.
+	index   map[K]int     // Key to its position in entries.
+}
+
+// Snapshot returns a consistent copy of the cache's unexpired entries, in LRU order. The entries are copied under
+// a single acquisition of the read lock, so the copy is never torn by a concurrent write, but values are copied
+// shallowly: pointers, maps and slices are shared with the cache.
+func (lru *Cache[K, V]) Snapshot() *Snapshot[K, V] {
+	s := &Snapshot[K, V]{taken: time.Now()}
+
+	lru.readLock(OperationOther)
+	if lru.stopped {
+		lru.lock.RUnlock()
+		return s
+	}
+	lru.runOnEventLoop(func() {
+		s.entries = make([]Entry[K, V], 0, lru.length)
+		for n := lru.head.next; n != lru.tail; n = n.next {
+			if !n.negative && !lru.isExpired(n, s.taken) {
+				s.entries = append(s.entries, n.entry())
+			}
+		}
+	})
+	lru.lock.RUnlock()
+
+	// Indexed outside the lock, as only the snapshot needs it.
+	s.index = make(map[K]int, len(s.entries))
+	for i, e := range s.entries {
+		s.index[e.Key] = i
+	}
+	return s
+}
+
+// Taken returns when the snapshot was taken. Entries are included if they were unexpired at this time.
+func (s *Snapshot[K, V]) Taken() time.Time {
+	return s.taken
+}
+
+// Len returns the number of entries in the snapshot.
+func (s *Snapshot[K, V]) Len() int {
+	return len(s.entries)
+}
+
+// Get returns the entry for k, as it was when the snapshot was taken.
+func (s *Snapshot[K, V]) Get(k K) (Entry[K, V], bool) {
+	i, found := s.index[k]
+	if !found {
+		return Entry[K, V]{}, false
+	}
+	return s.entries[i], true
+}
+
+// All returns an iterator over the entries, from the most recently used to the least.
+func (s *Snapshot[K, V]) All() iter.Seq[Entry[K, V]] {
+	return func(yield func(Entry[K, V]) bool) {
+		for _, e := range s.entries {
+			if !yield(e) {
+				return
+			}
+		}
+	}
+}
+
+// Entries returns a copy of the entries, from the most recently used to the least.
+func (s *Snapshot[K, V]) Entries() []Entry[K, V] {
+	return append([]Entry[K, V](nil), s.entries...)
+}
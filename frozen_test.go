@@ -0,0 +1,53 @@
+package lrucache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot_IsUnaffectedByLaterChanges(t *testing.T) {
+	// Checks a snapshot holds the entries in LRU order, and doesn't see writes made after it was taken.
+
+	cache := NewCache[string, int](3)
+	defer cache.Close()
+
+	require.NoError(t, cache.Set("a", 1))
+	require.NoError(t, cache.Set("b", 2))
+	require.NoError(t, cache.Set("c", 3))
+
+	s := cache.Snapshot()
+
+	require.NoError(t, cache.Set("d", 4)) // Evicts a.
+	require.NoError(t, cache.Set("b", 5))
+	cache.Delete("c")
+
+	assert.Equal(t, 3, s.Len())
+	var keys []string
+	for e := range s.All() {
+		keys = append(keys, e.Key)
+	}
+	assert.Equal(t, []string{"c", "b", "a"}, keys)
+
+	e, found := s.Get("b")
+	assert.True(t, found)
+	assert.Equal(t, 2, e.Value)
+	_, found = s.Get("d")
+	assert.False(t, found)
+
+	entries := s.Entries()
+	entries[0].Value = 100
+	e, _ = s.Get("c")
+	assert.Equal(t, 3, e.Value)
+}
+
+func TestSnapshot_Closed(t *testing.T) {
+	// Checks a closed cache gives an empty snapshot.
+
+	cache := NewCache[string, int](3)
+	require.NoError(t, cache.Set("a", 1))
+	cache.Close()
+
+	assert.Zero(t, cache.Snapshot().Len())
+}
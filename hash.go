@@ -0,0 +1,42 @@
+package lrucache
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/maphash"
+)
+
+// keyHasher hashes keys of any comparable type. Strings and integers are hashed directly; other types are hashed
+// by their Go-syntax representation, which is slower, but equal for equal keys. Each keyHasher has its own random
+// seed, so hashes differ between instances and processes, and can't be targeted by whoever chooses the keys.
+type keyHasher[K comparable] struct {
+	seed maphash.Seed
+}
+
+func newKeyHasher[K comparable]() keyHasher[K] {
+	return keyHasher[K]{seed: maphash.MakeSeed()}
+}
+
+// hash returns the hash of k.
+func (h keyHasher[K]) hash(k K) uint64 {
+	var b [8]byte
+	switch k := any(k).(type) {
+	case string:
+		return maphash.String(h.seed, k)
+	case int:
+		binary.LittleEndian.PutUint64(b[:], uint64(k))
+	case int32:
+		binary.LittleEndian.PutUint64(b[:], uint64(k))
+	case int64:
+		binary.LittleEndian.PutUint64(b[:], uint64(k))
+	case uint:
+		binary.LittleEndian.PutUint64(b[:], uint64(k))
+	case uint32:
+		binary.LittleEndian.PutUint64(b[:], uint64(k))
+	case uint64:
+		binary.LittleEndian.PutUint64(b[:], k)
+	default:
+		return maphash.String(h.seed, fmt.Sprintf("%#v", k))
+	}
+	return maphash.Bytes(h.seed, b[:])
+}
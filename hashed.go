@@ -0,0 +1,169 @@
+package lrucache
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// HashedCache is an LRU cache for keys that aren't comparable, such as byte slices or structs containing slices,
+// using caller-supplied hash and equality functions in place of Go's map equality. Keys are stored and looked up by
+// their hash, so there's no need to convert them, e.g. from []byte to string, on each lookup.
+//
+// Keys whose hashes collide share a single slot in the underlying cache: their sizes are summed, and they're
+// promoted and evicted together. With a good 64-bit hash, collisions are rare.
+type HashedCache[K any, V any] struct {
+	cache *Cache[uint64, []hashedEntry[K, V]]
+	hash  func(K) uint64
+	equal func(a, b K) bool
+
+	lock sync.Mutex // Serialises writes, as each reads and replaces a whole slot.
+}
+
+// hashedEntry is a single entry within a HashedCache slot.
+type hashedEntry[K any, V any] struct {
+	key     K
+	value   V
+	size    uint64
+	expires time.Time
+	tags    []string
+}
+
+// NewHashedCache creates a new HashedCache with the specified capacity, using hash and equal to identify keys.
+// Keys that are equal must have the same hash. The cache is configured by the given Options, except those taking
+// callbacks typed on the cache's keys or values, such as WithOnEvict, which aren't supported.
+func NewHashedCache[K any, V any](capacity uint64, hash func(K) uint64, equal func(a, b K) bool, opts ...Option) *HashedCache[K, V] {
+	return &HashedCache[K, V]{
+		cache: NewCacheWithOptions[uint64, []hashedEntry[K, V]](capacity, opts...),
+		hash:  hash,
+		equal: equal,
+	}
+}
+
+// Close shuts down the underlying cache; see Cache.Close.
+func (c *HashedCache[K, V]) Close() {
+	c.cache.Close()
+}
+
+// Get retrieves the value for k, moving it to the front of the list. found is false if k isn't in the cache or
+// has expired.
+func (c *HashedCache[K, V]) Get(k K) (v V, found bool) {
+	slot, found := c.cache.Get(c.hash(k))
+	if !found {
+		return v, false
+	}
+	if i := c.find(slot, k, time.Now()); i >= 0 {
+		return slot[i].value, true
+	}
+	return v, false
+}
+
+// Contains reports whether an unexpired entry exists for k, without affecting its LRU position.
+func (c *HashedCache[K, V]) Contains(k K) bool {
+	e, found := c.cache.Entry(c.hash(k))
+	return found && c.find(e.Value, k, time.Now()) >= 0
+}
+
+// Set adds a key-value pair to the cache with a size of 1 and no expiry, replacing any existing value for k.
+func (c *HashedCache[K, V]) Set(k K, v V) error {
+	return c.SetWithOptions(k, v)
+}
+
+// SetWithOptions adds a key-value pair to the cache, configured by the given EntryOptions. Tags and metadata
+// apply to the slot holding k, so are shared with any keys whose hashes collide with it. The slot keeps the tags of
+// every key in it, while metadata is replaced by that of the latest Set.
+func (c *HashedCache[K, V]) SetWithOptions(k K, v V, opts ...EntryOption) error {
+	eo := entryOptions{size: 1}
+	for _, opt := range opts {
+		opt(&eo)
+	}
+
+	if err := c.cache.validate(eo.size, eo.expires); err != nil {
+		return err
+	}
+
+	h := c.hash(k)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	slot := c.without(h, k)
+	slot = append(slot, hashedEntry[K, V]{key: k, value: v, size: eo.size, expires: eo.expires, tags: eo.tags})
+	return c.store(h, slot, eo)
+}
+
+// Delete removes the entry for k, if it exists.
+func (c *HashedCache[K, V]) Delete(k K) {
+	h := c.hash(k)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	e, found := c.cache.Entry(h)
+	if !found || c.find(e.Value, k, time.Now()) < 0 {
+		return
+	}
+
+	slot := c.without(h, k)
+	if len(slot) == 0 {
+		c.cache.Delete(h)
+		return
+	}
+	_ = c.store(h, slot, entryOptions{metadata: e.Metadata})
+}
+
+// find returns the index of the unexpired entry for k within slot, or -1 if there isn't one.
+func (c *HashedCache[K, V]) find(slot []hashedEntry[K, V], k K, now time.Time) int {
+	for i := range slot {
+		if c.equal(slot[i].key, k) && (slot[i].expires.IsZero() || !slot[i].expires.Before(now)) {
+			return i
+		}
+	}
+	return -1
+}
+
+// without returns a copy of the slot for h, without the entry for k or any expired entries.
+// Assumes c.lock is held.
+func (c *HashedCache[K, V]) without(h uint64, k K) []hashedEntry[K, V] {
+	e, found := c.cache.Entry(h)
+	if !found {
+		return nil
+	}
+
+	now := time.Now()
+	slot := make([]hashedEntry[K, V], 0, len(e.Value)+1)
+	for _, he := range e.Value {
+		if !c.equal(he.key, k) && (he.expires.IsZero() || !he.expires.Before(now)) {
+			slot = append(slot, he)
+		}
+	}
+	return slot
+}
+
+// store replaces the slot for h, with eo's metadata. Its size is the total of its entries', it expires with the
+// last of them, and it has all their tags.
+// Assumes c.lock is held.
+func (c *HashedCache[K, V]) store(h uint64, slot []hashedEntry[K, V], eo entryOptions) error {
+	var size uint64
+	var expires time.Time
+	var tags []string
+	forever := false
+	for _, he := range slot {
+		size += he.size
+		for _, tag := range he.tags {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		if he.expires.IsZero() {
+			forever = true
+		} else if he.expires.After(expires) {
+			expires = he.expires
+		}
+	}
+	if forever {
+		expires = time.Time{}
+	}
+
+	return c.cache.SetWithOptions(h, slot, WithSize(size), WithExpiry(expires), WithTags(tags...), WithMetadata(eo.metadata))
+}
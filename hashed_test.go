@@ -0,0 +1,84 @@
+package lrucache
+
+import (
+	"bytes"
+	"hash/maphash"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashedCache_ByteSliceKeys(t *testing.T) {
+	// Checks that non-comparable keys can be stored, replaced and deleted.
+
+	seed := maphash.MakeSeed()
+	cache := NewHashedCache[[]byte, int](10, func(k []byte) uint64 { return maphash.Bytes(seed, k) }, bytes.Equal)
+	defer cache.Close()
+
+	assert.NoError(t, cache.Set([]byte("a"), 1))
+	assert.NoError(t, cache.Set([]byte("b"), 2))
+	assert.NoError(t, cache.Set([]byte("a"), 3))
+
+	v, found := cache.Get([]byte("a"))
+	assert.True(t, found)
+	assert.Equal(t, 3, v)
+	assert.True(t, cache.Contains([]byte("b")))
+
+	cache.Delete([]byte("b"))
+	assert.False(t, cache.Contains([]byte("b")))
+
+	assert.ErrorIs(t, cache.SetWithOptions([]byte("c"), 1, WithSize(0)), ErrItemTooSmall)
+}
+
+func TestHashedCache_Collisions(t *testing.T) {
+	// Checks that keys with the same hash are kept apart, and share their slot's size and expiry.
+
+	cache := NewHashedCache[[]byte, string](10, func([]byte) uint64 { return 1 }, bytes.Equal)
+	defer cache.Close()
+
+	assert.NoError(t, cache.SetWithOptions([]byte("a"), "a", WithSize(2)))
+	assert.NoError(t, cache.SetWithOptions([]byte("b"), "b", WithSize(3), WithTTL(time.Millisecond)))
+
+	v, _ := cache.Get([]byte("a"))
+	assert.Equal(t, "a", v)
+	v, _ = cache.Get([]byte("b"))
+	assert.Equal(t, "b", v)
+	assert.Equal(t, uint64(5), cache.cache.Size())
+
+	// The slot outlives the expired key, as "a" has no expiry.
+	time.Sleep(5 * time.Millisecond)
+	assert.False(t, cache.Contains([]byte("b")))
+	assert.True(t, cache.Contains([]byte("a")))
+
+	cache.Delete([]byte("a"))
+	assert.False(t, cache.Contains([]byte("a")))
+	assert.Equal(t, uint64(0), cache.cache.Size())
+}
+
+func TestHashedCache_CollisionTags(t *testing.T) {
+	// Checks the slot keeps the tags of every key in it, through Sets and Deletes of the keys colliding with them.
+
+	cache := NewHashedCache[[]byte, string](10, func([]byte) uint64 { return 1 }, bytes.Equal)
+	defer cache.Close()
+
+	assert.NoError(t, cache.SetWithOptions([]byte("a"), "a", WithTags("x")))
+	assert.NoError(t, cache.SetWithOptions([]byte("b"), "b", WithTags("y")))
+	assert.NoError(t, cache.SetWithOptions([]byte("c"), "c", WithTags("y")))
+	assert.Equal(t, 1, cache.cache.TagCount("x"))
+	assert.Equal(t, 1, cache.cache.TagCount("y"))
+
+	cache.Delete([]byte("b"))
+	assert.Equal(t, 1, cache.cache.TagCount("x"))
+	assert.Equal(t, 1, cache.cache.TagCount("y"))
+
+	cache.Delete([]byte("c"))
+	assert.Equal(t, 1, cache.cache.TagCount("x"))
+	assert.Equal(t, 0, cache.cache.TagCount("y"))
+
+	// Invalidating the remaining key's tag removes it.
+	assert.NoError(t, cache.SetWithOptions([]byte("d"), "d", WithTags("z")))
+	cache.Delete([]byte("d"))
+	assert.Equal(t, 1, cache.cache.DeleteTag("x"))
+	assert.False(t, cache.Contains([]byte("a")))
+}
@@ -0,0 +1,69 @@
+package lrucache
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// checkpointHotKeys writes the hottest keys to the file of WithHotKeyProfile every interval and once more when the
+// cache is closed, until then.
+func (lru *Cache[K, V]) checkpointHotKeys(path string, n int, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-lru.done:
+			lru.saveHotKeyProfile(path, n)
+			return
+		case <-ticker.C:
+			lru.saveHotKeyProfile(path, n)
+		}
+	}
+}
+
+// saveHotKeyProfile writes up to n of the hottest keys to path, replacing it atomically, so a crash part way through
+// leaves the previous profile in place. Failures are passed to the error handler.
+func (lru *Cache[K, V]) saveHotKeyProfile(path string, n int) {
+	err := func() error {
+		f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(f.Name())
+
+		if err := lru.SaveHotKeys(f, n); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		return os.Rename(f.Name(), path)
+	}()
+	if err != nil {
+		lru.handleError(fmt.Errorf("unable to save hot key profile to %s: %w", path, err))
+		return
+	}
+	lru.log(slog.LevelDebug, "lrucache: saved hot key profile", "path", path)
+}
+
+// ReadHotKeyProfile reads the keys saved by WithHotKeyProfile to path, from the hottest to the coldest, e.g. to pass
+// to Prefetch when the process starts. If there's no profile yet, such as on the first run, the result is empty.
+func ReadHotKeyProfile[K comparable](path string) ([]K, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to open hot key profile: %w", err)
+	}
+	defer f.Close()
+
+	return ReadHotKeys[K](f)
+}
@@ -0,0 +1,64 @@
+package lrucache
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_HotKeyProfile(t *testing.T) {
+	// Checks the hottest keys are checkpointed periodically, and once more on close.
+
+	path := filepath.Join(t.TempDir(), "hot.keys")
+
+	keys, err := ReadHotKeyProfile[int](path)
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	cache := NewCacheWithOptions[int, int](10, WithHotKeyProfile(path, 2, 10*time.Millisecond))
+	for i := 0; i < 5; i++ {
+		require.NoError(t, cache.Set(i, i))
+	}
+
+	assert.Eventually(t, func() bool {
+		keys, err := ReadHotKeyProfile[int](path)
+		return err == nil && len(keys) == 2
+	}, time.Second, 5*time.Millisecond)
+
+	cache.Get(0)
+	cache.Close()
+
+	keys, err = ReadHotKeyProfile[int](path)
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 4}, keys)
+
+	// No temporary files are left behind.
+	files, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestCache_HotKeyProfileError(t *testing.T) {
+	// Checks a failure to write the profile is passed to the error handler.
+
+	var mu sync.Mutex
+	var reported []error
+	path := filepath.Join(t.TempDir(), "missing", "hot.keys")
+
+	cache := NewCacheWithOptions[int, int](10, WithHotKeyProfile(path, 2, time.Hour), WithErrorHandler(func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		reported = append(reported, err)
+	}))
+	cache.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, reported, 1)
+	assert.ErrorContains(t, reported[0], "unable to save hot key profile")
+}
@@ -0,0 +1,85 @@
+package httpcache
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cacheControl holds the parsed directives of a Cache-Control header.
+type cacheControl map[string]string
+
+// parseCacheControl parses the Cache-Control header(s) of h. Directive names are lower-cased.
+func parseCacheControl(h http.Header) cacheControl {
+	cc := cacheControl{}
+	for _, v := range h.Values("Cache-Control") {
+		for _, part := range strings.Split(v, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			name, value, _ := strings.Cut(part, "=")
+			cc[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+	return cc
+}
+
+func (cc cacheControl) has(name string) bool {
+	_, found := cc[name]
+	return found
+}
+
+// seconds returns the value of a directive such as max-age, as a duration.
+func (cc cacheControl) seconds(name string) (time.Duration, bool) {
+	v, found := cc[name]
+	if !found {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return time.Duration(n) * time.Second, true
+}
+
+// freshnessLifetime returns how long a response may be served from the cache, based on its Cache-Control and
+// Expires headers. A zero or negative duration means the response must not be cached.
+func freshnessLifetime(h http.Header, now time.Time) time.Duration {
+	cc := parseCacheControl(h)
+
+	if cc.has("no-store") || cc.has("no-cache") {
+		return 0
+	}
+
+	if ttl, found := cc.seconds("s-maxage"); found {
+		return ttl
+	}
+	if ttl, found := cc.seconds("max-age"); found {
+		return ttl
+	}
+
+	if v := h.Get("Expires"); v != "" {
+		expires, err := http.ParseTime(v)
+		if err != nil {
+			// An invalid Expires value means the response is already stale.
+			return 0
+		}
+
+		// Expires is relative to the server's clock, as given by the Date header.
+		date := now
+		if d, err := http.ParseTime(h.Get("Date")); err == nil {
+			date = d
+		}
+		return expires.Sub(date)
+	}
+
+	return 0
+}
+
+// sharedCacheable returns false if a response with the headers h mustn't be stored by a cache shared between
+// clients: it's marked private, or sets cookies, which would then be served to everyone.
+func sharedCacheable(h http.Header) bool {
+	return !parseCacheControl(h).has("private") && len(h.Values("Set-Cookie")) == 0
+}
@@ -0,0 +1,170 @@
+// Package httpcache provides HTTP caching helpers built on top of lrucache.
+package httpcache
+
+import (
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// KeyConfig describes how a request is turned into a cache key.
+// The zero value keys on the method, host, path and the full (sorted) query string.
+type KeyConfig struct {
+	// Vary lists request headers whose values form part of the key, in addition to any
+	// headers named by a response's Vary header.
+	Vary []string
+
+	// IncludeQuery, if not empty, restricts the query parameters used in the key to only these names.
+	IncludeQuery []string
+
+	// IgnoreQuery lists query parameters that are dropped from the key (e.g. utm_source).
+	IgnoreQuery []string
+
+	// IgnoreQueryValueOrder if true, repeated query parameters are sorted by value, so ?a=2&a=1 and ?a=1&a=2 share a key.
+	IgnoreQueryValueOrder bool
+
+	// CaseInsensitivePath if true, the path is lower-cased before being used in the key.
+	CaseInsensitivePath bool
+}
+
+// Key returns the cache key for the request.
+func (c KeyConfig) Key(r *http.Request) string {
+	return c.KeyWithVary(r, nil)
+}
+
+// KeyWithVary returns the cache key for the request, additionally honouring the header names given in vary.
+// vary is typically the parsed Vary header of a previously cached response; see ParseVary.
+func (c KeyConfig) KeyWithVary(r *http.Request, vary []string) string {
+	var b strings.Builder
+
+	b.WriteString(r.Method)
+	b.WriteByte(' ')
+
+	if r.URL != nil {
+		host := r.URL.Host
+		if host == "" {
+			host = r.Host
+		}
+		b.WriteString(strings.ToLower(r.URL.Scheme))
+		b.WriteString("://")
+		b.WriteString(strings.ToLower(host))
+
+		path := r.URL.EscapedPath()
+		if c.CaseInsensitivePath {
+			path = strings.ToLower(path)
+		}
+		b.WriteString(path)
+
+		if q := c.normaliseQuery(r.URL.Query()); q != "" {
+			b.WriteByte('?')
+			b.WriteString(q)
+		}
+	}
+
+	headers := normaliseHeaderNames(append(slices.Clone(c.Vary), vary...))
+	for _, name := range headers {
+		b.WriteByte('\n')
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(strings.Join(r.Header.Values(name), ","))
+	}
+
+	return b.String()
+}
+
+// normaliseQuery applies the include/ignore rules and returns the query in a stable, sorted encoding.
+func (c KeyConfig) normaliseQuery(values url.Values) string {
+	if len(c.IncludeQuery) > 0 {
+		for name := range values {
+			if !slices.Contains(c.IncludeQuery, name) {
+				delete(values, name)
+			}
+		}
+	}
+	for _, name := range c.IgnoreQuery {
+		delete(values, name)
+	}
+	if c.IgnoreQueryValueOrder {
+		for _, v := range values {
+			slices.Sort(v)
+		}
+	}
+	// Encode sorts by key.
+	return values.Encode()
+}
+
+// ParseVary returns the canonical header names listed in the Vary header(s) of h.
+// A Vary value of "*" is returned as-is; such responses should not be cached.
+func ParseVary(h http.Header) []string {
+	var names []string
+	for _, v := range h.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return normaliseHeaderNames(names)
+}
+
+// normaliseHeaderNames canonicalises, sorts and de-duplicates header names.
+func normaliseHeaderNames(names []string) []string {
+	for i, name := range names {
+		names[i] = textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(name))
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+//---
+
+// KeyRules selects a KeyConfig per route, so different endpoints can vary on different headers and query parameters.
+// Routes are matched by path prefix, with the longest matching prefix winning.
+type KeyRules struct {
+	// Default is used when no route matches.
+	Default KeyConfig
+
+	routes []route
+}
+
+type route struct {
+	prefix string
+	config KeyConfig
+}
+
+// Handle registers the config to be used for requests whose path starts with prefix.
+func (k *KeyRules) Handle(prefix string, config KeyConfig) {
+	k.routes = append(k.routes, route{prefix: prefix, config: config})
+
+	// Keep the longest prefixes first so the first match is the most specific.
+	slices.SortStableFunc(k.routes, func(a, b route) int {
+		return len(b.prefix) - len(a.prefix)
+	})
+}
+
+// Config returns the KeyConfig that applies to the request.
+func (k *KeyRules) Config(r *http.Request) KeyConfig {
+	path := ""
+	if r.URL != nil {
+		path = r.URL.Path
+	}
+	for _, rt := range k.routes {
+		if strings.HasPrefix(path, rt.prefix) {
+			return rt.config
+		}
+	}
+	return k.Default
+}
+
+// Key returns the cache key for the request, using the KeyConfig of the matching route.
+func (k *KeyRules) Key(r *http.Request) string {
+	return k.Config(r).Key(r)
+}
+
+// KeyWithVary returns the cache key for the request, using the KeyConfig of the matching route and
+// additionally honouring the given Vary header names.
+func (k *KeyRules) KeyWithVary(r *http.Request, vary []string) string {
+	return k.Config(r).KeyWithVary(r, vary)
+}
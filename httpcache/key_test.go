@@ -0,0 +1,71 @@
+package httpcache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyConfig_QueryNormalisation(t *testing.T) {
+	// Ensures that query parameter order does not affect the key, and that ignored parameters are dropped.
+
+	c := KeyConfig{IgnoreQuery: []string{"utm_source"}, IgnoreQueryValueOrder: true}
+
+	a := httptest.NewRequest(http.MethodGet, "http://example.com/p?b=2&a=1&a=0&utm_source=x", nil)
+	b := httptest.NewRequest(http.MethodGet, "http://Example.com/p?a=0&a=1&b=2", nil)
+	assert.Equal(t, c.Key(a), c.Key(b))
+
+	c = KeyConfig{IncludeQuery: []string{"id"}}
+	a = httptest.NewRequest(http.MethodGet, "http://example.com/p?id=1&cb=123", nil)
+	b = httptest.NewRequest(http.MethodGet, "http://example.com/p?cb=456&id=1", nil)
+	assert.Equal(t, c.Key(a), c.Key(b))
+
+	b = httptest.NewRequest(http.MethodGet, "http://example.com/p?id=2", nil)
+	assert.NotEqual(t, c.Key(a), c.Key(b))
+}
+
+func TestKeyConfig_Vary(t *testing.T) {
+	// Checks that headers named statically, or by a response's Vary header, form part of the key.
+
+	c := KeyConfig{Vary: []string{"accept-language"}}
+
+	a := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+	a.Header.Set("Accept-Language", "en")
+	b := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+	b.Header.Set("Accept-Language", "fr")
+	assert.NotEqual(t, c.Key(a), c.Key(b))
+
+	a.Header.Set("Accept-Encoding", "gzip")
+	b.Header.Set("Accept-Language", "en")
+	assert.Equal(t, c.Key(a), c.Key(b))
+
+	resp := http.Header{}
+	resp.Add("Vary", "accept-encoding, Accept-Language")
+	vary := ParseVary(resp)
+	assert.Equal(t, []string{"Accept-Encoding", "Accept-Language"}, vary)
+	assert.NotEqual(t, c.KeyWithVary(a, vary), c.KeyWithVary(b, vary))
+}
+
+func TestKeyRules_Routes(t *testing.T) {
+	// Validates that the most specific route's config is used.
+
+	rules := &KeyRules{}
+	rules.Handle("/api/", KeyConfig{IgnoreQuery: []string{"cb"}})
+	rules.Handle("/api/users/", KeyConfig{Vary: []string{"Authorization"}})
+
+	a := httptest.NewRequest(http.MethodGet, "http://example.com/api/items?cb=1", nil)
+	b := httptest.NewRequest(http.MethodGet, "http://example.com/api/items?cb=2", nil)
+	assert.Equal(t, rules.Key(a), rules.Key(b))
+
+	a = httptest.NewRequest(http.MethodGet, "http://example.com/api/users/1", nil)
+	a.Header.Set("Authorization", "one")
+	b = httptest.NewRequest(http.MethodGet, "http://example.com/api/users/1", nil)
+	b.Header.Set("Authorization", "two")
+	assert.NotEqual(t, rules.Key(a), rules.Key(b))
+
+	a = httptest.NewRequest(http.MethodGet, "http://example.com/other?x=1", nil)
+	b = httptest.NewRequest(http.MethodGet, "http://example.com/other?x=2", nil)
+	assert.NotEqual(t, rules.Key(a), rules.Key(b))
+}
@@ -0,0 +1,137 @@
+package httpcache
+
+import (
+	"bytes"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nsmithuk/lrucache"
+)
+
+// Middleware returns a handler caching the responses rendered by next, including their status, headers and body,
+// for GET requests. It's the server-side companion to Transport.
+//   - keyFn returns the cache key for a request. If nil, the zero KeyConfig's Key is used; a KeyConfig or KeyRules
+//     can be used to vary on headers and query parameters.
+//   - ttlFn returns how long a response may be cached; zero or negative means it isn't. If nil, the response's own
+//     Cache-Control and Expires headers decide, as for Transport, except that, as the cache is shared between
+//     clients, responses marked private or setting cookies aren't cached.
+//
+// Only responses with the status codes Transport caches are stored. A response with a Vary header is stored under a
+// key that also includes the request's values of the headers it names, as by Transport, so each variant is only
+// served to matching requests; responses that vary on "*" aren't cached. Responses served from the cache have
+// CacheHeader set to "HIT", and an Age header.
+func Middleware(next http.Handler, cache *lrucache.Cache[string, *CachedResponse], keyFn func(r *http.Request) string, ttlFn func(r *http.Request, resp *CachedResponse) time.Duration) http.Handler {
+	if keyFn == nil {
+		keyFn = KeyConfig{}.Key
+	}
+	if ttlFn == nil {
+		ttlFn = func(_ *http.Request, resp *CachedResponse) time.Duration {
+			if !sharedCacheable(resp.Header) {
+				return 0
+			}
+			return freshnessLifetime(resp.Header, resp.Stored)
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key := keyFn(r)
+		cached, found := cache.Get(key)
+		if found && cached.variants {
+			cached, found = cache.Get(varyKey(key, r, cached.Vary))
+		}
+		if found {
+			cached.write(w)
+			return
+		}
+
+		rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		if !slices.Contains(cacheableStatus, rec.status) {
+			return
+		}
+
+		vary := ParseVary(w.Header())
+		if slices.Contains(vary, "*") {
+			return
+		}
+
+		cached = &CachedResponse{
+			StatusCode: rec.status,
+			Header:     w.Header().Clone(),
+			Body:       rec.body.Bytes(),
+			Stored:     time.Now(),
+			Vary:       vary,
+		}
+		ttl := ttlFn(r, cached)
+		if ttl <= 0 {
+			return
+		}
+
+		// Failing to cache (e.g. the response is too big) shouldn't fail the request.
+		expires := cached.Stored.Add(ttl)
+		if len(vary) == 0 {
+			_ = cache.SetWithExpiry(key, cached, expires)
+			return
+		}
+		marker := &CachedResponse{Vary: vary, Stored: cached.Stored, variants: true}
+		_ = cache.SetWithExpiry(key, marker, expires)
+		_ = cache.SetWithExpiry(varyKey(key, r, vary), cached, expires)
+	})
+}
+
+// varyKey returns key extended with the request's values of the headers named in vary, as KeyWithVary does.
+func varyKey(key string, r *http.Request, vary []string) string {
+	var b strings.Builder
+	b.WriteString(key)
+	for _, name := range vary {
+		b.WriteByte('\n')
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(strings.Join(r.Header.Values(name), ","))
+	}
+	return b.String()
+}
+
+// write sends the cached response to w.
+func (c *CachedResponse) write(w http.ResponseWriter) {
+	header := w.Header()
+	for k, v := range c.Header {
+		header[k] = slices.Clone(v)
+	}
+	header.Set(CacheHeader, "HIT")
+	header.Set("Age", strconv.FormatInt(int64(time.Since(c.Stored)/time.Second), 10))
+
+	w.WriteHeader(c.StatusCode)
+	_, _ = w.Write(c.Body)
+}
+
+// recorder passes a response through to the client, while keeping a copy of its status and body.
+type recorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
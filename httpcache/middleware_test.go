@@ -0,0 +1,161 @@
+package httpcache
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nsmithuk/lrucache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware_CachesResponses(t *testing.T) {
+	// Checks fresh responses are served from the cache, with their status and headers, and others are not.
+
+	var hits atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := hits.Add(1)
+		switch r.URL.Path {
+		case "/fresh":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusNotFound)
+		case "/error":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		fmt.Fprintf(w, "response-%d", n)
+	})
+
+	cache := lrucache.NewCache[string, *CachedResponse](10)
+	defer cache.Close()
+	server := httptest.NewServer(Middleware(handler, cache, nil, nil))
+	defer server.Close()
+
+	resp, body := get(t, server.Client(), server.URL+"/fresh", nil)
+	assert.Equal(t, "response-1", body)
+	assert.Empty(t, resp.Header.Get(CacheHeader))
+
+	resp, body = get(t, server.Client(), server.URL+"/fresh", nil)
+	assert.Equal(t, "response-1", body)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, "text/plain", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "HIT", resp.Header.Get(CacheHeader))
+
+	_, body = get(t, server.Client(), server.URL+"/error", nil)
+	assert.Equal(t, "response-2", body)
+	_, body = get(t, server.Client(), server.URL+"/error", nil)
+	assert.Equal(t, "response-3", body)
+
+	// Without a freshness lifetime, nothing is cached.
+	_, body = get(t, server.Client(), server.URL+"/other", nil)
+	assert.Equal(t, "response-4", body)
+	_, body = get(t, server.Client(), server.URL+"/other", nil)
+	assert.Equal(t, "response-5", body)
+}
+
+func TestMiddleware_KeyAndTTL(t *testing.T) {
+	// Checks the key and TTL functions decide what's shared and for how long.
+
+	var hits atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "response-%d", hits.Add(1))
+	})
+
+	cache := lrucache.NewCache[string, *CachedResponse](10)
+	defer cache.Close()
+	keys := KeyConfig{Vary: []string{"Accept-Language"}}
+	ttl := func(*http.Request, *CachedResponse) time.Duration { return time.Minute }
+	server := httptest.NewServer(Middleware(handler, cache, keys.Key, ttl))
+	defer server.Close()
+
+	en := http.Header{"Accept-Language": {"en"}}
+	fr := http.Header{"Accept-Language": {"fr"}}
+
+	_, body := get(t, server.Client(), server.URL+"/a", en)
+	assert.Equal(t, "response-1", body)
+	_, body = get(t, server.Client(), server.URL+"/a", fr)
+	assert.Equal(t, "response-2", body)
+	_, body = get(t, server.Client(), server.URL+"/a", en)
+	assert.Equal(t, "response-1", body)
+}
+
+func TestMiddleware_PrivateResponses(t *testing.T) {
+	// Checks responses marked private, or setting cookies, aren't shared between clients by default.
+
+	var hits atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := hits.Add(1)
+		switch r.URL.Path {
+		case "/private":
+			w.Header().Set("Cache-Control", "private, max-age=60")
+		case "/cookie":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Header().Set("Set-Cookie", "session="+r.Header.Get("X-User"))
+		}
+		fmt.Fprintf(w, "response-%d", n)
+	})
+
+	cache := lrucache.NewCache[string, *CachedResponse](10)
+	defer cache.Close()
+	server := httptest.NewServer(Middleware(handler, cache, nil, nil))
+	defer server.Close()
+
+	_, body := get(t, server.Client(), server.URL+"/private", nil)
+	assert.Equal(t, "response-1", body)
+	_, body = get(t, server.Client(), server.URL+"/private", nil)
+	assert.Equal(t, "response-2", body)
+
+	resp, body := get(t, server.Client(), server.URL+"/cookie", http.Header{"X-User": {"alice"}})
+	assert.Equal(t, "response-3", body)
+	assert.Equal(t, "session=alice", resp.Header.Get("Set-Cookie"))
+	resp, body = get(t, server.Client(), server.URL+"/cookie", http.Header{"X-User": {"bob"}})
+	assert.Equal(t, "response-4", body)
+	assert.Equal(t, "session=bob", resp.Header.Get("Set-Cookie"))
+
+	assert.Equal(t, uint64(0), cache.EntryCount())
+}
+
+func TestMiddleware_Vary(t *testing.T) {
+	// Checks responses with a Vary header are only served to requests with the same values of the headers named,
+	// and those varying on "*" aren't cached.
+
+	var hits atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := hits.Add(1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		if r.URL.Path == "/any" {
+			w.Header().Set("Vary", "*")
+		} else {
+			w.Header().Set("Vary", "Accept-Language")
+		}
+		fmt.Fprintf(w, "%s-%d", r.Header.Get("Accept-Language"), n)
+	})
+
+	cache := lrucache.NewCache[string, *CachedResponse](10)
+	defer cache.Close()
+	server := httptest.NewServer(Middleware(handler, cache, nil, nil))
+	defer server.Close()
+
+	en := http.Header{"Accept-Language": {"en"}}
+	fr := http.Header{"Accept-Language": {"fr"}}
+
+	_, body := get(t, server.Client(), server.URL+"/a", en)
+	assert.Equal(t, "en-1", body)
+	_, body = get(t, server.Client(), server.URL+"/a", fr)
+	assert.Equal(t, "fr-2", body)
+
+	resp, body := get(t, server.Client(), server.URL+"/a", en)
+	assert.Equal(t, "en-1", body)
+	assert.Equal(t, "HIT", resp.Header.Get(CacheHeader))
+	_, body = get(t, server.Client(), server.URL+"/a", fr)
+	assert.Equal(t, "fr-2", body)
+
+	_, body = get(t, server.Client(), server.URL+"/any", en)
+	assert.Equal(t, "en-3", body)
+	_, body = get(t, server.Client(), server.URL+"/any", en)
+	assert.Equal(t, "en-4", body)
+}
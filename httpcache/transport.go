@@ -0,0 +1,219 @@
+package httpcache
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/nsmithuk/lrucache"
+)
+
+// CacheHeader is set to "HIT" on responses served from the cache.
+const CacheHeader = "X-Cache"
+
+// CachedResponse is a response stored in the cache.
+type CachedResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Stored     time.Time // When the response was received.
+
+	// Vary holds the response's Vary header names. On a variants marker, it's used to find the actual response.
+	Vary []string
+
+	// variants is true if this entry is a marker, recording that responses for the URL vary on Vary.
+	variants bool
+}
+
+// cacheableStatus lists the status codes that may be cached when a freshness lifetime is given.
+var cacheableStatus = []int{
+	http.StatusOK,
+	http.StatusNonAuthoritativeInfo,
+	http.StatusMultipleChoices,
+	http.StatusMovedPermanently,
+	http.StatusNotFound,
+	http.StatusGone,
+}
+
+// Transport is an http.RoundTripper that caches GET responses, honouring their Cache-Control and Expires headers,
+// and the Vary header. Responses without an explicit freshness lifetime are not cached.
+type Transport struct {
+	// Next is the RoundTripper used for requests not served from the cache. If nil, http.DefaultTransport is used.
+	Next http.RoundTripper
+
+	// Cache stores the responses.
+	Cache *lrucache.Cache[string, *CachedResponse]
+
+	// Keys decides the cache key for each request. If nil, the zero KeyConfig is used for all requests.
+	Keys *KeyRules
+
+	// Size returns the size of a response in the cache's capacity unit. If nil, every response has a size of 1.
+	Size func(*CachedResponse) uint64
+
+	// MaxBodySize is the largest response body that will be cached. Zero means no limit.
+	MaxBodySize int64
+}
+
+// NewTransport returns a Transport caching responses from next in cache.
+func NewTransport(next http.RoundTripper, cache *lrucache.Cache[string, *CachedResponse]) *Transport {
+	return &Transport{Next: next, Cache: cache}
+}
+
+// RoundTrip serves the request from the cache if possible, otherwise sends it using Next, caching the response
+// if allowed.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.next().RoundTrip(req)
+	}
+
+	reqCC := parseCacheControl(req.Header)
+	if !reqCC.has("no-cache") && !reqCC.has("no-store") {
+		if cached, found := t.lookup(req); found {
+			return cached.response(req), nil
+		}
+	}
+
+	resp, err := t.next().RoundTrip(req)
+	if err != nil || reqCC.has("no-store") {
+		return resp, err
+	}
+
+	return t.store(req, resp)
+}
+
+func (t *Transport) next() http.RoundTripper {
+	if t.Next == nil {
+		return http.DefaultTransport
+	}
+	return t.Next
+}
+
+func (t *Transport) config(req *http.Request) KeyConfig {
+	if t.Keys == nil {
+		return KeyConfig{}
+	}
+	return t.Keys.Config(req)
+}
+
+// lookup finds the cached response for the request, following a variants marker if there is one.
+func (t *Transport) lookup(req *http.Request) (*CachedResponse, bool) {
+	config := t.config(req)
+
+	cached, found := t.Cache.Get(config.Key(req))
+	if !found || !cached.variants {
+		return cached, found
+	}
+
+	return t.Cache.Get(config.KeyWithVary(req, cached.Vary))
+}
+
+// store caches the response, if allowed, returning a response with a body that can still be read by the caller.
+func (t *Transport) store(req *http.Request, resp *http.Response) (*http.Response, error) {
+	now := time.Now()
+
+	ttl := freshnessLifetime(resp.Header, now)
+	if ttl <= 0 || !slices.Contains(cacheableStatus, resp.StatusCode) {
+		return resp, nil
+	}
+
+	vary := ParseVary(resp.Header)
+	if slices.Contains(vary, "*") {
+		return resp, nil
+	}
+
+	if t.MaxBodySize > 0 && resp.ContentLength > t.MaxBodySize {
+		return resp, nil
+	}
+
+	body, err := t.readBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	if body == nil {
+		return resp, nil
+	}
+
+	cached := &CachedResponse{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		Body:       body,
+		Stored:     now,
+		Vary:       vary,
+	}
+
+	config := t.config(req)
+	expires := now.Add(ttl)
+
+	if len(vary) == 0 {
+		t.set(config.Key(req), cached, expires)
+	} else {
+		marker := &CachedResponse{Vary: vary, Stored: now, variants: true}
+		t.set(config.Key(req), marker, expires)
+		t.set(config.KeyWithVary(req, vary), cached, expires)
+	}
+
+	return resp, nil
+}
+
+// readBody reads the response body for caching, replacing it so the caller can still read it.
+// Returns nil, without error, if the body is larger than MaxBodySize.
+func (t *Transport) readBody(resp *http.Response) ([]byte, error) {
+	r := io.Reader(resp.Body)
+	if t.MaxBodySize > 0 {
+		r = io.LimitReader(resp.Body, t.MaxBodySize+1)
+	}
+
+	body, err := io.ReadAll(r)
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	if t.MaxBodySize > 0 && int64(len(body)) > t.MaxBodySize {
+		// Too big to cache; stitch the body back together for the caller.
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return nil, nil
+	}
+
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if body == nil {
+		body = []byte{}
+	}
+	return body, nil
+}
+
+func (t *Transport) set(key string, cached *CachedResponse, expires time.Time) {
+	size := uint64(1)
+	if t.Size != nil && !cached.variants {
+		size = max(t.Size(cached), 1)
+	}
+
+	// Failing to cache (e.g. the response is too big) shouldn't fail the request.
+	_ = t.Cache.SetWithSizeAndExpiry(key, cached, size, expires)
+}
+
+// response builds an *http.Response from the cached response, for the given request.
+func (c *CachedResponse) response(req *http.Request) *http.Response {
+	header := c.Header.Clone()
+	header.Set(CacheHeader, "HIT")
+	header.Set("Age", strconv.FormatInt(int64(time.Since(c.Stored)/time.Second), 10))
+
+	return &http.Response{
+		Status:        strconv.Itoa(c.StatusCode) + " " + http.StatusText(c.StatusCode),
+		StatusCode:    c.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(c.Body)),
+		ContentLength: int64(len(c.Body)),
+		Request:       req,
+	}
+}
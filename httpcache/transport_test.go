@@ -0,0 +1,125 @@
+package httpcache
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nsmithuk/lrucache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func get(t *testing.T, client *http.Client, url string, header http.Header) (*http.Response, string) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(body)
+}
+
+func TestTransport_CachesFreshResponses(t *testing.T) {
+	// Checks responses with a max-age are cached, and those marked no-store are not.
+
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := hits.Add(1)
+		switch r.URL.Path {
+		case "/fresh":
+			w.Header().Set("Cache-Control", "max-age=60")
+		case "/expires":
+			w.Header().Set("Expires", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+		case "/no-store":
+			w.Header().Set("Cache-Control", "no-store, max-age=60")
+		}
+		fmt.Fprintf(w, "response-%d", n)
+	}))
+	defer server.Close()
+
+	cache := lrucache.NewCache[string, *CachedResponse](10)
+	defer cache.Close()
+	client := &http.Client{Transport: NewTransport(nil, cache)}
+
+	resp, body := get(t, client, server.URL+"/fresh", nil)
+	assert.Equal(t, "response-1", body)
+	assert.Empty(t, resp.Header.Get(CacheHeader))
+
+	resp, body = get(t, client, server.URL+"/fresh", nil)
+	assert.Equal(t, "response-1", body)
+	assert.Equal(t, "HIT", resp.Header.Get(CacheHeader))
+
+	_, body = get(t, client, server.URL+"/expires", nil)
+	assert.Equal(t, "response-2", body)
+	_, body = get(t, client, server.URL+"/expires", nil)
+	assert.Equal(t, "response-2", body)
+
+	_, body = get(t, client, server.URL+"/no-store", nil)
+	assert.Equal(t, "response-3", body)
+	_, body = get(t, client, server.URL+"/no-store", nil)
+	assert.Equal(t, "response-4", body)
+
+	// A request with no-cache bypasses the cache.
+	_, body = get(t, client, server.URL+"/fresh", http.Header{"Cache-Control": {"no-cache"}})
+	assert.Equal(t, "response-5", body)
+}
+
+func TestTransport_Vary(t *testing.T) {
+	// Ensures responses that Vary on a request header are cached separately per header value.
+
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Vary", "Accept-Language")
+		fmt.Fprintf(w, "hello in %s", r.Header.Get("Accept-Language"))
+	}))
+	defer server.Close()
+
+	cache := lrucache.NewCache[string, *CachedResponse](10)
+	defer cache.Close()
+	client := &http.Client{Transport: NewTransport(nil, cache)}
+
+	for i := 0; i < 2; i++ {
+		_, body := get(t, client, server.URL, http.Header{"Accept-Language": {"en"}})
+		assert.Equal(t, "hello in en", body)
+		_, body = get(t, client, server.URL, http.Header{"Accept-Language": {"fr"}})
+		assert.Equal(t, "hello in fr", body)
+	}
+	assert.Equal(t, int32(2), hits.Load())
+}
+
+func TestTransport_MaxBodySize(t *testing.T) {
+	// Verifies bodies over the limit are passed through intact, but not cached.
+
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		// Flush before writing, so no Content-Length is sent.
+		w.(http.Flusher).Flush()
+		fmt.Fprint(w, "0123456789")
+	}))
+	defer server.Close()
+
+	cache := lrucache.NewCache[string, *CachedResponse](10)
+	defer cache.Close()
+	transport := NewTransport(nil, cache)
+	transport.MaxBodySize = 5
+	client := &http.Client{Transport: transport}
+
+	for i := 0; i < 2; i++ {
+		_, body := get(t, client, server.URL, nil)
+		assert.Equal(t, "0123456789", body)
+	}
+	assert.Equal(t, int32(2), hits.Load())
+}
@@ -0,0 +1,93 @@
+package lrucache
+
+import "time"
+
+// Invocation describes a single operation passing through the Interceptors of an InterceptedCache. Interceptors
+// may change it before calling next, e.g. to encrypt Value on a Set, and inspect or change it afterwards, e.g. to
+// decrypt Value on a Get.
+type Invocation[K comparable, V any] struct {
+	Op      Operation // OperationGet, OperationSet or OperationDelete.
+	Key     K
+	Value   V         // For a Set, the value to store; for a Get, the value found, once next has returned.
+	Expires time.Time // For a Set, the expiry; the zero value means none.
+	Found   bool      // For a Get, whether the value was found, once next has returned.
+	Peek    bool      // For a Get, true if it's a Contains, which doesn't read the value or affect the LRU order.
+}
+
+// Handler performs an Invocation, as the next step of an Interceptor chain.
+type Handler[K comparable, V any] func(inv *Invocation[K, V]) error
+
+// Interceptor wraps each operation on an InterceptedCache, calling next to carry on with it. This lets cross-cutting
+// concerns, such as logging, metrics, tracing or encrypting values, be layered onto a cache. An interceptor may
+// also return without calling next, e.g. to reject an operation.
+type Interceptor[K comparable, V any] func(inv *Invocation[K, V], next Handler[K, V]) error
+
+// InterceptedCache is a Cacher passing every operation through a chain of Interceptors before the wrapped cache.
+type InterceptedCache[K comparable, V any] struct {
+	cache   Cacher[K, V]
+	handler Handler[K, V]
+}
+
+// Intercept returns an InterceptedCache wrapping cache with the given interceptors. The first interceptor is the
+// outermost, so it sees each operation first, and its result last.
+func Intercept[K comparable, V any](cache Cacher[K, V], interceptors ...Interceptor[K, V]) *InterceptedCache[K, V] {
+	handler := func(inv *Invocation[K, V]) error {
+		switch {
+		case inv.Op == OperationGet && inv.Peek:
+			inv.Found = cache.Contains(inv.Key)
+		case inv.Op == OperationGet:
+			inv.Value, inv.Found = cache.Get(inv.Key)
+		case inv.Op == OperationSet:
+			return cache.SetWithExpiry(inv.Key, inv.Value, inv.Expires)
+		case inv.Op == OperationDelete:
+			cache.Delete(inv.Key)
+		}
+		return nil
+	}
+
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], handler
+		handler = func(inv *Invocation[K, V]) error {
+			return interceptor(inv, next)
+		}
+	}
+
+	return &InterceptedCache[K, V]{cache: cache, handler: handler}
+}
+
+// Get retrieves the value for k, through the interceptors. found is false if there's no value, or an interceptor
+// returned an error.
+func (c *InterceptedCache[K, V]) Get(k K) (V, bool) {
+	inv := Invocation[K, V]{Op: OperationGet, Key: k}
+	if err := c.handler(&inv); err != nil || !inv.Found {
+		var empty V
+		return empty, false
+	}
+	return inv.Value, true
+}
+
+// Contains reports whether an unexpired entry exists for k, through the interceptors.
+func (c *InterceptedCache[K, V]) Contains(k K) bool {
+	inv := Invocation[K, V]{Op: OperationGet, Key: k, Peek: true}
+	return c.handler(&inv) == nil && inv.Found
+}
+
+// Set stores the value for k with no expiry, through the interceptors.
+func (c *InterceptedCache[K, V]) Set(k K, v V) error {
+	return c.SetWithExpiry(k, v, time.Time{})
+}
+
+// SetWithExpiry stores the value for k, expiring at expires, through the interceptors.
+func (c *InterceptedCache[K, V]) SetWithExpiry(k K, v V, expires time.Time) error {
+	return c.handler(&Invocation[K, V]{Op: OperationSet, Key: k, Value: v, Expires: expires})
+}
+
+// Delete removes the entry for k, through the interceptors.
+func (c *InterceptedCache[K, V]) Delete(k K) {
+	_ = c.handler(&Invocation[K, V]{Op: OperationDelete, Key: k})
+}
+
+// Close closes the wrapped cache. It isn't intercepted.
+func (c *InterceptedCache[K, V]) Close() {
+	c.cache.Close()
+}
@@ -0,0 +1,78 @@
+package lrucache
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ Cacher[string, int] = (*InterceptedCache[string, int])(nil)
+
+func TestIntercept_Chain(t *testing.T) {
+	// Checks interceptors run in order, and can transform values on the way in and out.
+
+	inner := NewCache[string, string](10)
+	var log []string
+
+	logging := func(inv *Invocation[string, string], next Handler[string, string]) error {
+		log = append(log, "before "+inv.Op.String()+" "+inv.Key)
+		err := next(inv)
+		log = append(log, "after "+inv.Op.String()+" "+inv.Key)
+		return err
+	}
+	upper := func(inv *Invocation[string, string], next Handler[string, string]) error {
+		if inv.Op == OperationSet {
+			inv.Value = strings.ToUpper(inv.Value)
+		}
+		err := next(inv)
+		if inv.Op == OperationGet && inv.Found {
+			inv.Value = strings.ToLower(inv.Value)
+		}
+		return err
+	}
+
+	cache := Intercept[string, string](inner, logging, upper)
+	defer cache.Close()
+
+	require.NoError(t, cache.Set("a", "value"))
+	stored, _ := inner.Get("a")
+	assert.Equal(t, "VALUE", stored)
+
+	v, found := cache.Get("a")
+	assert.True(t, found)
+	assert.Equal(t, "value", v)
+	assert.True(t, cache.Contains("a"))
+
+	cache.Delete("a")
+	assert.False(t, inner.Contains("a"))
+
+	assert.Equal(t, []string{
+		"before set a", "after set a",
+		"before get a", "after get a",
+		"before get a", "after get a",
+		"before delete a", "after delete a",
+	}, log)
+}
+
+func TestIntercept_Reject(t *testing.T) {
+	// Checks an interceptor can stop an operation reaching the cache.
+
+	inner := NewCache[string, int](10)
+	errRejected := errors.New("rejected")
+	cache := Intercept[string, int](inner, func(inv *Invocation[string, int], next Handler[string, int]) error {
+		if inv.Key == "secret" {
+			return errRejected
+		}
+		return next(inv)
+	})
+	defer cache.Close()
+
+	assert.ErrorIs(t, cache.Set("secret", 1), errRejected)
+	assert.False(t, inner.Contains("secret"))
+	require.NoError(t, inner.Set("secret", 1))
+	_, found := cache.Get("secret")
+	assert.False(t, found)
+}
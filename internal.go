@@ -1,117 +1,464 @@
 package lrucache
 
 import (
-	"sync"
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"reflect"
+	"runtime"
 	"time"
 )
 
-// purgeExpired periodically checks and removes expired entries from the cache.
-// - dur: The duration between successive checks for expired entries.
-func (lru *Cache[K, V]) purgeExpired(dur time.Duration) {
+// purgeExpired periodically checks and removes expired entries from the cache, until the cache is closed or ctx is done.
+// - dur: The duration between successive checks for expired entries. With WithAdaptivePurge, this is only the first.
+func (lru *Cache[K, V]) purgeExpired(ctx context.Context, dur time.Duration) {
 	for {
 		select {
 		case <-lru.done:
 			// Exit the loop when the cache is closed.
 			return
+		case <-ctx.Done():
+			return
 		case <-time.After(dur):
 			// Triggered at regular intervals.
-			lru.lock.Lock()
+			var result purgeResult
+			lru.unattended(func() { result = lru.purge() })
+			if lru.opts.adaptivePurge {
+				dur = lru.nextPurgeInterval(dur, result)
+			}
+		}
+	}
+}
+
+// purge makes a single pass over the cache, removing expired entries, in steps if WithPurgeBudget is set.
+func (lru *Cache[K, V]) purge() purgeResult {
+	if lru.opts.purgeBudgetEntries > 0 || lru.opts.purgeBudgetDuration > 0 || lru.opts.purgeMaxEntries > 0 {
+		return lru.purgeIncremental()
+	}
+
+	start := time.Now()
+	lru.writeLock(OperationPurge)
+	if lru.stopped {
+		lru.lock.Unlock()
+		return purgeResult{}
+	}
+
+	// Remove expired entries.
+	var result purgeResult
+	lru.runOnEventLoop(func() {
+		result = lru.removeExpired(time.Now())
+	})
+
+	removed := lru.takeRemovals()
+	lru.lock.Unlock()
+
+	lru.notifyRemovals(removed)
+
+	duration := time.Since(start)
+	lru.purgeStats.record(result, duration)
+	lru.log(slog.LevelDebug, "lrucache: purged expired entries", "scanned", result.scanned, "removed", result.removed, "duration", duration)
+
+	return result
+}
+
+// purgeResult summarises a pass over the cache removing expired entries.
+type purgeResult struct {
+	scanned int       // The number of entries examined.
+	removed int       // The number of expired entries removed.
+	soonest time.Time // The earliest expiry of the remaining entries examined; zero if none have an expiry.
+	more    bool      // The pass stopped at WithPurgeMaxEntries, before examining every entry.
+}
 
-			// Send an event to remove expired entries.
-			wg := &sync.WaitGroup{}
-			wg.Add(1)
-			lru.events <- event[K, V]{a: EventActionRemoveExpired, finished: wg}
-			wg.Wait()
+// removeExpired removes all expired entries from the cache.
+// Assumes the lock is already acquired, and the list lock is held.
+func (lru *Cache[K, V]) removeExpired(now time.Time) purgeResult {
+	if lru.latency != nil {
+		defer lru.latency.purge.since(time.Now())
+	}
 
-			lru.lock.Unlock()
+	result := purgeResult{scanned: len(lru.cache)}
+	for _, n := range lru.cache {
+		switch {
+		case n.isExpired(now):
+			lru.removeNode(n, EvictionReasonExpired)
+			result.removed++
+		case lru.isStale(n):
+			lru.removeNode(n, EvictionReasonInvalidated)
+			result.removed++
+		case !n.expires.IsZero() && (result.soonest.IsZero() || n.expires.Before(result.soonest)):
+			result.soonest = n.expires
 		}
 	}
+	return result
+}
+
+// nextPurgeInterval returns the time until the next purge, for WithAdaptivePurge: the minimum interval if the last
+// pass removed anything, or stopped before examining every entry, otherwise double the last interval; either way no
+// later than the soonest upcoming expiry, and within the configured bounds.
+func (lru *Cache[K, V]) nextPurgeInterval(last time.Duration, result purgeResult) time.Duration {
+	next := last * 2
+	if result.removed > 0 || result.more {
+		next = lru.opts.purgeMin
+	}
+	if !result.soonest.IsZero() {
+		// Wake just after the soonest entry expires.
+		next = min(next, time.Until(result.soonest)+time.Millisecond)
+	}
+	return min(max(next, lru.opts.purgeMin), lru.opts.purgeMax)
 }
 
 // deleteNode removes a node from the cache and processes it for cleanup.
 // Assumes the lock is already acquired.
-func (lru *Cache[K, V]) deleteNode(n *node[K, V]) {
-	wg := &sync.WaitGroup{}
-	wg.Add(1)
+func (lru *Cache[K, V]) deleteNode(n *node[K, V], reason EvictionReason) {
+	lru.dispatch(event[K, V]{a: EventActionRemove, n: n, reason: reason})
+}
 
-	// Send an event to remove the node.
-	lru.events <- event[K, V]{a: EventActionRemove, n: n, finished: wg}
-	wg.Wait() // Wait for the node removal to complete.
+// runOnEventLoop runs fn with sole access to the linked list, once all previously queued promotions have been
+// applied. This gives fn a consistent view of the list.
+func (lru *Cache[K, V]) runOnEventLoop(fn func()) {
+	lru.dispatch(event[K, V]{a: EventActionRun, fn: fn})
 }
 
 func (n *node[K, V]) flagAsDeleted() {
 	n.deleted = true
 }
 
-// processEvents processes all events sent to the cache's event channel.
-// This method handles all modifications to the linked list without requiring additional locks.
+// isExpired returns true if the node has an expiry that is before now.
+func (n *node[K, V]) isExpired(now time.Time) bool {
+	return !n.expires.IsZero() && n.expires.Before(now)
+}
+
+// spawn runs fn in a new goroutine or, with WithSynchronous, in the caller's goroutine before returning.
+func (lru *Cache[K, V]) spawn(fn func()) {
+	if lru.opts.synchronous {
+		fn()
+		return
+	}
+	go fn()
+}
+
+// dispatch handles e in place, once all previously queued promotions have been applied.
+// Assumes the lock is already acquired, at least for reading.
+func (lru *Cache[K, V]) dispatch(e event[K, V]) {
+	lru.list.Lock()
+	lru.catchUp()
+	lru.handleEvent(e)
+	lru.list.Unlock()
+}
+
+// promote sends a promotion for a read to the event goroutine or, with WithStrictConsistency, handles it in place.
+// If the buffer is full, the policy set by WithOverflowPolicy applies; if ctx is done while waiting for space in the
+// buffer, the promotion is skipped.
+// Assumes the lock is already acquired for reading, so the events channel can't be closed first.
+func (lru *Cache[K, V]) promote(ctx context.Context, e event[K, V]) {
+	if lru.opts.strictConsistency {
+		lru.dispatch(e)
+		return
+	}
+	lru.checkSaturation()
+	select {
+	case lru.events <- e:
+		lru.recordQueueDepth(lru.queued.Add(1))
+		return
+	default:
+		if lru.promoteOnOverflow(e) {
+			return
+		}
+	}
+	select {
+	case lru.events <- e:
+		lru.recordQueueDepth(lru.queued.Add(1))
+	case <-ctx.Done():
+		lru.dropped.Add(1)
+	}
+}
+
+// recordQueueDepth updates the high-water mark of the promotions waiting to be applied, given the number queued.
+func (lru *Cache[K, V]) recordQueueDepth(queued uint64) {
+	depth := queued - min(lru.handled.Load(), queued)
+	for {
+		m := lru.maxQueue.Load()
+		if depth <= m || lru.maxQueue.CompareAndSwap(m, depth) {
+			return
+		}
+	}
+}
+
+// catchUp applies any promotions queued before it was called, so they're ordered before whatever follows.
+// Queued promotions are taken from the channel directly, rather than waiting for the event goroutine, unless the
+// event goroutine has already received them.
+// Assumes the list lock is held.
+func (lru *Cache[K, V]) catchUp() {
+	target := lru.queued.Load()
+	for lru.handled.Load() < target {
+		select {
+		case e, ok := <-lru.events:
+			if !ok {
+				return
+			}
+			lru.handleEvent(e)
+			lru.handled.Add(1)
+		default:
+			// The event goroutine holds the remaining promotions, and needs the list lock to apply them.
+			lru.list.Unlock()
+			runtime.Gosched()
+			lru.list.Lock()
+		}
+	}
+	lru.applyOverflow()
+	lru.applyReads()
+}
+
+// processEvents applies the promotions sent to the cache's event channel, until it's closed.
 func (lru *Cache[K, V]) processEvents() {
+	defer close(lru.processed)
 	for e := range lru.events {
-		switch e.a {
-		case EventActionRemove:
-			lru.lock.AssertLocked()
-
-			// Remove a node from the cache.
-			// Assumes the lock is already acquired.
-			delete(lru.cache, e.n.key)
-			lru.removeNodeFromList(e.n)
-			lru.size -= e.n.size
-			e.n.flagAsDeleted()
-
-		case EventActionAddToFront:
-			// Move a node to the front of the list (most recently used).
-
-			// Validate that it's not been removed since being added to the buffer.
-			if !e.n.deleted {
-				lru.addNodeToHead(e.n)
-			}
+		lru.list.Lock()
+		lru.handleEvent(e)
+		lru.handled.Add(1)
+		lru.applyOverflow()
+		lru.list.Unlock()
+	}
+}
 
-		case EventActionMakeSpaceFor:
-			// Free up space in the cache for a new entry.
-			// Assumes the lock is already acquired.
-			spaceAvailable := lru.capacity - lru.size
-			for spaceAvailable < e.n.size {
-				lru.lock.AssertLocked()
+// handleEvent applies a single event. It's called from the event goroutine or by dispatch, holding the list lock.
+func (lru *Cache[K, V]) handleEvent(e event[K, V]) {
+	switch e.a {
+	case EventActionRemove:
+		lru.lock.AssertLocked()
 
-				removed := lru.removeNodeFromTail()
-				delete(lru.cache, removed.key)
+		// Remove a node from the cache.
+		// Assumes the lock is already acquired.
+		lru.removeNode(e.n, e.reason)
 
-				lru.size -= removed.size
-				spaceAvailable = lru.capacity - lru.size
-				removed.flagAsDeleted()
-			}
+	case EventActionAddToFront:
+		// Move a node to the front of the list (most recently used).
 
-		case EventActionRemoveExpired:
-			// Remove all expired entries from the cache.
-			// Assumes the lock is already acquired.
-			now := time.Now()
-			for _, n := range lru.cache {
-				if !n.expires.IsZero() && n.expires.Before(now) {
-					lru.lock.AssertLocked()
-
-					delete(lru.cache, n.key)
-					lru.removeNodeFromList(n)
-					lru.size -= n.size
-					n.flagAsDeleted()
-				}
+		// Validate that it's not been removed since being added to the buffer.
+		if !e.n.deleted {
+			if e.hit {
+				lru.recordHitPosition(e.n)
+				lru.recordAccess(e.n)
 			}
+			lru.creditCost(e.n)
+			lru.addNodeToHead(e.n)
+			lru.promoteTags(e.n)
+		}
 
-		default:
-			panic("unknown action")
+	case EventActionMakeSpaceFor:
+		// Free up space in the cache for a new entry.
+		// Assumes the lock is already acquired.
+		lru.makeSpaceFor(e.n.size, 0)
+
+	case EventActionRemoveExpired:
+		// Remove all expired entries from the cache.
+		// Assumes the lock is already acquired.
+		lru.removeExpired(time.Now())
+
+	case EventActionRun:
+		e.fn()
+
+	default:
+		panic("unknown action")
+	}
+}
+
+// removeNode removes a node from the map and the list, and updates the cache's size.
+// Assumes the lock is already acquired, and the list lock is held.
+func (lru *Cache[K, V]) removeNode(n *node[K, V], reason EvictionReason) {
+	lru.lock.AssertLocked()
+
+	delete(lru.cache, n.key)
+	if lru.opts.lockFreeReads {
+		lru.index.Delete(n.key)
+	}
+	lru.removeNodeFromList(n)
+	lru.size -= n.size
+	lru.updateGauges()
+	lru.sizeCounts[sizeBucket(n.size)]--
+	n.flagAsDeleted()
+	lru.removeTags(n)
+	lru.scanRemoved()
+
+	lru.recordRemoval(n, reason)
+}
+
+// makeSpaceFor removes nodes from the tail of the list, skipping any pinned, until there is at least size space
+// available or, with WithEvictionWatermarks, until the cache is down to its low watermark once size has been added.
+// At most limit nodes are removed, unless limit is zero; the result is false if more need to be removed.
+// Assumes the lock is already acquired, and the list lock is held.
+func (lru *Cache[K, V]) makeSpaceFor(size uint64, limit int) bool {
+	if lru.latency != nil {
+		defer lru.latency.evict.since(time.Now())
+	}
+
+	target, _ := lru.evictionTarget(size)
+	now := time.Now()
+
+	removed := 0
+	defer func() {
+		if removed > 0 {
+			lru.evicted += removed
+			lru.log(slog.LevelDebug, "lrucache: evicted entries to make space", "evicted", removed, "size", lru.size, "capacity", lru.limit)
+		}
+	}()
+
+	for ; lru.size > target || lru.tooManyEntries(size); removed++ {
+		if limit > 0 && removed == limit {
+			return false
+		}
+		n := lru.victim(now)
+		if n == nil {
+			break
+		}
+		lru.removeNode(n, EvictionReasonCapacity)
+	}
+	return true
+}
+
+// evictBatch removes up to one batch of nodes, as configured by WithEvictionBatch, towards making space for n.
+// Space that will be freed by replacing an existing node for n's key is taken into account. The result is false if
+// more need to be removed.
+// Assumes the lock is already acquired.
+func (lru *Cache[K, V]) evictBatch(n *node[K, V]) bool {
+	size := n.size
+	if existing, found := lru.cache[n.key]; found {
+		size -= min(existing.size, size)
+	}
+	if _, evict := lru.evictionTarget(size); !evict {
+		return true
+	}
+
+	var done bool
+	lru.runOnEventLoop(func() {
+		done = lru.makeSpaceFor(size, lru.opts.evictionBatch)
+	})
+	return done
+}
+
+// tooManyEntries returns true if adding an entry of the given size would take the cache over WithMaxEntries.
+// A size of zero means the entries have already been added, so the cache only needs to be within the limit.
+// Assumes the lock is already acquired.
+func (lru *Cache[K, V]) tooManyEntries(size uint64) bool {
+	max := lru.opts.maxEntries
+	if max <= 0 {
+		return false
+	}
+	if size == 0 {
+		return len(lru.cache) > max
+	}
+	return len(lru.cache) >= max
+}
+
+// evictionTarget returns the size the cache must be evicted down to before an entry of the given size is added,
+// and whether any eviction is needed to reach it, either for space or, with WithMaxEntries, to make room. With
+// WithWeakCapacity, eviction is only needed for WithMaxEntries. With WithSynchronous, the soft capacity is the target.
+// Assumes the lock is already acquired.
+func (lru *Cache[K, V]) evictionTarget(size uint64) (uint64, bool) {
+	target := lru.limit - min(size, lru.limit)
+	if lru.tooManyEntries(size) {
+		return target, true
+	}
+
+	if lru.opts.weakCapacity {
+		return target, false
+	}
+
+	if soft := lru.softLimit(); soft > 0 {
+		if lru.opts.synchronous {
+			target = soft - min(size, soft)
+			return target, lru.size > target
 		}
+		if lru.size+size > soft {
+			lru.signalShrink()
+		}
+		return target, lru.size > target
+	}
 
-		// Signal that the event has been processed, if a wait group is provided.
-		if e.finished != nil {
-			e.finished.Done()
+	if lru.opts.highWatermark > 0 {
+		high := uint64(lru.opts.highWatermark * float64(lru.limit))
+		if lru.size+size <= high {
+			return target, lru.size > target
 		}
+		low := uint64(lru.opts.lowWatermark * float64(lru.limit))
+		target = min(target, low-min(size, low))
+		return target, true
+	}
+
+	return target, lru.size > target
+}
+
+// validate checks that an item of the given size and expiry can be added to the cache.
+func (lru *Cache[K, V]) validate(size uint64, expires time.Time) error {
+	if size == 0 {
+		return fmt.Errorf("%w: item size = %d", ErrItemTooSmall, size)
+	}
+
+	if lru.capacity == 0 {
+		return fmt.Errorf("%w: item size = %d", ErrNoCapacity, size)
+	}
+
+	if size > lru.capacity {
+		return fmt.Errorf("%w: item size = %d. cache capacity = %d", ErrItemTooBig, size, lru.capacity)
+	}
+
+	if max := lru.opts.maxEntrySize; max > 0 && size > max {
+		return fmt.Errorf("%w: item size = %d. max entry size = %d", ErrItemTooBig, size, max)
+	}
+
+	if f := lru.opts.maxEntryFraction; f > 0 && float64(size) > f*float64(lru.capacity) {
+		return fmt.Errorf("%w: item size = %d. max entry size = %g of cache capacity %d", ErrItemTooBig, size, f, lru.capacity)
+	}
+
+	if !expires.IsZero() && expires.Before(time.Now()) {
+		return fmt.Errorf("%w. expires is set to %s, but the current time is %s", ErrPastExpiry, expires.Format(DateTime), time.Now().Format(DateTime))
 	}
+
+	return nil
+}
+
+// jitter randomises the time remaining until expires by up to the WithTTLJitter fraction, either way.
+// A zero expiry, meaning none, is returned unchanged.
+func (lru *Cache[K, V]) jitter(expires time.Time) time.Time {
+	if lru.opts.ttlJitter <= 0 || expires.IsZero() {
+		return expires
+	}
+	now := time.Now()
+	ttl := float64(expires.Sub(now))
+	return now.Add(time.Duration(ttl * (1 + lru.opts.ttlJitter*(2*rand.Float64()-1))))
+}
+
+// checkNil applies the nil value options to v, returning the expiry the entry should be stored with.
+func (lru *Cache[K, V]) checkNil(v V, expires time.Time) (time.Time, error) {
+	if !lru.opts.rejectNilValues && lru.opts.nilValueTTL <= 0 {
+		return expires, nil
+	}
+
+	if !isNil(v) {
+		return expires, nil
+	}
+
+	if lru.opts.rejectNilValues {
+		return expires, ErrNilValue
+	}
+
+	capped := time.Now().Add(lru.opts.nilValueTTL)
+	if expires.IsZero() || expires.After(capped) {
+		return capped, nil
+	}
+	return expires, nil
 }
 
-// removeNodeFromTail removes and returns the least recently used node (at the tail of the list).
-func (lru *Cache[K, V]) removeNodeFromTail() *node[K, V] {
-	last := lru.tail.previous
-	lru.removeNodeFromList(last)
-	return last
+// isNil returns true if v is a nil pointer, map, slice, func, chan or interface.
+func isNil[V any](v V) bool {
+	rv := reflect.ValueOf(&v).Elem()
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.Interface, reflect.UnsafePointer:
+		return rv.IsNil()
+	default:
+		return false
+	}
 }
 
 // removeNodeFromList removes a node from its current position in the doubly linked list.
@@ -125,6 +472,7 @@ func (lru *Cache[K, V]) removeNodeFromList(n *node[K, V]) {
 	// Update pointers of adjacent nodes to bypass the node.
 	n.previous.next = n.next
 	n.next.previous = n.previous
+	lru.length--
 }
 
 // addNodeToHead moves a node to the head of the list (most recently used).
@@ -137,6 +485,14 @@ func (lru *Cache[K, V]) addNodeToHead(n *node[K, V]) {
 
 	// Insert the node between the head and the current first node.
 	lru.addNodeBetween(n, lru.head, lru.head.next)
+	lru.length++
+	n.sequence.Store(lru.sequence.Add(1))
+}
+
+// addNodeToTail adds a node that's not in the list at its tail (least recently used).
+func (lru *Cache[K, V]) addNodeToTail(n *node[K, V]) {
+	lru.addNodeBetween(n, lru.tail.previous, lru.tail)
+	lru.length++
 }
 
 // addNodeBetween inserts a node between two given nodes in the list.
@@ -0,0 +1,62 @@
+package lrucache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_NextPurgeInterval(t *testing.T) {
+	// Checks the adaptive purge interval backs off when idle, resets after removals, and wakes for the soonest expiry.
+
+	cache := NewCacheWithOptions[string, int](10, WithAdaptivePurge(10*time.Millisecond, time.Second))
+	defer cache.Close()
+
+	assert.Equal(t, 20*time.Millisecond, cache.nextPurgeInterval(10*time.Millisecond, purgeResult{}))
+	assert.Equal(t, time.Second, cache.nextPurgeInterval(800*time.Millisecond, purgeResult{}))
+	assert.Equal(t, 10*time.Millisecond, cache.nextPurgeInterval(800*time.Millisecond, purgeResult{removed: 3}))
+
+	soon := purgeResult{soonest: time.Now().Add(100 * time.Millisecond)}
+	next := cache.nextPurgeInterval(800*time.Millisecond, soon)
+	assert.LessOrEqual(t, next, 101*time.Millisecond)
+	assert.Greater(t, next, 50*time.Millisecond)
+
+	// An expiry that has already passed is clamped to the minimum.
+	past := purgeResult{soonest: time.Now().Add(-time.Second)}
+	assert.Equal(t, 10*time.Millisecond, cache.nextPurgeInterval(800*time.Millisecond, past))
+}
+
+func TestCache_AdaptivePurge(t *testing.T) {
+	// Ensures entries are purged soon after expiring, even once the interval has backed off.
+
+	cache := NewCacheWithOptions[string, int](10, WithAdaptivePurge(5*time.Millisecond, time.Hour))
+	defer cache.Close()
+
+	// Let the interval back off while the cache is empty.
+	time.Sleep(50 * time.Millisecond)
+
+	assert.NoError(t, cache.SetWithExpiry("a", 1, time.Now().Add(100*time.Millisecond)))
+	assert.Eventually(t, func() bool {
+		return cache.EntryCount() == 0
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestCache_DeleteDoesNotAllocate(t *testing.T) {
+	// Checks that waiting for the event goroutine to remove an entry doesn't allocate.
+
+	cache := NewCache[int, int](1000)
+	defer cache.Close()
+
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, cache.Set(i, i))
+	}
+
+	k := 0
+	allocs := testing.AllocsPerRun(500, func() {
+		cache.Delete(k)
+		k++
+	})
+	assert.Zero(t, allocs)
+}
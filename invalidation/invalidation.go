@@ -0,0 +1,169 @@
+// Package invalidation keeps the local caches of a fleet of processes coherent. When one process writes or deletes
+// a key, it publishes an invalidation message, and every other process subscribed to the same channel deletes its
+// own copy, so it's reloaded on the next read.
+//
+// Messages travel through an Invalidator. Adapters for Redis pub/sub and NATS are provided, each talking to the
+// broker through a small interface, to avoid a dependency on any particular client.
+package invalidation
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/nsmithuk/lrucache"
+)
+
+// Message asks the subscribers to drop the given keys from their caches.
+type Message struct {
+	Origin string   `json:"origin"` // Identifies the publishing process, so it can ignore its own messages.
+	Keys   []string `json:"keys"`
+}
+
+// Invalidator publishes and receives invalidation messages.
+type Invalidator interface {
+	// Publish sends msg to all subscribers, including, typically, the publisher itself.
+	Publish(ctx context.Context, msg Message) error
+
+	// Subscribe calls handler with each message published, from when it returns until ctx is done.
+	Subscribe(ctx context.Context, handler func(Message)) error
+}
+
+// Cache is an lrucache.Cacher that publishes an invalidation for every key it stores or deletes, and deletes the
+// keys invalidated by other processes from the wrapped cache.
+type Cache[V any] struct {
+	cache       lrucache.Cacher[string, V]
+	invalidator Invalidator
+	origin      string
+	timeout     time.Duration
+	onError     func(error)
+	cancel      context.CancelFunc
+}
+
+// Option configures a Cache.
+type Option[V any] func(*Cache[V])
+
+// WithOrigin sets the identity of this process in the messages it publishes. The default is random, which is
+// sufficient unless messages need to be traced back to their publisher.
+func WithOrigin[V any](origin string) Option[V] {
+	return func(c *Cache[V]) {
+		c.origin = origin
+	}
+}
+
+// WithPublishTimeout sets the maximum time a write waits for its invalidation to be published. The default is
+// one second.
+func WithPublishTimeout[V any](timeout time.Duration) Option[V] {
+	return func(c *Cache[V]) {
+		c.timeout = timeout
+	}
+}
+
+// WithErrorHandler sets a function to receive errors publishing invalidations for Delete, which can't return them.
+func WithErrorHandler[V any](fn func(error)) Option[V] {
+	return func(c *Cache[V]) {
+		c.onError = fn
+	}
+}
+
+// New returns a Cache wrapping cache, publishing and receiving invalidations through invalidator. It subscribes
+// before returning; the subscription ends when the Cache is closed.
+func New[V any](cache lrucache.Cacher[string, V], invalidator Invalidator, opts ...Option[V]) (*Cache[V], error) {
+	c := &Cache[V]{
+		cache:       cache,
+		invalidator: invalidator,
+		origin:      randomOrigin(),
+		timeout:     time.Second,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := invalidator.Subscribe(ctx, c.receive); err != nil {
+		cancel()
+		return nil, fmt.Errorf("unable to subscribe to invalidations: %w", err)
+	}
+	c.cancel = cancel
+	return c, nil
+}
+
+// Get returns the value for k from the wrapped cache.
+func (c *Cache[V]) Get(k string) (V, bool) {
+	return c.cache.Get(k)
+}
+
+// Contains reports whether the wrapped cache has an unexpired entry for k.
+func (c *Cache[V]) Contains(k string) bool {
+	return c.cache.Contains(k)
+}
+
+// Set stores the value for k, with no expiry, and invalidates k in the other processes.
+func (c *Cache[V]) Set(k string, v V) error {
+	return c.SetWithExpiry(k, v, time.Time{})
+}
+
+// SetWithExpiry stores the value for k, expiring at expires, and invalidates k in the other processes. An error
+// publishing the invalidation is returned, though the value has been stored locally.
+func (c *Cache[V]) SetWithExpiry(k string, v V, expires time.Time) error {
+	if err := c.cache.SetWithExpiry(k, v, expires); err != nil {
+		return err
+	}
+	return c.publish(k)
+}
+
+// Delete removes the entry for k, and invalidates k in the other processes. An error publishing the invalidation
+// is passed to the error handler.
+func (c *Cache[V]) Delete(k string) {
+	c.cache.Delete(k)
+	if err := c.publish(k); err != nil && c.onError != nil {
+		c.onError(err)
+	}
+}
+
+// Invalidate removes the entries for keys, locally and in the other processes, e.g. after the data they were
+// loaded from changed.
+func (c *Cache[V]) Invalidate(ctx context.Context, keys ...string) error {
+	for _, k := range keys {
+		c.cache.Delete(k)
+	}
+	if err := c.invalidator.Publish(ctx, Message{Origin: c.origin, Keys: keys}); err != nil {
+		return fmt.Errorf("unable to publish invalidation: %w", err)
+	}
+	return nil
+}
+
+// Close ends the subscription and closes the wrapped cache.
+func (c *Cache[V]) Close() {
+	c.cancel()
+	c.cache.Close()
+}
+
+// publish invalidates k in the other processes.
+func (c *Cache[V]) publish(k string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	if err := c.invalidator.Publish(ctx, Message{Origin: c.origin, Keys: []string{k}}); err != nil {
+		return fmt.Errorf("unable to publish invalidation for key %s: %w", k, err)
+	}
+	return nil
+}
+
+// receive deletes the keys invalidated by another process.
+func (c *Cache[V]) receive(msg Message) {
+	if msg.Origin == c.origin {
+		return
+	}
+	for _, k := range msg.Keys {
+		c.cache.Delete(k)
+	}
+}
+
+// randomOrigin returns a random identity for a process.
+func randomOrigin() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
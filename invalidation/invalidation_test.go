@@ -0,0 +1,163 @@
+package invalidation
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nsmithuk/lrucache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBroker is an in-memory broker, delivering messages synchronously, implementing both RedisClient and NATSConn.
+type fakeBroker struct {
+	lock     sync.Mutex
+	handlers map[string]map[int]func([]byte)
+	next     int
+	err      error
+}
+
+func newFakeBroker() *fakeBroker {
+	return &fakeBroker{handlers: make(map[string]map[int]func([]byte))}
+}
+
+func (b *fakeBroker) send(channel string, payload []byte) error {
+	b.lock.Lock()
+	if b.err != nil {
+		b.lock.Unlock()
+		return b.err
+	}
+	handlers := make([]func([]byte), 0, len(b.handlers[channel]))
+	for _, h := range b.handlers[channel] {
+		handlers = append(handlers, h)
+	}
+	b.lock.Unlock()
+
+	for _, h := range handlers {
+		h(payload)
+	}
+	return nil
+}
+
+func (b *fakeBroker) add(channel string, handler func([]byte)) func() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.handlers[channel] == nil {
+		b.handlers[channel] = make(map[int]func([]byte))
+	}
+	id := b.next
+	b.next++
+	b.handlers[channel][id] = handler
+	return func() error {
+		b.lock.Lock()
+		defer b.lock.Unlock()
+		delete(b.handlers[channel], id)
+		return nil
+	}
+}
+
+func (b *fakeBroker) subscribers(channel string) int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return len(b.handlers[channel])
+}
+
+type fakeRedis struct{ *fakeBroker }
+
+func (r fakeRedis) Publish(ctx context.Context, channel string, payload []byte) error {
+	return r.send(channel, payload)
+}
+
+func (r fakeRedis) Subscribe(ctx context.Context, channel string, handler func([]byte)) error {
+	unsubscribe := r.add(channel, handler)
+	context.AfterFunc(ctx, func() { _ = unsubscribe() })
+	return nil
+}
+
+type fakeNATS struct{ *fakeBroker }
+
+func (n fakeNATS) Publish(subject string, data []byte) error {
+	return n.send(subject, data)
+}
+
+func (n fakeNATS) Subscribe(subject string, handler func([]byte)) (func() error, error) {
+	return n.add(subject, handler), nil
+}
+
+// Compile-time checks that the wrapper and adapters satisfy their interfaces.
+var _ lrucache.Cacher[string, int] = (*Cache[int])(nil)
+var _ Invalidator = (*Redis)(nil)
+var _ Invalidator = (*NATS)(nil)
+
+func TestCache_Coherence(t *testing.T) {
+	// Checks writes and deletes in one process drop the key from the others, but not from the writer.
+
+	broker := newFakeBroker()
+	for name, invalidator := range map[string]Invalidator{
+		"redis": NewRedis(fakeRedis{broker}, "invalidations"),
+		"nats":  NewNATS(fakeNATS{broker}, "invalidations"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			a, err := New[int](lrucache.NewCache[string, int](10), invalidator)
+			require.NoError(t, err)
+			b, err := New[int](lrucache.NewCache[string, int](10), invalidator)
+			require.NoError(t, err)
+
+			require.NoError(t, a.Set("x", 1))
+			require.NoError(t, b.Set("x", 2))
+			assert.False(t, a.Contains("x"))
+			v, found := b.Get("x")
+			assert.True(t, found)
+			assert.Equal(t, 2, v)
+
+			require.NoError(t, a.Set("y", 1))
+			require.NoError(t, b.Set("z", 1))
+			a.Delete("z")
+			assert.False(t, b.Contains("z"))
+
+			require.NoError(t, b.Invalidate(context.Background(), "y"))
+			assert.False(t, a.Contains("y"))
+
+			a.Close()
+			b.Close()
+			// Subscriptions end asynchronously, once their contexts are cancelled.
+			assert.Eventually(t, func() bool {
+				return broker.subscribers("invalidations") == 0
+			}, time.Second, time.Millisecond)
+		})
+	}
+}
+
+func TestCache_PublishError(t *testing.T) {
+	broker := newFakeBroker()
+	var handled []error
+	c, err := New[int](lrucache.NewCache[string, int](10), NewRedis(fakeRedis{broker}, "invalidations"),
+		WithErrorHandler[int](func(err error) { handled = append(handled, err) }))
+	require.NoError(t, err)
+	defer c.Close()
+
+	failure := errors.New("broker unavailable")
+	broker.err = failure
+
+	// The value is stored locally, even though the invalidation couldn't be published.
+	assert.ErrorIs(t, c.Set("x", 1), failure)
+	assert.True(t, c.Contains("x"))
+
+	c.Delete("x")
+	require.Len(t, handled, 1)
+	assert.ErrorIs(t, handled[0], failure)
+}
+
+func TestRedis_IgnoresInvalidPayloads(t *testing.T) {
+	broker := newFakeBroker()
+	c, err := New[int](lrucache.NewCache[string, int](10), NewRedis(fakeRedis{broker}, "invalidations"))
+	require.NoError(t, err)
+	defer c.Close()
+
+	require.NoError(t, c.Set("x", 1))
+	require.NoError(t, broker.send("invalidations", []byte("not json")))
+	assert.True(t, c.Contains("x"))
+}
@@ -0,0 +1,66 @@
+package invalidation
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// NATSConn is the subset of a NATS connection used by the NATS adapter. With github.com/nats-io/nats.go, for
+// example:
+//
+//	type natsConn struct{ nc *nats.Conn }
+//
+//	func (n natsConn) Publish(subject string, data []byte) error {
+//		return n.nc.Publish(subject, data)
+//	}
+//
+//	func (n natsConn) Subscribe(subject string, handler func([]byte)) (func() error, error) {
+//		sub, err := n.nc.Subscribe(subject, func(m *nats.Msg) { handler(m.Data) })
+//		if err != nil {
+//			return nil, err
+//		}
+//		return sub.Unsubscribe, nil
+//	}
+type NATSConn interface {
+	// Publish sends data to subject.
+	Publish(subject string, data []byte) error
+
+	// Subscribe calls handler with the data of each message sent to subject, until unsubscribe is called.
+	Subscribe(subject string, handler func(data []byte)) (unsubscribe func() error, err error)
+}
+
+// NATS is an Invalidator using a NATS subject, with messages encoded as JSON.
+type NATS struct {
+	conn    NATSConn
+	subject string
+}
+
+// NewNATS returns an Invalidator publishing to, and subscribed to, the given NATS subject.
+func NewNATS(conn NATSConn, subject string) *NATS {
+	return &NATS{conn: conn, subject: subject}
+}
+
+// Publish sends msg to the subject. NATS publishes asynchronously, so ctx is only checked beforehand.
+func (n *NATS) Publish(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return n.conn.Publish(n.subject, b)
+}
+
+// Subscribe calls handler with each message sent to the subject, unsubscribing once ctx is done. Payloads that
+// aren't valid messages are ignored.
+func (n *NATS) Subscribe(ctx context.Context, handler func(Message)) error {
+	unsubscribe, err := n.conn.Subscribe(n.subject, decoding(handler))
+	if err != nil {
+		return err
+	}
+	context.AfterFunc(ctx, func() {
+		_ = unsubscribe()
+	})
+	return nil
+}
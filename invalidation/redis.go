@@ -0,0 +1,78 @@
+package invalidation
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// RedisClient is the subset of Redis pub/sub used by the Redis adapter. With github.com/redis/go-redis, for example:
+//
+//	type goRedis struct{ c *redis.Client }
+//
+//	func (g goRedis) Publish(ctx context.Context, channel string, payload []byte) error {
+//		return g.c.Publish(ctx, channel, payload).Err()
+//	}
+//
+//	func (g goRedis) Subscribe(ctx context.Context, channel string, handler func([]byte)) error {
+//		sub := g.c.Subscribe(ctx, channel)
+//		if _, err := sub.Receive(ctx); err != nil {
+//			sub.Close()
+//			return err
+//		}
+//		go func() {
+//			defer sub.Close()
+//			ch := sub.Channel()
+//			for {
+//				select {
+//				case m := <-ch:
+//					handler([]byte(m.Payload))
+//				case <-ctx.Done():
+//					return
+//				}
+//			}
+//		}()
+//		return nil
+//	}
+type RedisClient interface {
+	// Publish sends payload to channel.
+	Publish(ctx context.Context, channel string, payload []byte) error
+
+	// Subscribe calls handler with each payload sent to channel, from when it returns until ctx is done.
+	Subscribe(ctx context.Context, channel string, handler func(payload []byte)) error
+}
+
+// Redis is an Invalidator using Redis pub/sub, with messages encoded as JSON.
+type Redis struct {
+	client  RedisClient
+	channel string
+}
+
+// NewRedis returns an Invalidator publishing to, and subscribed to, the given Redis channel.
+func NewRedis(client RedisClient, channel string) *Redis {
+	return &Redis{client: client, channel: channel}
+}
+
+// Publish sends msg to the channel.
+func (r *Redis) Publish(ctx context.Context, msg Message) error {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return r.client.Publish(ctx, r.channel, b)
+}
+
+// Subscribe calls handler with each message sent to the channel. Payloads that aren't valid messages are ignored.
+func (r *Redis) Subscribe(ctx context.Context, handler func(Message)) error {
+	return r.client.Subscribe(ctx, r.channel, decoding(handler))
+}
+
+// decoding returns a payload handler decoding JSON messages for handler, ignoring any that are invalid.
+func decoding(handler func(Message)) func([]byte) {
+	return func(b []byte) {
+		var msg Message
+		if err := json.Unmarshal(b, &msg); err != nil {
+			return
+		}
+		handler(msg)
+	}
+}
@@ -0,0 +1,269 @@
+package lrucache
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Journal record operations.
+const (
+	journalSet uint8 = iota + 1
+	journalDelete
+)
+
+// journalRecord is the serialised form of a single operation in a journal.
+type journalRecord[K comparable, V any] struct {
+	Op       uint8
+	Key      K
+	Value    V
+	Size     uint64
+	Expires  time.Time
+	Metadata any
+}
+
+// JournalOption configures a JournaledCache. JournalOptions are passed to NewJournaledCache.
+type JournalOption func(*journalOptions)
+
+type journalOptions struct {
+	cache      []Option
+	sync       bool
+	compaction time.Duration
+	rotate     func() (snapshot, journal io.Writer, err error)
+}
+
+// WithJournalCacheOptions sets the Options used to create the in-memory cache.
+func WithJournalCacheOptions(opts ...Option) JournalOption {
+	return func(o *journalOptions) {
+		o.cache = append(o.cache, opts...)
+	}
+}
+
+// WithJournalSync calls the journal's Sync method, if it has one, such as an *os.File, after every record, so that
+// acknowledged writes survive a crash of the machine, not just the process. This makes writes much slower.
+func WithJournalSync() JournalOption {
+	return func(o *journalOptions) {
+		o.sync = true
+	}
+}
+
+// WithJournalCompaction compacts the journal every interval: rotate is called for a writer for a new snapshot, and
+// one for a new journal, and the cache's state is written to the snapshot, with later operations appended to the
+// new journal. Once rotate has returned, the old files are no longer needed, once the snapshot has been written.
+// Errors are passed to the cache's error handler.
+func WithJournalCompaction(interval time.Duration, rotate func() (snapshot, journal io.Writer, err error)) JournalOption {
+	return func(o *journalOptions) {
+		o.compaction = interval
+		o.rotate = rotate
+	}
+}
+
+// JournaledCache is an in-memory LRU cache that appends every Set and Delete to a journal, so its state can be
+// reconstructed with Replay after a crash. This suits caches that are expensive to rebuild from their origin.
+// Records are length-prefixed gob, so K, V and any metadata must be encodable by encoding/gob, as for SaveTo. The
+// journal grows with every write; Compact, or WithJournalCompaction, replaces it with a snapshot of the cache.
+//
+// Writes are serialised, so the journal's order matches the cache's. Evictions and expiries aren't journaled, as
+// replaying the writes into a cache of the same capacity reproduces them.
+type JournaledCache[K comparable, V any] struct {
+	cache *Cache[K, V]
+	opts  journalOptions
+
+	lock    sync.Mutex
+	journal io.Writer
+	buf     bytes.Buffer
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewJournaledCache creates a new JournaledCache, with an in-memory cache of the given capacity, appending its
+// writes to journal.
+func NewJournaledCache[K comparable, V any](capacity uint64, journal io.Writer, opts ...JournalOption) *JournaledCache[K, V] {
+	var o journalOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	j := &JournaledCache[K, V]{
+		cache:   NewCacheWithOptions[K, V](capacity, o.cache...),
+		opts:    o,
+		journal: journal,
+		done:    make(chan struct{}),
+	}
+
+	if o.compaction > 0 && o.rotate != nil {
+		j.wg.Add(1)
+		go j.compactEvery(o.compaction)
+	}
+
+	return j
+}
+
+// Cache returns the in-memory cache. Writes made to it directly aren't journaled.
+func (j *JournaledCache[K, V]) Cache() *Cache[K, V] {
+	return j.cache
+}
+
+// Get returns the value for k from the in-memory cache.
+func (j *JournaledCache[K, V]) Get(k K) (V, bool) {
+	return j.cache.Get(k)
+}
+
+// Set adds a key-value pair to the cache with a size of 1 and no expiry, and appends it to the journal.
+func (j *JournaledCache[K, V]) Set(k K, v V) error {
+	return j.SetWithOptions(k, v)
+}
+
+// SetWithOptions adds a key-value pair to the cache, configured by the given EntryOptions, and appends it to the
+// journal. If the journal can't be written, the entry remains in the cache, and the error is returned.
+func (j *JournaledCache[K, V]) SetWithOptions(k K, v V, opts ...EntryOption) error {
+	eo := entryOptions{size: 1}
+	for _, opt := range opts {
+		opt(&eo)
+	}
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if err := j.cache.set(context.Background(), k, v, eo); err != nil {
+		return err
+	}
+	return j.append(journalRecord[K, V]{Op: journalSet, Key: k, Value: v, Size: eo.size, Expires: eo.expires, Metadata: eo.metadata})
+}
+
+// Delete removes k from the cache, and appends the deletion to the journal.
+func (j *JournaledCache[K, V]) Delete(k K) error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if _, err := j.cache.delete(context.Background(), k); err != nil {
+		return err
+	}
+	return j.append(journalRecord[K, V]{Op: journalDelete, Key: k})
+}
+
+// append writes a record to the journal. Assumes the lock is held.
+func (j *JournaledCache[K, V]) append(r journalRecord[K, V]) error {
+	// Each record has its own encoder, so records are self-contained, and a journal can be appended to across restarts.
+	j.buf.Reset()
+	j.buf.Write(make([]byte, 4))
+	if err := gob.NewEncoder(&j.buf).Encode(r); err != nil {
+		return fmt.Errorf("unable to encode journal record for key %v: %w", r.Key, err)
+	}
+	b := j.buf.Bytes()
+	binary.BigEndian.PutUint32(b, uint32(len(b)-4))
+
+	if _, err := j.journal.Write(b); err != nil {
+		return fmt.Errorf("unable to write journal record for key %v: %w", r.Key, err)
+	}
+	if s, ok := j.journal.(interface{ Sync() error }); ok && j.opts.sync {
+		if err := s.Sync(); err != nil {
+			return fmt.Errorf("unable to sync journal: %w", err)
+		}
+	}
+	return nil
+}
+
+// Compact writes the cache's state to snapshot, as SaveTo does, and appends later writes to journal instead, so the
+// old journal can be discarded once this returns. Writes wait while the snapshot is taken.
+func (j *JournaledCache[K, V]) Compact(snapshot, journal io.Writer) error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if err := j.cache.SaveTo(snapshot); err != nil {
+		return fmt.Errorf("unable to write snapshot: %w", err)
+	}
+	j.journal = journal
+	return nil
+}
+
+// compactEvery compacts the journal every interval, until the cache is closed.
+func (j *JournaledCache[K, V]) compactEvery(interval time.Duration) {
+	defer j.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-j.done:
+			return
+		case <-ticker.C:
+		}
+
+		snapshot, journal, err := j.opts.rotate()
+		if err == nil {
+			err = j.Compact(snapshot, journal)
+		}
+		if err != nil {
+			j.cache.handleError(fmt.Errorf("unable to compact journal: %w", err))
+		}
+	}
+}
+
+// Replay reconstructs the cache's state from a snapshot, written by Compact or SaveTo, followed by the journal of the
+// writes made since. Either may be nil. Replayed writes aren't journaled again. Entries that have since expired are
+// skipped. A record cut short at the end of the journal, as by a crash mid-write, is ignored.
+func (j *JournaledCache[K, V]) Replay(snapshot, journal io.Reader) error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if snapshot != nil {
+		if err := j.cache.LoadFrom(snapshot); err != nil {
+			return err
+		}
+	}
+	if journal == nil {
+		return nil
+	}
+
+	r := bufio.NewReader(journal)
+	header := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil
+			}
+			return fmt.Errorf("unable to read journal: %w", err)
+		}
+		body := make([]byte, binary.BigEndian.Uint32(header))
+		if _, err := io.ReadFull(r, body); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil
+			}
+			return fmt.Errorf("unable to read journal: %w", err)
+		}
+
+		var rec journalRecord[K, V]
+		if err := gob.NewDecoder(bytes.NewReader(body)).Decode(&rec); err != nil {
+			return fmt.Errorf("unable to decode journal record: %w", err)
+		}
+
+		switch rec.Op {
+		case journalSet:
+			err := j.cache.SetWithOptions(rec.Key, rec.Value, WithSize(rec.Size), WithExpiry(rec.Expires), WithMetadata(rec.Metadata))
+			if err != nil && !errors.Is(err, ErrPastExpiry) {
+				return fmt.Errorf("unable to replay key %v: %w", rec.Key, err)
+			}
+		case journalDelete:
+			j.cache.Delete(rec.Key)
+		default:
+			return fmt.Errorf("unknown journal operation %d", rec.Op)
+		}
+	}
+}
+
+// Close stops any periodic compaction, and closes the in-memory cache. The journal isn't closed.
+func (j *JournaledCache[K, V]) Close() {
+	close(j.done)
+	j.wg.Wait()
+	j.cache.Close()
+}
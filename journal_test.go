@@ -0,0 +1,82 @@
+package lrucache
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournaledCache_Replay(t *testing.T) {
+	// Checks the cache's writes can be replayed into a new cache, ignoring a record cut short by a crash.
+
+	var journal bytes.Buffer
+	cache := NewJournaledCache[string, int](10, &journal)
+
+	require.NoError(t, cache.Set("a", 1))
+	require.NoError(t, cache.SetWithOptions("b", 2, WithSize(3), WithTTL(time.Hour)))
+	require.NoError(t, cache.SetWithOptions("c", 3, WithTTL(5*time.Millisecond)))
+	require.NoError(t, cache.Set("a", 4))
+	require.NoError(t, cache.Delete("d"))
+	require.NoError(t, cache.Delete("b"))
+	require.NoError(t, cache.Set("e", 5))
+	cache.Close()
+
+	time.Sleep(10 * time.Millisecond)
+
+	// Lose the end of the last record.
+	data := journal.Bytes()[:journal.Len()-3]
+
+	restored := NewJournaledCache[string, int](10, io.Discard)
+	defer restored.Close()
+	require.NoError(t, restored.Replay(nil, bytes.NewReader(data)))
+
+	assert.Equal(t, []string{"a"}, restored.Cache().OrderedKeys())
+	v, _ := restored.Get("a")
+	assert.Equal(t, 4, v)
+}
+
+func TestJournaledCache_Compact(t *testing.T) {
+	// Checks a compacted snapshot, followed by the new journal, restores the cache.
+
+	var journal1, journal2, snapshot bytes.Buffer
+	cache := NewJournaledCache[string, int](10, &journal1)
+
+	require.NoError(t, cache.Set("a", 1))
+	require.NoError(t, cache.Set("b", 2))
+	require.NoError(t, cache.Compact(&snapshot, &journal2))
+	require.NoError(t, cache.Set("c", 3))
+	require.NoError(t, cache.Delete("a"))
+	cache.Close()
+
+	restored := NewJournaledCache[string, int](10, io.Discard)
+	defer restored.Close()
+	require.NoError(t, restored.Replay(&snapshot, &journal2))
+
+	assert.Equal(t, []string{"c", "b"}, restored.Cache().OrderedKeys())
+}
+
+func TestJournaledCache_PeriodicCompaction(t *testing.T) {
+	// Checks the journal is rotated on the compaction interval.
+
+	var lock sync.Mutex
+	var rotations int
+	cache := NewJournaledCache[string, int](10, io.Discard, WithJournalCompaction(5*time.Millisecond, func() (io.Writer, io.Writer, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		rotations++
+		return io.Discard, io.Discard, nil
+	}))
+	defer cache.Close()
+
+	require.NoError(t, cache.Set("a", 1))
+	assert.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return rotations >= 2
+	}, time.Second, time.Millisecond)
+}
@@ -0,0 +1,113 @@
+package lrucache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// LatencyBuckets is the number of buckets in a LatencyHistogram.
+const LatencyBuckets = 20
+
+// latencyBase is the upper bound of the first bucket of a LatencyHistogram; each bucket's bound is double the last.
+const latencyBase = 128 * time.Nanosecond
+
+// LatencyBound returns the exclusive upper bound of bucket i of a LatencyHistogram. The last bucket has no bound,
+// so counts everything from the bound of the one before it.
+func LatencyBound(i int) time.Duration {
+	return latencyBase << i
+}
+
+// LatencyHistogram is the distribution of the time taken by one kind of operation, in buckets doubling in width
+// from 128ns; see LatencyBound.
+type LatencyHistogram struct {
+	Counts [LatencyBuckets]uint64
+	Count  uint64        // The number of operations measured.
+	Total  time.Duration // The total time they took.
+	Max    time.Duration // The longest any took.
+}
+
+// Mean returns the mean time taken, or zero if none were measured.
+func (h LatencyHistogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Total / time.Duration(h.Count)
+}
+
+// Quantile returns an upper bound for the time taken by the given fraction of operations, e.g. 0.99 for the 99th
+// percentile: the bound of the bucket it falls in, or Max if that's lower, or it falls in the last bucket.
+func (h LatencyHistogram) Quantile(q float64) time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	target := uint64(q * float64(h.Count))
+	var seen uint64
+	for i, c := range h.Counts[:LatencyBuckets-1] {
+		seen += c
+		if seen > target {
+			return min(LatencyBound(i), h.Max)
+		}
+	}
+	return h.Max
+}
+
+// LatencyStats holds the latency distributions measured by WithLatencyStats.
+type LatencyStats struct {
+	Get   LatencyHistogram // Reads, including waiting for the lock and for space to queue promotions.
+	Set   LatencyHistogram // Writes, including any eviction they wait for.
+	Evict LatencyHistogram // Passes evicting entries to make space.
+	Purge LatencyHistogram // Passes removing expired entries.
+}
+
+// WithLatencyStats enables measuring the time taken by reads, writes, eviction passes and purge passes, as
+// histograms available from Stats, so regressions from lock contention or a backed-up event queue are visible.
+// This adds the cost of reading the clock twice to every operation measured.
+func WithLatencyStats() Option {
+	return func(o *options) {
+		o.latencyStats = true
+	}
+}
+
+// latencyCounters accumulates a LatencyStats using atomics, so recording doesn't itself contend.
+type latencyCounters struct {
+	get, set, evict, purge latencyCounter
+}
+
+// latencyCounter accumulates a LatencyHistogram.
+type latencyCounter struct {
+	counts [LatencyBuckets]atomic.Uint64
+	count  atomic.Uint64
+	total  atomic.Int64
+	max    atomic.Int64
+}
+
+// since records the time elapsed since start.
+func (c *latencyCounter) since(start time.Time) {
+	d := time.Since(start)
+
+	i := 0
+	for i < LatencyBuckets-1 && d >= LatencyBound(i) {
+		i++
+	}
+	c.counts[i].Add(1)
+	c.count.Add(1)
+	c.total.Add(int64(d))
+	for {
+		m := c.max.Load()
+		if int64(d) <= m || c.max.CompareAndSwap(m, int64(d)) {
+			return
+		}
+	}
+}
+
+func (c *latencyCounter) snapshot() LatencyHistogram {
+	h := LatencyHistogram{
+		Count: c.count.Load(),
+		Total: time.Duration(c.total.Load()),
+		Max:   time.Duration(c.max.Load()),
+	}
+	for i := range c.counts {
+		h.Counts[i] = c.counts[i].Load()
+	}
+	return h
+}
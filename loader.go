@@ -0,0 +1,495 @@
+package lrucache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Loader loads the value for a key that is missing from the cache.
+// It returns the value, the time at which it should expire (the zero value meaning no expiry), and any error.
+type Loader[K comparable, V any] func(ctx context.Context, k K) (V, time.Time, error)
+
+// LoadConflictPolicy decides what happens when a key is Set or Deleted while a load for it is in flight.
+type LoadConflictPolicy uint8
+
+const (
+	// LoadConflictSetWins discards the loaded value if the key was Set or Deleted during the load,
+	// as the loaded value may be stale. This is the default.
+	LoadConflictSetWins LoadConflictPolicy = iota
+
+	// LoadConflictLoaderWins always stores the loaded value, replacing anything Set during the load.
+	LoadConflictLoaderWins
+)
+
+// LoadOutcome describes how GetOrLoad satisfied a request.
+type LoadOutcome uint8
+
+const (
+	LoadOutcomeHit       LoadOutcome = iota // The value was already cached; the loader wasn't called.
+	LoadOutcomeLoaded                       // The loader was called, and its value stored.
+	LoadOutcomeDiscarded                    // The loader was called, but the key was Set or Deleted during the load, so its value wasn't stored.
+	LoadOutcomeOverwrote                    // The loader was called, and its value replaced one Set during the load.
+	LoadOutcomeError                        // The loader, or storing its value, failed.
+	LoadOutcomeStale                        // The loader failed, so an expired value was returned instead; see WithStaleIfError.
+)
+
+// String returns a human-readable name for the outcome.
+func (o LoadOutcome) String() string {
+	switch o {
+	case LoadOutcomeHit:
+		return "hit"
+	case LoadOutcomeLoaded:
+		return "loaded"
+	case LoadOutcomeDiscarded:
+		return "discarded"
+	case LoadOutcomeOverwrote:
+		return "overwrote"
+	case LoadOutcomeStale:
+		return "stale"
+	default:
+		return "error"
+	}
+}
+
+// States of an in-flight load, used to detect Sets and Deletes that happen during the load.
+const (
+	loadStateLoading    int32 = iota // The loader is running.
+	loadStateSuperseded              // The key was Set or Deleted while the loader was running.
+	loadStateStoring                 // The loader has finished and its value is being stored.
+)
+
+// load represents an in-flight call to a Loader, shared by all callers waiting on the same key.
+type load[V any] struct {
+	done    chan struct{}
+	state   atomic.Int32
+	value   V
+	outcome LoadOutcome
+	err     error
+	panic   any // A panic from the loader re-raised by safely, which is raised again for the caller that started the load.
+
+	waiters int                // The callers registered by startLoad and not yet returned, protected by the loaders' lock.
+	cancel  context.CancelFunc // Cancels a load started by GetOrLoad once it has no waiters; nil for other loads.
+}
+
+// loaders tracks in-flight loads, so concurrent misses on the same key result in a single call to the Loader.
+type loaders[K comparable, V any] struct {
+	lock     sync.Mutex
+	inFlight map[K]*load[V]
+	count    atomic.Int32 // len(inFlight), so writes can cheaply skip checking for in-flight loads.
+	limiter  *loadLimiter
+
+	failures     map[K]loadFailure // Loader errors cached by WithErrorCaching, protected by lock.
+	failureCount atomic.Int32      // len(failures), so writes can cheaply skip forgetting them.
+	sweepAt      int               // The number of failures at which expired ones are next swept.
+}
+
+// loadFailure is a loader error cached by WithErrorCaching.
+type loadFailure struct {
+	err     error
+	expires time.Time
+}
+
+// GetOrLoad returns the value for k if it's in the cache. Otherwise, it calls loader to fetch the value, stores
+// it in the cache with a size of 1, and returns it. Concurrent calls for the same missing key share a single call
+// to the loader. Errors from the loader are returned, and not cached, unless WithNegativeCaching or
+// WithErrorCaching is set.
+// Each caller stops waiting when its own ctx is done. The shared call to the loader is given a context detached
+// from theirs, bounded by WithLoadTimeout, which is only cancelled once every caller waiting on it has given up.
+// If WithRefreshAhead is set, hits on entries close to expiring trigger an asynchronous reload.
+func (lru *Cache[K, V]) GetOrLoad(ctx context.Context, k K, loader Loader[K, V]) (V, error) {
+	v, _, err := lru.GetOrLoadWithOutcome(ctx, k, loader)
+	return v, err
+}
+
+// GetOrLoadWithOutcome behaves like GetOrLoad, additionally returning how the request was satisfied.
+// This is useful for reasoning about Sets and Deletes that race with loads; see WithLoadConflictPolicy.
+func (lru *Cache[K, V]) GetOrLoadWithOutcome(ctx context.Context, k K, loader Loader[K, V]) (V, LoadOutcome, error) {
+	n, found, err := lru.get(ctx, k)
+	if err != nil {
+		return lru.emptyV, LoadOutcomeError, err
+	}
+	if found {
+		if n.negative {
+			return lru.emptyV, LoadOutcomeHit, fmt.Errorf("%w: key %v (cached)", ErrNotFound, k)
+		}
+		lru.maybeRefresh(n, loader)
+		return n.value, LoadOutcomeHit, nil
+	}
+
+	l, owner := lru.startLoad(k)
+	if !owner {
+		return lru.waitForLoad(ctx, k, l, false)
+	}
+
+	// The load is shared by every caller waiting on it, so mustn't fail because this one gives up. With
+	// WithSynchronous there's no one else to share it with, and it runs in this goroutine, so ctx still applies.
+	parent := context.WithoutCancel(ctx)
+	if lru.opts.synchronous {
+		parent = ctx
+	}
+	loadCtx, cancel := context.WithCancel(parent)
+	lru.loaders.lock.Lock()
+	l.cancel = cancel
+	lru.loaders.lock.Unlock()
+
+	stale := lru.staleNode(k)
+
+	lru.spawn(func() {
+		defer lru.finishLoad(k, l)
+		defer cancel()
+		defer func() {
+			// A panic re-raised by safely is passed to the caller that started the load, if it's still waiting.
+			if r := recover(); r != nil {
+				l.panic = r
+			}
+		}()
+
+		l.value, l.outcome, l.err = lru.load(loadCtx, k, l, loader)
+
+		if l.err != nil && stale != nil && !errors.Is(l.err, ErrNotFound) {
+			lru.handleError(fmt.Errorf("serving stale value for key %v: %w", k, l.err))
+			l.value, l.outcome, l.err = stale.value, LoadOutcomeStale, nil
+		}
+	})
+
+	return lru.waitForLoad(ctx, k, l, true)
+}
+
+// staleNode returns the expired node for k, if WithStaleIfError is set and it expired no more than the maximum
+// staleness ago, so it can be served if the loader fails. Otherwise it returns nil.
+func (lru *Cache[K, V]) staleNode(k K) *node[K, V] {
+	maxStale := lru.opts.staleIfError
+	if maxStale <= 0 {
+		return nil
+	}
+
+	lru.readLock(OperationGet)
+	defer lru.lock.RUnlock()
+
+	n, found := lru.cache[k]
+	now := time.Now()
+	if !found || n.negative || !n.isExpired(now) || now.Sub(n.expires) > maxStale || lru.isStale(n) {
+		return nil
+	}
+	return n
+}
+
+// startLoad returns the in-flight load for k. If there wasn't one, a new load is registered and owner is true;
+// the caller must then perform the load and call finishLoad. Either way, the caller is counted as waiting on the
+// load until it calls waitForLoad or leaveLoad, so a load started by GetOrLoad isn't cancelled while it's needed.
+func (lru *Cache[K, V]) startLoad(k K) (l *load[V], owner bool) {
+	lru.loaders.lock.Lock()
+	defer lru.loaders.lock.Unlock()
+
+	if l, found := lru.loaders.inFlight[k]; found {
+		l.waiters++
+		return l, false
+	}
+
+	// If the loader panics and the panic is re-raised, this is what waiters will see.
+	l = &load[V]{done: make(chan struct{}), outcome: LoadOutcomeError, err: ErrCallbackPanic, waiters: 1}
+	lru.loaders.inFlight[k] = l
+	lru.loaders.count.Add(1)
+	return l, true
+}
+
+// finishLoad removes the in-flight load for k and releases any waiters.
+// It's deferred by owners so waiters are always released, even if the loader panics.
+func (lru *Cache[K, V]) finishLoad(k K, l *load[V]) {
+	lru.loaders.lock.Lock()
+	lru.forgetLoad(k, l)
+	lru.loaders.lock.Unlock()
+	close(l.done)
+}
+
+// supersedeLoad flags any in-flight load for k as superseded, as the key has been Set or Deleted, and forgets any
+// cached loader error for it. It must be called before the write takes the cache's lock.
+func (lru *Cache[K, V]) supersedeLoad(k K) {
+	if lru.loaders.count.Load() == 0 && lru.loaders.failureCount.Load() == 0 {
+		return
+	}
+	lru.loaders.lock.Lock()
+	if l, found := lru.loaders.inFlight[k]; found {
+		l.state.CompareAndSwap(loadStateLoading, loadStateSuperseded)
+	}
+	if _, found := lru.loaders.failures[k]; found {
+		delete(lru.loaders.failures, k)
+		lru.loaders.failureCount.Add(-1)
+	}
+	lru.loaders.lock.Unlock()
+}
+
+// cachedFailure returns the loader error cached for k by WithErrorCaching, or nil if there isn't an unexpired one.
+func (lru *Cache[K, V]) cachedFailure(k K) error {
+	if lru.loaders.failureCount.Load() == 0 {
+		return nil
+	}
+	lru.loaders.lock.Lock()
+	defer lru.loaders.lock.Unlock()
+
+	f, found := lru.loaders.failures[k]
+	if !found {
+		return nil
+	}
+	if time.Now().After(f.expires) {
+		delete(lru.loaders.failures, k)
+		lru.loaders.failureCount.Add(-1)
+		return nil
+	}
+	return fmt.Errorf("%w (cached)", f.err)
+}
+
+// forgetFailures forgets all the loader errors cached by WithErrorCaching.
+func (lru *Cache[K, V]) forgetFailures() {
+	lru.loaders.lock.Lock()
+	clear(lru.loaders.failures)
+	lru.loaders.failureCount.Store(0)
+	lru.loaders.lock.Unlock()
+}
+
+// cacheFailure caches err from loading k, for WithErrorCaching. Expired failures are swept each time the number
+// cached doubles, so failures for keys that are never read again don't accumulate.
+func (lru *Cache[K, V]) cacheFailure(k K, err error) {
+	lru.loaders.lock.Lock()
+	defer lru.loaders.lock.Unlock()
+
+	now := time.Now()
+	if lru.loaders.failures == nil {
+		lru.loaders.failures = make(map[K]loadFailure)
+	}
+	if len(lru.loaders.failures) >= lru.loaders.sweepAt {
+		for key, f := range lru.loaders.failures {
+			if now.After(f.expires) {
+				delete(lru.loaders.failures, key)
+			}
+		}
+		lru.loaders.sweepAt = max(2*len(lru.loaders.failures), 64)
+	}
+
+	lru.loaders.failures[k] = loadFailure{err: err, expires: now.Add(lru.opts.errorTTL)}
+	lru.loaders.failureCount.Store(int32(len(lru.loaders.failures)))
+}
+
+// maybeRefresh starts an asynchronous reload of n if it has less than the WithRefreshAhead fraction of its TTL
+// remaining, and isn't already being loaded; with WithSynchronous, the reload completes before it returns. Errors
+// from the reload are passed to the error handler.
+func (lru *Cache[K, V]) maybeRefresh(n *node[K, V], loader Loader[K, V]) {
+	fraction := lru.opts.refreshAhead
+	if fraction <= 0 {
+		return
+	}
+
+	// The expiry may be changed by the ExpiryPolicy on reads, so is read under the lock.
+	lru.readLock(OperationGet)
+	expires := n.expires
+	lru.lock.RUnlock()
+
+	if expires.IsZero() {
+		return
+	}
+
+	ttl := expires.Sub(n.created)
+	if time.Until(expires) > time.Duration(float64(ttl)*fraction) {
+		return
+	}
+
+	l, owner := lru.startLoad(n.key)
+	if !owner {
+		lru.leaveLoad(n.key, l)
+		return
+	}
+
+	lru.spawn(func() {
+		defer lru.finishLoad(n.key, l)
+		if perr := lru.unattended(func() {
+			l.value, l.outcome, l.err = lru.load(context.Background(), n.key, l, loader)
+		}); perr != nil {
+			l.value, l.outcome, l.err = lru.emptyV, LoadOutcomeError, perr
+		}
+		if l.err != nil {
+			lru.handleError(fmt.Errorf("unable to refresh key %v: %w", n.key, l.err))
+		}
+	})
+}
+
+// load calls the loader, subject to the concurrent load limit, storing the result in the cache on success.
+// With WithErrorCaching, a cached error for k is returned instead of calling the loader.
+func (lru *Cache[K, V]) load(ctx context.Context, k K, l *load[V], loader Loader[K, V]) (V, LoadOutcome, error) {
+	if err := lru.cachedFailure(k); err != nil {
+		return lru.emptyV, LoadOutcomeError, err
+	}
+
+	if err := lru.loaders.limiter.acquire(ctx); err != nil {
+		return lru.emptyV, LoadOutcomeError, err
+	}
+
+	var v V
+	var expires time.Time
+	var err error
+	start := time.Now()
+	func() {
+		defer lru.loaders.limiter.release()
+
+		loadCtx := ctx
+		if timeout := lru.opts.loadTimeout; timeout > 0 {
+			var cancel context.CancelFunc
+			loadCtx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		if perr := lru.safely("Loader", func() {
+			v, expires, err = loader(loadCtx, k)
+		}); perr != nil {
+			err = perr
+		}
+	}()
+	lru.recordLoad(start, err)
+
+	if err != nil {
+		if lru.opts.errorTTL > 0 && !errors.Is(err, ErrNotFound) && ctx.Err() == nil {
+			lru.cacheFailure(k, err)
+		}
+		if ttl := lru.opts.negativeTTL; ttl > 0 && errors.Is(err, ErrNotFound) {
+			eo := entryOptions{size: 1, expires: time.Now().Add(ttl), negative: true}
+			if _, serr := lru.storeLoaded(k, l, lru.emptyV, eo); serr != nil {
+				lru.handleError(fmt.Errorf("unable to cache miss for key %v: %w", k, serr))
+			}
+		}
+		return lru.emptyV, LoadOutcomeError, err
+	}
+
+	outcome, err := lru.storeLoaded(k, l, v, entryOptions{size: 1, expires: expires, cost: time.Since(start)})
+	if err != nil {
+		return lru.emptyV, LoadOutcomeError, fmt.Errorf("unable to cache loaded value: %w", err)
+	}
+
+	return v, outcome, nil
+}
+
+// storeLoaded stores a loaded value, honouring the LoadConflictPolicy if the key was Set or Deleted during the load.
+func (lru *Cache[K, V]) storeLoaded(k K, l *load[V], v V, eo entryOptions) (LoadOutcome, error) {
+	outcome := LoadOutcomeLoaded
+	if !l.state.CompareAndSwap(loadStateLoading, loadStateStoring) {
+		if lru.opts.loadConflictPolicy == LoadConflictSetWins {
+			return LoadOutcomeDiscarded, nil
+		}
+		outcome = LoadOutcomeOverwrote
+	}
+
+	// The value is shared by all waiters, so is stored even if the owner's context is done.
+	eo.fromLoad = true
+	if err := lru.set(context.Background(), k, v, eo); err != nil {
+		return LoadOutcomeError, err
+	}
+	return outcome, nil
+}
+
+// waitForLoad waits for the in-flight load for k to complete, or ctx to be done, then stops counting the caller as
+// waiting on it. If owner is true, a panic from the loader is re-raised.
+func (lru *Cache[K, V]) waitForLoad(ctx context.Context, k K, l *load[V], owner bool) (V, LoadOutcome, error) {
+	defer lru.leaveLoad(k, l)
+
+	select {
+	case <-l.done:
+		if owner && l.panic != nil {
+			panic(l.panic)
+		}
+		return l.value, l.outcome, l.err
+	case <-ctx.Done():
+		return lru.emptyV, LoadOutcomeError, ctx.Err()
+	}
+}
+
+// leaveLoad stops counting a caller as waiting on l, the load for k. If the load was started by GetOrLoad and no one
+// is waiting on it any more, it's cancelled, and forgotten so that later callers for k start a new load rather than
+// sharing its failure.
+func (lru *Cache[K, V]) leaveLoad(k K, l *load[V]) {
+	lru.loaders.lock.Lock()
+	defer lru.loaders.lock.Unlock()
+
+	l.waiters--
+	if l.waiters == 0 && l.cancel != nil {
+		l.cancel()
+		lru.forgetLoad(k, l)
+	}
+}
+
+// forgetLoad removes l from the in-flight loads, if it's still the one for k.
+// Assumes the loaders' lock is already acquired.
+func (lru *Cache[K, V]) forgetLoad(k K, l *load[V]) {
+	if lru.loaders.inFlight[k] == l {
+		delete(lru.loaders.inFlight, k)
+		lru.loaders.count.Add(-1)
+	}
+}
+
+//---
+
+// loadLimiter caps the number of loader calls running concurrently, with a bounded queue of waiting callers.
+// A nil *loadLimiter imposes no limit.
+type loadLimiter struct {
+	slots   chan struct{}
+	waiting atomic.Int64
+	queue   int64         // Maximum number of waiting callers; negative means unbounded.
+	timeout time.Duration // Maximum time to wait for a slot; zero means wait until the context is done.
+}
+
+// newLoadLimiter returns a limiter allowing max concurrent loads, or nil if max is zero.
+func newLoadLimiter(max int, queue int, timeout time.Duration) *loadLimiter {
+	if max <= 0 {
+		return nil
+	}
+	return &loadLimiter{
+		slots:   make(chan struct{}, max),
+		queue:   int64(queue),
+		timeout: timeout,
+	}
+}
+
+// acquire blocks until a load slot is available, the queue timeout passes, or ctx is done.
+func (l *loadLimiter) acquire(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	// Fast path for when a slot is free.
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	if waiting := l.waiting.Add(1); l.queue >= 0 && waiting > l.queue {
+		l.waiting.Add(-1)
+		return ErrLoadQueueFull
+	}
+	defer l.waiting.Add(-1)
+
+	var timeout <-chan time.Time
+	if l.timeout > 0 {
+		timer := time.NewTimer(l.timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-timeout:
+		return ErrLoadTimeout
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees a slot taken by acquire.
+func (l *loadLimiter) release() {
+	if l == nil {
+		return
+	}
+	<-l.slots
+}
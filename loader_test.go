@@ -0,0 +1,405 @@
+package lrucache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_GetOrLoad(t *testing.T) {
+	// Ensures concurrent misses on the same key call the loader once, the result is cached, and errors are not.
+
+	cache := NewCache[int, string](10)
+	defer cache.Close()
+
+	var calls atomic.Int32
+	loader := func(ctx context.Context, k int) (string, time.Time, error) {
+		calls.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		return "loaded", time.Time{}, nil
+	}
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := cache.GetOrLoad(context.Background(), 1, loader)
+			assert.NoError(t, err)
+			assert.Equal(t, "loaded", v)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	assert.True(t, cache.Contains(1))
+
+	failing := func(ctx context.Context, k int) (string, time.Time, error) {
+		return "", time.Time{}, errors.New("backend down")
+	}
+	_, err := cache.GetOrLoad(context.Background(), 2, failing)
+	assert.Error(t, err)
+	assert.False(t, cache.Contains(2))
+}
+
+func TestCache_GetOrLoadConcurrencyLimit(t *testing.T) {
+	// Checks that no more than the configured number of loaders run at once, and that the queue is bounded.
+
+	cache := NewCacheWithOptions[int, int](100, WithMaxConcurrentLoads(2), WithLoadQueue(3, time.Second))
+	defer cache.Close()
+
+	var running, peak atomic.Int32
+	release := make(chan struct{})
+	loader := func(ctx context.Context, k int) (int, time.Time, error) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		<-release
+		running.Add(-1)
+		return k, time.Time{}, nil
+	}
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := cache.GetOrLoad(context.Background(), i, loader)
+			assert.NoError(t, err)
+			assert.Equal(t, i, v)
+		}()
+	}
+
+	// Wait for 2 to be running and 3 to be queued.
+	require.Eventually(t, func() bool {
+		return running.Load() == 2 && cache.loaders.limiter.waiting.Load() == 3
+	}, time.Second, time.Millisecond)
+
+	_, err := cache.GetOrLoad(context.Background(), 100, loader)
+	assert.ErrorIs(t, err, ErrLoadQueueFull)
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(2), peak.Load())
+}
+
+func TestLoadingCache_Get(t *testing.T) {
+	// Validates that a LoadingCache loads missing entries, honours the loader's expiry, and doesn't cache errors.
+
+	var calls atomic.Int32
+	cache := NewLoadingCache[int, string](10, func(ctx context.Context, k int) (string, time.Time, error) {
+		calls.Add(1)
+		if k < 0 {
+			return "", time.Time{}, errors.New("negative keys are not supported")
+		}
+		return fmt.Sprintf("value-%d", k), time.Now().Add(50 * time.Millisecond), nil
+	})
+	defer cache.Close()
+
+	_, found := cache.GetIfPresent(1)
+	assert.False(t, found)
+
+	v, err := cache.Get(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "value-1", v)
+
+	v, found = cache.GetIfPresent(1)
+	assert.True(t, found)
+	assert.Equal(t, "value-1", v)
+
+	_, err = cache.Get(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// Once expired, the value is loaded again.
+	time.Sleep(100 * time.Millisecond)
+	_, err = cache.Get(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+
+	_, err = cache.Get(context.Background(), -1)
+	assert.Error(t, err)
+	_, err = cache.Get(context.Background(), -1)
+	assert.Error(t, err)
+	assert.Equal(t, int32(4), calls.Load())
+	assert.False(t, cache.Contains(-1))
+}
+
+func TestLoadingCache_RefreshAhead(t *testing.T) {
+	// Checks that hits near expiry return the current value, while a reload happens in the background.
+
+	var calls atomic.Int32
+	cache := NewLoadingCache[int, int32](10, func(ctx context.Context, k int) (int32, time.Time, error) {
+		return calls.Add(1), time.Now().Add(200 * time.Millisecond), nil
+	}, WithRefreshAhead(0.5))
+	defer cache.Close()
+
+	v, err := cache.Get(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), v)
+
+	// Not yet within the refresh window.
+	v, _ = cache.Get(context.Background(), 1)
+	assert.Equal(t, int32(1), v)
+	assert.Equal(t, int32(1), calls.Load())
+
+	time.Sleep(150 * time.Millisecond)
+
+	// Within the window, so the old value is returned, and a refresh triggered.
+	v, _ = cache.Get(context.Background(), 1)
+	assert.Equal(t, int32(1), v)
+
+	require.Eventually(t, func() bool {
+		v, _ := cache.GetIfPresent(1)
+		return v == 2
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestLoadingCache_NegativeCaching(t *testing.T) {
+	// Validates that not-found results are cached for the negative TTL, and reported as misses.
+
+	var calls atomic.Int32
+	cache := NewLoadingCache[int, *string](10, func(ctx context.Context, k int) (*string, time.Time, error) {
+		calls.Add(1)
+		return nil, time.Time{}, fmt.Errorf("user %d: %w", k, ErrNotFound)
+	}, WithNegativeCaching(50*time.Millisecond), WithRejectNilValues())
+	defer cache.Close()
+
+	_, err := cache.Get(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = cache.Get(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, int32(1), calls.Load())
+
+	_, found := cache.GetIfPresent(1)
+	assert.False(t, found)
+	assert.False(t, cache.Contains(1))
+
+	time.Sleep(100 * time.Millisecond)
+
+	_, err = cache.Get(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestCache_LoadConflictPolicy(t *testing.T) {
+	// Checks that a Set during an in-flight load wins by default, and is overwritten with LoadConflictLoaderWins,
+	// with the outcome reported to the caller.
+
+	run := func(policy LoadConflictPolicy) (string, LoadOutcome) {
+		cache := NewCacheWithOptions[int, string](10, WithLoadConflictPolicy(policy))
+		defer cache.Close()
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		loader := func(ctx context.Context, k int) (string, time.Time, error) {
+			close(started)
+			<-release
+			return "loaded", time.Time{}, nil
+		}
+
+		var outcome LoadOutcome
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			var err error
+			_, outcome, err = cache.GetOrLoadWithOutcome(context.Background(), 1, loader)
+			assert.NoError(t, err)
+		}()
+
+		<-started
+		require.NoError(t, cache.Set(1, "set"))
+		close(release)
+		<-done
+
+		v, _ := cache.Get(1)
+		return v, outcome
+	}
+
+	v, outcome := run(LoadConflictSetWins)
+	assert.Equal(t, "set", v)
+	assert.Equal(t, LoadOutcomeDiscarded, outcome)
+
+	v, outcome = run(LoadConflictLoaderWins)
+	assert.Equal(t, "loaded", v)
+	assert.Equal(t, LoadOutcomeOverwrote, outcome)
+
+	cache := NewCache[int, string](10)
+	defer cache.Close()
+	loader := func(ctx context.Context, k int) (string, time.Time, error) { return "loaded", time.Time{}, nil }
+	_, outcome, _ = cache.GetOrLoadWithOutcome(context.Background(), 1, loader)
+	assert.Equal(t, LoadOutcomeLoaded, outcome)
+	_, outcome, _ = cache.GetOrLoadWithOutcome(context.Background(), 1, loader)
+	assert.Equal(t, LoadOutcomeHit, outcome)
+}
+
+func TestCache_StaleIfError(t *testing.T) {
+	// Checks a recently expired value is served when the loader fails, but not once it's too stale, nor for misses.
+
+	var handled []error
+	cache := NewCacheWithOptions[string, string](10, WithStaleIfError(50*time.Millisecond), WithErrorHandler(func(err error) {
+		handled = append(handled, err)
+	}))
+	defer cache.Close()
+
+	failing := func(ctx context.Context, k string) (string, time.Time, error) {
+		return "", time.Time{}, errors.New("backend down")
+	}
+
+	require.NoError(t, cache.SetWithExpiry("a", "old", time.Now().Add(5*time.Millisecond)))
+	time.Sleep(10 * time.Millisecond)
+
+	v, outcome, err := cache.GetOrLoadWithOutcome(context.Background(), "a", failing)
+	require.NoError(t, err)
+	assert.Equal(t, "old", v)
+	assert.Equal(t, LoadOutcomeStale, outcome)
+	require.Len(t, handled, 1)
+	assert.ErrorContains(t, handled[0], "backend down")
+
+	_, err = cache.GetOrLoad(context.Background(), "a", func(ctx context.Context, k string) (string, time.Time, error) {
+		return "", time.Time{}, ErrNotFound
+	})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	time.Sleep(60 * time.Millisecond)
+	_, err = cache.GetOrLoad(context.Background(), "a", failing)
+	assert.EqualError(t, err, "backend down")
+}
+
+func TestCache_LoadTimeout(t *testing.T) {
+	// Checks the loader's context is done once the timeout passes.
+
+	cache := NewCacheWithOptions[string, string](10, WithLoadTimeout(10*time.Millisecond))
+	defer cache.Close()
+
+	_, err := cache.GetOrLoad(context.Background(), "a", func(ctx context.Context, k string) (string, time.Time, error) {
+		<-ctx.Done()
+		return "", time.Time{}, ctx.Err()
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestCache_GetOrLoadOwnerCancelled(t *testing.T) {
+	// Checks the caller that starts a load giving up doesn't fail the others waiting on it.
+
+	cache := NewCache[string, string](10)
+	defer cache.Close()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	loader := func(ctx context.Context, k string) (string, time.Time, error) {
+		close(started)
+		select {
+		case <-release:
+			return "loaded", time.Time{}, nil
+		case <-ctx.Done():
+			return "", time.Time{}, ctx.Err()
+		}
+	}
+
+	ownerCtx, cancelOwner := context.WithCancel(context.Background())
+	ownerErr := make(chan error)
+	go func() {
+		_, err := cache.GetOrLoad(ownerCtx, "a", loader)
+		ownerErr <- err
+	}()
+	<-started
+
+	waiter := make(chan string)
+	go func() {
+		v, err := cache.GetOrLoad(context.Background(), "a", loader)
+		assert.NoError(t, err)
+		waiter <- v
+	}()
+
+	// Gives the waiter time to join the load before the owner leaves.
+	time.Sleep(10 * time.Millisecond)
+	cancelOwner()
+	assert.ErrorIs(t, <-ownerErr, context.Canceled)
+
+	close(release)
+	assert.Equal(t, "loaded", <-waiter)
+	assert.True(t, cache.Contains("a"))
+}
+
+func TestCache_GetOrLoadAllCancelled(t *testing.T) {
+	// Checks the shared load is cancelled once every caller waiting on it has given up.
+
+	cache := NewCache[string, string](10)
+	defer cache.Close()
+
+	cancelled := make(chan struct{})
+	loader := func(ctx context.Context, k string) (string, time.Time, error) {
+		<-ctx.Done()
+		close(cancelled)
+		return "", time.Time{}, ctx.Err()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := cache.GetOrLoad(ctx, "a", loader)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("load wasn't cancelled")
+	}
+}
+
+func TestCache_ErrorCaching(t *testing.T) {
+	// Checks loader errors are returned without calling the loader again until they expire, or the key is Set.
+
+	cache := NewCacheWithOptions[string, string](10, WithErrorCaching(30*time.Millisecond))
+	defer cache.Close()
+
+	var calls atomic.Int32
+	failing := func(ctx context.Context, k string) (string, time.Time, error) {
+		calls.Add(1)
+		return "", time.Time{}, errors.New("backend down")
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := cache.GetOrLoad(context.Background(), "a", failing)
+		assert.ErrorContains(t, err, "backend down")
+	}
+	assert.Equal(t, int32(1), calls.Load())
+
+	time.Sleep(40 * time.Millisecond)
+	_, err := cache.GetOrLoad(context.Background(), "a", failing)
+	assert.ErrorContains(t, err, "backend down")
+	assert.Equal(t, int32(2), calls.Load())
+
+	// Setting the key forgets the error.
+	require.NoError(t, cache.Set("a", "set"))
+	cache.Delete("a")
+	v, err := cache.GetOrLoad(context.Background(), "a", func(ctx context.Context, k string) (string, time.Time, error) {
+		return "loaded", time.Time{}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "loaded", v)
+
+	// Errors from the caller's context aren't cached.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = cache.GetOrLoad(ctx, "b", func(ctx context.Context, k string) (string, time.Time, error) {
+		return "", time.Time{}, ctx.Err()
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = cache.GetOrLoad(context.Background(), "b", func(ctx context.Context, k string) (string, time.Time, error) {
+		return "b", time.Time{}, nil
+	})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,110 @@
+package lrucache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// LoaderStage is a single data source in a LoaderChain.
+type LoaderStage[K comparable, V any] struct {
+	Name    string        // Identifies the stage in StageStats.
+	Load    Loader[K, V]  // Loads the value for a key; returning an error wrapping ErrNotFound falls through to the next stage.
+	Timeout time.Duration // Maximum time the stage may take, after which the next stage is tried. Zero means no timeout.
+}
+
+// StageStats reports how a LoaderChain stage has handled the keys passed to it.
+type StageStats struct {
+	Name     string
+	Hits     uint64 // Keys for which the stage returned the value.
+	Misses   uint64 // Keys for which the stage returned ErrNotFound.
+	Errors   uint64 // Keys for which the stage returned any other error.
+	Timeouts uint64 // Keys for which the stage didn't return within its timeout.
+}
+
+// stageCounters accumulates StageStats using atomics.
+type stageCounters struct {
+	hits, misses, errors, timeouts atomic.Uint64
+}
+
+// LoaderChain is an ordered chain of loaders, such as a local snapshot, then an L2, then the origin. On a miss,
+// each stage is tried in turn until one returns a value. Misses, errors and timeouts all fall through to the next
+// stage. Use its Load method as the Loader for GetOrLoad or a LoadingCache.
+type LoaderChain[K comparable, V any] struct {
+	stages   []LoaderStage[K, V]
+	counters []stageCounters
+}
+
+// NewLoaderChain returns a LoaderChain that tries the given stages in order.
+func NewLoaderChain[K comparable, V any](stages ...LoaderStage[K, V]) *LoaderChain[K, V] {
+	return &LoaderChain[K, V]{
+		stages:   stages,
+		counters: make([]stageCounters, len(stages)),
+	}
+}
+
+// Load tries each stage in order, returning the first value found. If every stage misses, an error wrapping
+// ErrNotFound is returned. If any stage failed, the errors from the failed stages are returned, joined; these don't
+// wrap ErrNotFound, so aren't negatively cached, as the failed stage may have held the value.
+func (c *LoaderChain[K, V]) Load(ctx context.Context, k K) (V, time.Time, error) {
+	var errs []error
+
+	for i, stage := range c.stages {
+		v, expires, err := c.loadStage(ctx, stage, k)
+
+		counters := &c.counters[i]
+		switch {
+		case err == nil:
+			counters.hits.Add(1)
+			return v, expires, nil
+		case errors.Is(err, ErrNotFound):
+			counters.misses.Add(1)
+		case errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil:
+			// The stage's own timeout passed, rather than the caller's.
+			counters.timeouts.Add(1)
+			errs = append(errs, fmt.Errorf("stage %s: %w", stage.Name, err))
+		default:
+			counters.errors.Add(1)
+			errs = append(errs, fmt.Errorf("stage %s: %w", stage.Name, err))
+		}
+
+		// If the caller has given up, there's no point trying further stages.
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	var empty V
+	if len(errs) == 0 {
+		return empty, time.Time{}, fmt.Errorf("%w: key %v, in %d stages", ErrNotFound, k, len(c.stages))
+	}
+	return empty, time.Time{}, errors.Join(errs...)
+}
+
+// loadStage calls a single stage's loader, subject to its timeout.
+func (c *LoaderChain[K, V]) loadStage(ctx context.Context, stage LoaderStage[K, V], k K) (V, time.Time, error) {
+	if stage.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, stage.Timeout)
+		defer cancel()
+	}
+	return stage.Load(ctx, k)
+}
+
+// Stats returns the stats for each stage, in the order the stages are tried.
+func (c *LoaderChain[K, V]) Stats() []StageStats {
+	stats := make([]StageStats, len(c.stages))
+	for i, stage := range c.stages {
+		counters := &c.counters[i]
+		stats[i] = StageStats{
+			Name:     stage.Name,
+			Hits:     counters.hits.Load(),
+			Misses:   counters.misses.Load(),
+			Errors:   counters.errors.Load(),
+			Timeouts: counters.timeouts.Load(),
+		}
+	}
+	return stats
+}
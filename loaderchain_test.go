@@ -0,0 +1,93 @@
+package lrucache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoaderChain(t *testing.T) {
+	// Checks each stage is tried in turn, falling through on misses, errors and timeouts, with per-stage stats.
+
+	snapshot := map[string]string{"a": "from snapshot"}
+
+	chain := NewLoaderChain(
+		LoaderStage[string, string]{
+			Name: "snapshot",
+			Load: func(ctx context.Context, k string) (string, time.Time, error) {
+				if v, found := snapshot[k]; found {
+					return v, time.Time{}, nil
+				}
+				return "", time.Time{}, ErrNotFound
+			},
+		},
+		LoaderStage[string, string]{
+			Name:    "l2",
+			Timeout: 10 * time.Millisecond,
+			Load: func(ctx context.Context, k string) (string, time.Time, error) {
+				switch k {
+				case "slow":
+					<-ctx.Done()
+					return "", time.Time{}, ctx.Err()
+				case "broken":
+					return "", time.Time{}, errors.New("l2 unavailable")
+				}
+				return "", time.Time{}, ErrNotFound
+			},
+		},
+		LoaderStage[string, string]{
+			Name: "origin",
+			Load: func(ctx context.Context, k string) (string, time.Time, error) {
+				if k == "missing" {
+					return "", time.Time{}, ErrNotFound
+				}
+				return "from origin", time.Time{}, nil
+			},
+		},
+	)
+
+	cache := NewLoadingCache[string, string](10, chain.Load)
+	defer cache.Close()
+	ctx := context.Background()
+
+	v, err := cache.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "from snapshot", v)
+
+	for _, k := range []string{"b", "slow", "broken"} {
+		v, err = cache.Get(ctx, k)
+		require.NoError(t, err)
+		assert.Equal(t, "from origin", v)
+	}
+
+	_, err = cache.Get(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.Equal(t, []StageStats{
+		{Name: "snapshot", Hits: 1, Misses: 4},
+		{Name: "l2", Misses: 2, Errors: 1, Timeouts: 1},
+		{Name: "origin", Hits: 3, Misses: 1},
+	}, chain.Stats())
+}
+
+func TestLoaderChain_FailureIsNotNotFound(t *testing.T) {
+	// Ensures a miss isn't reported as ErrNotFound if an earlier stage failed, as it may have held the value.
+
+	failure := errors.New("unavailable")
+	chain := NewLoaderChain(
+		LoaderStage[string, string]{Name: "broken", Load: func(ctx context.Context, k string) (string, time.Time, error) {
+			return "", time.Time{}, failure
+		}},
+		LoaderStage[string, string]{Name: "origin", Load: func(ctx context.Context, k string) (string, time.Time, error) {
+			return "", time.Time{}, ErrNotFound
+		}},
+	)
+
+	_, _, err := chain.Load(context.Background(), "key")
+	assert.ErrorIs(t, err, failure)
+	assert.NotErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,69 @@
+package lrucache
+
+import (
+	"context"
+	"errors"
+)
+
+// LoadingCache is a read-through cache: Get transparently loads, stores and returns missing entries using a Loader.
+// All other Cache methods are available, and behave as they do on Cache.
+type LoadingCache[K comparable, V any] struct {
+	*Cache[K, V]
+	loader     Loader[K, V]
+	bulkLoader BulkLoader[K, V]
+}
+
+// NewLoadingCache creates a new read-through LRU cache with the specified capacity, using loader to fetch missing entries.
+// Loaded entries have a size of 1, and the expiry returned by the loader.
+func NewLoadingCache[K comparable, V any](capacity uint64, loader Loader[K, V], opts ...Option) *LoadingCache[K, V] {
+	return &LoadingCache[K, V]{
+		Cache:  NewCacheWithOptions[K, V](capacity, opts...),
+		loader: loader,
+	}
+}
+
+// NewBulkLoadingCache creates a read-through LRU cache as NewLoadingCache, additionally using bulkLoader to fetch
+// the entries missing from a GetAll with a single call.
+func NewBulkLoadingCache[K comparable, V any](capacity uint64, loader Loader[K, V], bulkLoader BulkLoader[K, V], opts ...Option) *LoadingCache[K, V] {
+	c := NewLoadingCache[K, V](capacity, loader, opts...)
+	c.bulkLoader = bulkLoader
+	return c
+}
+
+// Get returns the value for k, calling the loader if it's missing or has expired.
+// Concurrent calls for the same missing key share a single call to the loader.
+// Errors from the loader are returned. By default they aren't cached, so the next Get calls the loader again. With
+// WithNegativeCaching, errors wrapping ErrNotFound are cached as negative entries, and with WithErrorCaching, other
+// errors are cached, other than those from the callers' contexts; in either case, Get returns the cached error
+// without calling the loader until it expires, or the key is Set or Deleted.
+func (c *LoadingCache[K, V]) Get(ctx context.Context, k K) (V, error) {
+	return c.Cache.GetOrLoad(ctx, k, c.loader)
+}
+
+// GetIfPresent returns the value for k if it's in the cache, without calling the loader.
+func (c *LoadingCache[K, V]) GetIfPresent(k K) (V, bool) {
+	return c.Cache.Get(k)
+}
+
+// GetAll returns the values for all the given keys, loading those missing. With a bulk loader, from
+// NewBulkLoadingCache, they're loaded with a single call, as by GetMultiOrLoad; otherwise the loader is called for
+// each. Keys that have no value are absent from the returned map. The first other error is returned, along with the
+// values that were found.
+func (c *LoadingCache[K, V]) GetAll(ctx context.Context, keys []K) (map[K]V, error) {
+	if c.bulkLoader != nil {
+		return c.Cache.GetMultiOrLoad(ctx, keys, c.bulkLoader)
+	}
+
+	values := make(map[K]V, len(keys))
+	var err error
+	for _, k := range keys {
+		v, lerr := c.Cache.GetOrLoad(ctx, k, c.loader)
+		switch {
+		case lerr == nil:
+			values[k] = v
+		case !errors.Is(lerr, ErrNotFound) && err == nil:
+			err = lerr
+		}
+	}
+	return values, err
+}
@@ -1,6 +1,7 @@
 package lrucache
 
 import (
+	"context"
 	"sync"
 	"sync/atomic"
 )
@@ -24,6 +25,23 @@ func (l *AssertRWLock) Unlock() {
 	l.rwMutex.Unlock()
 }
 
+// LockContext acquires the write lock, unless ctx is done first, in which case ctx's error is returned.
+func (l *AssertRWLock) LockContext(ctx context.Context) error {
+	switch {
+	case ctx.Done() == nil:
+		l.rwMutex.Lock()
+	case l.rwMutex.TryLock():
+	default:
+		if err := waitForLock(ctx, l.rwMutex.Lock, l.rwMutex.Unlock); err != nil {
+			return err
+		}
+	}
+	if !atomic.CompareAndSwapInt32(&l.writer, 0, 1) {
+		panic("Write lock already held!")
+	}
+	return nil
+}
+
 func (l *AssertRWLock) RLock() {
 	l.rwMutex.RLock()
 }
@@ -32,9 +50,42 @@ func (l *AssertRWLock) RUnlock() {
 	l.rwMutex.RUnlock()
 }
 
+// RLockContext acquires the read lock, unless ctx is done first, in which case ctx's error is returned.
+func (l *AssertRWLock) RLockContext(ctx context.Context) error {
+	switch {
+	case ctx.Done() == nil:
+		l.rwMutex.RLock()
+	case l.rwMutex.TryRLock():
+	default:
+		return waitForLock(ctx, l.rwMutex.RLock, l.rwMutex.RUnlock)
+	}
+	return nil
+}
+
 func (l *AssertRWLock) AssertLocked() {
 	// Ensure a write lock is held
 	if atomic.LoadInt32(&l.writer) != 1 {
 		panic("Write lock is not held")
 	}
 }
+
+// waitForLock calls lock in a goroutine, waiting for it to return or ctx to be done.
+// If ctx is done first, the lock is released by the goroutine as soon as it's acquired.
+func waitForLock(ctx context.Context, lock, unlock func()) error {
+	acquired := make(chan struct{})
+	go func() {
+		lock()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		return nil
+	case <-ctx.Done():
+		go func() {
+			<-acquired
+			unlock()
+		}()
+		return ctx.Err()
+	}
+}
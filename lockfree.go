@@ -0,0 +1,119 @@
+package lrucache
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+const (
+	readStripes    = 16  // The number of buffers lock-free reads record their promotions in.
+	readBufferSize = 64  // The number of promotions in a buffer at which it's drained.
+	readBufferMax  = 256 // The number of promotions in a buffer beyond which they're dropped, until it's drained.
+
+	readDrainInterval = 10 * time.Millisecond // How often the buffers are drained, if they don't fill first.
+)
+
+// WithLockFreeReads makes hits on unexpired entries read the cache without taking its lock, for workloads with far
+// more reads than writes, where contention for the read lock is measurable. Entries are also held in a concurrent
+// index, which makes writes a little slower. Reads record their promotions in a set of striped buffers, rather than
+// sending them to the event goroutine, and another goroutine applies them to the list periodically, or once a
+// buffer fills; promotions beyond what the buffers hold are dropped, so the order of the list is more approximate.
+//
+// Reads still take the lock whenever they need a consistent view of an entry: with an ExpiryPolicy, which may
+// change expiries on read; with WithStrictConsistency, which has no background goroutines; once BumpTagEpoch has
+// been used; and for misses. Other reads, such as GetMulti and Contains, always take the lock.
+func WithLockFreeReads() Option {
+	return func(o *options) {
+		o.lockFreeReads = true
+	}
+}
+
+// readBuffer is one stripe of the promotions recorded by lock-free reads.
+type readBuffer[K comparable, V any] struct {
+	lock  sync.Mutex
+	nodes []*node[K, V]
+	_     [32]byte // Padding, so neighbouring stripes don't share a cache line.
+}
+
+// lockFree reports whether reads may currently skip the lock; see WithLockFreeReads.
+func (lru *Cache[K, V]) lockFree() bool {
+	return lru.opts.lockFreeReads && !lru.opts.strictConsistency && lru.opts.expiryPolicy == nil && !lru.tagEpochs.Load()
+}
+
+// getLockFree returns the unexpired node for k, recording its promotion as decided by p, without taking the lock. found is false if
+// there's no such node, in which case, as for a closed cache, the caller should fall back to the locked path, which
+// also deals with misses.
+func (lru *Cache[K, V]) getLockFree(k K, p Promotion) (n *node[K, V], found bool) {
+	if lru.halted.Load() {
+		return nil, false
+	}
+	v, found := lru.index.Load(k)
+	if !found {
+		return nil, false
+	}
+	n = v.(*node[K, V])
+	if n.isExpired(time.Now()) || n.version <= lru.epoch.Load() {
+		return nil, false
+	}
+
+	if lru.promoteRead(n, p) {
+		b := &lru.reads[rand.IntN(readStripes)]
+		b.lock.Lock()
+		if len(b.nodes) < readBufferMax {
+			b.nodes = append(b.nodes, n)
+		} else {
+			lru.dropped.Add(1)
+		}
+		full := len(b.nodes) == readBufferSize
+		b.lock.Unlock()
+
+		if full {
+			select {
+			case lru.drainReads <- struct{}{}:
+			default:
+			}
+		}
+	}
+
+	lru.recordLookup(k, true)
+	return n, true
+}
+
+// applyReads applies the promotions recorded by lock-free reads.
+// Assumes the list lock is held.
+func (lru *Cache[K, V]) applyReads() {
+	if !lru.opts.lockFreeReads {
+		return
+	}
+	for i := range lru.reads {
+		b := &lru.reads[i]
+		b.lock.Lock()
+		nodes := b.nodes
+		b.nodes = make([]*node[K, V], 0, readBufferSize)
+		b.lock.Unlock()
+
+		for _, n := range nodes {
+			lru.handleEvent(event[K, V]{a: EventActionAddToFront, n: n, hit: true})
+		}
+	}
+}
+
+// applyReadsInBackground applies the promotions recorded by lock-free reads as buffers fill, or periodically,
+// until the cache is closed.
+func (lru *Cache[K, V]) applyReadsInBackground() {
+	ticker := time.NewTicker(readDrainInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-lru.done:
+			return
+		case <-lru.drainReads:
+		case <-ticker.C:
+		}
+		lru.list.Lock()
+		lru.applyReads()
+		lru.list.Unlock()
+	}
+}
@@ -0,0 +1,79 @@
+package lrucache
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_LockFreeReads(t *testing.T) {
+	// Checks hits don't take the lock, and their promotions are still applied.
+
+	cache := NewCacheWithOptions[int, int](10, WithLockFreeReads())
+	defer cache.Close()
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, cache.Set(i, i))
+	}
+	require.NoError(t, cache.SetWithExpiry(3, 3, time.Now().Add(5*time.Millisecond)))
+
+	// Holding the write lock would block a read that took the lock.
+	cache.lock.Lock()
+	v, found := cache.Get(0)
+	cache.lock.Unlock()
+	assert.True(t, found)
+	assert.Equal(t, 0, v)
+
+	cache.Sync()
+	assert.Equal(t, []int{0, 3, 2, 1}, cache.OrderedKeys())
+
+	// Expired, invalidated, deleted and missing entries are misses.
+	time.Sleep(10 * time.Millisecond)
+	_, found = cache.Get(3)
+	assert.False(t, found)
+	cache.Delete(2)
+	_, found = cache.Get(2)
+	assert.False(t, found)
+	_, found = cache.Get(9)
+	assert.False(t, found)
+	cache.BumpEpoch()
+	_, found = cache.Get(1)
+	assert.False(t, found)
+
+	// Closed caches return an error, as ever.
+	cache.Close()
+	_, err := cache.Lookup(0)
+	assert.ErrorIs(t, err, ErrCacheClosed)
+}
+
+func TestCache_LockFreeReadsConcurrently(t *testing.T) {
+	// Checks concurrent reads and writes keep the cache consistent; run with -race.
+
+	cache := NewCacheWithOptions[string, int](100, WithLockFreeReads())
+	defer cache.Close()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				k := strconv.Itoa(i % 150)
+				if i%10 == 0 {
+					_ = cache.Set(k, i)
+				} else {
+					cache.Get(k)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	cache.Sync()
+	assert.NoError(t, cache.CheckIntegrity())
+	assert.LessOrEqual(t, cache.EntryCount(), uint64(100))
+}
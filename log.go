@@ -0,0 +1,26 @@
+package lrucache
+
+import (
+	"context"
+	"log/slog"
+)
+
+// log writes a record to the logger set by WithLogger, if any.
+func (lru *Cache[K, V]) log(level slog.Level, msg string, args ...any) {
+	if lru.logger == nil || !lru.logger.Enabled(context.Background(), level) {
+		return
+	}
+	lru.logger.Log(context.Background(), level, msg, args...)
+}
+
+// checkSaturation warns when the events channel becomes full, so operations start waiting on the event goroutine.
+// It warns once each time the channel fills, rather than for every event sent while it's full.
+func (lru *Cache[K, V]) checkSaturation() {
+	if lru.logger == nil || cap(lru.events) == 0 {
+		return
+	}
+	full := len(lru.events) == cap(lru.events)
+	if lru.saturated.Swap(full) != full && full {
+		lru.log(slog.LevelWarn, "lrucache: event buffer is full", "buffer", cap(lru.events))
+	}
+}
@@ -0,0 +1,53 @@
+package lrucache
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// syncBuffer is a bytes.Buffer that's safe for concurrent use, as the cache logs from its own goroutines.
+type syncBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.String()
+}
+
+func TestCache_WithLogger(t *testing.T) {
+	// Checks that evictions, purges and rejected entries are logged.
+
+	var out syncBuffer
+	logger := slog.New(slog.NewTextHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	cache := NewCacheWithOptions[int, string](2, WithLogger(logger), WithPurgeInterval(time.Millisecond))
+	defer cache.Close()
+
+	for i := 1; i <= 3; i++ {
+		assert.NoError(t, cache.Set(i, "value"))
+	}
+	assert.Error(t, cache.SetWithSize(4, "value", 3))
+
+	assert.Eventually(t, func() bool {
+		return strings.Contains(out.String(), "purged expired entries")
+	}, time.Second, time.Millisecond)
+
+	logs := out.String()
+	assert.Contains(t, logs, `msg="lrucache: evicted entries to make space" evicted=1`)
+	assert.Contains(t, logs, `msg="lrucache: rejected entry" key=4`)
+}
@@ -0,0 +1,109 @@
+// Package lrucachetest provides utilities for testing code that uses lrucache, in particular for asserting how
+// loaders behave under concurrent misses, and what a consumer reads and writes, without relying on sleeps.
+package lrucachetest
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Loader is a controllable loader, whose calls block until Release is called. Its Load method can be passed
+// anywhere an lrucache.Loader is expected.
+type Loader[K comparable, V any] struct {
+	Value   V         // Returned by every call.
+	Expires time.Time // Returned by every call.
+	Err     error     // Returned by every call, if not nil.
+
+	calls    atomic.Int32
+	started  chan struct{}
+	start    sync.Once
+	released chan struct{}
+	release  sync.Once
+}
+
+// NewLoader returns a Loader that returns v, once released.
+func NewLoader[K comparable, V any](v V) *Loader[K, V] {
+	return &Loader[K, V]{
+		Value:    v,
+		started:  make(chan struct{}),
+		released: make(chan struct{}),
+	}
+}
+
+// Load records the call, then blocks until Release is called, or ctx is done.
+func (l *Loader[K, V]) Load(ctx context.Context, k K) (V, time.Time, error) {
+	l.calls.Add(1)
+	l.start.Do(func() {
+		close(l.started)
+	})
+
+	select {
+	case <-l.released:
+	case <-ctx.Done():
+		var empty V
+		return empty, time.Time{}, ctx.Err()
+	}
+
+	if l.Err != nil {
+		var empty V
+		return empty, time.Time{}, l.Err
+	}
+	return l.Value, l.Expires, nil
+}
+
+// Started is closed once Load has first been called.
+func (l *Loader[K, V]) Started() <-chan struct{} {
+	return l.started
+}
+
+// Release unblocks all current and future calls to Load.
+func (l *Loader[K, V]) Release() {
+	l.release.Do(func() {
+		close(l.released)
+	})
+}
+
+// Calls returns the number of times Load has been called.
+func (l *Loader[K, V]) Calls() int {
+	return int(l.calls.Load())
+}
+
+// Result is the outcome of a single call made by Stampede.
+type Result[V any] struct {
+	Value V
+	Err   error
+}
+
+// Stampede simulates n concurrent misses: it calls get from n goroutines, all released at once, waits for the
+// loader to start, then releases the loader and returns each call's result. get would typically call
+// GetOrLoad with loader.Load. Callers that miss the in-flight load find the stored value, so a successful loader
+// is called exactly once.
+func Stampede[K comparable, V any](n int, loader *Loader[K, V], get func() (V, error)) []Result[V] {
+	results := make([]Result[V], n)
+
+	var ready, done sync.WaitGroup
+	gate := make(chan struct{})
+
+	ready.Add(n)
+	done.Add(n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer done.Done()
+			ready.Done()
+			<-gate
+			v, err := get()
+			results[i] = Result[V]{Value: v, Err: err}
+		}(i)
+	}
+
+	ready.Wait()
+	close(gate)
+
+	<-loader.Started()
+	loader.Release()
+
+	done.Wait()
+	return results
+}
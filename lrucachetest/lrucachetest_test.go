@@ -0,0 +1,41 @@
+package lrucachetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nsmithuk/lrucache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStampede(t *testing.T) {
+	// Checks a stampede of misses on one key results in exactly one loader call, with every caller getting the value.
+
+	cache := lrucache.NewCache[string, int](10)
+	defer cache.Close()
+
+	loader := NewLoader[string](42)
+	results := Stampede(50, loader, func() (int, error) {
+		return cache.GetOrLoad(context.Background(), "key", loader.Load)
+	})
+
+	require.Len(t, results, 50)
+	for _, r := range results {
+		assert.NoError(t, r.Err)
+		assert.Equal(t, 42, r.Value)
+	}
+	assert.Equal(t, 1, loader.Calls())
+}
+
+func TestLoader_Canceled(t *testing.T) {
+	// Ensures a blocked Load returns when its context is done.
+
+	loader := NewLoader[string](42)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := loader.Load(ctx, "key")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, loader.Calls())
+}
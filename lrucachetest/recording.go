@@ -0,0 +1,180 @@
+package lrucachetest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nsmithuk/lrucache"
+)
+
+// Operation names, as recorded in Call.Op.
+const (
+	OpGet           = "Get"
+	OpSet           = "Set"
+	OpSetWithExpiry = "SetWithExpiry"
+	OpContains      = "Contains"
+	OpDelete        = "Delete"
+	OpClose         = "Close"
+)
+
+// Call is a single operation made on a RecordingCache.
+type Call[K comparable, V any] struct {
+	Op      string
+	Key     K         // The key; the zero value for Close.
+	Value   V         // The value set, or returned by Get.
+	Expires time.Time // The expiry given to SetWithExpiry.
+	Found   bool      // The result of Get or Contains.
+	Err     error     // The error returned by Set or SetWithExpiry.
+}
+
+// scripted is a response set by Hit or Miss, returned in place of the wrapped cache's.
+type scripted[V any] struct {
+	value V
+	found bool
+}
+
+// RecordingCache is an lrucache.Cacher that records every operation made on it, for consumers to assert on their
+// caching behaviour. Operations are passed on to the wrapped cache, except for reads of keys scripted by Hit or
+// Miss, which are answered from the script instead.
+type RecordingCache[K comparable, V any] struct {
+	cache lrucache.Cacher[K, V]
+
+	lock   sync.Mutex
+	calls  []Call[K, V]
+	script map[K]scripted[V]
+}
+
+// NewRecordingCache returns a RecordingCache wrapping cache. If cache is nil, an lrucache.NopCache is used, so reads
+// miss unless scripted.
+func NewRecordingCache[K comparable, V any](cache lrucache.Cacher[K, V]) *RecordingCache[K, V] {
+	if cache == nil {
+		cache = lrucache.NopCache[K, V]{}
+	}
+	return &RecordingCache[K, V]{
+		cache:  cache,
+		script: make(map[K]scripted[V]),
+	}
+}
+
+// Hit scripts reads of k to find v, regardless of the wrapped cache's contents, until Unscript is called.
+func (c *RecordingCache[K, V]) Hit(k K, v V) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.script[k] = scripted[V]{value: v, found: true}
+}
+
+// Miss scripts reads of k to miss, regardless of the wrapped cache's contents, until Unscript is called.
+func (c *RecordingCache[K, V]) Miss(k K) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.script[k] = scripted[V]{}
+}
+
+// Unscript removes any script for k, so its reads are passed to the wrapped cache again.
+func (c *RecordingCache[K, V]) Unscript(k K) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.script, k)
+}
+
+// Get returns the scripted response for k, if any, otherwise the wrapped cache's, recording the call.
+func (c *RecordingCache[K, V]) Get(k K) (V, bool) {
+	s, ok := c.scripted(k)
+	if !ok {
+		s.value, s.found = c.cache.Get(k)
+	}
+	c.record(Call[K, V]{Op: OpGet, Key: k, Value: s.value, Found: s.found})
+	return s.value, s.found
+}
+
+// Set passes the value to the wrapped cache, recording the call.
+func (c *RecordingCache[K, V]) Set(k K, v V) error {
+	err := c.cache.Set(k, v)
+	c.record(Call[K, V]{Op: OpSet, Key: k, Value: v, Err: err})
+	return err
+}
+
+// SetWithExpiry passes the value to the wrapped cache, recording the call.
+func (c *RecordingCache[K, V]) SetWithExpiry(k K, v V, expires time.Time) error {
+	err := c.cache.SetWithExpiry(k, v, expires)
+	c.record(Call[K, V]{Op: OpSetWithExpiry, Key: k, Value: v, Expires: expires, Err: err})
+	return err
+}
+
+// Contains returns whether k is scripted to hit, if it's scripted, otherwise the wrapped cache's response,
+// recording the call.
+func (c *RecordingCache[K, V]) Contains(k K) bool {
+	s, ok := c.scripted(k)
+	if !ok {
+		s.found = c.cache.Contains(k)
+	}
+	c.record(Call[K, V]{Op: OpContains, Key: k, Found: s.found})
+	return s.found
+}
+
+// Delete passes the deletion to the wrapped cache, recording the call. Any script for k is kept.
+func (c *RecordingCache[K, V]) Delete(k K) {
+	c.cache.Delete(k)
+	c.record(Call[K, V]{Op: OpDelete, Key: k})
+}
+
+// Close closes the wrapped cache, recording the call.
+func (c *RecordingCache[K, V]) Close() {
+	c.cache.Close()
+	c.record(Call[K, V]{Op: OpClose})
+}
+
+// Calls returns the calls made so far, in order.
+func (c *RecordingCache[K, V]) Calls() []Call[K, V] {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]Call[K, V](nil), c.calls...)
+}
+
+// Count returns the number of calls made so far of the given operation, e.g. OpGet.
+func (c *RecordingCache[K, V]) Count(op string) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	count := 0
+	for _, call := range c.calls {
+		if call.Op == op {
+			count++
+		}
+	}
+	return count
+}
+
+// Hits returns the number of Get calls that found a value.
+func (c *RecordingCache[K, V]) Hits() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	hits := 0
+	for _, call := range c.calls {
+		if call.Op == OpGet && call.Found {
+			hits++
+		}
+	}
+	return hits
+}
+
+// ResetCalls forgets the calls made so far. Scripts are kept.
+func (c *RecordingCache[K, V]) ResetCalls() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.calls = nil
+}
+
+func (c *RecordingCache[K, V]) scripted(k K) (scripted[V], bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	s, ok := c.script[k]
+	return s, ok
+}
+
+func (c *RecordingCache[K, V]) record(call Call[K, V]) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.calls = append(c.calls, call)
+}
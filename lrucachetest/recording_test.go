@@ -0,0 +1,62 @@
+package lrucachetest
+
+import (
+	"testing"
+
+	"github.com/nsmithuk/lrucache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordingCache_RecordsCalls(t *testing.T) {
+	// Checks operations are passed to the wrapped cache and recorded in order.
+
+	cache := NewRecordingCache[string, int](lrucache.NewCache[string, int](10))
+	defer cache.Close()
+
+	require.NoError(t, cache.Set("a", 1))
+	v, found := cache.Get("a")
+	assert.True(t, found)
+	assert.Equal(t, 1, v)
+	_, found = cache.Get("b")
+	assert.False(t, found)
+	cache.Delete("a")
+	assert.False(t, cache.Contains("a"))
+
+	calls := cache.Calls()
+	require.Len(t, calls, 5)
+	assert.Equal(t, Call[string, int]{Op: OpSet, Key: "a", Value: 1}, calls[0])
+	assert.Equal(t, Call[string, int]{Op: OpGet, Key: "a", Value: 1, Found: true}, calls[1])
+	assert.Equal(t, OpDelete, calls[3].Op)
+	assert.Equal(t, 2, cache.Count(OpGet))
+	assert.Equal(t, 1, cache.Hits())
+
+	cache.ResetCalls()
+	assert.Empty(t, cache.Calls())
+}
+
+func TestRecordingCache_Scripted(t *testing.T) {
+	// Checks scripted keys are answered from the script, whatever the wrapped cache holds.
+
+	cache := NewRecordingCache[string, int](nil)
+
+	cache.Hit("a", 42)
+	v, found := cache.Get("a")
+	assert.True(t, found)
+	assert.Equal(t, 42, v)
+	assert.True(t, cache.Contains("a"))
+
+	inner := lrucache.NewCache[string, int](10)
+	defer inner.Close()
+	require.NoError(t, inner.Set("b", 1))
+
+	cache = NewRecordingCache[string, int](inner)
+	cache.Miss("b")
+	_, found = cache.Get("b")
+	assert.False(t, found)
+
+	cache.Unscript("b")
+	v, found = cache.Get("b")
+	assert.True(t, found)
+	assert.Equal(t, 1, v)
+}
@@ -0,0 +1,83 @@
+package lrucache
+
+import "time"
+
+// MergePolicy decides which entry Merge keeps when a key is in both caches.
+type MergePolicy uint8
+
+const (
+	MergeKeepNewer    MergePolicy = iota // Keep whichever entry was stored most recently.
+	MergeKeepExisting                    // Keep the destination's entry.
+	MergeReplace                         // Replace the destination's entry with the source's.
+)
+
+// Merge copies the unexpired entries of src into the cache, under a single acquisition of each cache's lock, with
+// policy deciding the outcome for keys in both. The copied entries keep their sizes, expiries and metadata, but not
+// their tags, and become the most recently used, in their order in src. Entries too big for the cache are skipped,
+// and once the cache is full, its least recently used entries are evicted as usual. Values are copied shallowly.
+// This lets a live cache be migrated into a new, larger one without a cold start, or per-shard caches be combined.
+func (lru *Cache[K, V]) Merge(src *Cache[K, V], policy MergePolicy) error {
+	now := time.Now()
+
+	var nodes []*node[K, V]
+	src.readLock(OperationOther)
+	if src.stopped {
+		src.lock.RUnlock()
+		return ErrCacheClosed
+	}
+	src.runOnEventLoop(func() {
+		nodes = make([]*node[K, V], 0, src.length)
+		var total uint64
+		for n := src.head.next; n != src.tail; n = n.next {
+			if n.negative || src.isExpired(n, now) || lru.validate(n.size, time.Time{}) != nil {
+				continue
+			}
+
+			// Once the cache is full, anything less recent than this would be evicted anyway.
+			if total+n.size > lru.capacity {
+				break
+			}
+			total += n.size
+
+			nodes = append(nodes, &node[K, V]{
+				key:      n.key,
+				value:    n.value,
+				size:     n.size,
+				created:  n.created,
+				expires:  n.expires,
+				metadata: n.metadata,
+			})
+		}
+	})
+	src.lock.RUnlock()
+
+	var replace func(n, existing *node[K, V]) bool
+	switch policy {
+	case MergeKeepNewer:
+		replace = func(n, existing *node[K, V]) bool {
+			return existing.negative || lru.isExpired(existing, now) || n.created.After(existing.created)
+		}
+	case MergeKeepExisting:
+		replace = func(_, existing *node[K, V]) bool {
+			return existing.negative || lru.isExpired(existing, now)
+		}
+	}
+
+	return lru.insertNodes(nodes, nil, "", false, replace)
+}
+
+// CopyTo copies the unexpired entries of the cache into dst, replacing any entries dst has for the same keys.
+// It's equivalent to dst.Merge(lru, MergeReplace).
+func (lru *Cache[K, V]) CopyTo(dst *Cache[K, V]) error {
+	return dst.Merge(lru, MergeReplace)
+}
+
+// Clone returns an independent copy of the cache's unexpired entries and their order, for analysing its contents
+// offline while the original keeps serving. The clone has the same capacity, but none of the cache's Options, other
+// than WithStrictConsistency, so it runs no goroutines, and needn't be closed. Values are copied shallowly, and tags
+// aren't copied.
+func (lru *Cache[K, V]) Clone() *Cache[K, V] {
+	clone := NewCacheWithOptions[K, V](lru.capacity, WithStrictConsistency())
+	_ = clone.Merge(lru, MergeReplace)
+	return clone
+}
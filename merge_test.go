@@ -0,0 +1,91 @@
+package lrucache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_CopyTo(t *testing.T) {
+	// Checks entries are copied with their order, sizes and expiries, replacing the destination's.
+
+	src := NewCache[string, int](10)
+	defer src.Close()
+	dst := NewCache[string, int](20)
+	defer dst.Close()
+
+	expires := time.Now().Add(time.Hour)
+	require.NoError(t, src.SetWithOptions("a", 1, WithSize(2), WithExpiry(expires)))
+	require.NoError(t, src.Set("b", 2))
+	require.NoError(t, src.SetWithExpiry("expired", 0, time.Now().Add(time.Millisecond)))
+	require.NoError(t, src.Set("c", 3))
+	time.Sleep(5 * time.Millisecond)
+
+	require.NoError(t, dst.Set("b", 20))
+	require.NoError(t, dst.Set("d", 4))
+
+	require.NoError(t, src.CopyTo(dst))
+
+	assert.Equal(t, []string{"c", "b", "a", "d"}, dst.OrderedKeys())
+	e, _ := dst.Entry("a")
+	assert.Equal(t, uint64(2), e.Size)
+	assert.True(t, expires.Equal(e.Expires))
+	v, _ := dst.Get("b")
+	assert.Equal(t, 2, v)
+
+	// The source is unchanged.
+	assert.Equal(t, []string{"c", "b", "a"}, src.OrderedKeys())
+}
+
+func TestCache_MergePolicies(t *testing.T) {
+	// Checks the policy decides which entry is kept for keys in both caches.
+
+	older := NewCache[string, int](10)
+	defer older.Close()
+	require.NoError(t, older.Set("a", 1))
+	time.Sleep(time.Millisecond)
+
+	newer := NewCache[string, int](10)
+	defer newer.Close()
+	require.NoError(t, newer.Set("a", 2))
+
+	require.NoError(t, newer.Merge(older, MergeKeepNewer))
+	v, _ := newer.Get("a")
+	assert.Equal(t, 2, v)
+
+	require.NoError(t, older.Merge(newer, MergeKeepNewer))
+	v, _ = older.Get("a")
+	assert.Equal(t, 2, v)
+
+	require.NoError(t, older.Set("a", 3))
+	require.NoError(t, older.Merge(newer, MergeKeepExisting))
+	v, _ = older.Get("a")
+	assert.Equal(t, 3, v)
+
+	require.NoError(t, older.Merge(newer, MergeReplace))
+	v, _ = older.Get("a")
+	assert.Equal(t, 2, v)
+}
+
+func TestCache_Clone(t *testing.T) {
+	// Checks a clone has the same entries and order, and is independent of the original.
+
+	cache := NewCache[string, int](10)
+	defer cache.Close()
+
+	require.NoError(t, cache.Set("a", 1))
+	require.NoError(t, cache.Set("b", 2))
+	cache.Get("a")
+	cache.Sync()
+
+	clone := cache.Clone()
+	assert.Equal(t, cache.OrderedKeys(), clone.OrderedKeys())
+	assert.Equal(t, cache.Capacity(), clone.Capacity())
+
+	require.NoError(t, cache.Set("c", 3))
+	clone.Delete("a")
+	assert.Equal(t, []string{"c", "a", "b"}, cache.OrderedKeys())
+	assert.Equal(t, []string{"b"}, clone.OrderedKeys())
+}
@@ -0,0 +1,690 @@
+package lrucache
+
+import (
+	"io"
+	"log/slog"
+	"time"
+)
+
+// Option configures optional behaviour of a Cache. Options are passed to NewCacheWithOptions.
+type Option func(*options)
+
+// options holds the configuration built up from the Options passed to NewCacheWithOptions.
+type options struct {
+	buffer        uint16
+	purgeInterval time.Duration
+
+	adaptivePurge bool
+	purgeMin      time.Duration
+	purgeMax      time.Duration
+
+	purgeBudgetEntries  int
+	purgeBudgetDuration time.Duration
+	purgeMaxEntries     int
+
+	purgeAtExpiry bool
+
+	rejectNilValues bool
+	nilValueTTL     time.Duration
+
+	maxConcurrentLoads int
+	loadQueueSize      int
+	loadQueueTimeout   time.Duration
+
+	errorHandler func(error)
+	logger       *slog.Logger
+	onEvict      any // func(K, V, EvictionReason), checked against the cache's types at construction.
+	onEvictEntry any // func(Entry[K, V], EvictionReason), checked against the cache's types at construction.
+	evictHook    any // Internal only; func(K, V, time.Time, EvictionReason), as for onEvict but including the expiry.
+
+	lockContentionStats bool
+	latencyStats        bool
+	hitPositionStats    bool
+	accessStats         bool
+
+	maxEntriesPerTag int
+
+	tenantQuotas map[string]uint64
+	tenantFunc   any // func(K) string, checked against the cache's key type at construction.
+
+	maxEntrySize     uint64
+	maxEntryFraction float64
+	maxEntries       int
+
+	pressure         func() float64
+	pressureInterval time.Duration
+
+	doorkeeperWindow int
+
+	evictionBatch int
+	evictionYield bool
+
+	highWatermark float64
+	lowWatermark  float64
+
+	softCapacity uint64
+
+	weakCapacity bool
+	weakIdle     time.Duration
+
+	hotKeyPath     string
+	hotKeyCount    int
+	hotKeyInterval time.Duration
+
+	refreshAhead float64
+
+	negativeTTL time.Duration
+
+	loadTimeout  time.Duration
+	errorTTL     time.Duration
+	staleIfError time.Duration
+
+	ttlJitter float64
+
+	loadConflictPolicy LoadConflictPolicy
+
+	externalRun bool
+
+	strictConsistency bool
+	synchronous       bool
+
+	keepExpiryOnUpdate  bool
+	renewExpiryOnUpdate bool
+
+	noPromoteOnGet     bool
+	promotionThreshold float64
+	promotionSampling  int
+
+	onCapacityPressure        any // func(CapacityPressure[K]), checked against the cache's key type at construction.
+	capacityPressureThreshold int
+
+	equal any // func(V, V) bool, checked against the cache's value type at construction.
+
+	expiryPolicy any // ExpiryPolicy[K, V], checked against the cache's types at construction.
+
+	auditLog io.Writer
+
+	shadowFactors []float64
+
+	adaptiveMin      uint64
+	adaptiveTarget   float64
+	adaptiveInterval time.Duration
+
+	refCounting bool
+
+	evictionLess   any // func(EvictionCandidate[K, V], EvictionCandidate[K, V]) bool, checked at construction.
+	evictionSample int
+
+	costAware  bool
+	costSample int
+
+	weigher         any // func(K, V) uint64, checked at construction.
+	reweighInterval time.Duration
+
+	expiredChannel bool
+	expiredBuffer  int
+
+	onExpiredBatch    any // func([]K), checked against the cache's key type at construction.
+	expiredBatchSize  int
+	expiredBatchDelay time.Duration
+
+	statsRecorder StatsRecorder
+
+	overflowPolicy OverflowPolicy
+	lockFreeReads  bool
+
+	onUtilization         func(UtilizationEvent)
+	utilizationThresholds []float64
+
+	onThrash        func(ThrashAlert)
+	thrashWindow    time.Duration
+	maxEvictionRate float64
+	maxMissRatio    float64
+}
+
+// defaultOptions returns the configuration used when no Options are given.
+func defaultOptions() options {
+	return options{
+		buffer:        DefaultBufferSize,
+		purgeInterval: DefaultPurgeTimerInterval,
+		loadQueueSize: -1,
+	}
+}
+
+// WithBuffer sets the buffer size for the event channel. See DefaultBufferSize.
+func WithBuffer(buffer uint16) Option {
+	return func(o *options) {
+		o.buffer = buffer
+	}
+}
+
+// WithPurgeInterval sets the duration between purging expired nodes. Zero disables the periodic purge.
+func WithPurgeInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.purgeInterval = interval
+	}
+}
+
+// WithAdaptivePurge replaces the fixed purge interval with one that adapts to the entries in the cache. After a pass
+// that removes expired entries, the next is after min; after one that removes nothing, the interval doubles, up to
+// max. The next pass is never later than just after the soonest upcoming expiry, while staying within min and max.
+// This avoids wasted wake-ups for caches with few entries that expire.
+func WithAdaptivePurge(min, max time.Duration) Option {
+	return func(o *options) {
+		o.adaptivePurge = true
+		o.purgeInterval = min
+		o.purgeMin = min
+		o.purgeMax = max
+	}
+}
+
+// WithRejectNilValues causes Set calls with a nil value (nil pointer, map, slice, func, chan or interface) to
+// return ErrNilValue, rather than caching it.
+func WithRejectNilValues() Option {
+	return func(o *options) {
+		o.rejectNilValues = true
+	}
+}
+
+// WithNilValueTTL caps the lifetime of nil values to ttl, so an accidentally cached nil result doesn't persist.
+// Entries with a nil value and no expiry, or an expiry later than ttl from now, will expire after ttl. Otherwise
+// they're stored, and returned by Get, like any other value: they aren't negative entries, so can't be told apart
+// from a cached non-nil value other than by the value itself. To cache a key's absence distinctly, have the loader
+// return ErrNotFound, with WithNegativeCaching. Ignored if WithRejectNilValues is also set.
+func WithNilValueTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.nilValueTTL = ttl
+	}
+}
+
+// WithMaxConcurrentLoads caps the number of loader calls that may run at the same time.
+// Callers beyond the limit wait in a queue; see WithLoadQueue. Zero (the default) means no limit.
+func WithMaxConcurrentLoads(max int) Option {
+	return func(o *options) {
+		o.maxConcurrentLoads = max
+	}
+}
+
+// WithLoadQueue configures the queue of callers waiting for a load slot when WithMaxConcurrentLoads is set.
+// - size: Maximum number of waiting callers, beyond which ErrLoadQueueFull is returned. Negative (the default) means unbounded.
+// - timeout: Maximum time to wait for a slot, after which ErrLoadTimeout is returned. Zero means wait until the context is done.
+func WithLoadQueue(size int, timeout time.Duration) Option {
+	return func(o *options) {
+		o.loadQueueSize = size
+		o.loadQueueTimeout = timeout
+	}
+}
+
+// WithErrorHandler sets a function to receive errors that can't be returned to a caller, such as panics recovered
+// from user-supplied callbacks (as a *PanicError). Without an error handler, panics in callbacks are re-raised,
+// except those from callbacks run by the cache's background work, such as purging expired entries, which have no
+// caller to reach. These are logged to the WithLogger logger and dropped, skipping the rest of that pass's callbacks.
+func WithErrorHandler(fn func(error)) Option {
+	return func(o *options) {
+		o.errorHandler = fn
+	}
+}
+
+// WithLogger sets a structured logger for notable events: entries evicted to make space, purge passes and rejected
+// entries are logged at debug level, and the event buffer filling up at warn level. By default, nothing is logged.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithOnEvict sets a callback that's run whenever an entry is removed from the cache, with the reason it was removed.
+// The callback is run after the cache's lock has been released. Its key and value types must match the cache's.
+func WithOnEvict[K comparable, V any](fn func(k K, v V, reason EvictionReason)) Option {
+	return func(o *options) {
+		o.onEvict = fn
+	}
+}
+
+// WithLockContentionStats enables measuring the time spent waiting to acquire the cache's lock, per Operation.
+// The results are available from Stats. This adds a small overhead to every operation.
+func WithLockContentionStats() Option {
+	return func(o *options) {
+		o.lockContentionStats = true
+	}
+}
+
+// WithHitPositionStats enables tracking the approximate position in the list of each hit, available from
+// Stats as HitPositions.
+func WithHitPositionStats() Option {
+	return func(o *options) {
+		o.hitPositionStats = true
+	}
+}
+
+// WithAccessStats enables counting the reads of each entry, and recording when it was last read, available from
+// EntryInfo. This adds a small overhead to every read.
+func WithAccessStats() Option {
+	return func(o *options) {
+		o.accessStats = true
+	}
+}
+
+// WithMaxEntriesPerTag limits the number of entries that may share a tag. When adding an entry would exceed the
+// limit for one of its tags, the least recently used entry with that tag is evicted. Zero (the default) means no limit.
+func WithMaxEntriesPerTag(max int) Option {
+	return func(o *options) {
+		o.maxEntriesPerTag = max
+	}
+}
+
+// WithMaxEntrySize rejects entries larger than max with ErrItemTooBig, even if they'd fit within the cache's
+// capacity, so a single huge entry can't evict the whole working set. Zero (the default) means no limit.
+func WithMaxEntrySize(max uint64) Option {
+	return func(o *options) {
+		o.maxEntrySize = max
+	}
+}
+
+// WithMaxEntryFraction rejects entries larger than fraction of the cache's capacity with ErrItemTooBig, as for
+// WithMaxEntrySize, but scaling with the capacity, so call sites needn't compare sizes against it themselves.
+// Callers can treat the error as a signal to bypass the cache. Zero (the default) means no limit.
+func WithMaxEntryFraction(fraction float64) Option {
+	return func(o *options) {
+		o.maxEntryFraction = fraction
+	}
+}
+
+// WithMaxEntries limits the number of entries in the cache, in addition to its capacity, evicting from the tail
+// when either would be exceeded. This bounds the per-entry overhead of the cache, even when entries are small.
+func WithMaxEntries(max int) Option {
+	return func(o *options) {
+		o.maxEntries = max
+	}
+}
+
+// WithDoorkeeper only admits a new key into the cache on the second time it's Set (or loaded) within a window of
+// window new keys, so keys that are only ever used once don't displace the working set. Sightings are recorded in
+// a bloom filter, which is cleared at the end of each window; around 1% of first sightings are admitted in error.
+// Replacing an existing entry is always allowed, and bulk operations, such as SetAll and Warm, bypass it.
+// The number of keys refused is available from Stats.
+func WithDoorkeeper(window int) Option {
+	return func(o *options) {
+		o.doorkeeperWindow = window
+	}
+}
+
+// WithMemoryPressure shrinks the cache while memory is short. Every interval, fn is called for the fraction of the
+// capacity to use, from 0 to 1; entries are then evicted from the tail down to that fraction of the capacity, and
+// Sets evict to stay within it, until fn allows the cache to grow back. HeapLimitPressure returns a suitable fn.
+func WithMemoryPressure(fn func() float64, interval time.Duration) Option {
+	return func(o *options) {
+		o.pressure = fn
+		o.pressureInterval = interval
+	}
+}
+
+// WithEvictionWatermarks evicts in batches: once adding an entry would take the cache's size above high, given as a
+// fraction of its capacity, entries are evicted from the tail until the size, including the new entry, is at most
+// low. This amortises the cost of eviction under sustained writes. For example, 0.95 and 0.8. Unless
+// 0 < low <= high <= 1, the watermarks are ignored, and just enough is evicted for each entry.
+func WithEvictionWatermarks(high, low float64) Option {
+	return func(o *options) {
+		if 0 < low && low <= high && high <= 1 {
+			o.highWatermark = high
+			o.lowWatermark = low
+		}
+	}
+}
+
+// WithSoftCapacity lets Sets take the cache above soft, up to its capacity, without waiting for any eviction.
+// Whenever the cache goes over soft, a background goroutine evicts from the tail until it's back within it, in
+// batches if WithEvictionBatch is set. The capacity remains a hard limit, enforced synchronously, so eviction only
+// adds latency to Sets once the background eviction has fallen behind. It replaces WithEvictionWatermarks.
+func WithSoftCapacity(soft uint64) Option {
+	return func(o *options) {
+		o.softCapacity = soft
+	}
+}
+
+// WithWeakCapacity admits entries without evicting any to make space, so the cache can grow beyond its capacity,
+// for batch workloads that want as much reuse as possible during a job. The cache is only brought back within its
+// capacity by Trim or, if idle is greater than zero, once the cache has gone unused for idle, e.g. between jobs.
+// Entries larger than the capacity are still rejected, and WithMaxEntries is still enforced on each Set. It
+// replaces WithSoftCapacity and WithEvictionWatermarks.
+func WithWeakCapacity(idle time.Duration) Option {
+	return func(o *options) {
+		o.weakCapacity = true
+		o.weakIdle = idle
+	}
+}
+
+// WithHotKeyProfile writes up to n of the hottest keys, as returned by HottestKeys, to the file at path every
+// interval, and once more when the cache is closed. Only the keys are saved, so the profile stays small; the next
+// run of the process can read it with ReadHotKeyProfile and warm its cache with Prefetch. The file is replaced
+// atomically, and failures to write it are passed to the handler set by WithErrorHandler. K must be encodable by
+// encoding/gob.
+func WithHotKeyProfile(path string, n int, interval time.Duration) Option {
+	return func(o *options) {
+		o.hotKeyPath = path
+		o.hotKeyCount = n
+		o.hotKeyInterval = interval
+	}
+}
+
+// WithEvictionBatch limits the number of entries evicted from the tail in a single pass to size, so that making space
+// for a large entry doesn't stall promotions from reads. If yield is true, Set also releases the cache's lock between
+// passes, letting other operations run while it evicts. Zero size (the default) means no limit.
+func WithEvictionBatch(size int, yield bool) Option {
+	return func(o *options) {
+		o.evictionBatch = size
+		o.evictionYield = yield && size > 0
+	}
+}
+
+// WithTenantQuota limits the total size of the entries belonging to tenant to quota. When adding an entry would
+// exceed it, the tenant's own least recently used entries are evicted, so one tenant can't evict everyone else's.
+// Entries larger than the quota are rejected with ErrItemTooBig. An entry's tenant is set by WithTenant, or
+// derived from its key by WithTenantFunc. Tenants without a quota are only limited by the cache's capacity.
+func WithTenantQuota(tenant string, quota uint64) Option {
+	return func(o *options) {
+		if o.tenantQuotas == nil {
+			o.tenantQuotas = make(map[string]uint64)
+		}
+		o.tenantQuotas[tenant] = quota
+	}
+}
+
+// WithTenantFunc sets a function deriving each entry's tenant from its key, for WithTenantQuota, when it isn't
+// given by WithTenant. Its key type must match the cache's.
+func WithTenantFunc[K comparable](fn func(k K) string) Option {
+	return func(o *options) {
+		o.tenantFunc = fn
+	}
+}
+
+// WithRefreshAhead enables refreshing entries before they expire. When GetOrLoad (or a LoadingCache's Get) hits an
+// entry with less than fraction of its TTL remaining, the current value is returned and the loader is called
+// asynchronously to replace it. For example, 0.1 refreshes entries in the last 10% of their TTL.
+func WithRefreshAhead(fraction float64) Option {
+	return func(o *options) {
+		o.refreshAhead = fraction
+	}
+}
+
+// WithNegativeCaching enables caching of misses: when a loader returns an error wrapping ErrNotFound, a
+// negative entry is stored for ttl, during which GetOrLoad returns ErrNotFound without calling the loader again.
+// Negative entries are reported as missing by Get and Contains. Other loader errors are never cached.
+func WithNegativeCaching(ttl time.Duration) Option {
+	return func(o *options) {
+		o.negativeTTL = ttl
+	}
+}
+
+// WithLoadTimeout limits each call to a loader to the given duration, by way of its context, so a slow backend
+// can't hold up the callers waiting on a key indefinitely. A loader that overruns should return the context's
+// error, which is then returned to them. Zero, the default, leaves loads bounded only by the callers' contexts.
+func WithLoadTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.loadTimeout = timeout
+	}
+}
+
+// WithErrorCaching caches loader errors, other than those wrapping ErrNotFound (see WithNegativeCaching), for ttl,
+// during which GetOrLoad returns the same error for the key without calling the loader again. This stops a
+// failing or flapping backend being hammered by every caller retrying through the cache. Errors from the caller's
+// own context being done aren't cached, but those from WithLoadTimeout are. Cached errors don't occupy any of
+// the cache's capacity, and are forgotten if the key is Set or Deleted.
+func WithErrorCaching(ttl time.Duration) Option {
+	return func(o *options) {
+		o.errorTTL = ttl
+	}
+}
+
+// WithStaleIfError makes GetOrLoad (and a LoadingCache's Get) return the expired value for a key when its loader
+// fails, rather than the error, provided the value expired no more than maxStale ago, and hasn't been removed from
+// the cache. The outcome is then LoadOutcomeStale, and the loader's error is passed to the error handler. This keeps
+// serving while a backend is down. Loader errors wrapping ErrNotFound are always returned.
+func WithStaleIfError(maxStale time.Duration) Option {
+	return func(o *options) {
+		o.staleIfError = maxStale
+	}
+}
+
+// WithTTLJitter randomises each entry's time to live by up to fraction either way, e.g. 0.1 for ±10%, so that
+// entries stored together with the same TTL don't all expire at once. It applies to entries added by Set, SetAll
+// and loaders, but not to those restored by Warm or LoadFrom, which keep their expiries. Fractions must be below 1,
+// so a jittered TTL is never zero or negative; others, and negative fractions, leave it unchanged.
+func WithTTLJitter(fraction float64) Option {
+	return func(o *options) {
+		if fraction >= 0 && fraction < 1 {
+			o.ttlJitter = fraction
+		}
+	}
+}
+
+// WithLoadConflictPolicy sets what happens when a key is Set or Deleted while a load for it is in flight.
+// The default is LoadConflictSetWins. The outcome of each load is available from GetOrLoadWithOutcome.
+func WithLoadConflictPolicy(policy LoadConflictPolicy) Option {
+	return func(o *options) {
+		o.loadConflictPolicy = policy
+	}
+}
+
+// WithOnCapacityPressure sets a callback that's run when the cache looks too small: when an entry is rejected with
+// ErrItemTooBig, or when storing one evicts more than threshold others to make space for it. This lets applications
+// alarm on undersized caches, rather than silently degrading. It covers Set and its variants, and loaders.
+// The key type must match the cache's.
+func WithOnCapacityPressure[K comparable](fn func(p CapacityPressure[K]), threshold int) Option {
+	return func(o *options) {
+		o.onCapacityPressure = fn
+		o.capacityPressureThreshold = threshold
+	}
+}
+
+// WithOnEvictEntry sets a callback that's run whenever an entry is removed from the cache, as for WithOnEvict, but
+// receiving the full Entry, including its size, expiry and metadata.
+func WithOnEvictEntry[K comparable, V any](fn func(e Entry[K, V], reason EvictionReason)) Option {
+	return func(o *options) {
+		o.onEvictEntry = fn
+	}
+}
+
+// WithExternalRun stops the constructor starting the periodic purge of expired entries in its own goroutine.
+// Instead, the purge runs within Run, so the cache's background work can be managed by a service supervisor.
+func WithExternalRun() Option {
+	return func(o *options) {
+		o.externalRun = true
+	}
+}
+
+// WithEqual sets the function CompareAndSwap uses to compare values. Without it, values are compared with ==, which
+// panics if the value type, or the dynamic type of an interface value, isn't comparable. Its value type must match
+// the cache's.
+func WithEqual[V any](fn func(a, b V) bool) Option {
+	return func(o *options) {
+		o.equal = fn
+	}
+}
+
+// WithExpiryPolicy sets the ExpiryPolicy deciding when entries expire, e.g. ExpireAfterAccess for a sliding TTL.
+// The default is AbsoluteExpiry. Its key and value types must match the cache's.
+func WithExpiryPolicy[K comparable, V any](policy ExpiryPolicy[K, V]) Option {
+	return func(o *options) {
+		o.expiryPolicy = policy
+	}
+}
+
+// WithOnExpiredBatch sets a callback that receives the keys of expired entries in batches, for mirroring the cache
+// into other systems. A batch is passed to fn once it holds maxBatch keys, or maxDelay after its first key was added,
+// whichever comes first. Zero maxBatch means no limit on the batch size; zero maxDelay means keys are passed on as
+// soon as they're removed. Any pending keys are passed to fn when the cache is closed.
+// Keys are reported when expired entries are removed by the periodic purge, or to make space. Calls to fn are never
+// concurrent. The key type must match the cache's.
+func WithOnExpiredBatch[K comparable](fn func(keys []K), maxBatch int, maxDelay time.Duration) Option {
+	return func(o *options) {
+		o.onExpiredBatch = fn
+		o.expiredBatchSize = maxBatch
+		o.expiredBatchDelay = maxDelay
+	}
+}
+
+// WithExpiredChannel enables the channel returned by Expired, with room for buffer entries. Expired entries are
+// sent to it as they're removed by the periodic purge, or to make space, without blocking: if the buffer is full,
+// they're dropped, so it should be drained promptly, and sized for bursts of expiries.
+func WithExpiredChannel(buffer int) Option {
+	return func(o *options) {
+		o.expiredChannel = true
+		o.expiredBuffer = buffer
+	}
+}
+
+// WithStrictConsistency makes reads apply their promotions to the list before returning, as writes always do,
+// rather than queuing them for the event goroutine. The LRU order is then always consistent, and no goroutines are
+// started unless a purge interval is set, so the cache doesn't need to be closed. The event buffer is ignored, and
+// concurrent reads are serialised while they update the list, which reduces throughput under contention.
+func WithStrictConsistency() Option {
+	return func(o *options) {
+		o.strictConsistency = true
+	}
+}
+
+// WithSynchronous makes the cache start no goroutines of its own: every operation, including refreshes from
+// WithRefreshAhead, Prefetch's loads and Close, runs to completion in the caller's goroutine. This makes the cache
+// deterministic when driven from a single goroutine, e.g. by a fuzzer, and usable on targets without threads, such as
+// WASM. It implies WithStrictConsistency, and WithSoftCapacity evicts as entries are added rather than in the
+// background. Features that need a background goroutine are inactive: the periodic purge, unless run by Run with
+// WithExternalRun, so expired entries are only removed when found or by DeleteExpired; and the work of
+// WithMemoryPressure, WithAdaptiveCapacity, WithReweighInterval, WithHotKeyProfile, WithWeakCapacity's idle trim and
+// WithThrashAlert. OnExpiredBatch callbacks are made without a delay. The cache remains safe for concurrent use.
+func WithSynchronous() Option {
+	return func(o *options) {
+		o.synchronous = true
+		o.strictConsistency = true
+	}
+}
+
+// WithKeepExpiryOnUpdate makes a Set without an expiry, replacing an unexpired entry, keep that entry's expiry,
+// rather than storing the new value with none. Refreshing a value then doesn't unintentionally make it immortal.
+// For such updates it takes precedence over the ExpiryPolicy. A Set with an expiry replaces it as usual.
+// It replaces WithRenewExpiryOnUpdate, if given before it.
+func WithKeepExpiryOnUpdate() Option {
+	return func(o *options) {
+		o.keepExpiryOnUpdate = true
+		o.renewExpiryOnUpdate = false
+	}
+}
+
+// WithRenewExpiryOnUpdate makes every overwrite of an entry restart its expiry from now, as given by the
+// ExpiryPolicy for a new write, e.g. the TTL of ExpireAfterWrite. Set already does so; this extends it to
+// CompareAndSwap and Compute, which otherwise keep the entry's expiry. It replaces WithKeepExpiryOnUpdate, if given
+// before it, as the two are alternative semantics for updates.
+func WithRenewExpiryOnUpdate() Option {
+	return func(o *options) {
+		o.renewExpiryOnUpdate = true
+		o.keepExpiryOnUpdate = false
+	}
+}
+
+// WithNoPromoteOnGet stops reads moving entries to the front of the list, so entries are evicted in the order they
+// were stored, as a FIFO cache. Reads then send no events at all, trading hit rate for throughput on scan-heavy
+// workloads. Without promotions, WithAccessStats and WithHitPositionStats don't count reads.
+func WithNoPromoteOnGet() Option {
+	return func(o *options) {
+		o.noPromoteOnGet = true
+	}
+}
+
+// WithPromotionThreshold skips moving an entry to the front of the list on a read if it's already within the most
+// recently used fraction of the list, e.g. 0.1 for the top 10%. Entries near the front are in little danger of
+// eviction, so for skewed workloads this keeps nearly all of the hit rate, while sending far fewer events.
+// An entry's position is estimated, as for Stats.HitPositions.
+func WithPromotionThreshold(fraction float64) Option {
+	return func(o *options) {
+		o.promotionThreshold = fraction
+	}
+}
+
+// WithPromotionSampling only moves an entry to the front of the list on one in n reads, chosen at random.
+// Hot entries are read often enough to stay near the front regardless, so this cuts the events sent by reads by
+// about n times, for a small loss of hit rate. Reads that skip their promotion aren't counted by WithAccessStats
+// or WithHitPositionStats.
+func WithPromotionSampling(n int) Option {
+	return func(o *options) {
+		o.promotionSampling = n
+	}
+}
+
+//---
+
+// EntryOption configures a single entry. EntryOptions are passed to SetWithOptions.
+type EntryOption func(*entryOptions)
+
+// entryOptions holds the configuration built up from the EntryOptions passed to SetWithOptions.
+type entryOptions struct {
+	size     uint64
+	expires  time.Time
+	tags     []string
+	metadata any
+	tenant   string
+
+	negative bool // Internal only; see WithNegativeCaching.
+	fromLoad bool // Internal only; the value came from a loader, so shouldn't supersede the in-flight load.
+
+	checkVersion bool   // Internal only; only store the entry if the current version is version. See SetIfVersion.
+	version      uint64 // Internal only; see checkVersion.
+
+	ifAbsent bool // Internal only; only store the entry if there's no unexpired entry for its key. See GetOrSet.
+
+	cost time.Duration // Internal only; how long the value took to load. See WithCostAwareEviction.
+
+	atTail bool
+}
+
+// WithSize sets the size of the entry. The default is 1. It has no effect with WithWeigher.
+func WithSize(size uint64) EntryOption {
+	return func(eo *entryOptions) {
+		eo.size = size
+	}
+}
+
+// WithInsertAtTail adds the entry at the tail of the list, as the least recently used, rather than at the front, so
+// storing data for a scan or bulk load doesn't push the hot set towards eviction. The entry is the next to be evicted
+// unless it's read, or other entries are added at the tail after it.
+func WithInsertAtTail() EntryOption {
+	return func(eo *entryOptions) {
+		eo.atTail = true
+	}
+}
+
+// WithExpiry sets the time at which the entry expires. The default is no expiry.
+func WithExpiry(expires time.Time) EntryOption {
+	return func(eo *entryOptions) {
+		eo.expires = expires
+	}
+}
+
+// WithTTL sets the entry to expire after ttl, from now.
+func WithTTL(ttl time.Duration) EntryOption {
+	return func(eo *entryOptions) {
+		eo.expires = time.Now().Add(ttl)
+	}
+}
+
+// WithTags associates the entry with one or more tags, allowing it to be removed with DeleteTag, and limited
+// with WithMaxEntriesPerTag.
+func WithTags(tags ...string) EntryOption {
+	return func(eo *entryOptions) {
+		eo.tags = append(eo.tags, tags...)
+	}
+}
+
+// WithTenant sets the tenant the entry belongs to, for WithTenantQuota, overriding WithTenantFunc.
+func WithTenant(tenant string) EntryOption {
+	return func(eo *entryOptions) {
+		eo.tenant = tenant
+	}
+}
+
+// WithMetadata attaches arbitrary user metadata to the entry, such as its source URL, checksum or a trace ID.
+// It's returned by GetWithMetadata and Entry, and passed to the WithOnEvictEntry callback.
+func WithMetadata(metadata any) EntryOption {
+	return func(eo *entryOptions) {
+		eo.metadata = metadata
+	}
+}
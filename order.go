@@ -0,0 +1,111 @@
+package lrucache
+
+import "time"
+
+// RemoveOldest removes the least recently used entry from the cache, and returns it. found is false if the cache
+// has no unexpired entries. Expired entries found at the tail are removed, with EvictionReasonExpired, along the way.
+// The returned entry is reported to eviction callbacks with EvictionReasonDeleted.
+func (lru *Cache[K, V]) RemoveOldest() (k K, v V, found bool) {
+	now := time.Now()
+
+	lru.writeLock(OperationDelete)
+	if lru.stopped {
+		lru.lock.Unlock()
+		return k, v, false
+	}
+	lru.runOnEventLoop(func() {
+		for n := lru.tail.previous; n != lru.head; n = lru.tail.previous {
+			switch {
+			case n.isExpired(now):
+				lru.removeNode(n, EvictionReasonExpired)
+			case lru.isStale(n):
+				lru.removeNode(n, EvictionReasonInvalidated)
+			case n.negative:
+				lru.removeNode(n, EvictionReasonDeleted)
+			default:
+				lru.removeNode(n, EvictionReasonDeleted)
+				k, v, found = n.key, n.value, true
+				return
+			}
+		}
+	})
+	removed := lru.takeRemovals()
+	lru.lock.Unlock()
+
+	lru.notifyRemovals(removed)
+
+	return k, v, found
+}
+
+// PeekOldest returns the least recently used unexpired entry, i.e. the next to be evicted, without affecting the
+// order of the list. found is false if the cache has no unexpired entries.
+func (lru *Cache[K, V]) PeekOldest() (e Entry[K, V], found bool) {
+	now := time.Now()
+
+	lru.readLock(OperationGet)
+	if lru.stopped {
+		lru.lock.RUnlock()
+		return e, false
+	}
+
+	lru.runOnEventLoop(func() {
+		for n := lru.tail.previous; n != lru.head; n = n.previous {
+			if !n.negative && !lru.isExpired(n, now) {
+				e, found = n.entry(), true
+				return
+			}
+		}
+	})
+	lru.lock.RUnlock()
+
+	return e, found
+}
+
+// PeekNewest returns the most recently used unexpired entry, without affecting the order of the list.
+// found is false if the cache has no unexpired entries.
+func (lru *Cache[K, V]) PeekNewest() (e Entry[K, V], found bool) {
+	now := time.Now()
+
+	lru.readLock(OperationGet)
+	if lru.stopped {
+		lru.lock.RUnlock()
+		return e, false
+	}
+
+	lru.runOnEventLoop(func() {
+		for n := lru.head.next; n != lru.tail; n = n.next {
+			if !n.negative && !lru.isExpired(n, now) {
+				e, found = n.entry(), true
+				return
+			}
+		}
+	})
+	lru.lock.RUnlock()
+
+	return e, found
+}
+
+// OrderedKeys returns the keys of the unexpired entries, from the most recently used to the least, without affecting
+// the order of the list. With a non-zero buffer, reads only affect the order once their promotions have been applied.
+func (lru *Cache[K, V]) OrderedKeys() []K {
+	now := time.Now()
+
+	lru.readLock(OperationOther)
+	if lru.stopped {
+		lru.lock.RUnlock()
+		return nil
+	}
+
+	var keys []K
+	lru.runOnEventLoop(func() {
+		keys = make([]K, 0, lru.length)
+		for n := lru.head.next; n != lru.tail; n = n.next {
+			if !n.negative && !lru.isExpired(n, now) {
+				keys = append(keys, n.key)
+			}
+		}
+	})
+	lru.lock.RUnlock()
+
+	return keys
+}
@@ -0,0 +1,112 @@
+package lrucache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_RemoveOldest(t *testing.T) {
+	// Checks entries are removed from the least recently used, skipping expired entries.
+
+	var reasons []EvictionReason
+	cache := NewCacheWithOptions[string, int](10, WithOnEvict(func(k string, v int, reason EvictionReason) {
+		reasons = append(reasons, reason)
+	}))
+	defer cache.Close()
+
+	require.NoError(t, cache.SetWithExpiry("expired", 0, time.Now().Add(time.Millisecond)))
+	require.NoError(t, cache.Set("a", 1))
+	require.NoError(t, cache.Set("b", 2))
+	time.Sleep(5 * time.Millisecond)
+
+	// Using "a" makes "b" the oldest.
+	cache.Get("a")
+
+	k, v, found := cache.RemoveOldest()
+	assert.True(t, found)
+	assert.Equal(t, "b", k)
+	assert.Equal(t, 2, v)
+	assert.Equal(t, []EvictionReason{EvictionReasonExpired, EvictionReasonDeleted}, reasons)
+
+	k, _, found = cache.RemoveOldest()
+	assert.True(t, found)
+	assert.Equal(t, "a", k)
+
+	_, _, found = cache.RemoveOldest()
+	assert.False(t, found)
+	assert.Equal(t, uint64(0), cache.Size())
+}
+
+func TestCache_PeekOldestNewest(t *testing.T) {
+	// Ensures peeking returns the ends of the list without changing its order.
+
+	cache := NewCache[string, int](10)
+	defer cache.Close()
+
+	_, found := cache.PeekOldest()
+	assert.False(t, found)
+	_, found = cache.PeekNewest()
+	assert.False(t, found)
+
+	require.NoError(t, cache.Set("a", 1))
+	require.NoError(t, cache.Set("b", 2))
+	require.NoError(t, cache.SetWithExpiry("expired", 3, time.Now().Add(time.Millisecond)))
+	time.Sleep(5 * time.Millisecond)
+
+	for i := 0; i < 2; i++ {
+		e, found := cache.PeekOldest()
+		assert.True(t, found)
+		assert.Equal(t, "a", e.Key)
+
+		// The expired entry is at the head, so is skipped.
+		e, found = cache.PeekNewest()
+		assert.True(t, found)
+		assert.Equal(t, "b", e.Key)
+		assert.Equal(t, 2, e.Value)
+	}
+
+	k, _, _ := cache.RemoveOldest()
+	assert.Equal(t, "a", k)
+}
+
+func TestCache_OrderedKeys(t *testing.T) {
+	// Checks keys are returned from the most recently used, skipping expired entries, without promoting any.
+
+	cache := NewCache[string, int](10)
+	defer cache.Close()
+
+	require.NoError(t, cache.SetWithExpiry("expired", 0, time.Now().Add(time.Millisecond)))
+	require.NoError(t, cache.Set("a", 1))
+	require.NoError(t, cache.Set("b", 2))
+	require.NoError(t, cache.Set("c", 3))
+	time.Sleep(5 * time.Millisecond)
+
+	cache.Get("a")
+
+	assert.Equal(t, []string{"a", "c", "b"}, cache.OrderedKeys())
+	assert.Equal(t, []string{"a", "c", "b"}, cache.OrderedKeys())
+
+	cache.Close()
+	assert.Nil(t, cache.OrderedKeys())
+}
+
+func TestCache_NoPromoteOnGet(t *testing.T) {
+	// Checks reads don't reorder the list, so the first entry stored is the first evicted.
+
+	cache := NewCacheWithOptions[string, int](3, WithNoPromoteOnGet())
+	defer cache.Close()
+
+	for i, k := range []string{"a", "b", "c"} {
+		require.NoError(t, cache.Set(k, i))
+	}
+	cache.Get("a")
+	cache.GetMulti([]string{"a", "b"})
+	assert.Equal(t, []string{"c", "b", "a"}, cache.OrderedKeys())
+
+	require.NoError(t, cache.Set("d", 3))
+	assert.False(t, cache.Contains("a"))
+	assert.Equal(t, []string{"d", "c", "b"}, cache.OrderedKeys())
+}
@@ -0,0 +1,110 @@
+package lrucache
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy decides what a read does with its promotion when the event buffer is full. See WithOverflowPolicy.
+type OverflowPolicy uint8
+
+const (
+	// OverflowBlock waits for space in the buffer, so every promotion is applied in order. This is the default.
+	OverflowBlock OverflowPolicy = iota
+
+	// OverflowDrop skips the promotion, so reads never wait on the event goroutine, at the cost of the list's order
+	// no longer reflecting every read.
+	OverflowDrop
+
+	// OverflowCoalesce sets the promotion aside, to be applied once the buffer has been drained. Further reads of an
+	// entry whose promotion is already set aside are merged with it, so reads never wait, and hot entries still
+	// reach the front of the list, though not in the exact order they were read.
+	OverflowCoalesce
+)
+
+// String returns a human-readable name for the policy.
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowBlock:
+		return "block"
+	case OverflowDrop:
+		return "drop"
+	case OverflowCoalesce:
+		return "coalesce"
+	default:
+		return "unknown"
+	}
+}
+
+// WithOverflowPolicy sets what a read does with its promotion when the event buffer is full; see OverflowPolicy.
+// The default, OverflowBlock, waits for space. Promotions of entries read by GetMulti always wait.
+// Promotions skipped or merged are counted in Stats.EventQueue.
+func WithOverflowPolicy(p OverflowPolicy) Option {
+	return func(o *options) {
+		o.overflowPolicy = p
+	}
+}
+
+// overflow holds the promotions set aside while the event buffer was full, for OverflowCoalesce.
+type overflow[K comparable, V any] struct {
+	waiting atomic.Bool // True while nodes is non-empty, so it's only locked when there's something to apply.
+
+	lock    sync.Mutex
+	nodes   []*node[K, V]            // In the order they were first set aside.
+	pending map[*node[K, V]]struct{} // The nodes set aside, to merge repeated reads of an entry.
+}
+
+// add sets aside a promotion of n, returning false if one was already waiting.
+func (o *overflow[K, V]) add(n *node[K, V]) bool {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if _, ok := o.pending[n]; ok {
+		return false
+	}
+	if o.pending == nil {
+		o.pending = make(map[*node[K, V]]struct{})
+	}
+	o.pending[n] = struct{}{}
+	o.nodes = append(o.nodes, n)
+	o.waiting.Store(true)
+	return true
+}
+
+// take removes and returns the nodes set aside.
+func (o *overflow[K, V]) take() []*node[K, V] {
+	if !o.waiting.Load() {
+		return nil
+	}
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	nodes := o.nodes
+	o.nodes = nil
+	clear(o.pending)
+	o.waiting.Store(false)
+	return nodes
+}
+
+// promoteOnOverflow applies the policy set by WithOverflowPolicy to a promotion that found the event buffer full,
+// returning false if it must wait for space instead.
+func (lru *Cache[K, V]) promoteOnOverflow(e event[K, V]) bool {
+	switch {
+	case lru.opts.overflowPolicy == OverflowDrop:
+		lru.dropped.Add(1)
+		return true
+	case lru.opts.overflowPolicy == OverflowCoalesce && e.a == EventActionAddToFront:
+		if !lru.overflow.add(e.n) {
+			lru.coalesced.Add(1)
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// applyOverflow applies the promotions set aside by OverflowCoalesce.
+// Assumes the list lock is held.
+func (lru *Cache[K, V]) applyOverflow() {
+	for _, n := range lru.overflow.take() {
+		lru.handleEvent(event[K, V]{a: EventActionAddToFront, n: n, hit: true})
+	}
+}
@@ -0,0 +1,64 @@
+package lrucache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fillEventBuffer sets keys 0 to 4, then holds the list lock and reads 0 and 1, so the event goroutine is stuck
+// applying the first promotion and the second fills the buffer of one.
+func fillEventBuffer(t *testing.T, cache *Cache[int, int]) {
+	for i := 0; i < 5; i++ {
+		require.NoError(t, cache.Set(i, i))
+	}
+	cache.list.Lock()
+	cache.Get(0)
+	require.Eventually(t, func() bool { return len(cache.events) == 0 }, time.Second, time.Millisecond)
+	cache.Get(1)
+	require.Equal(t, 1, len(cache.events))
+}
+
+func TestCache_OverflowDrop(t *testing.T) {
+	cache := NewCacheWithOptions[int, int](10, WithBuffer(1), WithOverflowPolicy(OverflowDrop))
+	defer cache.Close()
+
+	fillEventBuffer(t, cache)
+
+	// Doesn't wait for the event goroutine, which would deadlock.
+	_, found := cache.Get(2)
+	assert.True(t, found)
+
+	cache.list.Unlock()
+	cache.Sync()
+
+	assert.Equal(t, uint64(1), cache.Stats().EventQueue.Dropped)
+	keys := cache.OrderedKeys()
+	assert.ElementsMatch(t, []int{0, 1}, keys[:2])
+	assert.Equal(t, []int{4, 3, 2}, keys[2:])
+}
+
+func TestCache_OverflowCoalesce(t *testing.T) {
+	cache := NewCacheWithOptions[int, int](10, WithBuffer(1), WithOverflowPolicy(OverflowCoalesce))
+	defer cache.Close()
+
+	fillEventBuffer(t, cache)
+
+	cache.Get(2)
+	cache.Get(3)
+	cache.Get(2)
+
+	cache.list.Unlock()
+	cache.Sync()
+
+	s := cache.Stats().EventQueue
+	assert.Equal(t, uint64(0), s.Dropped)
+	assert.Equal(t, uint64(1), s.Coalesced)
+
+	// The promotions set aside are applied once the goroutine has applied the one it was stuck on.
+	keys := cache.OrderedKeys()
+	assert.ElementsMatch(t, []int{0, 1, 2, 3}, keys[:4])
+	assert.Equal(t, 4, keys[4])
+}
@@ -0,0 +1,168 @@
+package peer
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nsmithuk/lrucache"
+)
+
+// errNotFound is used between peers to signal that the owner's loader returned lrucache.ErrNotFound.
+var errNotFound = lrucache.ErrNotFound
+
+// Codec converts values to and from bytes for transfer between peers.
+type Codec[V any] interface {
+	Marshal(v V) ([]byte, error)
+	Unmarshal(b []byte) (V, error)
+}
+
+// GobCodec is a Codec using encoding/gob. It's the default.
+type GobCodec[V any] struct{}
+
+func (GobCodec[V]) Marshal(v V) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	err := gob.NewEncoder(buf).Encode(&v)
+	return buf.Bytes(), err
+}
+
+func (GobCodec[V]) Unmarshal(b []byte) (V, error) {
+	var v V
+	err := gob.NewDecoder(bytes.NewReader(b)).Decode(&v)
+	return v, err
+}
+
+// Group is a named, distributed, read-through cache. Each peer in the Pool creates a Group with the same name and
+// loader; keys are then loaded by, and cached on, the peer that owns them.
+type Group[V any] struct {
+	name   string
+	pool   *Pool
+	loader lrucache.Loader[string, V]
+	codec  Codec[V]
+
+	main *lrucache.Cache[string, V] // Keys owned by this peer.
+	hot  *lrucache.Cache[string, V] // Keys owned by other peers, fetched recently.
+
+	hotTTL time.Duration
+}
+
+// GroupOption configures a Group.
+type GroupOption[V any] func(*Group[V])
+
+// WithCodec sets the Codec used to transfer values between peers. The default is GobCodec.
+func WithCodec[V any](codec Codec[V]) GroupOption[V] {
+	return func(g *Group[V]) {
+		g.codec = codec
+	}
+}
+
+// WithHotCache sets the capacity of the cache holding keys fetched from other peers, and the maximum time they're
+// kept for, so changes on the owner are eventually seen. The defaults are an eighth of the main capacity, and one minute.
+func WithHotCache[V any](capacity uint64, ttl time.Duration) GroupOption[V] {
+	return func(g *Group[V]) {
+		g.hot.Close()
+		g.hot = lrucache.NewCache[string, V](capacity)
+		g.hotTTL = ttl
+	}
+}
+
+// NewGroup creates a Group and registers it with the pool. capacity is the capacity of the cache holding the keys
+// this peer owns; loader is called, on the owning peer only, to load missing keys.
+func NewGroup[V any](pool *Pool, name string, capacity uint64, loader lrucache.Loader[string, V], opts ...GroupOption[V]) *Group[V] {
+	g := &Group[V]{
+		name:   name,
+		pool:   pool,
+		loader: loader,
+		codec:  GobCodec[V]{},
+		main:   lrucache.NewCache[string, V](capacity),
+		hot:    lrucache.NewCache[string, V](max(capacity/8, 1)),
+		hotTTL: time.Minute,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	pool.register(name, g)
+	return g
+}
+
+// Name returns the group's name.
+func (g *Group[V]) Name() string {
+	return g.name
+}
+
+// Get returns the value for key. It's served from the local caches if possible, otherwise from the owning peer,
+// which loads it if needed. If the owning peer can't be reached, the value is loaded locally.
+func (g *Group[V]) Get(ctx context.Context, key string) (V, error) {
+	if v, found := g.main.Get(key); found {
+		return v, nil
+	}
+	if v, found := g.hot.Get(key); found {
+		return v, nil
+	}
+
+	owner := g.pool.Owner(key)
+	if owner == g.pool.Self() {
+		return g.main.GetOrLoad(ctx, key, g.loader)
+	}
+
+	v, err := g.hot.GetOrLoad(ctx, key, func(ctx context.Context, key string) (V, time.Time, error) {
+		return g.fetch(ctx, owner, key)
+	})
+	if err == nil || errors.Is(err, lrucache.ErrNotFound) || ctx.Err() != nil {
+		return v, err
+	}
+
+	// The owner is unavailable, so fall back to loading the value ourselves.
+	return g.main.GetOrLoad(ctx, key, g.loader)
+}
+
+// Close closes the group's caches. The group remains registered with the pool.
+func (g *Group[V]) Close() {
+	g.main.Close()
+	g.hot.Close()
+}
+
+// fetch gets the value for key from the given peer, capping its expiry at the hot cache TTL.
+func (g *Group[V]) fetch(ctx context.Context, peer, key string) (V, time.Time, error) {
+	var empty V
+
+	b, expires, err := g.pool.fetch(ctx, peer, g.name, key)
+	if err != nil {
+		return empty, time.Time{}, err
+	}
+
+	v, err := g.codec.Unmarshal(b)
+	if err != nil {
+		return empty, time.Time{}, fmt.Errorf("unable to decode value from peer %s: %w", peer, err)
+	}
+
+	if hotExpires := time.Now().Add(g.hotTTL); expires.IsZero() || expires.After(hotExpires) {
+		expires = hotExpires
+	}
+
+	return v, expires, nil
+}
+
+// serve returns the encoded value for key, loading it if needed, for a request from another peer.
+func (g *Group[V]) serve(ctx context.Context, key string) ([]byte, time.Time, error) {
+	v, err := g.main.GetOrLoad(ctx, key, g.loader)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	// Pass on the expiry, if the entry is still cached; it may already have been evicted.
+	entry, found := g.main.Entry(key)
+	if !found {
+		entry = lrucache.Entry[string, V]{Key: key, Value: v}
+	}
+
+	b, err := g.codec.Marshal(entry.Value)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("unable to encode value: %w", err)
+	}
+	return b, entry.Expires, nil
+}
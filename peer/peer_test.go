@@ -0,0 +1,128 @@
+package peer
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nsmithuk/lrucache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRing_Distribution(t *testing.T) {
+	// Checks keys are spread across peers, and removing a peer only moves the keys it owned.
+
+	ring := NewRing(DefaultReplicas, nil)
+	ring.Add("a", "b", "c")
+
+	owners := make(map[string]string)
+	counts := make(map[string]int)
+	for i := 0; i < 3000; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		owners[key] = ring.Get(key)
+		counts[owners[key]]++
+	}
+	assert.Len(t, counts, 3)
+	for _, c := range counts {
+		assert.Greater(t, c, 500)
+	}
+
+	ring.Remove("b")
+	for key, owner := range owners {
+		if owner != "b" {
+			assert.Equal(t, owner, ring.Get(key))
+		} else {
+			assert.NotEqual(t, "b", ring.Get(key))
+		}
+	}
+
+	assert.Equal(t, "", NewRing(0, nil).Get("key"))
+}
+
+// testPeer is a peer served by an httptest server.
+type testPeer struct {
+	server *httptest.Server
+	pool   *Pool
+	group  *Group[string]
+	loads  map[string]int
+}
+
+func newTestPeers(t *testing.T, n int) []*testPeer {
+	lock := &sync.Mutex{}
+	peers := make([]*testPeer, n)
+	urls := make([]string, n)
+
+	for i := range peers {
+		p := &testPeer{loads: make(map[string]int)}
+		p.server = httptest.NewUnstartedServer(nil)
+		p.server.Start()
+		p.pool = NewPool(p.server.URL)
+		p.server.Config.Handler = p.pool
+		p.group = NewGroup[string](p.pool, "users", 100, func(ctx context.Context, key string) (string, time.Time, error) {
+			lock.Lock()
+			p.loads[key]++
+			lock.Unlock()
+			if key == "missing" {
+				return "", time.Time{}, lrucache.ErrNotFound
+			}
+			return "value-" + key, time.Now().Add(time.Hour), nil
+		})
+		peers[i] = p
+		urls[i] = p.server.URL
+		t.Cleanup(p.server.Close)
+		t.Cleanup(p.group.Close)
+	}
+
+	for _, p := range peers {
+		p.pool.Set(urls...)
+	}
+	return peers
+}
+
+func TestGroup_Get(t *testing.T) {
+	// Verifies that each key is only loaded by its owner, however many peers ask for it.
+
+	peers := newTestPeers(t, 3)
+	ctx := context.Background()
+
+	for i := 0; i < 30; i++ {
+		key := fmt.Sprintf("%d", i)
+		for _, p := range peers {
+			v, err := p.group.Get(ctx, key)
+			require.NoError(t, err)
+			assert.Equal(t, "value-"+key, v)
+		}
+
+		owner := peers[0].pool.Owner(key)
+		for _, p := range peers {
+			if p.pool.Self() == owner {
+				assert.Equal(t, 1, p.loads[key])
+			} else {
+				assert.Equal(t, 0, p.loads[key])
+			}
+		}
+	}
+
+	for _, p := range peers {
+		_, err := p.group.Get(ctx, "missing")
+		assert.ErrorIs(t, err, lrucache.ErrNotFound)
+	}
+}
+
+func TestGroup_OwnerUnavailable(t *testing.T) {
+	// Ensures that when the owning peer is down, the value is loaded locally.
+
+	peers := newTestPeers(t, 2)
+	peers[1].server.Close()
+
+	for i := 0; i < 20; i++ {
+		key := fmt.Sprintf("%d", i)
+		v, err := peers[0].group.Get(context.Background(), key)
+		require.NoError(t, err)
+		assert.Equal(t, "value-"+key, v)
+	}
+}
@@ -0,0 +1,219 @@
+// Package peer lets multiple processes, each running an lrucache, form a peer group in the style of groupcache.
+//
+// Every key is owned by exactly one peer, chosen by consistent hashing. Gets for keys owned by another peer are
+// fetched from that peer over HTTP, and kept in a small local "hot" cache, while the owner loads missing keys
+// from the origin and keeps them in its "main" cache. This spreads both the origin load and the memory footprint
+// across the group.
+package peer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBasePath is the HTTP path prefix under which a Pool serves requests from its peers.
+const DefaultBasePath = "/_lrucache/"
+
+// expiresHeader carries an entry's absolute expiry, as Unix nanoseconds, between peers.
+const expiresHeader = "X-Lrucache-Expires"
+
+// ErrUnknownGroup is returned by a peer that has no group with the requested name.
+var ErrUnknownGroup = errors.New("unknown group")
+
+// group is the type-erased view of a Group used by Pool to serve requests.
+type group interface {
+	serve(ctx context.Context, key string) ([]byte, time.Time, error)
+}
+
+// Pool is the set of peers in a group, and the HTTP endpoint through which they fetch keys from each other.
+// It implements http.Handler, and must be served at its base path on the address given as self.
+type Pool struct {
+	self     string
+	basePath string
+	client   *http.Client
+
+	lock   sync.RWMutex
+	ring   *Ring
+	groups map[string]group
+}
+
+// PoolOption configures a Pool.
+type PoolOption func(*Pool)
+
+// WithBasePath sets the HTTP path prefix used between peers. The default is DefaultBasePath.
+func WithBasePath(path string) PoolOption {
+	return func(p *Pool) {
+		p.basePath = path
+	}
+}
+
+// WithHTTPClient sets the client used to fetch from other peers. The default is a client with a 10-second timeout.
+func WithHTTPClient(client *http.Client) PoolOption {
+	return func(p *Pool) {
+		p.client = client
+	}
+}
+
+// WithRing sets the Ring used to assign keys to peers, e.g. to change the number of virtual nodes or the hash.
+func WithRing(ring *Ring) PoolOption {
+	return func(p *Pool) {
+		p.ring = ring
+	}
+}
+
+// NewPool returns a Pool for the peer at self, a base URL such as "http://10.0.0.1:8080".
+func NewPool(self string, opts ...PoolOption) *Pool {
+	p := &Pool{
+		self:     strings.TrimSuffix(self, "/"),
+		basePath: DefaultBasePath,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		ring:     NewRing(DefaultReplicas, nil),
+		groups:   make(map[string]group),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Self returns the base URL of this peer.
+func (p *Pool) Self() string {
+	return p.self
+}
+
+// Set replaces the set of peers, which should include self.
+func (p *Pool) Set(peers ...string) {
+	p.lock.RLock()
+	ring := NewRing(p.ring.replicas, p.ring.hash)
+	p.lock.RUnlock()
+
+	for _, peer := range peers {
+		ring.Add(strings.TrimSuffix(peer, "/"))
+	}
+
+	p.lock.Lock()
+	p.ring = ring
+	p.lock.Unlock()
+}
+
+// Owner returns the base URL of the peer that owns key. It's self if there are no peers.
+func (p *Pool) Owner(key string) string {
+	p.lock.RLock()
+	owner := p.ring.Get(key)
+	p.lock.RUnlock()
+
+	if owner == "" {
+		return p.self
+	}
+	return owner
+}
+
+// register adds a group, so its keys can be served to peers.
+func (p *Pool) register(name string, g group) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, found := p.groups[name]; found {
+		panic(fmt.Sprintf("peer: group %q is already registered", name))
+	}
+	p.groups[name] = g
+}
+
+// ServeHTTP serves requests for keys from other peers, at <basePath><group>/<key>.
+func (p *Pool) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, p.basePath) {
+		http.NotFound(w, r)
+		return
+	}
+
+	name, escapedKey, found := strings.Cut(strings.TrimPrefix(r.URL.EscapedPath(), p.basePath), "/")
+	if !found {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	name, err := url.PathUnescape(name)
+	if err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	key, err := url.PathUnescape(escapedKey)
+	if err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	p.lock.RLock()
+	g, found := p.groups[name]
+	p.lock.RUnlock()
+
+	if !found {
+		// Not a 404, as that would be taken to mean the key doesn't exist.
+		http.Error(w, ErrUnknownGroup.Error(), http.StatusBadRequest)
+		return
+	}
+
+	b, expires, err := g.serve(r.Context(), key)
+	if errors.Is(err, errNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	if !expires.IsZero() {
+		w.Header().Set(expiresHeader, strconv.FormatInt(expires.UnixNano(), 10))
+	}
+	_, _ = w.Write(b)
+}
+
+// fetch gets the encoded value for key in the named group from the given peer.
+func (p *Pool) fetch(ctx context.Context, peer, name, key string) ([]byte, time.Time, error) {
+	u := peer + p.basePath + url.PathEscape(name) + "/" + url.PathEscape(key)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, time.Time{}, fmt.Errorf("%w: peer %s: %s", errNotFound, peer, strings.TrimSpace(string(b)))
+	default:
+		return nil, time.Time{}, fmt.Errorf("peer %s returned %s: %s", peer, resp.Status, strings.TrimSpace(string(b)))
+	}
+
+	var expires time.Time
+	if h := resp.Header.Get(expiresHeader); h != "" {
+		nanos, err := strconv.ParseInt(h, 10, 64)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("peer %s returned an invalid expiry: %w", peer, err)
+		}
+		expires = time.Unix(0, nanos)
+	}
+
+	return b, expires, nil
+}
@@ -0,0 +1,89 @@
+package peer
+
+import (
+	"hash/crc32"
+	"slices"
+	"strconv"
+)
+
+// DefaultReplicas is the number of virtual nodes each peer is given on a Ring.
+const DefaultReplicas = 50
+
+// Hash maps bytes to a point on a Ring.
+type Hash func(data []byte) uint32
+
+// Ring is a consistent hash ring, mapping keys to peers such that adding or removing a peer only moves the keys
+// owned by that peer. Each peer is placed on the ring multiple times (virtual nodes) to even out the distribution.
+// A Ring is not safe for concurrent modification; Pool guards its Ring with a lock.
+type Ring struct {
+	hash     Hash
+	replicas int
+	points   []uint32          // Sorted positions of all virtual nodes.
+	owners   map[uint32]string // The peer at each position.
+}
+
+// NewRing returns an empty Ring with the given number of virtual nodes per peer.
+// If hash is nil, crc32.ChecksumIEEE is used.
+func NewRing(replicas int, hash Hash) *Ring {
+	if replicas <= 0 {
+		replicas = DefaultReplicas
+	}
+	if hash == nil {
+		hash = crc32.ChecksumIEEE
+	}
+	return &Ring{
+		hash:     hash,
+		replicas: replicas,
+		owners:   make(map[uint32]string),
+	}
+}
+
+// Add places the given peers on the ring.
+func (r *Ring) Add(peers ...string) {
+	for _, p := range peers {
+		for i := 0; i < r.replicas; i++ {
+			point := r.hash([]byte(strconv.Itoa(i) + p))
+			r.points = append(r.points, point)
+			r.owners[point] = p
+		}
+	}
+	slices.Sort(r.points)
+}
+
+// Remove takes the given peers off the ring.
+func (r *Ring) Remove(peers ...string) {
+	for _, p := range peers {
+		for i := 0; i < r.replicas; i++ {
+			point := r.hash([]byte(strconv.Itoa(i) + p))
+			if r.owners[point] == p {
+				delete(r.owners, point)
+			}
+		}
+	}
+	r.points = r.points[:0]
+	for point := range r.owners {
+		r.points = append(r.points, point)
+	}
+	slices.Sort(r.points)
+}
+
+// IsEmpty returns true if there are no peers on the ring.
+func (r *Ring) IsEmpty() bool {
+	return len(r.points) == 0
+}
+
+// Get returns the peer that owns key, or an empty string if the ring is empty.
+func (r *Ring) Get(key string) string {
+	if r.IsEmpty() {
+		return ""
+	}
+
+	h := r.hash([]byte(key))
+
+	// The owner is the first virtual node clockwise from the key, wrapping around to the start.
+	i, _ := slices.BinarySearch(r.points, h)
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.owners[r.points[i]]
+}
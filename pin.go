@@ -0,0 +1,49 @@
+package lrucache
+
+import "time"
+
+// PinUntil exempts the entry for k from eviction to make space until t, after which it's treated as any other
+// entry, by its position in the list. This guarantees precomputed values stay resident through a known window.
+// A pinned entry still expires, and can be deleted; replacing it with Set removes the pin, though CompareAndSwap
+// and Compute keep it. A zero t removes the pin. It returns false if there's no unexpired entry for k.
+//
+// Evictions skip over pinned entries, so if pinned entries fill the cache, new entries take it over its capacity
+// until their pins lapse.
+func (lru *Cache[K, V]) PinUntil(k K, t time.Time) bool {
+	lru.writeLock(OperationSet)
+	defer lru.lock.Unlock()
+
+	if lru.stopped {
+		return false
+	}
+	n, found := lru.cache[k]
+	if !found || n.negative || lru.isExpired(n, time.Now()) {
+		return false
+	}
+	n.pinned = t
+	return true
+}
+
+// isPinned returns true if n is exempt from eviction at now; see PinUntil.
+// Assumes the lock is already acquired.
+func (n *node[K, V]) isPinned(now time.Time) bool {
+	return !n.pinned.IsZero() && now.Before(n.pinned)
+}
+
+// victim returns the least recently used node that may be evicted to make space, skipping any that are pinned,
+// or nil if there's none. With WithEvictionOrder, it's instead chosen from a sample of the least recently used.
+// Assumes the lock is already acquired, and the list lock is held.
+func (lru *Cache[K, V]) victim(now time.Time) *node[K, V] {
+	if lru.evictionLess != nil {
+		return lru.orderedVictim(now)
+	}
+	if lru.opts.costAware {
+		return lru.cheapestVictim(now)
+	}
+	for n := lru.tail.previous; n != lru.head; n = n.previous {
+		if !n.isPinned(now) {
+			return n
+		}
+	}
+	return nil
+}
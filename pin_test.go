@@ -0,0 +1,59 @@
+package lrucache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_PinUntil(t *testing.T) {
+	cache := NewCacheWithOptions[int, int](3, WithStrictConsistency())
+	defer cache.Close()
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, cache.Set(i, i))
+	}
+	assert.True(t, cache.PinUntil(0, time.Now().Add(50*time.Millisecond)))
+	assert.False(t, cache.PinUntil(9, time.Now().Add(time.Hour)))
+
+	// 0 is the least recently used, but pinned, so 1 is evicted instead.
+	require.NoError(t, cache.Set(3, 3))
+	assert.True(t, cache.Contains(0))
+	assert.False(t, cache.Contains(1))
+
+	// Compute keeps the pin.
+	_, err := cache.Compute(0, func(old int, found bool) (int, bool) { return old + 10, true })
+	require.NoError(t, err)
+	for i := 4; i < 7; i++ {
+		require.NoError(t, cache.Set(i, i))
+	}
+	assert.Equal(t, []int{6, 5, 0}, cache.OrderedKeys())
+
+	// Once the pin lapses, 0 is evicted as normal.
+	time.Sleep(60 * time.Millisecond)
+	require.NoError(t, cache.Set(7, 7))
+	assert.False(t, cache.Contains(0))
+	assert.NoError(t, cache.CheckIntegrity())
+}
+
+func TestCache_PinUntilOverCapacity(t *testing.T) {
+	// Checks pinned entries aren't evicted, even if that takes the cache over its capacity.
+
+	cache := NewCacheWithOptions[int, int](2, WithStrictConsistency())
+	defer cache.Close()
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, cache.Set(i, i))
+		require.True(t, cache.PinUntil(i, time.Now().Add(time.Hour)))
+	}
+	require.NoError(t, cache.Set(2, 2))
+	assert.Equal(t, uint64(3), cache.Size())
+
+	// Unpinning one lets the cache shrink back to its capacity on the next write.
+	require.True(t, cache.PinUntil(0, time.Time{}))
+	require.NoError(t, cache.Set(3, 3))
+	assert.Equal(t, uint64(2), cache.Size())
+	assert.ElementsMatch(t, []int{1, 3}, cache.OrderedKeys())
+}
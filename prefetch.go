@@ -0,0 +1,165 @@
+package lrucache
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// keyListVersion is written at the start of every key list, allowing the format to change in future.
+const keyListVersion = 1
+
+// keyList is the serialised form of the keys written by SaveHotKeys.
+// Keys are ordered from the hottest to the coldest.
+type keyList[K comparable] struct {
+	Version int
+	Saved   time.Time
+	Keys    []K
+}
+
+// PrefetchOption configures how Prefetch loads its keys.
+type PrefetchOption func(*prefetchOptions)
+
+// prefetchOptions holds the configuration built up from the PrefetchOptions passed to Prefetch.
+type prefetchOptions struct {
+	rate        float64
+	concurrency int
+}
+
+// WithPrefetchRate limits Prefetch to starting perSecond loads a second, so warming the cache doesn't overwhelm the
+// backend the loader reads from. Zero, the default, means no limit.
+func WithPrefetchRate(perSecond float64) PrefetchOption {
+	return func(po *prefetchOptions) {
+		po.rate = perSecond
+	}
+}
+
+// WithPrefetchConcurrency limits Prefetch to n loads in flight at once. The default is 1.
+func WithPrefetchConcurrency(n int) PrefetchOption {
+	return func(po *prefetchOptions) {
+		po.concurrency = n
+	}
+}
+
+// PrefetchResult counts what Prefetch did with each of its keys.
+type PrefetchResult struct {
+	Loaded  int // Keys loaded and stored.
+	Skipped int // Keys already cached, or that the loader had no value for.
+	Failed  int // Keys whose load failed; each error is passed to the handler set by WithErrorHandler.
+}
+
+// Prefetch warms the cache by loading the given keys through loader, as GetOrLoad, in order, so a newly started
+// process doesn't begin with a storm of misses. Keys already cached are skipped without affecting their LRU
+// position. Loads are spread out according to WithPrefetchRate and WithPrefetchConcurrency. If ctx is done before
+// every key has been loaded, the remaining keys are skipped and ctx's error is returned; the loads in flight are
+// left to finish, or observe ctx themselves.
+func (lru *Cache[K, V]) Prefetch(ctx context.Context, keys []K, loader Loader[K, V], opts ...PrefetchOption) (PrefetchResult, error) {
+	po := prefetchOptions{concurrency: 1}
+	for _, opt := range opts {
+		opt(&po)
+	}
+	po.concurrency = max(po.concurrency, 1)
+
+	var tick <-chan time.Time
+	if po.rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / po.rate))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	var (
+		result PrefetchResult
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		err    error
+	)
+	slots := make(chan struct{}, po.concurrency)
+	started := false
+
+	for _, k := range keys {
+		if lru.Contains(k) {
+			result.Skipped++
+			continue
+		}
+
+		// The first load starts straight away; each one after waits for the next tick.
+		if tick != nil && started {
+			select {
+			case <-ctx.Done():
+			case <-tick:
+			}
+		}
+		select {
+		case <-ctx.Done():
+		case slots <- struct{}{}:
+		}
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		started = true
+
+		wg.Add(1)
+		lru.spawn(func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			var lerr error
+			if perr := lru.unattended(func() { _, lerr = lru.GetOrLoad(ctx, k, loader) }); perr != nil {
+				lerr = perr
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case lerr == nil:
+				result.Loaded++
+			case errors.Is(lerr, ErrNotFound):
+				result.Skipped++
+			default:
+				result.Failed++
+				lru.handleError(fmt.Errorf("unable to prefetch key %v: %w", k, lerr))
+			}
+		})
+	}
+	wg.Wait()
+
+	lru.log(slog.LevelDebug, "lrucache: prefetched keys", "loaded", result.Loaded, "skipped", result.Skipped, "failed", result.Failed)
+	return result, err
+}
+
+// PrefetchFrom reads a list of keys written by SaveHotKeys from r, typically by the previous run of the process,
+// and passes them to Prefetch.
+func (lru *Cache[K, V]) PrefetchFrom(ctx context.Context, r io.Reader, loader Loader[K, V], opts ...PrefetchOption) (PrefetchResult, error) {
+	keys, err := ReadHotKeys[K](r)
+	if err != nil {
+		return PrefetchResult{}, err
+	}
+	return lru.Prefetch(ctx, keys, loader, opts...)
+}
+
+// SaveHotKeys writes up to n of the cache's hottest keys, as returned by HottestKeys, to w using gob, without their
+// values. K must be encodable by encoding/gob.
+func (lru *Cache[K, V]) SaveHotKeys(w io.Writer, n int) error {
+	l := keyList[K]{Version: keyListVersion, Saved: time.Now(), Keys: lru.HottestKeys(n)}
+	if err := gob.NewEncoder(w).Encode(l); err != nil {
+		return fmt.Errorf("unable to encode hot keys: %w", err)
+	}
+	return nil
+}
+
+// ReadHotKeys reads a list of keys written by SaveHotKeys from r, from the hottest to the coldest.
+func ReadHotKeys[K comparable](r io.Reader) ([]K, error) {
+	var l keyList[K]
+	if err := gob.NewDecoder(r).Decode(&l); err != nil {
+		return nil, fmt.Errorf("unable to decode hot keys: %w", err)
+	}
+	if l.Version != keyListVersion {
+		return nil, fmt.Errorf("unsupported hot keys version %d", l.Version)
+	}
+	return l.Keys, nil
+}
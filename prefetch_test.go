@@ -0,0 +1,117 @@
+package lrucache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_Prefetch(t *testing.T) {
+	// Checks missing keys are loaded, cached keys are skipped, and failures are counted and reported.
+
+	var mu sync.Mutex
+	var reported []error
+	cache := NewCacheWithOptions[int, string](10, WithErrorHandler(func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		reported = append(reported, err)
+	}))
+	defer cache.Close()
+
+	require.NoError(t, cache.Set(1, "cached"))
+
+	var calls atomic.Int32
+	loader := func(ctx context.Context, k int) (string, time.Time, error) {
+		calls.Add(1)
+		switch k {
+		case 3:
+			return "", time.Time{}, ErrNotFound
+		case 4:
+			return "", time.Time{}, errors.New("backend down")
+		}
+		return fmt.Sprintf("loaded %d", k), time.Time{}, nil
+	}
+
+	result, err := cache.Prefetch(context.Background(), []int{1, 2, 3, 4, 5}, loader, WithPrefetchConcurrency(2))
+	require.NoError(t, err)
+	assert.Equal(t, PrefetchResult{Loaded: 2, Skipped: 2, Failed: 1}, result)
+	assert.Equal(t, int32(4), calls.Load())
+
+	v, ok := cache.Get(2)
+	assert.True(t, ok)
+	assert.Equal(t, "loaded 2", v)
+	v, _ = cache.Get(1)
+	assert.Equal(t, "cached", v)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, reported, 1)
+	assert.ErrorContains(t, reported[0], "backend down")
+}
+
+func TestCache_PrefetchRate(t *testing.T) {
+	// Checks loads are spread out at the configured rate, and cancelling stops the prefetch.
+
+	cache := NewCache[int, int](100)
+	defer cache.Close()
+
+	loader := func(ctx context.Context, k int) (int, time.Time, error) {
+		return k, time.Time{}, nil
+	}
+
+	start := time.Now()
+	result, err := cache.Prefetch(context.Background(), []int{1, 2, 3, 4, 5}, loader, WithPrefetchRate(100))
+	require.NoError(t, err)
+	assert.Equal(t, 5, result.Loaded)
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	keys := make([]int, 100)
+	for i := range keys {
+		keys[i] = 100 + i
+	}
+	result, err = cache.Prefetch(ctx, keys, loader, WithPrefetchRate(100))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, result.Loaded, 10)
+}
+
+func TestCache_PrefetchFromHotKeys(t *testing.T) {
+	// Checks the hottest keys saved from one cache can be used to warm another.
+
+	cache := NewCache[string, int](10)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, cache.Set(fmt.Sprint(i), i))
+	}
+	cache.Get("1")
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, cache.SaveHotKeys(buf, 3))
+	cache.Close()
+
+	keys, err := ReadHotKeys[string](bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "4", "3"}, keys)
+
+	warmed := NewCache[string, int](10)
+	defer warmed.Close()
+
+	loader := func(ctx context.Context, k string) (int, time.Time, error) {
+		return len(k), time.Time{}, nil
+	}
+	result, err := warmed.PrefetchFrom(context.Background(), buf, loader)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Loaded)
+	assert.Equal(t, []string{"3", "4", "1"}, warmed.OrderedKeys())
+
+	_, err = warmed.PrefetchFrom(context.Background(), bytes.NewReader([]byte("nonsense")), loader)
+	assert.Error(t, err)
+}
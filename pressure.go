@@ -0,0 +1,83 @@
+package lrucache
+
+import (
+	"log/slog"
+	"runtime/metrics"
+	"time"
+)
+
+// heapMetric is the runtime/metrics sample read by HeapLimitPressure.
+const heapMetric = "/memory/classes/heap/objects:bytes"
+
+// HeapLimitPressure returns a function for WithMemoryPressure that scales the cache with the size of the heap,
+// relative to limit bytes. Below 80% of the limit, the full capacity is used; from there, the fraction falls
+// linearly, to 10% of the capacity once the heap reaches the limit.
+func HeapLimitPressure(limit uint64) func() float64 {
+	sample := []metrics.Sample{{Name: heapMetric}}
+	return func() float64 {
+		metrics.Read(sample)
+		if sample[0].Value.Kind() != metrics.KindUint64 {
+			return 1
+		}
+		used := float64(sample[0].Value.Uint64()) / float64(limit)
+		return pressureFraction(used)
+	}
+}
+
+// pressureFraction returns the fraction of capacity to use when the heap is at used times its limit.
+func pressureFraction(used float64) float64 {
+	const start, floor = 0.8, 0.1
+	switch {
+	case used <= start:
+		return 1
+	case used >= 1:
+		return floor
+	default:
+		return 1 - (used-start)/(1-start)*(1-floor)
+	}
+}
+
+// watchPressure applies the fraction of capacity returned by fn every interval, until the cache is closed.
+func (lru *Cache[K, V]) watchPressure(fn func() float64, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-lru.done:
+			return
+		case <-ticker.C:
+			lru.unattended(func() {
+				var fraction float64
+				if err := lru.safely("MemoryPressure", func() { fraction = fn() }); err != nil {
+					return
+				}
+				limit := uint64(min(max(fraction, 0), 1) * float64(lru.capacity))
+				if previous, changed := lru.setLimit(limit); changed {
+					lru.log(slog.LevelDebug, "lrucache: capacity limit changed under memory pressure", "previous", previous, "limit", limit)
+				}
+			})
+		}
+	}
+}
+
+// setLimit changes the size the cache is kept within, evicting from the tail if it's now over it. It returns the
+// previous limit, and whether it changed.
+func (lru *Cache[K, V]) setLimit(limit uint64) (previous uint64, changed bool) {
+	lru.writeLock(OperationOther)
+	if lru.stopped || lru.limit == limit {
+		lru.lock.Unlock()
+		return limit, false
+	}
+	previous = lru.limit
+	lru.limit = limit
+	lru.runOnEventLoop(func() {
+		lru.makeSpaceFor(0, 0)
+	})
+	removed := lru.takeRemovals()
+	lru.lock.Unlock()
+
+	lru.notifyRemovals(removed)
+
+	return previous, true
+}
@@ -0,0 +1,51 @@
+package lrucache
+
+import (
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_MemoryPressure(t *testing.T) {
+	// Checks that the cache shrinks while under pressure, and grows back once it subsides.
+
+	var fraction atomic.Uint64
+	fraction.Store(math.Float64bits(1))
+
+	cache := NewCacheWithOptions[int, int](10, WithMemoryPressure(func() float64 {
+		return math.Float64frombits(fraction.Load())
+	}, time.Millisecond))
+	defer cache.Close()
+
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, cache.Set(i, i))
+	}
+
+	fraction.Store(math.Float64bits(0.5))
+	assert.Eventually(t, func() bool { return cache.Stats().Limit == 5 }, time.Second, time.Millisecond)
+	assert.Equal(t, uint64(5), cache.Size())
+	assert.True(t, cache.Contains(9))
+	assert.False(t, cache.Contains(4))
+
+	assert.NoError(t, cache.Set(10, 10))
+	assert.Equal(t, uint64(5), cache.Size())
+
+	fraction.Store(math.Float64bits(1))
+	assert.Eventually(t, func() bool { return cache.Stats().Limit == 10 }, time.Second, time.Millisecond)
+	for i := 11; i < 20; i++ {
+		assert.NoError(t, cache.Set(i, i))
+	}
+	assert.Equal(t, uint64(10), cache.Size())
+}
+
+func TestPressureFraction(t *testing.T) {
+	assert.Equal(t, 1.0, pressureFraction(0.5))
+	assert.Equal(t, 1.0, pressureFraction(0.8))
+	assert.InDelta(t, 0.55, pressureFraction(0.9), 0.0001)
+	assert.Equal(t, 0.1, pressureFraction(1.2))
+
+	assert.Equal(t, 1.0, HeapLimitPressure(math.MaxUint64)())
+}
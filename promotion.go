@@ -0,0 +1,61 @@
+package lrucache
+
+import (
+	"context"
+	"math/rand/v2"
+)
+
+// Promotion overrides, for a single read, whether the entry read is moved to the front of the list.
+type Promotion uint8
+
+const (
+	PromoteDefault Promotion = iota // As configured by WithNoPromoteOnGet, WithPromotionThreshold and WithPromotionSampling.
+	PromoteNever                    // Leave the entry where it is, e.g. for scans that mustn't disturb the hot set.
+	PromoteAlways                   // Move the entry to the front, regardless of the cache's promotion options.
+)
+
+// GetWithPromotion returns the value for k, as Get does, but with p deciding whether the entry is moved to the front
+// of the list. Reads that aren't promoted aren't counted by WithAccessStats.
+func (lru *Cache[K, V]) GetWithPromotion(k K, p Promotion) (V, bool) {
+	n, found, _ := lru.getWithPromotion(context.Background(), k, p)
+	if !found || n.negative {
+		return lru.emptyV, false
+	}
+	return n.value, true
+}
+
+// promoteRead returns true if a read of n should move it to the front of the list, as decided by p, or otherwise
+// by shouldPromote. It's safe to call without the lock.
+func (lru *Cache[K, V]) promoteRead(n *node[K, V], p Promotion) bool {
+	switch p {
+	case PromoteNever:
+		return false
+	case PromoteAlways:
+		return true
+	default:
+		return lru.shouldPromote(n)
+	}
+}
+
+// shouldPromote returns true if a read of n should move it to the front of the list, as configured by
+// WithNoPromoteOnGet, WithPromotionThreshold and WithPromotionSampling.
+// It's safe to call without the lock.
+func (lru *Cache[K, V]) shouldPromote(n *node[K, V]) bool {
+	if lru.opts.noPromoteOnGet {
+		return false
+	}
+
+	if fraction := lru.opts.promotionThreshold; fraction > 0 {
+		// The number of promotions since n's, relative to the number of entries, estimates how far back it is.
+		behind := lru.sequence.Load() - n.sequence.Load()
+		if float64(behind) < fraction*float64(lru.entryGauge.Load()) {
+			return false
+		}
+	}
+
+	if sampling := lru.opts.promotionSampling; sampling > 1 && rand.IntN(sampling) != 0 {
+		return false
+	}
+
+	return true
+}
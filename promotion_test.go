@@ -0,0 +1,99 @@
+package lrucache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_PromotionThreshold(t *testing.T) {
+	// Checks reads only promote entries outside the most recently used fraction of the list.
+
+	cache := NewCacheWithOptions[int, int](10, WithPromotionThreshold(0.5), WithStrictConsistency())
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, cache.Set(i, i))
+	}
+
+	// 8 is near the front, so stays where it is.
+	cache.Get(8)
+	assert.Equal(t, []int{9, 8, 7}, cache.OrderedKeys()[:3])
+
+	// 1 is near the back, so is promoted.
+	cache.Get(1)
+	assert.Equal(t, []int{1, 9, 8}, cache.OrderedKeys()[:3])
+}
+
+func TestCache_PromotionSampling(t *testing.T) {
+	// Checks only around one in n reads promote the entry.
+
+	cache := NewCacheWithOptions[int, int](10, WithPromotionSampling(10), WithStrictConsistency(), WithHitPositionStats())
+
+	require.NoError(t, cache.Set(0, 0))
+	for i := 0; i < 1000; i++ {
+		cache.Get(0)
+	}
+
+	var promoted uint64
+	for _, count := range cache.Stats().HitPositions {
+		promoted += count
+	}
+	assert.Greater(t, promoted, uint64(50))
+	assert.Less(t, promoted, uint64(200))
+}
+
+func TestCache_GetWithPromotion(t *testing.T) {
+	// Checks a single read can override the cache's promotion options, either way.
+
+	cache := NewCacheWithOptions[int, int](10, WithNoPromoteOnGet(), WithStrictConsistency())
+	defer cache.Close()
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, cache.Set(i, i))
+	}
+
+	v, found := cache.GetWithPromotion(0, PromoteAlways)
+	assert.True(t, found)
+	assert.Equal(t, 0, v)
+	assert.Equal(t, []int{0, 2, 1}, cache.OrderedKeys())
+
+	cache.GetWithPromotion(1, PromoteDefault)
+	assert.Equal(t, []int{0, 2, 1}, cache.OrderedKeys())
+
+	other := NewCacheWithOptions[int, int](10, WithStrictConsistency())
+	defer other.Close()
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, other.Set(i, i))
+	}
+	other.GetWithPromotion(0, PromoteNever)
+	assert.Equal(t, []int{2, 1, 0}, other.OrderedKeys())
+
+	_, found = other.GetWithPromotion(3, PromoteAlways)
+	assert.False(t, found)
+}
+
+func TestCache_InsertAtTail(t *testing.T) {
+	// Checks entries stored at the tail are evicted before the existing entries.
+
+	cache := NewCache[int, int](3)
+	defer cache.Close()
+
+	require.NoError(t, cache.Set(0, 0))
+	require.NoError(t, cache.Set(1, 1))
+	require.NoError(t, cache.SetWithOptions(2, 2, WithInsertAtTail()))
+	assert.Equal(t, []int{1, 0, 2}, cache.OrderedKeys())
+
+	require.NoError(t, cache.SetWithOptions(3, 3, WithInsertAtTail()))
+	assert.Equal(t, []int{1, 0, 3}, cache.OrderedKeys())
+
+	// Bulk loads at the tail keep their relative order, after the existing entries.
+	bulk := NewCache[int, int](10)
+	defer bulk.Close()
+
+	require.NoError(t, bulk.Set(0, 0))
+	require.NoError(t, bulk.SetAll(map[int]int{1: 1}, WithInsertAtTail()))
+	require.NoError(t, bulk.SetAll(map[int]int{2: 2}, WithInsertAtTail()))
+	assert.Equal(t, []int{0, 1, 2}, bulk.OrderedKeys())
+}
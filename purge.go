@@ -0,0 +1,251 @@
+package lrucache
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// purgeBudgetCheck is how many entries are examined between checks of the time budget, as reading the clock for
+// every entry would dominate the cost of the pass.
+const purgeBudgetCheck = 64
+
+// WithPurgeBudget splits each pass removing expired entries, whether periodic or by DeleteExpired, into steps of at
+// most entries entries, or lasting at most duration, whichever is reached first; zero means no limit on either.
+// The lock is released between steps, so large caches don't stall for the whole pass. Each step resumes where the
+// last left off, walking the list from the least recently used entry; if that entry has since been removed or
+// read, the step starts again from the tail, but no pass examines more entries than the cache held when it began.
+func WithPurgeBudget(entries int, duration time.Duration) Option {
+	return func(o *options) {
+		o.purgeBudgetEntries = entries
+		o.purgeBudgetDuration = duration
+	}
+}
+
+// WithPurgeMaxEntries limits each pass removing expired entries, whether periodic or by DeleteExpired, to examining
+// at most entries entries, bounding the cost of a pass over a very large cache. Each pass resumes where the last
+// left off, walking the list from the least recently used entry towards the most, and starting again from the tail
+// once it reaches the front, so every entry is examined over successive passes. With WithAdaptivePurge or
+// WithPurgeAtExpiry, the next pass is brought forward while there are entries left to examine. The cost of passes
+// is reported by Stats.Purge.
+func WithPurgeMaxEntries(entries int) Option {
+	return func(o *options) {
+		o.purgeMaxEntries = entries
+	}
+}
+
+// PurgeStats summarises the passes removing expired entries, whether periodic or by DeleteExpired, to help tune the
+// purge interval against the cost of each pass.
+type PurgeStats struct {
+	Passes   uint64        // Number of passes.
+	Scanned  uint64        // Entries examined, across all passes.
+	Removed  uint64        // Expired or invalidated entries removed, across all passes.
+	Duration time.Duration // Time spent in passes, including waiting for the lock.
+
+	LastScanned  int           // Entries examined by the most recent pass.
+	LastRemoved  int           // Entries removed by the most recent pass.
+	LastDuration time.Duration // Time taken by the most recent pass.
+}
+
+// purgeCounters accumulates PurgeStats. Passes are infrequent, so a mutex suffices.
+type purgeCounters struct {
+	lock  sync.Mutex
+	stats PurgeStats
+}
+
+// record adds a pass to the stats.
+func (c *purgeCounters) record(result purgeResult, duration time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.stats.Passes++
+	c.stats.Scanned += uint64(result.scanned)
+	c.stats.Removed += uint64(result.removed)
+	c.stats.Duration += duration
+	c.stats.LastScanned = result.scanned
+	c.stats.LastRemoved = result.removed
+	c.stats.LastDuration = duration
+}
+
+// snapshot returns the stats recorded so far.
+func (c *purgeCounters) snapshot() PurgeStats {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.stats
+}
+
+// purgeIncremental removes expired entries in steps, as configured by WithPurgeBudget, releasing the lock between
+// them. With WithPurgeMaxEntries, the pass starts where the last one stopped, and stops once it has examined that
+// many entries.
+func (lru *Cache[K, V]) purgeIncremental() purgeResult {
+	start := time.Now()
+
+	var result purgeResult
+	var cursor *node[K, V] // The next node to examine; nil to start from the tail.
+	var sequence uint64    // The cursor's sequence when it was chosen, to detect it being moved.
+	examined, total, steps := 0, -1, 0
+	limit := lru.opts.purgeMaxEntries
+
+	for done := false; !done; steps++ {
+		if steps > 0 {
+			runtime.Gosched()
+		}
+
+		lru.writeLock(OperationPurge)
+		if lru.stopped {
+			lru.lock.Unlock()
+			break
+		}
+		lru.runOnEventLoop(func() {
+			if total < 0 {
+				total = lru.length
+				if limit > 0 {
+					total = min(total, limit)
+					cursor, sequence = lru.purgeCursor, lru.purgeSequence
+				}
+			}
+			if cursor == nil || cursor.deleted || cursor.sequence.Load() != sequence {
+				cursor = lru.tail.previous
+			}
+
+			stepStart := time.Now()
+			now := stepStart
+			for i := 0; ; i++ {
+				if cursor == lru.head || examined >= total {
+					done = true
+					if limit > 0 {
+						// The next pass resumes from here, unless the front of the list was reached.
+						lru.purgeCursor, lru.purgeSequence = nil, 0
+						if cursor != lru.head {
+							lru.purgeCursor, lru.purgeSequence = cursor, cursor.sequence.Load()
+							result.more = true
+						}
+					}
+					return
+				}
+				if i > 0 && i%purgeBudgetCheck == 0 {
+					now = time.Now()
+				}
+				if (lru.opts.purgeBudgetEntries > 0 && i == lru.opts.purgeBudgetEntries) ||
+					(lru.opts.purgeBudgetDuration > 0 && now.Sub(stepStart) >= lru.opts.purgeBudgetDuration) {
+					sequence = cursor.sequence.Load()
+					return
+				}
+
+				n := cursor
+				cursor = n.previous
+				examined++
+
+				switch {
+				case n.isExpired(now):
+					lru.removeNode(n, EvictionReasonExpired)
+					result.removed++
+				case lru.isStale(n):
+					lru.removeNode(n, EvictionReasonInvalidated)
+					result.removed++
+				case !n.expires.IsZero() && (result.soonest.IsZero() || n.expires.Before(result.soonest)):
+					result.soonest = n.expires
+				}
+			}
+		})
+		removed := lru.takeRemovals()
+		lru.lock.Unlock()
+
+		lru.notifyRemovals(removed)
+	}
+
+	result.scanned = examined
+	duration := time.Since(start)
+	lru.purgeStats.record(result, duration)
+	lru.log(slog.LevelDebug, "lrucache: purged expired entries", "scanned", examined, "removed", result.removed, "steps", steps, "duration", duration)
+
+	return result
+}
+
+// WithPurgeAtExpiry replaces the fixed purge interval with purges scheduled for when entries actually expire: the
+// purge goroutine sleeps until the soonest expiry in the cache, and is woken early if an entry is stored with an
+// earlier one. Caches that go hours without anything expiring then don't wake at all. It replaces WithPurgeInterval
+// and WithAdaptivePurge, and adds an atomic operation to every write of an entry with an expiry.
+func WithPurgeAtExpiry() Option {
+	return func(o *options) {
+		o.purgeAtExpiry = true
+	}
+}
+
+// NextExpiry returns the soonest expiry of the entries in the cache, which is in the past if an expired entry is
+// yet to be removed. found is false if no entries have an expiry. It makes a full pass over the cache.
+func (lru *Cache[K, V]) NextExpiry() (expires time.Time, found bool) {
+	lru.readLock(OperationOther)
+	defer lru.lock.RUnlock()
+
+	for _, n := range lru.cache {
+		if !n.expires.IsZero() && (!found || n.expires.Before(expires)) {
+			expires, found = n.expires, true
+		}
+	}
+	return expires, found
+}
+
+// noteExpiry brings the purge scheduled by WithPurgeAtExpiry forward to expires, if it's sooner, or if no purge is
+// scheduled.
+func (lru *Cache[K, V]) noteExpiry(expires time.Time) {
+	if !lru.opts.purgeAtExpiry || expires.IsZero() {
+		return
+	}
+	e := expires.UnixNano()
+	for {
+		next := lru.nextPurge.Load()
+		if next != 0 && next <= e {
+			return
+		}
+		if lru.nextPurge.CompareAndSwap(next, e) {
+			select {
+			case lru.reschedule <- struct{}{}:
+			default:
+			}
+			return
+		}
+	}
+}
+
+// purgeAtExpiry removes expired entries whenever the soonest expiry passes, until the cache is closed or ctx is done.
+func (lru *Cache[K, V]) purgeAtExpiry(ctx context.Context) {
+	if expires, found := lru.NextExpiry(); found {
+		lru.noteExpiry(expires)
+	}
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		var wake <-chan time.Time
+		timer.Stop()
+		if next := lru.nextPurge.Load(); next != 0 {
+			// Woken just after the entry expires, as entries expire once their expiry is in the past.
+			timer.Reset(time.Until(time.Unix(0, next)) + time.Millisecond)
+			wake = timer.C
+		}
+
+		select {
+		case <-lru.done:
+			return
+		case <-ctx.Done():
+			return
+		case <-lru.reschedule:
+		case <-wake:
+			// Cleared first, so any entry stored during the purge schedules the next.
+			lru.nextPurge.Store(0)
+			var result purgeResult
+			if lru.unattended(func() { result = lru.purge() }) != nil {
+				// The purge was cut short by a callback, so the soonest expiry is unknown.
+				result.more = true
+			}
+			lru.noteExpiry(result.soonest)
+			if result.more {
+				lru.noteExpiry(time.Now())
+			}
+		}
+	}
+}
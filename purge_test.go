@@ -0,0 +1,138 @@
+package lrucache
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_PurgeBudget(t *testing.T) {
+	// Checks a pass split into steps removes every expired entry, while reads and writes run between the steps.
+
+	cache := NewCacheWithOptions[int, int](1000, WithPurgeBudget(10, time.Millisecond))
+	defer cache.Close()
+
+	for i := 0; i < 500; i++ {
+		expires := time.Now().Add(time.Hour)
+		if i%2 == 0 {
+			expires = time.Now().Add(5 * time.Millisecond)
+		}
+		require.NoError(t, cache.SetWithExpiry(i, i, expires))
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 500; i++ {
+			cache.Get(i)
+			if i%10 == 1 {
+				cache.Delete(i)
+			}
+		}
+	}()
+
+	removed := cache.DeleteExpired()
+	wg.Wait()
+
+	// Entries read during the pass may be skipped until the next.
+	removed += cache.DeleteExpired()
+	assert.Equal(t, 250, removed)
+	assert.Equal(t, uint64(200), cache.EntryCount())
+	for i := 0; i < 500; i += 2 {
+		assert.False(t, cache.Contains(i))
+	}
+}
+
+func TestCache_PurgeMaxEntries(t *testing.T) {
+	// Checks each pass examines at most the maximum entries, resuming where the last stopped, and is reported by Stats.
+
+	cache := NewCacheWithOptions[int, int](100, WithPurgeInterval(0), WithPurgeMaxEntries(4))
+	defer cache.Close()
+
+	// Added oldest first, so the expired entries are at the tail and the front of the list.
+	for i := 0; i < 10; i++ {
+		expires := time.Now().Add(time.Hour)
+		if i < 2 || i >= 8 {
+			expires = time.Now().Add(5 * time.Millisecond)
+		}
+		require.NoError(t, cache.SetWithExpiry(i, i, expires))
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	assert.Equal(t, 2, cache.DeleteExpired())
+	assert.Equal(t, uint64(8), cache.EntryCount())
+	assert.Equal(t, 0, cache.DeleteExpired())
+	assert.Equal(t, 2, cache.DeleteExpired())
+	assert.Equal(t, uint64(6), cache.EntryCount())
+
+	stats := cache.Stats().Purge
+	assert.Equal(t, uint64(3), stats.Passes)
+	assert.Equal(t, uint64(10), stats.Scanned)
+	assert.Equal(t, uint64(4), stats.Removed)
+	assert.Equal(t, 2, stats.LastScanned)
+	assert.Equal(t, 2, stats.LastRemoved)
+	assert.Positive(t, stats.Duration)
+}
+
+func TestCache_PurgeStats(t *testing.T) {
+	// Checks full passes are reported by Stats.
+
+	cache := NewCacheWithOptions[int, int](100, WithPurgeInterval(0))
+	defer cache.Close()
+
+	require.NoError(t, cache.SetWithExpiry(0, 0, time.Now().Add(5*time.Millisecond)))
+	require.NoError(t, cache.Set(1, 1))
+	time.Sleep(10 * time.Millisecond)
+
+	assert.Equal(t, 1, cache.DeleteExpired())
+	stats := cache.Stats().Purge
+	assert.Equal(t, uint64(1), stats.Passes)
+	assert.Equal(t, 2, stats.LastScanned)
+	assert.Equal(t, 1, stats.LastRemoved)
+}
+
+func TestCache_NextExpiry(t *testing.T) {
+	// Checks the soonest expiry is found, ignoring entries without one.
+
+	cache := NewCache[string, int](10)
+	defer cache.Close()
+
+	_, found := cache.NextExpiry()
+	assert.False(t, found)
+
+	soonest := time.Now().Add(time.Minute)
+	require.NoError(t, cache.Set("a", 1))
+	require.NoError(t, cache.SetWithExpiry("b", 2, time.Now().Add(time.Hour)))
+	require.NoError(t, cache.SetWithExpiry("c", 3, soonest))
+
+	expires, found := cache.NextExpiry()
+	assert.True(t, found)
+	assert.True(t, soonest.Equal(expires))
+}
+
+func TestCache_PurgeAtExpiry(t *testing.T) {
+	// Checks entries are purged when they expire, including ones stored sooner than the scheduled purge.
+
+	var expired atomic.Int32
+	cache := NewCacheWithOptions[string, int](10, WithPurgeAtExpiry(), WithOnEvict(func(_ string, _ int, reason EvictionReason) {
+		if reason == EvictionReasonExpired {
+			expired.Add(1)
+		}
+	}))
+	defer cache.Close()
+
+	require.NoError(t, cache.SetWithExpiry("late", 1, time.Now().Add(time.Hour)))
+	require.NoError(t, cache.SetWithExpiry("soon", 2, time.Now().Add(20*time.Millisecond)))
+
+	assert.Eventually(t, func() bool { return expired.Load() == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, uint64(1), cache.EntryCount())
+
+	require.NoError(t, cache.SetWithExpiry("again", 3, time.Now().Add(10*time.Millisecond)))
+	assert.Eventually(t, func() bool { return expired.Load() == 2 }, time.Second, time.Millisecond)
+}
@@ -0,0 +1,35 @@
+package lrucache
+
+// ReadOnlyCache is a view of a cache that can only read from it, for passing to code that must never modify or
+// invalidate state shared with the rest of the application. It's enforced by type: the underlying cache isn't
+// reachable through it.
+type ReadOnlyCache[K comparable, V any] struct {
+	cache *Cache[K, V]
+}
+
+// ReadOnly returns a read-only view of the cache.
+func (lru *Cache[K, V]) ReadOnly() *ReadOnlyCache[K, V] {
+	return &ReadOnlyCache[K, V]{cache: lru}
+}
+
+// Get returns the value for k, if it's in the cache and unexpired, moving it to the front of the LRU list as for
+// Cache.Get. Reads still affect the order entries are evicted in, and the stats.
+func (r *ReadOnlyCache[K, V]) Get(k K) (V, bool) {
+	return r.cache.Get(k)
+}
+
+// Peek returns the value for k, if it's in the cache and unexpired, without affecting its LRU position.
+func (r *ReadOnlyCache[K, V]) Peek(k K) (V, bool) {
+	e, found := r.cache.Entry(k)
+	return e.Value, found
+}
+
+// Contains reports whether an unexpired entry exists for k, without affecting its LRU position.
+func (r *ReadOnlyCache[K, V]) Contains(k K) bool {
+	return r.cache.Contains(k)
+}
+
+// Stats returns the cache's stats; see Cache.Stats.
+func (r *ReadOnlyCache[K, V]) Stats() Stats {
+	return r.cache.Stats()
+}
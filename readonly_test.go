@@ -0,0 +1,40 @@
+package lrucache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_ReadOnly(t *testing.T) {
+	// Checks the view reads the cache's entries, with only Get affecting the LRU order.
+
+	cache := NewCache[string, int](10)
+	defer cache.Close()
+
+	require.NoError(t, cache.Set("a", 1))
+	require.NoError(t, cache.Set("b", 2))
+
+	view := cache.ReadOnly()
+
+	v, found := view.Peek("a")
+	assert.True(t, found)
+	assert.Equal(t, 1, v)
+	assert.Equal(t, []string{"b", "a"}, cache.OrderedKeys())
+
+	v, found = view.Get("a")
+	assert.True(t, found)
+	assert.Equal(t, 1, v)
+	assert.Equal(t, []string{"a", "b"}, cache.OrderedKeys())
+
+	assert.True(t, view.Contains("b"))
+	assert.False(t, view.Contains("c"))
+	_, found = view.Peek("c")
+	assert.False(t, found)
+
+	// Changes to the cache are seen through the view.
+	cache.Delete("a")
+	assert.False(t, view.Contains("a"))
+	assert.Equal(t, uint64(1), view.Stats().EntryCount)
+}
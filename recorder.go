@@ -0,0 +1,68 @@
+package lrucache
+
+import "time"
+
+// StatsRecorder receives the cache's activity as it happens, so it can be exported to a metrics system, such as
+// Prometheus or OpenTelemetry, or an in-house library. It's set by WithStatsRecorder. Its methods may be called
+// concurrently, and should return quickly, as they're called inline with the cache's operations.
+type StatsRecorder interface {
+	// RecordHit is called when a read finds an unexpired entry.
+	RecordHit()
+
+	// RecordMiss is called when a read finds no unexpired entry.
+	RecordMiss()
+
+	// RecordEviction is called when an entry is removed from the cache, for any reason.
+	RecordEviction(reason EvictionReason)
+
+	// RecordLoad is called after each call to a Loader, with the time it took and the error it returned, if any.
+	RecordLoad(duration time.Duration, err error)
+}
+
+// WithStatsRecorder sets a StatsRecorder to receive the cache's hits, misses, removals and loads. Hits and misses
+// are recorded by Get and its variants, including GetOrLoad; Contains, Entry and the like aren't counted. A
+// negatively cached miss counts as a hit, as the loader isn't called. Removals are recorded after the cache's lock
+// has been released, as for WithOnEvict.
+func WithStatsRecorder(r StatsRecorder) Option {
+	return func(o *options) {
+		o.statsRecorder = r
+	}
+}
+
+// recordLookup passes a hit or miss to the StatsRecorder, if one is configured, counts it for WithThrashAlert, and
+// looks up k in the shadow caches.
+func (lru *Cache[K, V]) recordLookup(k K, found bool) {
+	lru.shadowLookup(k)
+
+	if lru.thrash != nil {
+		if found {
+			lru.thrash.hits.Add(1)
+		} else {
+			lru.thrash.misses.Add(1)
+		}
+	}
+
+	r := lru.opts.statsRecorder
+	if r == nil {
+		return
+	}
+	_ = lru.safely("StatsRecorder", func() {
+		if found {
+			r.RecordHit()
+		} else {
+			r.RecordMiss()
+		}
+	})
+}
+
+// recordLoad passes the duration and result of a loader call to the StatsRecorder, if one is configured.
+func (lru *Cache[K, V]) recordLoad(start time.Time, err error) {
+	r := lru.opts.statsRecorder
+	if r == nil {
+		return
+	}
+	d := time.Since(start)
+	_ = lru.safely("StatsRecorder", func() {
+		r.RecordLoad(d, err)
+	})
+}
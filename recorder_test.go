@@ -0,0 +1,80 @@
+package lrucache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingRecorder is a StatsRecorder that counts what it's given.
+type countingRecorder struct {
+	lock      sync.Mutex
+	hits      int
+	misses    int
+	evictions map[EvictionReason]int
+	loads     []error
+}
+
+func (r *countingRecorder) RecordHit() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.hits++
+}
+
+func (r *countingRecorder) RecordMiss() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.misses++
+}
+
+func (r *countingRecorder) RecordEviction(reason EvictionReason) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.evictions[reason]++
+}
+
+func (r *countingRecorder) RecordLoad(_ time.Duration, err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.loads = append(r.loads, err)
+}
+
+func TestStatsRecorder(t *testing.T) {
+	// Checks hits, misses, removals and loads are passed to the recorder.
+
+	recorder := &countingRecorder{evictions: make(map[EvictionReason]int)}
+	cache := NewCacheWithOptions[string, int](2, WithStatsRecorder(recorder))
+	defer cache.Close()
+
+	require.NoError(t, cache.Set("a", 1))
+	require.NoError(t, cache.Set("b", 2))
+	require.NoError(t, cache.Set("c", 3)) // Evicts a.
+	require.NoError(t, cache.Set("b", 4)) // Replaces b.
+	cache.Delete("c")
+
+	cache.Get("a")
+	cache.Get("b")
+	cache.GetMulti([]string{"b", "c"})
+
+	_, err := cache.GetOrLoad(context.Background(), "d", func(ctx context.Context, k string) (int, time.Time, error) {
+		return 0, time.Time{}, errors.New("failed")
+	})
+	assert.Error(t, err)
+
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+	assert.Equal(t, 2, recorder.hits)
+	assert.Equal(t, 3, recorder.misses)
+	assert.Equal(t, map[EvictionReason]int{
+		EvictionReasonCapacity: 1,
+		EvictionReasonReplaced: 1,
+		EvictionReasonDeleted:  1,
+	}, recorder.evictions)
+	require.Len(t, recorder.loads, 1)
+	assert.EqualError(t, recorder.loads[0], "failed")
+}
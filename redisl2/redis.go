@@ -0,0 +1,167 @@
+// Package redisl2 provides a Redis-backed lrucache.SecondLevel, for use with lrucache.TieredCache.
+//
+// To avoid a dependency on any particular Redis client, the adapter talks to Redis through the small Client
+// interface. With github.com/redis/go-redis, for example:
+//
+//	type goRedis struct{ c *redis.Client }
+//
+//	func (g goRedis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+//		b, err := g.c.Get(ctx, key).Bytes()
+//		if errors.Is(err, redis.Nil) {
+//			return nil, false, nil
+//		}
+//		return b, err == nil, err
+//	}
+//
+//	func (g goRedis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+//		return g.c.Set(ctx, key, value, ttl).Err()
+//	}
+//
+//	func (g goRedis) Del(ctx context.Context, key string) error {
+//		return g.c.Del(ctx, key).Err()
+//	}
+package redisl2
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Client is the subset of Redis commands used by the adapter.
+type Client interface {
+	// Get returns the value stored at key. found is false if the key doesn't exist.
+	Get(ctx context.Context, key string) (value []byte, found bool, err error)
+
+	// Set stores value at key. A ttl of zero means the key doesn't expire.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Del removes key.
+	Del(ctx context.Context, key string) error
+}
+
+// Codec converts values to and from bytes for storage in Redis.
+type Codec[V any] interface {
+	Marshal(v V) ([]byte, error)
+	Unmarshal(b []byte) (V, error)
+}
+
+// GobCodec is a Codec using encoding/gob. It's the default.
+type GobCodec[V any] struct{}
+
+func (GobCodec[V]) Marshal(v V) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	err := gob.NewEncoder(buf).Encode(&v)
+	return buf.Bytes(), err
+}
+
+func (GobCodec[V]) Unmarshal(b []byte) (V, error) {
+	var v V
+	err := gob.NewDecoder(bytes.NewReader(b)).Decode(&v)
+	return v, err
+}
+
+// ErrCorruptValue is returned when a value read from Redis is not in the format written by the adapter.
+var ErrCorruptValue = errors.New("the value stored in redis is corrupt")
+
+// SecondLevel stores entries in Redis. It implements lrucache.SecondLevel.
+// Each value is stored with its absolute expiry time, so it can be restored precisely into the L1 cache,
+// and Redis is given the matching TTL so expired entries are removed there too.
+type SecondLevel[K comparable, V any] struct {
+	client Client
+	codec  Codec[V]
+	key    func(K) string
+}
+
+// Option configures a SecondLevel.
+type Option[K comparable, V any] func(*SecondLevel[K, V])
+
+// WithCodec sets the Codec used for values. The default is GobCodec.
+func WithCodec[K comparable, V any](codec Codec[V]) Option[K, V] {
+	return func(s *SecondLevel[K, V]) {
+		s.codec = codec
+	}
+}
+
+// WithKeyFunc sets the function used to convert cache keys to Redis keys.
+// The default formats the key with fmt.Sprint, after the prefix passed to New.
+func WithKeyFunc[K comparable, V any](fn func(K) string) Option[K, V] {
+	return func(s *SecondLevel[K, V]) {
+		s.key = fn
+	}
+}
+
+// New returns a SecondLevel using client, with all keys prefixed with prefix.
+func New[K comparable, V any](client Client, prefix string, opts ...Option[K, V]) *SecondLevel[K, V] {
+	s := &SecondLevel[K, V]{
+		client: client,
+		codec:  GobCodec[V]{},
+		key: func(k K) string {
+			return prefix + fmt.Sprint(k)
+		},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Get returns the value and expiry for k.
+func (s *SecondLevel[K, V]) Get(ctx context.Context, k K) (V, time.Time, bool, error) {
+	var empty V
+
+	b, found, err := s.client.Get(ctx, s.key(k))
+	if err != nil || !found {
+		return empty, time.Time{}, false, err
+	}
+
+	// The first 8 bytes hold the expiry, as Unix nanoseconds, with zero meaning no expiry.
+	if len(b) < 8 {
+		return empty, time.Time{}, false, ErrCorruptValue
+	}
+
+	var expires time.Time
+	if nanos := int64(binary.BigEndian.Uint64(b[:8])); nanos != 0 {
+		expires = time.Unix(0, nanos)
+	}
+
+	v, err := s.codec.Unmarshal(b[8:])
+	if err != nil {
+		return empty, time.Time{}, false, fmt.Errorf("%w: %w", ErrCorruptValue, err)
+	}
+
+	return v, expires, true, nil
+}
+
+// Set stores the value for k, expiring at expires.
+func (s *SecondLevel[K, V]) Set(ctx context.Context, k K, v V, expires time.Time) error {
+	var ttl time.Duration
+	var nanos int64
+	if !expires.IsZero() {
+		ttl = time.Until(expires)
+		if ttl <= 0 {
+			return nil
+		}
+		nanos = expires.UnixNano()
+	}
+
+	payload, err := s.codec.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("unable to encode value: %w", err)
+	}
+
+	b := make([]byte, 8, 8+len(payload))
+	binary.BigEndian.PutUint64(b, uint64(nanos))
+	b = append(b, payload...)
+
+	return s.client.Set(ctx, s.key(k), b, ttl)
+}
+
+// Delete removes k.
+func (s *SecondLevel[K, V]) Delete(ctx context.Context, k K) error {
+	return s.client.Del(ctx, s.key(k))
+}
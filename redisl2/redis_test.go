@@ -0,0 +1,100 @@
+package redisl2
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nsmithuk/lrucache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient is an in-memory Client, for testing.
+type fakeClient struct {
+	lock sync.Mutex
+	data map[string][]byte
+	ttls map[string]time.Duration
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{data: make(map[string][]byte), ttls: make(map[string]time.Duration)}
+}
+
+func (f *fakeClient) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	b, found := f.data[key]
+	return b, found, nil
+}
+
+func (f *fakeClient) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.data[key] = value
+	f.ttls[key] = ttl
+	return nil
+}
+
+func (f *fakeClient) Del(ctx context.Context, key string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	delete(f.data, key)
+	return nil
+}
+
+// Compile-time check that SecondLevel satisfies lrucache.SecondLevel.
+var _ lrucache.SecondLevel[int, string] = (*SecondLevel[int, string])(nil)
+
+func TestSecondLevel_RoundTrip(t *testing.T) {
+	// Checks values and expiries survive a round trip, with keys prefixed and a matching TTL set.
+
+	client := newFakeClient()
+	l2 := New[int, string](client, "users:")
+	ctx := context.Background()
+
+	expires := time.Now().Add(time.Minute)
+	require.NoError(t, l2.Set(ctx, 1, "alice", expires))
+	require.NoError(t, l2.Set(ctx, 2, "bob", time.Time{}))
+
+	assert.Contains(t, client.data, "users:1")
+	assert.InDelta(t, time.Minute, client.ttls["users:1"], float64(time.Second))
+	assert.Equal(t, time.Duration(0), client.ttls["users:2"])
+
+	v, e, found, err := l2.Get(ctx, 1)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "alice", v)
+	assert.True(t, expires.Equal(e))
+
+	_, e, found, _ = l2.Get(ctx, 2)
+	assert.True(t, found)
+	assert.True(t, e.IsZero())
+
+	require.NoError(t, l2.Delete(ctx, 1))
+	_, _, found, _ = l2.Get(ctx, 1)
+	assert.False(t, found)
+
+	client.data["users:3"] = []byte{1}
+	_, _, _, err = l2.Get(ctx, 3)
+	assert.ErrorIs(t, err, ErrCorruptValue)
+}
+
+func TestSecondLevel_Tiered(t *testing.T) {
+	// Uses the adapter as the L2 of a TieredCache with demotion.
+
+	client := newFakeClient()
+	cache := lrucache.NewTieredCache[int, string](1, New[int, string](client, ""), lrucache.WithDemotion())
+	defer cache.Close()
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, 1, "value-1"))
+	require.NoError(t, cache.Set(ctx, 2, "value-2"))
+	assert.Len(t, client.data, 1)
+
+	v, found, err := cache.Get(ctx, 1)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "value-1", v)
+}
@@ -0,0 +1,80 @@
+package lrucache
+
+import "context"
+
+// refRemoved is added to a node's refs once it has been removed from the cache, and its OnEvict callbacks are due.
+// The callbacks run when both it's set and no handles are outstanding.
+const refRemoved = int64(1) << 62
+
+// Handle is a reference to an entry's value, returned by Acquire. While any handle to it is outstanding, an entry
+// that's removed from the cache has its OnEvict and OnEvictEntry callbacks deferred until the last is released, so
+// they can free the value safely, e.g. if it wraps an mmap'd region or C allocation.
+type Handle[K comparable, V any] struct {
+	cache    *Cache[K, V]
+	n        *node[K, V]
+	released bool
+}
+
+// WithRefCounting enables Acquire, deferring the OnEvict and OnEvictEntry callbacks for an entry that's removed
+// while it's held until it's released.
+func WithRefCounting() Option {
+	return func(o *options) {
+		o.refCounting = true
+	}
+}
+
+// Acquire returns a handle to the value for k, which must be released once the value is no longer used. It's a
+// read, as Get. Panics unless the cache was created WithRefCounting.
+func (lru *Cache[K, V]) Acquire(k K) (*Handle[K, V], bool) {
+	if !lru.opts.refCounting {
+		panic("lrucache: Acquire requires WithRefCounting")
+	}
+
+	n, found, _ := lru.get(context.Background(), k)
+	if !found || n.negative {
+		return nil, false
+	}
+
+	// Once removed, the node's callbacks may already have run, so it can't be acquired.
+	for {
+		refs := n.refs.Load()
+		if refs&refRemoved != 0 {
+			return nil, false
+		}
+		if n.refs.CompareAndSwap(refs, refs+1) {
+			return &Handle[K, V]{cache: lru, n: n}, true
+		}
+	}
+}
+
+// Key returns the entry's key.
+func (h *Handle[K, V]) Key() K {
+	return h.n.key
+}
+
+// Value returns the entry's value, which remains valid until the handle is released.
+func (h *Handle[K, V]) Value() V {
+	return h.n.value
+}
+
+// Release gives up the handle, running the entry's deferred callbacks if it was the last to be held since the entry
+// was removed. Releasing a handle more than once has no effect. A Handle must not be released concurrently.
+func (h *Handle[K, V]) Release() {
+	if h.released {
+		return
+	}
+	h.released = true
+	if h.n.refs.Add(-1) == refRemoved {
+		h.cache.evictCallbacks(h.n, h.n.releaseReason)
+	}
+}
+
+// deferEvictCallbacks marks n as removed for the given reason, returning true if its callbacks must wait for its
+// handles to be released.
+func (lru *Cache[K, V]) deferEvictCallbacks(n *node[K, V], reason EvictionReason) bool {
+	if !lru.opts.refCounting {
+		return false
+	}
+	n.releaseReason = reason
+	return n.refs.Add(refRemoved) != refRemoved
+}
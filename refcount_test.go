@@ -0,0 +1,69 @@
+package lrucache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_RefCounting(t *testing.T) {
+	// Checks the OnEvict callback for an entry removed while it's held waits until its last handle is released.
+
+	var evicted []string
+	cache := NewCacheWithOptions[string, int](2, WithRefCounting(), WithStrictConsistency(),
+		WithOnEvict(func(k string, v int, reason EvictionReason) {
+			evicted = append(evicted, k+":"+reason.String())
+		}))
+	defer cache.Close()
+
+	require.NoError(t, cache.Set("a", 1))
+	h1, found := cache.Acquire("a")
+	require.True(t, found)
+	h2, found := cache.Acquire("a")
+	require.True(t, found)
+	assert.Equal(t, "a", h1.Key())
+	assert.Equal(t, 1, h1.Value())
+
+	// Replacing the value removes the old entry, but its callback is deferred.
+	require.NoError(t, cache.Set("a", 2))
+	assert.Empty(t, evicted)
+	assert.Equal(t, 1, h1.Value())
+
+	h1.Release()
+	h1.Release()
+	assert.Empty(t, evicted)
+	h2.Release()
+	assert.Equal(t, []string{"a:replaced"}, evicted)
+
+	// Entries that aren't held are handled immediately.
+	cache.Delete("a")
+	assert.Equal(t, []string{"a:replaced", "a:deleted"}, evicted)
+
+	_, found = cache.Acquire("a")
+	assert.False(t, found)
+}
+
+func TestCache_RefCountingEviction(t *testing.T) {
+	var evicted []int
+	cache := NewCacheWithOptions[int, int](1, WithRefCounting(), WithStrictConsistency(),
+		WithOnEvict(func(k, v int, reason EvictionReason) { evicted = append(evicted, k) }))
+	defer cache.Close()
+
+	require.NoError(t, cache.Set(1, 1))
+	h, found := cache.Acquire(1)
+	require.True(t, found)
+
+	require.NoError(t, cache.Set(2, 2))
+	assert.False(t, cache.Contains(1))
+	assert.Empty(t, evicted)
+
+	h.Release()
+	assert.Equal(t, []int{1}, evicted)
+}
+
+func TestCache_AcquireRequiresRefCounting(t *testing.T) {
+	cache := NewCache[int, int](1)
+	defer cache.Close()
+	assert.Panics(t, func() { cache.Acquire(1) })
+}
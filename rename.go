@@ -0,0 +1,67 @@
+package lrucache
+
+import "time"
+
+// Rename moves the entry for oldK to newK in a single locked operation, keeping its value, size, expiry, pin,
+// metadata, tags, tenant and position in the LRU order, so no reader sees both keys missing or both present. Any
+// entry for newK is replaced, and reported to eviction callbacks with EvictionReasonReplaced; the entry for oldK is
+// reported with EvictionReasonRenamed. The result is false, and nothing changes, if there's no unexpired entry for
+// oldK.
+func (lru *Cache[K, V]) Rename(oldK, newK K) bool {
+	if oldK == newK {
+		return lru.Contains(oldK)
+	}
+
+	lru.supersedeLoad(oldK)
+	lru.supersedeLoad(newK)
+
+	lru.writeLock(OperationSet)
+	if lru.stopped {
+		lru.lock.Unlock()
+		return false
+	}
+
+	n, found := lru.cache[oldK]
+	if !found || n.negative || lru.isExpired(n, time.Now()) || lru.isStale(n) {
+		lru.lock.Unlock()
+		return false
+	}
+
+	r := &node[K, V]{
+		key:      newK,
+		value:    n.value,
+		size:     n.size,
+		created:  n.created,
+		expires:  n.expires,
+		pinned:   n.pinned,
+		metadata: n.metadata,
+		cost:     n.cost,
+	}
+	tags, tenant := lru.tagNames(n), lru.tenantName(n)
+
+	// The node after the old one marks the position to move the new one into.
+	var next *node[K, V]
+	lru.runOnEventLoop(func() {
+		next = n.next
+		r.credit = n.credit
+		r.sequence.Store(n.sequence.Load())
+	})
+
+	lru.deleteNode(n, EvictionReasonRenamed)
+	lru.insertLocked(r, tags, tenant)
+
+	lru.runOnEventLoop(func() {
+		// The following node may have been removed too, if it was the entry replaced for newK.
+		for next != lru.tail && next.deleted {
+			next = next.next
+		}
+		lru.addNodeBetween(r, next.previous, next)
+		lru.length++
+	})
+
+	removed := lru.takeRemovals()
+	lru.lock.Unlock()
+
+	lru.notifyRemovals(removed)
+	return true
+}
@@ -0,0 +1,87 @@
+package lrucache
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_Rename(t *testing.T) {
+	// Checks the entry moves to the new key, keeping its details and position in the LRU order.
+
+	var mu sync.Mutex
+	reasons := map[string]EvictionReason{}
+	cache := NewCacheWithOptions[string, int](10, WithOnEvict(func(k string, _ int, reason EvictionReason) {
+		mu.Lock()
+		defer mu.Unlock()
+		reasons[k] = reason
+	}))
+	defer cache.Close()
+
+	expires := time.Now().Add(time.Hour)
+	require.NoError(t, cache.Set("a", 1))
+	require.NoError(t, cache.SetWithOptions("b", 2, WithSize(3), WithExpiry(expires), WithMetadata("meta")))
+	require.NoError(t, cache.Set("c", 3))
+	require.Equal(t, []string{"c", "b", "a"}, cache.OrderedKeys())
+
+	assert.True(t, cache.Rename("b", "renamed"))
+	assert.Equal(t, []string{"c", "renamed", "a"}, cache.OrderedKeys())
+	assert.False(t, cache.Contains("b"))
+
+	info, found := cache.EntryInfo("renamed")
+	require.True(t, found)
+	assert.Equal(t, 2, info.Value)
+	assert.Equal(t, uint64(3), info.Size)
+	assert.Equal(t, expires, info.Expires)
+	assert.Equal(t, "meta", info.Metadata)
+	assert.Equal(t, uint64(5), cache.Size())
+	assert.Equal(t, uint64(3), cache.EntryCount())
+
+	mu.Lock()
+	assert.Equal(t, EvictionReasonRenamed, reasons["b"])
+	mu.Unlock()
+
+	assert.False(t, cache.Rename("missing", "other"))
+	assert.False(t, cache.Contains("other"))
+	assert.True(t, cache.Rename("a", "a"))
+}
+
+func TestCache_RenameReplacesExisting(t *testing.T) {
+	// Checks an entry for the new key is replaced, including when it's next to the renamed one in the list.
+
+	cache := NewCache[string, int](10)
+	defer cache.Close()
+
+	require.NoError(t, cache.Set("a", 1))
+	require.NoError(t, cache.Set("b", 2))
+	require.NoError(t, cache.Set("c", 3))
+
+	assert.True(t, cache.Rename("b", "a"))
+	assert.Equal(t, []string{"c", "a"}, cache.OrderedKeys())
+	v, _ := cache.Get("a")
+	assert.Equal(t, 2, v)
+	assert.Equal(t, uint64(2), cache.Size())
+
+	assert.True(t, cache.Rename("c", "a"))
+	assert.Equal(t, []string{"a"}, cache.OrderedKeys())
+	v, _ = cache.Get("a")
+	assert.Equal(t, 3, v)
+}
+
+func TestCache_RenameExpired(t *testing.T) {
+	// Checks expired entries can't be renamed.
+
+	cache := NewCache[string, int](10)
+	defer cache.Close()
+
+	require.NoError(t, cache.SetWithExpiry("a", 1, time.Now().Add(10*time.Millisecond)))
+	time.Sleep(20 * time.Millisecond)
+	assert.False(t, cache.Rename("a", "b"))
+	assert.False(t, cache.Contains("b"))
+
+	cache.Close()
+	assert.False(t, cache.Rename("a", "b"))
+}
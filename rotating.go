@@ -0,0 +1,183 @@
+package lrucache
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// rotatingEntry is a value held by a RotatingCache, with its expiry; zero means none.
+type rotatingEntry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+// RotatingCache is a cache of coarse recency, holding its entries in a fixed number of generations, each with room
+// for a fixed number of entries. New entries go into the newest generation; once it's full, or every interval,
+// the generations are rotated, so a new one is started and the oldest is dropped whole. Lookups check the
+// generations from the newest to the oldest, copying entries found in older generations into the newest, so
+// entries that keep being read survive rotation.
+//
+// Entries are held in plain maps, so there's none of the per-entry linked list overhead of a Cache, at the cost
+// of evicting a generation at a time rather than an entry at a time. Entries have no size; the capacity is in
+// entries.
+type RotatingCache[K comparable, V any] struct {
+	lock        sync.Mutex
+	generations []map[K]rotatingEntry[V] // From the newest to the oldest.
+	size        int                      // The maximum number of entries in a generation.
+
+	done  chan struct{}
+	close sync.Once
+}
+
+// NewRotatingCache creates a RotatingCache of the given number of generations, each holding up to size entries.
+// If interval is positive, the generations are also rotated on that interval, until the cache is closed, so an
+// entry that isn't read lasts between generations-1 and generations intervals.
+func NewRotatingCache[K comparable, V any](generations, size int, interval time.Duration) *RotatingCache[K, V] {
+	generations = max(generations, 1)
+	c := &RotatingCache[K, V]{
+		generations: make([]map[K]rotatingEntry[V], generations),
+		size:        max(size, 1),
+		done:        make(chan struct{}),
+	}
+	for i := range c.generations {
+		c.generations[i] = make(map[K]rotatingEntry[V])
+	}
+
+	if interval > 0 {
+		go c.rotateEvery(interval)
+	}
+
+	return c
+}
+
+// rotateEvery rotates the generations every interval, until the cache is closed.
+func (c *RotatingCache[K, V]) rotateEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			c.lock.Lock()
+			c.rotate()
+			c.lock.Unlock()
+		}
+	}
+}
+
+// rotate drops the oldest generation, and starts a new, empty, newest one. Assumes the lock is already acquired.
+func (c *RotatingCache[K, V]) rotate() {
+	copy(c.generations[1:], c.generations[:len(c.generations)-1])
+	c.generations[0] = make(map[K]rotatingEntry[V], c.size)
+}
+
+// Capacity returns the maximum number of entries the cache can hold.
+func (c *RotatingCache[K, V]) Capacity() uint64 {
+	return uint64(len(c.generations) * c.size)
+}
+
+// EntryCount returns the number of entries in the cache, including any that have expired but not yet been rotated
+// out. A key is counted once for each generation holding it.
+func (c *RotatingCache[K, V]) EntryCount() uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	count := 0
+	for _, g := range c.generations {
+		count += len(g)
+	}
+	return uint64(count)
+}
+
+// Close stops the periodic rotation, if any.
+func (c *RotatingCache[K, V]) Close() {
+	c.close.Do(func() {
+		close(c.done)
+	})
+}
+
+// Set adds a key-value pair to the newest generation, with no expiry.
+func (c *RotatingCache[K, V]) Set(k K, v V) error {
+	return c.SetWithExpiry(k, v, time.Time{})
+}
+
+// SetWithExpiry adds a key-value pair to the newest generation, expiring at the given time; the zero value means
+// no expiry. If the newest generation is full, the generations are rotated first.
+func (c *RotatingCache[K, V]) SetWithExpiry(k K, v V, expires time.Time) error {
+	if !expires.IsZero() && expires.Before(time.Now()) {
+		return fmt.Errorf("%w. expires is set to %s, but the current time is %s", ErrPastExpiry, expires.Format(DateTime), time.Now().Format(DateTime))
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.store(k, rotatingEntry[V]{value: v, expires: expires})
+	return nil
+}
+
+// store adds e to the newest generation, rotating first if it's full, and removes k from the older generations.
+// Assumes the lock is already acquired.
+func (c *RotatingCache[K, V]) store(k K, e rotatingEntry[V]) {
+	if _, found := c.generations[0][k]; !found && len(c.generations[0]) >= c.size {
+		c.rotate()
+	}
+	c.generations[0][k] = e
+	for _, g := range c.generations[1:] {
+		delete(g, k)
+	}
+}
+
+// Get retrieves the value for k, checking the generations from the newest to the oldest. An entry found in an
+// older generation is moved into the newest.
+func (c *RotatingCache[K, V]) Get(k K) (V, bool) {
+	now := time.Now()
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for i, g := range c.generations {
+		e, found := g[k]
+		if !found {
+			continue
+		}
+		if !e.expires.IsZero() && e.expires.Before(now) {
+			delete(g, k)
+			break
+		}
+		if i > 0 {
+			c.store(k, e)
+		}
+		return e.value, true
+	}
+
+	var empty V
+	return empty, false
+}
+
+// Contains reports whether an unexpired entry exists for k, without moving it.
+func (c *RotatingCache[K, V]) Contains(k K) bool {
+	now := time.Now()
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, g := range c.generations {
+		if e, found := g[k]; found {
+			return e.expires.IsZero() || !e.expires.Before(now)
+		}
+	}
+	return false
+}
+
+// Delete removes the entry for k, if it exists.
+func (c *RotatingCache[K, V]) Delete(k K) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, g := range c.generations {
+		delete(g, k)
+	}
+}
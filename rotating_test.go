@@ -0,0 +1,63 @@
+package lrucache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingCache_RotatesWhenFull(t *testing.T) {
+	// Checks a full generation starts a new one, dropping the oldest, and that reads carry entries forward.
+
+	cache := NewRotatingCache[int, int](2, 2, 0)
+	defer cache.Close()
+
+	require.NoError(t, cache.Set(1, 1))
+	require.NoError(t, cache.Set(2, 2))
+	require.NoError(t, cache.Set(3, 3)) // Rotates: [3], [1 2].
+
+	// Reading 1 moves it into the newest generation: [3 1], [2].
+	v, found := cache.Get(1)
+	assert.True(t, found)
+	assert.Equal(t, 1, v)
+
+	require.NoError(t, cache.Set(4, 4)) // Rotates: [4], [3 1].
+
+	assert.False(t, cache.Contains(2))
+	assert.True(t, cache.Contains(1))
+	assert.True(t, cache.Contains(3))
+	assert.True(t, cache.Contains(4))
+	assert.Equal(t, uint64(3), cache.EntryCount())
+	assert.Equal(t, uint64(4), cache.Capacity())
+
+	cache.Delete(3)
+	assert.False(t, cache.Contains(3))
+}
+
+func TestRotatingCache_RotatesOnInterval(t *testing.T) {
+	// Checks entries that aren't read are dropped once they've been rotated through every generation.
+
+	cache := NewRotatingCache[string, int](2, 100, 10*time.Millisecond)
+	defer cache.Close()
+
+	require.NoError(t, cache.Set("a", 1))
+	assert.Eventually(t, func() bool {
+		return !cache.Contains("a")
+	}, time.Second, time.Millisecond)
+}
+
+func TestRotatingCache_Expiry(t *testing.T) {
+	// Checks expired entries are treated as missing, and past expiries are rejected.
+
+	cache := NewRotatingCache[string, int](2, 10, 0)
+	defer cache.Close()
+
+	require.NoError(t, cache.SetWithExpiry("a", 1, time.Now().Add(5*time.Millisecond)))
+	time.Sleep(10 * time.Millisecond)
+
+	_, found := cache.Get("a")
+	assert.False(t, found)
+	assert.ErrorIs(t, cache.SetWithExpiry("b", 1, time.Now().Add(-time.Second)), ErrPastExpiry)
+}
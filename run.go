@@ -0,0 +1,41 @@
+package lrucache
+
+import "context"
+
+// Run blocks until ctx is done or the cache is closed, then closes the cache. If the cache was created with
+// WithExternalRun, the periodic purge of expired entries runs within Run, rather than in its own goroutine.
+// This allows the cache's lifecycle to be managed alongside other components, e.g. with errgroup:
+//
+//	g.Go(func() error { return cache.Run(ctx) })
+//
+// Run returns nil once the cache is closed, or ErrCacheClosed if the cache was already closed when it was called.
+func (lru *Cache[K, V]) Run(ctx context.Context) error {
+	lru.lifecycle.Lock()
+	if lru.closed {
+		lru.lifecycle.Unlock()
+		return ErrCacheClosed
+	}
+	lru.background.Add(1)
+	lru.lifecycle.Unlock()
+
+	func() {
+		defer lru.background.Done()
+
+		if lru.opts.externalRun && lru.opts.purgeAtExpiry {
+			lru.purgeAtExpiry(ctx)
+			return
+		}
+		if lru.opts.externalRun && lru.purgeInterval > 0 {
+			lru.purgeExpired(ctx, lru.purgeInterval)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-lru.done:
+		}
+	}()
+
+	lru.Close()
+	return nil
+}
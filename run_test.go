@@ -0,0 +1,66 @@
+package lrucache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_Run(t *testing.T) {
+	// Checks Run purges expired entries with WithExternalRun, and closes the cache when ctx is done.
+
+	cache := NewCacheWithOptions[string, int](10, WithPurgeInterval(5*time.Millisecond), WithExternalRun())
+
+	require.NoError(t, cache.SetWithExpiry("a", 1, time.Now().Add(time.Millisecond)))
+	time.Sleep(20 * time.Millisecond)
+
+	// Without Run, nothing purges the entry.
+	assert.Equal(t, uint64(1), cache.EntryCount())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error)
+	go func() {
+		result <- cache.Run(ctx)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return cache.EntryCount() == 0
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+	select {
+	case err := <-result:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after ctx was canceled")
+	}
+
+	assert.ErrorIs(t, cache.Run(context.Background()), ErrCacheClosed)
+}
+
+func TestCache_RunReturnsOnClose(t *testing.T) {
+	// Ensures Run returns when the cache is closed directly.
+
+	cache := NewCache[string, int](10)
+
+	result := make(chan error)
+	go func() {
+		result <- cache.Run(context.Background())
+	}()
+
+	// Give Run a chance to start; Close must work whether or not it has.
+	time.Sleep(5 * time.Millisecond)
+	cache.Close()
+
+	select {
+	case err := <-result:
+		if err != nil {
+			assert.ErrorIs(t, err, ErrCacheClosed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after Close")
+	}
+}
@@ -0,0 +1,167 @@
+package lrucache
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultSamples is the number of entries a SampledCache compares to choose each eviction, if not given.
+const DefaultSamples = 5
+
+// sampledEntry is a value held by a SampledCache.
+type sampledEntry[K comparable, V any] struct {
+	key      K
+	value    V
+	expires  time.Time    // Zero means none.
+	accessed atomic.Int64 // Unix nanoseconds of the last read or write.
+	index    int          // The entry's position in the cache's entries, for removal.
+}
+
+// SampledCache is a cache approximating LRU by sampling, as Redis does. Each entry only records the time it was
+// last used. To make space, a few entries are chosen at random, and the least recently used of them is evicted,
+// or any that has expired. The more samples, the closer the approximation, and the slower the eviction.
+//
+// With no list to keep in order, there's none of its per-entry overhead, and reads only take a read lock and
+// record the time, suiting very large caches where approximate LRU is good enough. Entries have no size; the
+// capacity is in entries.
+type SampledCache[K comparable, V any] struct {
+	lock     sync.RWMutex
+	index    map[K]*sampledEntry[K, V]
+	entries  []*sampledEntry[K, V] // All the entries, densely, so they can be sampled uniformly.
+	capacity int
+	samples  int
+}
+
+// NewSampledCache creates a SampledCache holding up to capacity entries, comparing the given number of samples for
+// each eviction. If samples isn't positive, DefaultSamples is used.
+func NewSampledCache[K comparable, V any](capacity, samples int) *SampledCache[K, V] {
+	if samples <= 0 {
+		samples = DefaultSamples
+	}
+	capacity = max(capacity, 1)
+	return &SampledCache[K, V]{
+		index:    make(map[K]*sampledEntry[K, V], capacity),
+		entries:  make([]*sampledEntry[K, V], 0, capacity),
+		capacity: capacity,
+		samples:  samples,
+	}
+}
+
+// Capacity returns the maximum number of entries the cache can hold.
+func (c *SampledCache[K, V]) Capacity() uint64 {
+	return uint64(c.capacity)
+}
+
+// EntryCount returns the number of entries in the cache, including any that have expired but not yet been evicted.
+func (c *SampledCache[K, V]) EntryCount() uint64 {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return uint64(len(c.entries))
+}
+
+// Close does nothing, as a SampledCache has no background work; it's present to satisfy Cacher.
+func (c *SampledCache[K, V]) Close() {}
+
+// Set adds a key-value pair to the cache, with no expiry.
+func (c *SampledCache[K, V]) Set(k K, v V) error {
+	return c.SetWithExpiry(k, v, time.Time{})
+}
+
+// SetWithExpiry adds a key-value pair to the cache, expiring at the given time; the zero value means no expiry.
+// If the cache is full, an entry is evicted first, chosen by sampling.
+func (c *SampledCache[K, V]) SetWithExpiry(k K, v V, expires time.Time) error {
+	now := time.Now()
+	if !expires.IsZero() && expires.Before(now) {
+		return fmt.Errorf("%w. expires is set to %s, but the current time is %s", ErrPastExpiry, expires.Format(DateTime), now.Format(DateTime))
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if e, found := c.index[k]; found {
+		e.value, e.expires = v, expires
+		e.accessed.Store(now.UnixNano())
+		return nil
+	}
+
+	if len(c.entries) >= c.capacity {
+		c.remove(c.sample(now))
+	}
+
+	e := &sampledEntry[K, V]{key: k, value: v, expires: expires, index: len(c.entries)}
+	e.accessed.Store(now.UnixNano())
+	c.index[k] = e
+	c.entries = append(c.entries, e)
+	return nil
+}
+
+// sample returns the entry to evict: the least recently used of a random sample, or the first expired one found.
+// Assumes the lock is already acquired, and the cache isn't empty.
+func (c *SampledCache[K, V]) sample(now time.Time) *sampledEntry[K, V] {
+	var oldest *sampledEntry[K, V]
+	n := len(c.entries)
+	for i := range min(c.samples, n) {
+		// If there are no more entries than samples, all of them are compared.
+		e := c.entries[i]
+		if c.samples < n {
+			e = c.entries[rand.IntN(n)]
+		}
+		if !e.expires.IsZero() && e.expires.Before(now) {
+			return e
+		}
+		if oldest == nil || e.accessed.Load() < oldest.accessed.Load() {
+			oldest = e
+		}
+	}
+	return oldest
+}
+
+// remove removes e, moving the last entry into its place. Assumes the lock is already acquired for writing.
+func (c *SampledCache[K, V]) remove(e *sampledEntry[K, V]) {
+	last := c.entries[len(c.entries)-1]
+	c.entries[e.index] = last
+	last.index = e.index
+	c.entries[len(c.entries)-1] = nil
+	c.entries = c.entries[:len(c.entries)-1]
+	delete(c.index, e.key)
+}
+
+// Get retrieves the value for k, recording the time it was read.
+func (c *SampledCache[K, V]) Get(k K) (V, bool) {
+	now := time.Now()
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if e, found := c.index[k]; found && (e.expires.IsZero() || !e.expires.Before(now)) {
+		e.accessed.Store(now.UnixNano())
+		return e.value, true
+	}
+
+	var empty V
+	return empty, false
+}
+
+// Contains reports whether an unexpired entry exists for k, without recording a read.
+func (c *SampledCache[K, V]) Contains(k K) bool {
+	now := time.Now()
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	e, found := c.index[k]
+	return found && (e.expires.IsZero() || !e.expires.Before(now))
+}
+
+// Delete removes the entry for k, if it exists.
+func (c *SampledCache[K, V]) Delete(k K) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if e, found := c.index[k]; found {
+		c.remove(e)
+	}
+}
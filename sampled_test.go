@@ -0,0 +1,70 @@
+package lrucache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampledCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	// Checks that, sampling every entry, the least recently used is always the one evicted.
+
+	cache := NewSampledCache[int, int](3, 100)
+	defer cache.Close()
+
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, cache.Set(i, i))
+		time.Sleep(time.Millisecond)
+	}
+
+	v, found := cache.Get(1)
+	assert.True(t, found)
+	assert.Equal(t, 1, v)
+
+	require.NoError(t, cache.Set(4, 4))
+	assert.False(t, cache.Contains(2))
+	assert.True(t, cache.Contains(1))
+	assert.True(t, cache.Contains(3))
+	assert.True(t, cache.Contains(4))
+	assert.Equal(t, uint64(3), cache.EntryCount())
+	assert.Equal(t, uint64(3), cache.Capacity())
+
+	cache.Delete(1)
+	assert.False(t, cache.Contains(1))
+	assert.Equal(t, uint64(2), cache.EntryCount())
+}
+
+func TestSampledCache_ApproximatesLRU(t *testing.T) {
+	// Checks a frequently read working set survives a stream of one-off entries.
+
+	cache := NewSampledCache[int, int](1000, 0)
+	defer cache.Close()
+
+	for i := 0; i < 10000; i++ {
+		require.NoError(t, cache.Set(i, i))
+		if i%10 == 0 {
+			for hot := 0; hot < 10; hot++ {
+				cache.Get(hot)
+			}
+		}
+	}
+	for hot := 0; hot < 10; hot++ {
+		assert.True(t, cache.Contains(hot))
+	}
+	assert.Equal(t, uint64(1000), cache.EntryCount())
+}
+
+func TestSampledCache_Expiry(t *testing.T) {
+	cache := NewSampledCache[string, int](2, 0)
+	defer cache.Close()
+
+	require.NoError(t, cache.SetWithExpiry("a", 1, time.Now().Add(5*time.Millisecond)))
+	require.NoError(t, cache.Set("b", 2))
+	assert.ErrorIs(t, cache.SetWithExpiry("c", 3, time.Now().Add(-time.Second)), ErrPastExpiry)
+
+	time.Sleep(10 * time.Millisecond)
+	_, found := cache.Get("a")
+	assert.False(t, found)
+}
@@ -0,0 +1,101 @@
+package lrucache
+
+import (
+	"cmp"
+	"slices"
+	"sort"
+	"time"
+)
+
+// DefaultScanCount is the number of entries Scan examines when given a count of zero.
+const DefaultScanCount = 10
+
+// scanIndex holds the cache's nodes in the order they were stored, i.e. by ascending version, so Scan can resume
+// from a cursor without walking the whole cache. Removed nodes are left in place until they make up half of it.
+type scanIndex[K comparable, V any] struct {
+	nodes []*node[K, V]
+	stale int // Removed nodes still in nodes.
+}
+
+// Scan returns a page of the keys of unexpired entries, for enumerating a very large cache incrementally, e.g. from
+// admin tooling, without copying every key or holding the lock for a full pass. Start with a cursor of zero, and
+// pass the next cursor returned to each following call, until it's zero again. Each call examines at most count
+// entries, so may return fewer keys, or none, before the end; a count of zero means DefaultScanCount.
+//
+// Keys are returned in the order their entries were stored. Every key with an entry throughout the scan is
+// returned exactly once, unless it's replaced, in which case it may be returned again. Keys stored or removed
+// during the scan may or may not be returned.
+//
+// The cache indexes its entries for Scan from the first call, which makes a full pass to build the index, and
+// maintains it from then on, at the cost of a pointer for each entry.
+func (lru *Cache[K, V]) Scan(cursor uint64, count int) (keys []K, next uint64) {
+	if count <= 0 {
+		count = DefaultScanCount
+	}
+
+	lru.writeLock(OperationOther)
+	defer lru.lock.Unlock()
+	if lru.stopped {
+		return nil, 0
+	}
+
+	if lru.scan == nil {
+		lru.buildScanIndex()
+	}
+
+	nodes := lru.scan.nodes
+	i := sort.Search(len(nodes), func(i int) bool {
+		return nodes[i].version > cursor
+	})
+
+	now := time.Now()
+	end := min(i+count, len(nodes))
+	for _, n := range nodes[i:end] {
+		if !n.deleted && !n.negative && !lru.isExpired(n, now) && !lru.isStale(n) {
+			keys = append(keys, n.key)
+		}
+	}
+
+	if end == len(nodes) {
+		return keys, 0
+	}
+	return keys, nodes[end-1].version
+}
+
+// buildScanIndex indexes every node in the cache for Scan.
+// Assumes the lock is already acquired.
+func (lru *Cache[K, V]) buildScanIndex() {
+	nodes := make([]*node[K, V], 0, len(lru.cache))
+	for _, n := range lru.cache {
+		nodes = append(nodes, n)
+	}
+	slices.SortFunc(nodes, func(a, b *node[K, V]) int {
+		return cmp.Compare(a.version, b.version)
+	})
+	lru.scan = &scanIndex[K, V]{nodes: nodes}
+}
+
+// scanStored adds n, which has just been given the newest version, to the Scan index, if there is one.
+// Assumes the lock is already acquired.
+func (lru *Cache[K, V]) scanStored(n *node[K, V]) {
+	if lru.scan != nil {
+		lru.scan.nodes = append(lru.scan.nodes, n)
+	}
+}
+
+// scanRemoved records that a node in the Scan index, if there is one, has been removed, compacting the index once
+// half of it has been.
+// Assumes the lock is already acquired.
+func (lru *Cache[K, V]) scanRemoved() {
+	s := lru.scan
+	if s == nil {
+		return
+	}
+	s.stale++
+	if s.stale > len(s.nodes)/2 {
+		s.nodes = slices.DeleteFunc(s.nodes, func(n *node[K, V]) bool {
+			return n.deleted
+		})
+		s.stale = 0
+	}
+}
@@ -0,0 +1,90 @@
+package lrucache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_Scan(t *testing.T) {
+	// Checks every key is returned once, in pages, in the order stored.
+
+	cache := NewCache[int, int](100)
+	defer cache.Close()
+
+	for i := 0; i < 25; i++ {
+		require.NoError(t, cache.Set(i, i))
+	}
+
+	var all []int
+	var cursor uint64
+	pages := 0
+	for {
+		keys, next := cache.Scan(cursor, 10)
+		all = append(all, keys...)
+		pages++
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+	assert.Equal(t, 3, pages)
+
+	expected := make([]int, 25)
+	for i := range expected {
+		expected[i] = i
+	}
+	assert.Equal(t, expected, all)
+}
+
+func TestCache_ScanConcurrentChanges(t *testing.T) {
+	// Checks keys present throughout a scan are returned, while removed and expired ones aren't.
+
+	cache := NewCache[int, int](100)
+	defer cache.Close()
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, cache.Set(i, i))
+	}
+	require.NoError(t, cache.SetWithExpiry(10, 10, time.Now().Add(5*time.Millisecond)))
+
+	keys, next := cache.Scan(0, 4)
+	assert.Equal(t, []int{0, 1, 2, 3}, keys)
+
+	// Removed keys are skipped, and stored ones are returned at the end.
+	cache.Delete(5)
+	require.NoError(t, cache.Set(11, 11))
+	time.Sleep(10 * time.Millisecond)
+
+	keys, next = cache.Scan(next, 4)
+	assert.Equal(t, []int{4, 6, 7}, keys)
+	keys, next = cache.Scan(next, 10)
+	assert.Equal(t, []int{8, 9, 11}, keys)
+	assert.Zero(t, next)
+}
+
+func TestCache_ScanCompacts(t *testing.T) {
+	// Checks the index drops removed entries, so it doesn't grow with churn.
+
+	cache := NewCache[int, int](10)
+	defer cache.Close()
+
+	cache.Scan(0, 1)
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, cache.Set(i, i))
+	}
+	cache.lock.Lock()
+	assert.LessOrEqual(t, len(cache.scan.nodes), 21)
+	cache.lock.Unlock()
+
+	keys, next := cache.Scan(0, 100)
+	assert.Len(t, keys, 10)
+	assert.Zero(t, next)
+
+	cache.Close()
+	keys, next = cache.Scan(0, 100)
+	assert.Empty(t, keys)
+	assert.Zero(t, next)
+}
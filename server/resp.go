@@ -0,0 +1,139 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultMaxBulkLength is the largest bulk string accepted in a request, unless set by WithMaxBulkLength.
+	DefaultMaxBulkLength = 8 << 20
+
+	// DefaultMaxArgs is the largest number of arguments accepted in a request, unless set by WithMaxArgs.
+	DefaultMaxArgs = 1024
+)
+
+// bulkChunk is the most read into a bulk string at a time, so its buffer only grows as its data arrives, rather
+// than being allocated up front from the length claimed by the client.
+const bulkChunk = 64 << 10
+
+// limits bounds the size of the requests read by readCommand.
+type limits struct {
+	maxBulkLength int
+	maxArgs       int
+}
+
+// errProtocol is returned when a request isn't valid RESP. The connection is then closed, as Redis does.
+var errProtocol = errors.New("protocol error")
+
+// readCommand reads a request: either a RESP array of bulk strings, or an inline command of space-separated words,
+// as sent by telnet or redis-cli in inline mode. An empty inline line returns no arguments. Requests exceeding
+// the limits are rejected with errProtocol.
+func readCommand(r *bufio.Reader, l limits) ([][]byte, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 || line[0] != '*' {
+		fields := strings.Fields(string(line))
+		args := make([][]byte, len(fields))
+		for i, f := range fields {
+			args[i] = []byte(f)
+		}
+		return args, nil
+	}
+
+	n, err := strconv.Atoi(string(line[1:]))
+	if err != nil || n > l.maxArgs {
+		return nil, fmt.Errorf("%w: invalid multibulk length", errProtocol)
+	}
+	// The count is only a claim until the arguments arrive, so the slice grows with them beyond a small start.
+	args := make([][]byte, 0, min(max(n, 0), 16))
+	for i := 0; i < n; i++ {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 || line[0] != '$' {
+			return nil, fmt.Errorf("%w: expected '$', got %q", errProtocol, line)
+		}
+		size, err := strconv.Atoi(string(line[1:]))
+		if err != nil || size < 0 || size > l.maxBulkLength {
+			return nil, fmt.Errorf("%w: invalid bulk length", errProtocol)
+		}
+		b, err := readBulk(r, size)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, b)
+	}
+	return args, nil
+}
+
+// readBulk reads a bulk string of size bytes, and its terminating CRLF. The buffer grows by at most bulkChunk
+// bytes at a time, so a client claiming a large size must send the data to make the server allocate it.
+func readBulk(r *bufio.Reader, size int) ([]byte, error) {
+	b := make([]byte, 0, min(size, bulkChunk))
+	for len(b) < size {
+		n := min(size-len(b), bulkChunk)
+		b = slices.Grow(b, n)
+		if _, err := io.ReadFull(r, b[len(b):len(b)+n]); err != nil {
+			return nil, err
+		}
+		b = b[:len(b)+n]
+	}
+
+	var crlf [2]byte
+	if _, err := io.ReadFull(r, crlf[:]); err != nil {
+		return nil, err
+	}
+	if crlf != [2]byte{'\r', '\n'} {
+		return nil, fmt.Errorf("%w: bulk string not terminated by CRLF", errProtocol)
+	}
+	return b, nil
+}
+
+// readLine reads a line, without its terminating CRLF, or LF.
+func readLine(r *bufio.Reader) ([]byte, error) {
+	line, err := r.ReadSlice('\n')
+	if errors.Is(err, bufio.ErrBufferFull) {
+		return nil, fmt.Errorf("%w: line too long", errProtocol)
+	}
+	if err != nil {
+		return nil, err
+	}
+	line = line[:len(line)-1]
+	if len(line) > 0 && line[len(line)-1] == '\r' {
+		line = line[:len(line)-1]
+	}
+	return line, nil
+}
+
+// The RESP replies used by the server.
+
+func writeSimple(w *bufio.Writer, s string) {
+	w.WriteString("+" + s + "\r\n")
+}
+
+func writeError(w *bufio.Writer, msg string) {
+	w.WriteString("-" + msg + "\r\n")
+}
+
+func writeInteger(w *bufio.Writer, n int64) {
+	w.WriteString(":" + strconv.FormatInt(n, 10) + "\r\n")
+}
+
+func writeBulk(w *bufio.Writer, b []byte) {
+	w.WriteString("$" + strconv.Itoa(len(b)) + "\r\n")
+	w.Write(b)
+	w.WriteString("\r\n")
+}
+
+func writeNull(w *bufio.Writer) {
+	w.WriteString("$-1\r\n")
+}
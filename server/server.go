@@ -0,0 +1,347 @@
+// Package server exposes a Cache[string, []byte] over the Redis protocol (RESP), so processes not written in Go,
+// such as sidecars, can share the same in-memory cache. Any Redis client can connect to it.
+//
+// The commands supported are PING, ECHO, GET, SET (with EX or PX), DEL, EXISTS, TTL, PTTL and QUIT.
+// Entries are stored with a size of the length of their value, so the cache's capacity is in bytes.
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nsmithuk/lrucache"
+)
+
+// ErrServerClosed is returned by Serve once the Server has been closed.
+var ErrServerClosed = errors.New("server: closed")
+
+// Server serves a cache over RESP.
+type Server struct {
+	cache  *lrucache.Cache[string, []byte]
+	logger *slog.Logger
+	limits limits
+
+	lock      sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithLogger sets the logger for errors serving connections. The default discards them.
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Server) {
+		s.logger = logger
+	}
+}
+
+// WithMaxBulkLength sets the largest argument, such as a value to SET, accepted in a request, in bytes. Larger
+// requests are rejected with a protocol error, and the connection closed. The default is DefaultMaxBulkLength;
+// values below 1 leave it unchanged.
+func WithMaxBulkLength(n int) Option {
+	return func(s *Server) {
+		if n > 0 {
+			s.limits.maxBulkLength = n
+		}
+	}
+}
+
+// WithMaxArgs sets the largest number of arguments accepted in a request, including the command's name. Larger
+// requests are rejected with a protocol error, and the connection closed. The default is DefaultMaxArgs; values
+// below 1 leave it unchanged.
+func WithMaxArgs(n int) Option {
+	return func(s *Server) {
+		if n > 0 {
+			s.limits.maxArgs = n
+		}
+	}
+}
+
+// New returns a Server for cache. The cache isn't closed when the Server is.
+func New(cache *lrucache.Cache[string, []byte], opts ...Option) *Server {
+	s := &Server{
+		cache:     cache,
+		logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+		limits:    limits{maxBulkLength: DefaultMaxBulkLength, maxArgs: DefaultMaxArgs},
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// ListenAndServe listens on the TCP address addr, and serves connections to it until the Server is closed.
+func (s *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve accepts connections from l, serving each on its own goroutine, until the Server is closed, when it
+// returns ErrServerClosed. l is closed on return.
+func (s *Server) Serve(l net.Listener) error {
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		l.Close()
+		return ErrServerClosed
+	}
+	s.listeners[l] = struct{}{}
+	s.lock.Unlock()
+
+	defer func() {
+		s.lock.Lock()
+		delete(s.listeners, l)
+		s.lock.Unlock()
+		l.Close()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.lock.Lock()
+			closed := s.closed
+			s.lock.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+
+		s.lock.Lock()
+		if s.closed {
+			s.lock.Unlock()
+			conn.Close()
+			return ErrServerClosed
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.lock.Unlock()
+
+		go func() {
+			defer s.wg.Done()
+			s.ServeConn(conn)
+
+			s.lock.Lock()
+			delete(s.conns, conn)
+			s.lock.Unlock()
+		}()
+	}
+}
+
+// Close stops the listeners, closes all connections, and waits for their handlers to return.
+func (s *Server) Close() error {
+	s.lock.Lock()
+	s.closed = true
+	for l := range s.listeners {
+		l.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.lock.Unlock()
+
+	s.wg.Wait()
+	return nil
+}
+
+// ServeConn serves requests from conn until it's closed, the client quits, or it sends an invalid request. conn is
+// closed on return.
+func (s *Server) ServeConn(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	for {
+		args, err := readCommand(r, s.limits)
+		if err != nil {
+			if errors.Is(err, errProtocol) {
+				writeError(w, "ERR "+err.Error())
+				w.Flush()
+			}
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				s.logger.Debug("server: closing connection", "remote", conn.RemoteAddr(), "error", err)
+			}
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		quit := s.execute(w, args)
+
+		// Replies to pipelined requests are flushed together.
+		if r.Buffered() == 0 || quit {
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+		if quit {
+			return
+		}
+	}
+}
+
+// execute runs a single command, writing its reply to w. It returns true if the client quit.
+func (s *Server) execute(w *bufio.Writer, args [][]byte) (quit bool) {
+	name := strings.ToUpper(string(args[0]))
+	args = args[1:]
+
+	switch name {
+	case "PING":
+		switch len(args) {
+		case 0:
+			writeSimple(w, "PONG")
+		case 1:
+			writeBulk(w, args[0])
+		default:
+			writeArity(w, name)
+		}
+
+	case "ECHO":
+		if len(args) != 1 {
+			writeArity(w, name)
+			return false
+		}
+		writeBulk(w, args[0])
+
+	case "GET":
+		if len(args) != 1 {
+			writeArity(w, name)
+			return false
+		}
+		if v, found := s.cache.Get(string(args[0])); found {
+			writeBulk(w, v)
+		} else {
+			writeNull(w)
+		}
+
+	case "SET":
+		s.set(w, args)
+
+	case "DEL":
+		if len(args) == 0 {
+			writeArity(w, name)
+			return false
+		}
+		// Expired entries are removed too, but like missing ones, aren't counted.
+		keys := make([]string, len(args))
+		var n int64
+		for i, k := range args {
+			keys[i] = string(k)
+			if s.cache.Contains(keys[i]) {
+				n++
+			}
+		}
+		s.cache.DeleteMulti(keys...)
+		writeInteger(w, n)
+
+	case "EXISTS":
+		if len(args) == 0 {
+			writeArity(w, name)
+			return false
+		}
+		var n int64
+		for _, k := range args {
+			if s.cache.Contains(string(k)) {
+				n++
+			}
+		}
+		writeInteger(w, n)
+
+	case "TTL", "PTTL":
+		if len(args) != 1 {
+			writeArity(w, name)
+			return false
+		}
+		e, found := s.cache.Entry(string(args[0]))
+		switch {
+		case !found:
+			writeInteger(w, -2)
+		case e.Expires.IsZero():
+			writeInteger(w, -1)
+		case name == "TTL":
+			// Rounded up, so an entry about to expire isn't reported as having none left.
+			writeInteger(w, int64((time.Until(e.Expires)+time.Second-1)/time.Second))
+		default:
+			writeInteger(w, time.Until(e.Expires).Milliseconds())
+		}
+
+	case "QUIT":
+		writeSimple(w, "OK")
+		return true
+
+	default:
+		writeError(w, fmt.Sprintf("ERR unknown command '%s'", truncate(name)))
+	}
+	return false
+}
+
+// set runs SET key value [EX seconds | PX milliseconds].
+func (s *Server) set(w *bufio.Writer, args [][]byte) {
+	if len(args) < 2 {
+		writeArity(w, "SET")
+		return
+	}
+	key, value := string(args[0]), bytes.Clone(args[1])
+
+	var expires time.Time
+	for i := 2; i < len(args); i++ {
+		switch opt := strings.ToUpper(string(args[i])); opt {
+		case "EX", "PX":
+			if i+1 == len(args) || !expires.IsZero() {
+				writeError(w, "ERR syntax error")
+				return
+			}
+			i++
+			n, err := strconv.ParseInt(string(args[i]), 10, 64)
+			if err != nil || n <= 0 {
+				writeError(w, "ERR invalid expire time in 'set' command")
+				return
+			}
+			unit := time.Second
+			if opt == "PX" {
+				unit = time.Millisecond
+			}
+			expires = time.Now().Add(time.Duration(n) * unit)
+		default:
+			writeError(w, "ERR syntax error")
+			return
+		}
+	}
+	opts := []lrucache.EntryOption{lrucache.WithSize(uint64(max(len(value), 1))), lrucache.WithExpiry(expires)}
+	if err := s.cache.SetWithOptions(key, value, opts...); err != nil {
+		writeError(w, "ERR "+err.Error())
+		return
+	}
+	writeSimple(w, "OK")
+}
+
+// writeArity writes the error for a command given the wrong number of arguments.
+func writeArity(w *bufio.Writer, name string) {
+	writeError(w, fmt.Sprintf("ERR wrong number of arguments for '%s' command", strings.ToLower(name)))
+}
+
+// truncate returns a command name safe to echo back in an error, truncated if it's long.
+func truncate(name string) string {
+	if len(name) > 64 {
+		return name[:64] + "..."
+	}
+	return name
+}
@@ -0,0 +1,173 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nsmithuk/lrucache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startServer serves a new cache on a local port, returning the cache and a connection to the server.
+func startServer(t *testing.T) (*lrucache.Cache[string, []byte], net.Conn) {
+	t.Helper()
+
+	cache := lrucache.NewCache[string, []byte](1024)
+	s := New(cache)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	served := make(chan error, 1)
+	go func() { served <- s.Serve(l) }()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		conn.Close()
+		require.NoError(t, s.Close())
+		assert.True(t, errors.Is(<-served, ErrServerClosed))
+		cache.Close()
+	})
+	return cache, conn
+}
+
+// command sends args as a RESP array, returning the reply, with the CRLFs replaced by spaces.
+func command(t *testing.T, conn net.Conn, r *bufio.Reader, args ...string) string {
+	t.Helper()
+
+	var b strings.Builder
+	b.WriteString("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, a := range args {
+		b.WriteString("$" + strconv.Itoa(len(a)) + "\r\n" + a + "\r\n")
+	}
+	_, err := conn.Write([]byte(b.String()))
+	require.NoError(t, err)
+	return reply(t, r)
+}
+
+// reply reads one reply, which is a single line unless it's a non-null bulk string.
+func reply(t *testing.T, r *bufio.Reader) string {
+	t.Helper()
+
+	line, err := r.ReadString('\n')
+	require.NoError(t, err)
+	line = strings.TrimSuffix(line, "\r\n")
+	if strings.HasPrefix(line, "$") && line != "$-1" {
+		body, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line += " " + strings.TrimSuffix(body, "\r\n")
+	}
+	return line
+}
+
+func TestServer_Commands(t *testing.T) {
+	cache, conn := startServer(t)
+	r := bufio.NewReader(conn)
+
+	assert.Equal(t, "+PONG", command(t, conn, r, "PING"))
+	assert.Equal(t, "$5 hello", command(t, conn, r, "echo", "hello"))
+
+	assert.Equal(t, "$-1", command(t, conn, r, "GET", "a"))
+	assert.Equal(t, "+OK", command(t, conn, r, "SET", "a", "apple"))
+	assert.Equal(t, "$5 apple", command(t, conn, r, "GET", "a"))
+	assert.Equal(t, uint64(5), cache.Size())
+
+	assert.Equal(t, ":-1", command(t, conn, r, "TTL", "a"))
+	assert.Equal(t, ":-2", command(t, conn, r, "TTL", "missing"))
+	assert.Equal(t, "+OK", command(t, conn, r, "SET", "b", "banana", "EX", "60"))
+	assert.Equal(t, ":60", command(t, conn, r, "TTL", "b"))
+	pttl := command(t, conn, r, "PTTL", "b")
+	assert.True(t, strings.HasPrefix(pttl, ":59") || pttl == ":60000", pttl)
+
+	assert.Equal(t, "+OK", command(t, conn, r, "SET", "c", "cherry", "PX", "20"))
+	time.Sleep(40 * time.Millisecond)
+	assert.Equal(t, "$-1", command(t, conn, r, "GET", "c"))
+
+	assert.Equal(t, ":2", command(t, conn, r, "EXISTS", "a", "b", "c"))
+	assert.Equal(t, ":2", command(t, conn, r, "DEL", "a", "b", "c"))
+	assert.Equal(t, ":0", command(t, conn, r, "EXISTS", "a", "b"))
+
+	assert.Equal(t, "-ERR wrong number of arguments for 'get' command", command(t, conn, r, "GET"))
+	assert.Equal(t, "-ERR syntax error", command(t, conn, r, "SET", "a", "b", "NX"))
+	assert.Equal(t, "-ERR invalid expire time in 'set' command", command(t, conn, r, "SET", "a", "b", "EX", "0"))
+	assert.Equal(t, "-ERR unknown command 'FLUSHALL'", command(t, conn, r, "FLUSHALL"))
+
+	assert.Equal(t, "+OK", command(t, conn, r, "QUIT"))
+	_, err := r.ReadByte()
+	assert.Error(t, err)
+}
+
+func TestServer_InlineAndPipelined(t *testing.T) {
+	_, conn := startServer(t)
+	r := bufio.NewReader(conn)
+
+	_, err := conn.Write([]byte("SET k v\r\nGET k\r\n\r\nPING\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "+OK", reply(t, r))
+	assert.Equal(t, "$1 v", reply(t, r))
+	assert.Equal(t, "+PONG", reply(t, r))
+}
+
+func TestServer_ProtocolError(t *testing.T) {
+	_, conn := startServer(t)
+	r := bufio.NewReader(conn)
+
+	_, err := conn.Write([]byte("*1\r\n+GET\r\n"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(reply(t, r), "-ERR protocol error"))
+	_, err = r.ReadByte()
+	assert.Error(t, err)
+}
+
+func TestReadCommand_Limits(t *testing.T) {
+	// Checks requests beyond the limits are rejected, and a claimed bulk length isn't allocated before its data
+	// arrives.
+
+	read := func(req string, l limits) ([][]byte, error) {
+		return readCommand(bufio.NewReader(strings.NewReader(req)), l)
+	}
+	l := limits{maxBulkLength: 4, maxArgs: 2}
+
+	args, err := read("*2\r\n$3\r\nGET\r\n$4\r\nabcd\r\n", l)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("GET"), []byte("abcd")}, args)
+
+	_, err = read("*3\r\n$3\r\nDEL\r\n$1\r\na\r\n$1\r\nb\r\n", l)
+	assert.ErrorIs(t, err, errProtocol)
+	_, err = read("*2\r\n$3\r\nGET\r\n$5\r\nabcde\r\n", l)
+	assert.ErrorIs(t, err, errProtocol)
+	_, err = read("*1\r\n$3\r\nGETxx", l)
+	assert.ErrorIs(t, err, errProtocol)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err = read("*1048576\r\n$8000000\r\nabc", limits{maxBulkLength: DefaultMaxBulkLength, maxArgs: 1 << 20})
+	runtime.ReadMemStats(&after)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(1<<20))
+}
+
+func TestServer_MaxBulkLength(t *testing.T) {
+	cache := lrucache.NewCache[string, []byte](1024)
+	defer cache.Close()
+	s := New(cache, WithMaxBulkLength(3))
+
+	client, conn := net.Pipe()
+	go s.ServeConn(conn)
+	defer client.Close()
+	r := bufio.NewReader(client)
+
+	assert.Equal(t, "+OK", command(t, client, r, "SET", "k", "abc"))
+	_, err := client.Write([]byte("*3\r\n$3\r\nSET\r\n$1\r\nk\r\n$4\r\nabcd\r\n"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(reply(t, r), "-ERR protocol error"))
+}
@@ -0,0 +1,176 @@
+package lrucache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// ShadowStats reports the hit rate a shadow cache of another capacity would have had; see WithShadowCapacities.
+type ShadowStats struct {
+	Factor   float64 // The shadow's capacity, as a multiple of the cache's.
+	Capacity uint64
+	Hits     uint64 // Lookups that would have been hits at this capacity.
+	Misses   uint64 // Lookups that would have been misses.
+}
+
+// HitRatio returns the fraction of lookups that would have been hits, or zero if there were none.
+func (s ShadowStats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// WithShadowCapacities tracks the hit rate the cache would have had with each of the given multiples of its
+// capacity, reported in Stats.Shadows, so capacity can be planned from production traffic. The default factors,
+// if none are given, are 0.5 and 2; include 1 to compare them with the actual capacity on equal terms.
+//
+// Each shadow is an LRU of keys, sizes and expiries, without values, fed every write and lookup, so costs memory
+// in proportion to its capacity, and a little time on every operation. Lookups are counted as for WithStatsRecorder.
+func WithShadowCapacities(factors ...float64) Option {
+	return func(o *options) {
+		if len(factors) == 0 {
+			factors = []float64{0.5, 2}
+		}
+		o.shadowFactors = factors
+	}
+}
+
+// shadowEntry is a key held by a shadowCache.
+type shadowEntry[K comparable] struct {
+	key     K
+	size    uint64
+	expires time.Time
+}
+
+// shadowCache is an LRU of keys, simulating a cache of another capacity.
+type shadowCache[K comparable] struct {
+	factor   float64
+	capacity uint64
+
+	lock    sync.Mutex
+	size    uint64
+	entries map[K]*list.Element
+	order   *list.List // Of *shadowEntry, from the most to the least recently used.
+	hits    uint64
+	misses  uint64
+}
+
+// newShadowCaches returns a shadowCache for each of the factors of capacity.
+func newShadowCaches[K comparable](capacity uint64, factors []float64) []*shadowCache[K] {
+	shadows := make([]*shadowCache[K], len(factors))
+	for i, f := range factors {
+		shadows[i] = &shadowCache[K]{
+			factor:   f,
+			capacity: uint64(float64(capacity) * f),
+			entries:  make(map[K]*list.Element),
+			order:    list.New(),
+		}
+	}
+	return shadows
+}
+
+// shadowLookup records a lookup of k in each shadow cache.
+func (lru *Cache[K, V]) shadowLookup(k K) {
+	if len(lru.shadows) == 0 {
+		return
+	}
+	now := time.Now()
+	for _, s := range lru.shadows {
+		s.lookup(k, now)
+	}
+}
+
+// shadowStore records n being stored in each shadow cache.
+func (lru *Cache[K, V]) shadowStore(n *node[K, V]) {
+	for _, s := range lru.shadows {
+		s.store(n.key, n.size, n.expires)
+	}
+}
+
+// shadowRemove records k being deleted from each shadow cache. Entries evicted or expired from the cache aren't
+// removed from the shadows, which evict and expire them by their own capacity.
+func (lru *Cache[K, V]) shadowRemove(k K, reason EvictionReason) {
+	if reason != EvictionReasonDeleted && reason != EvictionReasonInvalidated && reason != EvictionReasonRenamed {
+		return
+	}
+	for _, s := range lru.shadows {
+		s.remove(k)
+	}
+}
+
+// shadowStats returns the stats of each shadow cache.
+func (lru *Cache[K, V]) shadowStats() []ShadowStats {
+	if len(lru.shadows) == 0 {
+		return nil
+	}
+	stats := make([]ShadowStats, len(lru.shadows))
+	for i, s := range lru.shadows {
+		s.lock.Lock()
+		stats[i] = ShadowStats{Factor: s.factor, Capacity: s.capacity, Hits: s.hits, Misses: s.misses}
+		s.lock.Unlock()
+	}
+	return stats
+}
+
+func (s *shadowCache[K]) lookup(k K, now time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	el, found := s.entries[k]
+	if !found {
+		s.misses++
+		return
+	}
+	if e := el.Value.(*shadowEntry[K]); !e.expires.IsZero() && !now.Before(e.expires) {
+		s.removeElement(el)
+		s.misses++
+		return
+	}
+	s.hits++
+	s.order.MoveToFront(el)
+}
+
+func (s *shadowCache[K]) store(k K, size uint64, expires time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if el, found := s.entries[k]; found {
+		s.removeElement(el)
+	}
+	if size > s.capacity {
+		return
+	}
+	for s.size+size > s.capacity {
+		s.removeElement(s.order.Back())
+	}
+	s.entries[k] = s.order.PushFront(&shadowEntry[K]{key: k, size: size, expires: expires})
+	s.size += size
+}
+
+func (s *shadowCache[K]) remove(k K) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if el, found := s.entries[k]; found {
+		s.removeElement(el)
+	}
+}
+
+// clear removes all the entries, keeping the counts.
+func (s *shadowCache[K]) clear() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	clear(s.entries)
+	s.order.Init()
+	s.size = 0
+}
+
+// removeElement removes an entry. Assumes the lock is held.
+func (s *shadowCache[K]) removeElement(el *list.Element) {
+	e := s.order.Remove(el).(*shadowEntry[K])
+	delete(s.entries, e.key)
+	s.size -= e.size
+}
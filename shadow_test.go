@@ -0,0 +1,59 @@
+package lrucache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_ShadowCapacities(t *testing.T) {
+	// Cycling through 8 keys misses every time in an LRU of 4, or of 2, but hits every time in one of 8 or more.
+
+	cache := NewCacheWithOptions[int, int](4, WithShadowCapacities(0.5, 1, 2), WithStrictConsistency())
+	defer cache.Close()
+
+	for round := 0; round < 3; round++ {
+		for k := 0; k < 8; k++ {
+			if _, found := cache.Get(k); !found {
+				require.NoError(t, cache.Set(k, k))
+			}
+		}
+	}
+
+	shadows := cache.Stats().Shadows
+	require.Len(t, shadows, 3)
+
+	assert.Equal(t, 0.5, shadows[0].Factor)
+	assert.Equal(t, uint64(2), shadows[0].Capacity)
+	assert.Equal(t, uint64(0), shadows[0].Hits)
+	assert.Equal(t, uint64(24), shadows[0].Misses)
+
+	assert.Equal(t, uint64(4), shadows[1].Capacity)
+	assert.Equal(t, uint64(0), shadows[1].Hits)
+
+	assert.Equal(t, uint64(8), shadows[2].Capacity)
+	assert.Equal(t, uint64(16), shadows[2].Hits)
+	assert.Equal(t, uint64(8), shadows[2].Misses)
+	assert.InDelta(t, 2.0/3, shadows[2].HitRatio(), 0.001)
+}
+
+func TestCache_ShadowCapacitiesDelete(t *testing.T) {
+	cache := NewCacheWithOptions[string, int](4, WithShadowCapacities())
+	defer cache.Close()
+
+	require.NoError(t, cache.Set("a", 1))
+	cache.Delete("a")
+	cache.Get("a")
+
+	shadows := cache.Stats().Shadows
+	require.Len(t, shadows, 2)
+	for _, s := range shadows {
+		assert.Equal(t, uint64(0), s.Hits)
+		assert.Equal(t, uint64(1), s.Misses)
+	}
+
+	plain := NewCache[string, int](4)
+	defer plain.Close()
+	assert.Nil(t, plain.Stats().Shadows)
+}
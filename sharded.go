@@ -0,0 +1,175 @@
+package lrucache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// ShardedCache spreads its entries over a number of independent Caches, by the hash of their keys, so operations
+// on different shards don't contend for the same lock. Each shard is a separate LRU of an equal share of the
+// capacity, so an entry is evicted when its own shard is full, even if others have room. Keys that hash unevenly
+// over the shards waste capacity and concentrate contention; ShardStats shows how evenly they're spread.
+//
+// Keys are hashed with a random seed chosen for each ShardedCache, so the shard a key lands on can't be predicted
+// from outside the process. Callers controlling the keys, such as clients choosing request URLs, then can't craft
+// keys that all land on one shard to degrade the cache.
+type ShardedCache[K comparable, V any] struct {
+	shards []*Cache[K, V]
+	counts []shardCounts
+	hasher keyHasher[K]
+}
+
+// shardCounts counts a shard's lookups, for ShardStats.
+type shardCounts struct {
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// NewShardedCache creates a ShardedCache of the given number of shards, sharing the capacity between them. Each
+// shard is created with the given Options.
+func NewShardedCache[K comparable, V any](shards int, capacity uint64, opts ...Option) *ShardedCache[K, V] {
+	shards = max(shards, 1)
+	c := &ShardedCache[K, V]{
+		shards: make([]*Cache[K, V], shards),
+		counts: make([]shardCounts, shards),
+		hasher: newKeyHasher[K](),
+	}
+	for i := range c.shards {
+		// Any remainder is spread over the first shards.
+		share := capacity / uint64(shards)
+		if uint64(i) < capacity%uint64(shards) {
+			share++
+		}
+		c.shards[i] = NewCacheWithOptions[K, V](share, opts...)
+	}
+	return c
+}
+
+// shardIndex returns the index of the shard holding k.
+func (c *ShardedCache[K, V]) shardIndex(k K) int {
+	return int(c.hasher.hash(k) % uint64(len(c.shards)))
+}
+
+// Shard returns the shard holding k, for operations not offered by ShardedCache itself. Lookups made directly on
+// the shard aren't counted by ShardStats.
+func (c *ShardedCache[K, V]) Shard(k K) *Cache[K, V] {
+	return c.shards[c.shardIndex(k)]
+}
+
+// Get returns the value for k, and whether it was found.
+func (c *ShardedCache[K, V]) Get(k K) (V, bool) {
+	i := c.shardIndex(k)
+	v, found := c.shards[i].Get(k)
+	if found {
+		c.counts[i].hits.Add(1)
+	} else {
+		c.counts[i].misses.Add(1)
+	}
+	return v, found
+}
+
+// Set adds a key-value pair to its shard, with a size of 1 and no expiry.
+func (c *ShardedCache[K, V]) Set(k K, v V) error {
+	return c.Shard(k).Set(k, v)
+}
+
+// SetWithExpiry adds a key-value pair to its shard, with a size of 1, expiring at the given time.
+func (c *ShardedCache[K, V]) SetWithExpiry(k K, v V, expires time.Time) error {
+	return c.Shard(k).SetWithExpiry(k, v, expires)
+}
+
+// Contains returns true if k is in the cache and not expired, without counting as a read.
+func (c *ShardedCache[K, V]) Contains(k K) bool {
+	return c.Shard(k).Contains(k)
+}
+
+// Delete removes k from the cache.
+func (c *ShardedCache[K, V]) Delete(k K) {
+	c.Shard(k).Delete(k)
+}
+
+// Capacity returns the total capacity of the shards.
+func (c *ShardedCache[K, V]) Capacity() uint64 {
+	var total uint64
+	for _, s := range c.shards {
+		total += s.Capacity()
+	}
+	return total
+}
+
+// Size returns the total size of the entries in all the shards.
+func (c *ShardedCache[K, V]) Size() uint64 {
+	var total uint64
+	for _, s := range c.shards {
+		total += s.Size()
+	}
+	return total
+}
+
+// EntryCount returns the number of entries in all the shards.
+func (c *ShardedCache[K, V]) EntryCount() uint64 {
+	var total uint64
+	for _, s := range c.shards {
+		total += s.EntryCount()
+	}
+	return total
+}
+
+// Close closes all the shards.
+func (c *ShardedCache[K, V]) Close() {
+	for _, s := range c.shards {
+		s.Close()
+	}
+}
+
+//---
+
+// ShardStats is a point-in-time summary of one shard of a ShardedCache.
+type ShardStats struct {
+	Capacity   uint64
+	Size       uint64
+	EntryCount uint64
+	Hits       uint64 // Reads through the ShardedCache that found their key in this shard.
+	Misses     uint64
+}
+
+// HitRatio returns the fraction of the shard's reads that were hits, or zero if it's had none.
+func (s ShardStats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// ShardStats returns a summary of each shard, in order. Comparing them shows skew from keys that hash unevenly:
+// a shard that's much fuller, or read much more often, than the others suggests a different key or shard count.
+// See also Skew.
+func (c *ShardedCache[K, V]) ShardStats() []ShardStats {
+	stats := make([]ShardStats, len(c.shards))
+	for i, s := range c.shards {
+		stats[i] = ShardStats{
+			Capacity:   s.Capacity(),
+			Size:       s.Size(),
+			EntryCount: s.EntryCount(),
+			Hits:       c.counts[i].hits.Load(),
+			Misses:     c.counts[i].misses.Load(),
+		}
+	}
+	return stats
+}
+
+// Skew returns the number of reads of the most read shard, relative to the mean over all the shards: 1 when
+// reads are spread evenly, and up to the number of shards when they all go to one. It returns zero before any
+// reads.
+func (c *ShardedCache[K, V]) Skew() float64 {
+	var total, most uint64
+	for i := range c.counts {
+		reads := c.counts[i].hits.Load() + c.counts[i].misses.Load()
+		total += reads
+		most = max(most, reads)
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(most) * float64(len(c.counts)) / float64(total)
+}
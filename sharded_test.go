@@ -0,0 +1,75 @@
+package lrucache
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShardedCache(t *testing.T) {
+	cache := NewShardedCache[int, int](4, 1002)
+	defer cache.Close()
+
+	stats := cache.ShardStats()
+	require.Len(t, stats, 4)
+	assert.Equal(t, []uint64{251, 251, 250, 250}, []uint64{stats[0].Capacity, stats[1].Capacity, stats[2].Capacity, stats[3].Capacity})
+	assert.Equal(t, uint64(1002), cache.Capacity())
+
+	for i := 0; i < 400; i++ {
+		require.NoError(t, cache.Set(i, i))
+	}
+	assert.Equal(t, uint64(400), cache.EntryCount())
+
+	for i := 0; i < 800; i++ {
+		v, found := cache.Get(i)
+		assert.Equal(t, i < 400, found)
+		if found {
+			assert.Equal(t, i, v)
+		}
+	}
+
+	var entries, hits, misses uint64
+	for _, s := range cache.ShardStats() {
+		// Each shard should have a reasonable share of the keys.
+		assert.Greater(t, s.EntryCount, uint64(50))
+		assert.InDelta(t, 0.5, s.HitRatio(), 0.2)
+		entries += s.EntryCount
+		hits += s.Hits
+		misses += s.Misses
+	}
+	assert.Equal(t, uint64(400), entries)
+	assert.Equal(t, uint64(400), hits)
+	assert.Equal(t, uint64(400), misses)
+	assert.Less(t, cache.Skew(), 1.5)
+
+	// Reading one key repeatedly skews the reads to its shard.
+	for i := 0; i < 10000; i++ {
+		cache.Get(0)
+	}
+	assert.Greater(t, cache.Skew(), 3.0)
+
+	cache.Delete(0)
+	assert.False(t, cache.Contains(0))
+	assert.False(t, cache.Shard(0).Contains(0))
+}
+
+func TestShardedCache_SeededHashing(t *testing.T) {
+	// Checks each ShardedCache places keys with its own seed, so the same keys are spread differently, and the
+	// placement can't be predicted to target one shard.
+
+	a := NewShardedCache[string, int](64, 1024)
+	defer a.Close()
+	b := NewShardedCache[string, int](64, 1024)
+	defer b.Close()
+
+	same := 0
+	for i := 0; i < 100; i++ {
+		k := fmt.Sprintf("/users/%d", i)
+		if a.shardIndex(k) == b.shardIndex(k) {
+			same++
+		}
+	}
+	assert.Less(t, same, 50)
+}
@@ -0,0 +1,244 @@
+package lrucache
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/maphash"
+	"sync"
+	"time"
+)
+
+// slabHeaderSize is the size of the header written before each record in a SlabCache's buffer: the key and value
+// lengths, the key's hash, and the expiry as Unix nanoseconds, zero for none.
+const slabHeaderSize = 4 + 4 + 8 + 8
+
+// SlabCache is a cache of string keys to byte slice values, for holding millions of entries without burdening the
+// garbage collector. Rather than a node per entry, every entry is copied into a single preallocated buffer, and
+// indexed by a map holding no pointers, so there's nothing for the GC to scan.
+//
+// The buffer is used as a ring: new entries are appended, overwriting the oldest once it's full. Entries read
+// from the older half of the ring are moved back to the front, so access order is approximately LRU, as for Cache.
+// Space from replaced and deleted entries is reclaimed as the ring passes over it. The capacity is in bytes,
+// including a small header per entry.
+//
+// SlabCache supports the subset of Cache's API in Cacher, so can be used wherever a Cacher[string, []byte] is
+// accepted, along with Capacity, Size and EntryCount. Features relying on per-entry state or callbacks, such as
+// sizes, tags, metadata, eviction callbacks, loaders, statistics and snapshots, aren't supported.
+type SlabCache struct {
+	lock sync.Mutex
+	seed maphash.Seed
+
+	buf   []byte
+	index map[uint64]uint64 // Key hash to the absolute offset of its record.
+	head  uint64            // Absolute offset at which the next record is written.
+	tail  uint64            // Absolute offset of the oldest record still in the buffer.
+	live  uint64            // Total size of the records in the index.
+}
+
+// NewSlabCache creates a new SlabCache with a buffer of capacity bytes.
+func NewSlabCache(capacity uint64) *SlabCache {
+	return &SlabCache{
+		seed:  maphash.MakeSeed(),
+		buf:   make([]byte, capacity),
+		index: make(map[uint64]uint64),
+	}
+}
+
+// Capacity returns the size of the cache's buffer, in bytes.
+func (c *SlabCache) Capacity() uint64 {
+	return uint64(len(c.buf))
+}
+
+// Size returns the total size of the entries in the cache, including their headers.
+func (c *SlabCache) Size() uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.live
+}
+
+// EntryCount returns the number of entries in the cache, including any that have expired but not yet been removed.
+func (c *SlabCache) EntryCount() uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return uint64(len(c.index))
+}
+
+// Close exists for parity with Cache; a SlabCache has no background work, so doesn't need to be closed.
+func (c *SlabCache) Close() {}
+
+// Set adds a key-value pair to the cache with no expiry. The value is copied.
+func (c *SlabCache) Set(k string, v []byte) error {
+	return c.SetWithExpiry(k, v, time.Time{})
+}
+
+// SetWithExpiry adds a key-value pair to the cache, expiring at the given time; the zero value means no expiry.
+// The value is copied. If the entry, with its header, is larger than the capacity, ErrItemTooBig is returned.
+func (c *SlabCache) SetWithExpiry(k string, v []byte, expires time.Time) error {
+	size := uint64(slabHeaderSize + len(k) + len(v))
+	if size > uint64(len(c.buf)) {
+		return fmt.Errorf("%w: item size = %d. cache capacity = %d", ErrItemTooBig, size, len(c.buf))
+	}
+	if !expires.IsZero() && expires.Before(time.Now()) {
+		return fmt.Errorf("%w. expires is set to %s, but the current time is %s", ErrPastExpiry, expires.Format(DateTime), time.Now().Format(DateTime))
+	}
+
+	var nanos int64
+	if !expires.IsZero() {
+		nanos = expires.UnixNano()
+	}
+
+	h := maphash.String(c.seed, k)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.remove(h)
+	c.append(h, nanos, []byte(k), v)
+	return nil
+}
+
+// Get returns a copy of the value for k. found is false if k isn't in the cache, or has expired.
+func (c *SlabCache) Get(k string) (v []byte, found bool) {
+	h := maphash.String(c.seed, k)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	off, found := c.lookup(h, k)
+	if !found {
+		return nil, false
+	}
+	keyLen, valLen, _, _ := c.header(off)
+	v = make([]byte, valLen)
+	c.read(v, off+slabHeaderSize+uint64(keyLen))
+
+	// Entries in the older half of the ring would soon be overwritten, so are moved to the front.
+	if c.head-off > uint64(len(c.buf))/2 {
+		_, _, _, nanos := c.header(off)
+		key := make([]byte, keyLen)
+		c.read(key, off+slabHeaderSize)
+		c.remove(h)
+		c.append(h, nanos, key, v)
+	}
+
+	return v, true
+}
+
+// Contains reports whether an unexpired entry exists for k, without affecting its position.
+func (c *SlabCache) Contains(k string) bool {
+	h := maphash.String(c.seed, k)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	_, found := c.lookup(h, k)
+	return found
+}
+
+// Delete removes the entry for k, if it exists.
+func (c *SlabCache) Delete(k string) {
+	h := maphash.String(c.seed, k)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if _, found := c.lookup(h, k); found {
+		c.remove(h)
+	}
+}
+
+// lookup returns the offset of the unexpired record for k, removing it if it has expired.
+// Assumes the lock is held.
+func (c *SlabCache) lookup(h uint64, k string) (uint64, bool) {
+	off, found := c.index[h]
+	if !found {
+		return 0, false
+	}
+
+	keyLen, _, _, nanos := c.header(off)
+	if int(keyLen) != len(k) {
+		return 0, false
+	}
+	// Compared a byte at a time, so k isn't converted to a byte slice.
+	for i := 0; i < len(k); i++ {
+		if c.buf[(off+slabHeaderSize+uint64(i))%uint64(len(c.buf))] != k[i] {
+			return 0, false
+		}
+	}
+
+	if nanos != 0 && nanos < time.Now().UnixNano() {
+		c.remove(h)
+		return 0, false
+	}
+	return off, true
+}
+
+// remove drops the record for h from the index. Its space is reclaimed once the ring passes over it.
+// Assumes the lock is held.
+func (c *SlabCache) remove(h uint64) {
+	off, found := c.index[h]
+	if !found {
+		return
+	}
+	c.live -= c.recordSize(off)
+	delete(c.index, h)
+}
+
+// append writes a record at the head of the ring, first overwriting the oldest records to make space.
+// Assumes the lock is held, and that the record fits within the buffer.
+func (c *SlabCache) append(h uint64, nanos int64, key, value []byte) {
+	size := uint64(slabHeaderSize + len(key) + len(value))
+
+	for c.head+size-c.tail > uint64(len(c.buf)) {
+		oldest := c.recordSize(c.tail)
+		_, _, oh, _ := c.header(c.tail)
+		if off, found := c.index[oh]; found && off == c.tail {
+			c.live -= oldest
+			delete(c.index, oh)
+		}
+		c.tail += oldest
+	}
+
+	var header [slabHeaderSize]byte
+	binary.LittleEndian.PutUint32(header[0:], uint32(len(key)))
+	binary.LittleEndian.PutUint32(header[4:], uint32(len(value)))
+	binary.LittleEndian.PutUint64(header[8:], h)
+	binary.LittleEndian.PutUint64(header[16:], uint64(nanos))
+
+	off := c.head
+	c.write(off, header[:])
+	c.write(off+slabHeaderSize, key)
+	c.write(off+slabHeaderSize+uint64(len(key)), value)
+
+	c.index[h] = off
+	c.live += size
+	c.head += size
+}
+
+// header decodes the header of the record at off.
+func (c *SlabCache) header(off uint64) (keyLen, valLen uint32, h uint64, nanos int64) {
+	var header [slabHeaderSize]byte
+	c.read(header[:], off)
+	return binary.LittleEndian.Uint32(header[0:]), binary.LittleEndian.Uint32(header[4:]),
+		binary.LittleEndian.Uint64(header[8:]), int64(binary.LittleEndian.Uint64(header[16:]))
+}
+
+// recordSize returns the total size of the record at off.
+func (c *SlabCache) recordSize(off uint64) uint64 {
+	keyLen, valLen, _, _ := c.header(off)
+	return slabHeaderSize + uint64(keyLen) + uint64(valLen)
+}
+
+// write copies b into the ring at the absolute offset off, wrapping around the end of the buffer.
+func (c *SlabCache) write(off uint64, b []byte) {
+	start := off % uint64(len(c.buf))
+	n := copy(c.buf[start:], b)
+	copy(c.buf, b[n:])
+}
+
+// read copies from the ring at the absolute offset off into b, wrapping around the end of the buffer.
+func (c *SlabCache) read(b []byte, off uint64) {
+	start := off % uint64(len(c.buf))
+	n := copy(b, c.buf[start:])
+	copy(b[n:], c.buf)
+}
@@ -0,0 +1,83 @@
+package lrucache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlabCache_SetAndGet(t *testing.T) {
+	// Checks that values are stored, replaced, copied and deleted.
+
+	cache := NewSlabCache(1024)
+	defer cache.Close()
+
+	v := []byte("value-a")
+	assert.NoError(t, cache.Set("a", v))
+	v[0] = 'X'
+
+	got, found := cache.Get("a")
+	assert.True(t, found)
+	assert.Equal(t, []byte("value-a"), got)
+
+	assert.NoError(t, cache.Set("a", []byte("replaced")))
+	got, _ = cache.Get("a")
+	assert.Equal(t, []byte("replaced"), got)
+	assert.Equal(t, uint64(1), cache.EntryCount())
+	assert.Equal(t, uint64(slabHeaderSize+1+8), cache.Size())
+
+	cache.Delete("a")
+	assert.False(t, cache.Contains("a"))
+	assert.Equal(t, uint64(0), cache.Size())
+
+	assert.ErrorIs(t, cache.Set("big", make([]byte, 1024)), ErrItemTooBig)
+
+	assert.NoError(t, cache.SetWithExpiry("b", []byte("b"), time.Now().Add(time.Millisecond)))
+	time.Sleep(5 * time.Millisecond)
+	_, found = cache.Get("b")
+	assert.False(t, found)
+	assert.Equal(t, uint64(0), cache.EntryCount())
+}
+
+func TestSlabCache_Wraparound(t *testing.T) {
+	// Checks that the oldest entries are overwritten as the ring wraps, records spanning its end are intact, and
+	// recently read entries survive.
+
+	// Each record is 24 + 6 + 10 = 40 bytes, so 10 fit.
+	cache := NewSlabCache(10*40 + 15)
+
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, cache.Set(fmt.Sprintf("key-%02d", i), []byte(fmt.Sprintf("value-%04d", i))))
+	}
+	assert.Equal(t, uint64(10), cache.EntryCount())
+
+	// Reading key-00, in the older half, moves it to the front, reusing its own space. The next entry then
+	// overwrites key-01, rather than key-00.
+	got, found := cache.Get("key-00")
+	assert.True(t, found)
+	assert.Equal(t, []byte("value-0000"), got)
+	assert.True(t, cache.Contains("key-01"))
+
+	assert.NoError(t, cache.Set("key-10", []byte("value-0010")))
+	assert.False(t, cache.Contains("key-01"))
+	assert.True(t, cache.Contains("key-00"))
+
+	for i := 11; i < 100; i++ {
+		assert.NoError(t, cache.Set(fmt.Sprintf("key-%02d", i), []byte(fmt.Sprintf("value-%04d", i))))
+		if i%3 == 0 {
+			cache.Get("key-00")
+		}
+	}
+
+	// The space not taken by key-00, and its older copies, holds the most recent entries.
+	for i := 92; i < 100; i++ {
+		assert.True(t, cache.Contains(fmt.Sprintf("key-%02d", i)), i)
+	}
+	got, _ = cache.Get("key-99")
+	assert.Equal(t, []byte("value-0099"), got)
+	assert.True(t, cache.Contains("key-00"))
+	assert.False(t, cache.Contains("key-50"))
+	assert.LessOrEqual(t, cache.Size(), cache.Capacity())
+}
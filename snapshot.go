@@ -0,0 +1,131 @@
+package lrucache
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"time"
+)
+
+// snapshotVersion is written at the start of every snapshot, allowing the format to change in future.
+const snapshotVersion = 1
+
+// snapshotEntry is the serialised form of a single cache entry.
+type snapshotEntry[K comparable, V any] struct {
+	Key      K
+	Value    V
+	Size     uint64
+	Expires  time.Time
+	Metadata any
+}
+
+// snapshot is the serialised form of the cache.
+// Entries are ordered from the most recently used to the least recently used.
+type snapshot[K comparable, V any] struct {
+	Version int
+	Saved   time.Time // When the snapshot was taken; zero in snapshots from before it was recorded.
+	Entries []snapshotEntry[K, V]
+}
+
+// LoadOption configures how LoadFrom restores a snapshot.
+type LoadOption func(*loadOptions)
+
+// loadOptions holds the configuration built up from the LoadOptions passed to LoadFrom.
+type loadOptions struct {
+	grace    time.Duration
+	relative bool
+}
+
+// WithExpiredGrace loads entries that have expired since the snapshot was taken, rather than dropping them, with
+// an expiry of grace from now. This lets a restarted process serve slightly stale values while it refreshes them.
+func WithExpiredGrace(grace time.Duration) LoadOption {
+	return func(lo *loadOptions) {
+		lo.grace = grace
+	}
+}
+
+// WithRelativeExpiries moves every expiry later by the time since the snapshot was taken, so entries keep the TTL
+// they had remaining when it was saved, and time spent down doesn't count against them. Without it, expiries are
+// absolute. It has no effect on snapshots that don't record when they were taken.
+func WithRelativeExpiries() LoadOption {
+	return func(lo *loadOptions) {
+		lo.relative = true
+	}
+}
+
+// SaveTo writes all live entries in the cache, including their sizes, expiries and LRU order, to w using gob.
+// Entries that have expired, or been invalidated by BumpEpoch or BumpTagEpoch, aren't written.
+// K and V must be encodable by encoding/gob, and the concrete types of any entry metadata must be registered
+// with gob.Register.
+func (lru *Cache[K, V]) SaveTo(w io.Writer) error {
+	now := time.Now()
+	s := snapshot[K, V]{Version: snapshotVersion, Saved: now}
+
+	lru.writeLock(OperationOther)
+	if lru.stopped {
+		lru.lock.Unlock()
+		return ErrCacheClosed
+	}
+	lru.runOnEventLoop(func() {
+		s.Entries = make([]snapshotEntry[K, V], 0, len(lru.cache))
+		for n := lru.head.next; n != lru.tail && n != nil; n = n.next {
+			if n.negative || lru.isExpired(n, now) {
+				continue
+			}
+			s.Entries = append(s.Entries, snapshotEntry[K, V]{
+				Key:      n.key,
+				Value:    n.value,
+				Size:     n.size,
+				Expires:  n.expires,
+				Metadata: n.metadata,
+			})
+		}
+	})
+	lru.lock.Unlock()
+
+	// Encode outside the lock, so slow writers don't block the cache.
+	return gob.NewEncoder(w).Encode(s)
+}
+
+// LoadFrom reads a snapshot written by SaveTo and adds its entries to the cache, restoring their LRU order and
+// expiries. Entries that have expired since the snapshot was taken are skipped, unless WithExpiredGrace is given.
+// Existing entries with the same key are replaced.
+func (lru *Cache[K, V]) LoadFrom(r io.Reader, opts ...LoadOption) error {
+	var lo loadOptions
+	for _, opt := range opts {
+		opt(&lo)
+	}
+
+	var s snapshot[K, V]
+	if err := gob.NewDecoder(r).Decode(&s); err != nil {
+		return fmt.Errorf("unable to decode snapshot: %w", err)
+	}
+
+	if s.Version != snapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d", s.Version)
+	}
+
+	now := time.Now()
+
+	var shift time.Duration
+	if lo.relative && !s.Saved.IsZero() {
+		shift = max(now.Sub(s.Saved), 0)
+	}
+
+	entries := make([]Entry[K, V], 0, len(s.Entries))
+	for _, e := range s.Entries {
+		if !e.Expires.IsZero() {
+			e.Expires = e.Expires.Add(shift)
+		}
+		if !e.Expires.IsZero() && e.Expires.Before(now) {
+			if lo.grace <= 0 {
+				continue
+			}
+			e.Expires = now.Add(lo.grace)
+		}
+		entries = append(entries, Entry[K, V]{Key: e.Key, Value: e.Value, Size: e.Size, Expires: e.Expires, Metadata: e.Metadata})
+	}
+
+	// Entries are already ordered from the most to the least recently used.
+	return lru.Warm(entries)
+}
@@ -0,0 +1,134 @@
+package lrucache
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_SaveAndLoadSnapshot(t *testing.T) {
+	// Round-trips a cache through SaveTo and LoadFrom, checking values, sizes, expiries and LRU order survive,
+	// and that entries which expire before loading are skipped.
+
+	cache := NewCache[int, string](10)
+	defer cache.Close()
+
+	expires := time.Now().Add(time.Hour)
+	require.NoError(t, cache.SetWithSizeAndExpiry(1, "value-1", 2, expires))
+	require.NoError(t, cache.Set(2, "value-2"))
+	require.NoError(t, cache.SetWithExpiry(3, "value-3", time.Now().Add(50*time.Millisecond)))
+	require.NoError(t, cache.Set(4, "value-4"))
+
+	// Makes 1 the most recently used.
+	cache.Get(1)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, cache.SaveTo(buf))
+
+	time.Sleep(100 * time.Millisecond)
+
+	restored := NewCache[int, string](10)
+	defer restored.Close()
+	require.NoError(t, restored.LoadFrom(buf))
+
+	assert.Equal(t, uint64(3), restored.EntryCount())
+	assert.Equal(t, uint64(4), restored.Size())
+
+	_, found := restored.Get(3)
+	assert.False(t, found)
+
+	n := restored.cache[1]
+	assert.Equal(t, "value-1", n.value)
+	assert.Equal(t, uint64(2), n.size)
+	assert.True(t, expires.Equal(n.expires))
+
+	// Expect the order, most recent first, to be 1, 4, 2.
+	restored.runOnEventLoop(func() {
+		head := restored.head
+		for _, k := range []int{1, 4, 2} {
+			head = head.next
+			assert.Equal(t, k, head.key)
+		}
+	})
+}
+
+func TestCache_LoadSnapshotInvalid(t *testing.T) {
+	cache := NewCache[int, string](10)
+	defer cache.Close()
+
+	err := cache.LoadFrom(bytes.NewBufferString(fmt.Sprintf("not a snapshot %d", 1)))
+	assert.Error(t, err)
+}
+
+func TestCache_LoadSnapshotExpiryPolicies(t *testing.T) {
+	// Checks that expired entries can be loaded with a grace TTL, and expiries shifted by the time since saving.
+
+	cache := NewCache[int, string](10)
+	defer cache.Close()
+
+	require.NoError(t, cache.SetWithExpiry(1, "short", time.Now().Add(20*time.Millisecond)))
+	require.NoError(t, cache.SetWithExpiry(2, "long", time.Now().Add(time.Hour)))
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, cache.SaveTo(buf))
+	snapshot := buf.Bytes()
+
+	time.Sleep(50 * time.Millisecond)
+
+	graced := NewCache[int, string](10)
+	defer graced.Close()
+	require.NoError(t, graced.LoadFrom(bytes.NewReader(snapshot), WithExpiredGrace(time.Minute)))
+
+	e, found := graced.Entry(1)
+	assert.True(t, found)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), e.Expires, time.Second)
+
+	relative := NewCache[int, string](10)
+	defer relative.Close()
+	require.NoError(t, relative.LoadFrom(bytes.NewReader(snapshot), WithRelativeExpiries()))
+
+	e, found = relative.Entry(1)
+	assert.True(t, found)
+	assert.WithinDuration(t, time.Now().Add(20*time.Millisecond), e.Expires, 10*time.Millisecond)
+
+	e, _ = relative.Entry(2)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), e.Expires, 10*time.Millisecond)
+}
+
+func TestCache_SaveSnapshotSkipsInvalidated(t *testing.T) {
+	// Checks entries invalidated by an epoch bump aren't saved, so aren't revived by loading the snapshot.
+
+	cache := NewCache[string, int](10)
+	defer cache.Close()
+
+	require.NoError(t, cache.SetWithOptions("a", 1, WithTags("x")))
+	require.NoError(t, cache.Set("b", 2))
+	cache.BumpTagEpoch("x")
+	require.NoError(t, cache.Set("c", 3))
+	cache.BumpEpoch()
+	require.NoError(t, cache.Set("d", 4))
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, cache.SaveTo(buf))
+
+	restored := NewCache[string, int](10)
+	defer restored.Close()
+	require.NoError(t, restored.LoadFrom(buf))
+
+	assert.Equal(t, uint64(1), restored.EntryCount())
+	assert.False(t, restored.Contains("a"))
+	assert.False(t, restored.Contains("b"))
+	assert.False(t, restored.Contains("c"))
+	assert.True(t, restored.Contains("d"))
+
+	// Loading back into the same cache doesn't revive them either.
+	buf.Reset()
+	require.NoError(t, cache.SaveTo(buf))
+	require.NoError(t, cache.LoadFrom(buf))
+	_, found := cache.Get("a")
+	assert.False(t, found)
+}
@@ -0,0 +1,68 @@
+package lrucache
+
+import (
+	"log/slog"
+	"time"
+)
+
+// softLimit returns the size the background eviction of WithSoftCapacity keeps the cache within, or zero if it's
+// not enabled. Assumes the lock is already acquired.
+func (lru *Cache[K, V]) softLimit() uint64 {
+	if lru.opts.softCapacity == 0 {
+		return 0
+	}
+	return min(lru.opts.softCapacity, lru.limit)
+}
+
+// signalShrink wakes the background eviction, if it isn't already due to run.
+func (lru *Cache[K, V]) signalShrink() {
+	select {
+	case lru.shrink <- struct{}{}:
+	default:
+	}
+}
+
+// shrinkInBackground evicts from the tail down to the soft capacity whenever signalled, until the cache is closed.
+// With WithEvictionBatch, the lock is released between batches.
+func (lru *Cache[K, V]) shrinkInBackground() {
+	for {
+		select {
+		case <-lru.done:
+			return
+		case <-lru.shrink:
+		}
+
+		for done := false; !done; {
+			lru.writeLock(OperationOther)
+			if lru.stopped {
+				lru.lock.Unlock()
+				return
+			}
+			evicted := 0
+			lru.runOnEventLoop(func() {
+				target := lru.softLimit()
+				now := time.Now()
+				for lru.size > target {
+					if batch := lru.opts.evictionBatch; batch > 0 && evicted == batch {
+						return
+					}
+					n := lru.victim(now)
+					if n == nil {
+						break
+					}
+					lru.removeNode(n, EvictionReasonCapacity)
+					evicted++
+				}
+				done = true
+			})
+			removed := lru.takeRemovals()
+			lru.lock.Unlock()
+
+			lru.unattended(func() { lru.notifyRemovals(removed) })
+
+			if evicted > 0 {
+				lru.log(slog.LevelDebug, "lrucache: evicted entries down to the soft capacity", "evicted", evicted)
+			}
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package lrucache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_SoftCapacity(t *testing.T) {
+	// Checks sets above the soft capacity succeed without eviction, and the cache is then shrunk in the background.
+
+	cache := NewCacheWithOptions[int, int](10, WithSoftCapacity(5))
+	defer cache.Close()
+
+	require.NoError(t, cache.SetWithSize(0, 0, 4))
+	require.NoError(t, cache.SetWithSize(1, 1, 4))
+	assert.True(t, cache.Contains(0))
+
+	assert.Eventually(t, func() bool {
+		return cache.Size() == 4
+	}, time.Second, time.Millisecond)
+	assert.False(t, cache.Contains(0))
+	assert.True(t, cache.Contains(1))
+
+	// The capacity is still enforced synchronously.
+	require.NoError(t, cache.SetWithSize(2, 2, 4))
+	require.NoError(t, cache.SetWithSize(3, 3, 4))
+	assert.LessOrEqual(t, cache.Size(), uint64(10))
+	assert.True(t, cache.Contains(3))
+
+	assert.Eventually(t, func() bool {
+		return cache.Size() <= 5
+	}, time.Second, time.Millisecond)
+}
+
+func TestCache_SoftCapacityBatches(t *testing.T) {
+	// Checks the background eviction completes when it's done in batches.
+
+	cache := NewCacheWithOptions[int, int](100, WithSoftCapacity(10), WithEvictionBatch(3, true))
+	defer cache.Close()
+
+	for i := 0; i < 100; i++ {
+		require.NoError(t, cache.Set(i, i))
+	}
+
+	assert.Eventually(t, func() bool {
+		return cache.Size() <= 10
+	}, time.Second, time.Millisecond)
+	assert.True(t, cache.Contains(99))
+}
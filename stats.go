@@ -0,0 +1,250 @@
+package lrucache
+
+import (
+	"context"
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+// Operation identifies a type of cache operation, for per-operation statistics.
+type Operation uint8
+
+const (
+	OperationGet    Operation = iota // Reads: Get, Contains, etc.
+	OperationSet                     // Writes: Set, Warm, etc.
+	OperationDelete                  // Deletes.
+	OperationPurge                   // Purging expired entries.
+	OperationOther                   // Everything else, e.g. Stats, Sync and SaveTo.
+
+	operationCount // The number of operation types; must be last.
+)
+
+// String returns a human-readable name for the operation.
+func (o Operation) String() string {
+	switch o {
+	case OperationGet:
+		return "get"
+	case OperationSet:
+		return "set"
+	case OperationDelete:
+		return "delete"
+	case OperationPurge:
+		return "purge"
+	default:
+		return "other"
+	}
+}
+
+// Stats is a point-in-time summary of the cache's state and behaviour.
+type Stats struct {
+	Capacity uint64
+
+	// Limit is the capacity currently in effect. It's less than Capacity under WithMemoryPressure, and with
+	// WithAdaptiveCapacity it's the limit most recently chosen to hold the target hit ratio, so changes over time.
+	Limit uint64
+
+	Size       uint64
+	EntryCount uint64
+
+	// LockWait holds the time spent waiting to acquire the cache's lock, indexed by Operation.
+	// Only populated when the cache was created WithLockContentionStats.
+	LockWait [operationCount]LockWaitStats
+
+	// HitPositions counts hits by their approximate position in the list when they were hit: HitPositions[0] counts
+	// hits in the most recently used tenth of the list, HitPositions[9] the least recently used tenth. A large share
+	// of hits near the tail suggests the capacity is barely sufficient. Only populated when the cache was created
+	// WithHitPositionStats.
+	HitPositions [HitPositionBuckets]uint64
+
+	// DoorkeeperRejections counts the new keys that weren't stored, as it was their first sighting. Only populated
+	// when the cache was created WithDoorkeeper.
+	DoorkeeperRejections uint64
+
+	// SizeCounts counts the entries by size, in powers of two: SizeCounts[i] counts those with a size from 2^i to
+	// 2^(i+1)-1. A few large entries crowding out many small ones is visible here; see WithMaxEntrySize.
+	SizeCounts [SizeBuckets]uint64
+
+	// EventQueue reports on the buffer of promotions waiting to be applied to the list, which shows how far the
+	// order of the list lags behind reads.
+	EventQueue EventQueueStats
+
+	// Shadows reports the hit rate the cache would have had at other capacities. Only populated when the cache was
+	// created WithShadowCapacities.
+	Shadows []ShadowStats
+
+	// Purge summarises the passes removing expired entries.
+	Purge PurgeStats
+
+	// Latency holds the distributions of the time taken by reads, writes, eviction and purge passes. Only populated
+	// when the cache was created WithLatencyStats.
+	Latency LatencyStats
+}
+
+// EventQueueStats reports on the cache's buffer of promotions; see WithBuffer.
+type EventQueueStats struct {
+	Length    uint64 // Promotions currently waiting to be applied.
+	Capacity  uint64 // The size of the buffer. Reads wait for space once Length reaches it.
+	HighWater uint64 // The most promotions that have been waiting at once.
+	Dropped   uint64 // Promotions skipped, under OverflowDrop or as their context was done while waiting for space.
+	Coalesced uint64 // Promotions merged with one already set aside for the same entry, under OverflowCoalesce.
+}
+
+// SizeBuckets is the number of buckets in Stats.SizeCounts.
+const SizeBuckets = 64
+
+// HitPositionBuckets is the number of buckets in Stats.HitPositions.
+const HitPositionBuckets = 10
+
+// LockWaitStats summarises the time spent waiting to acquire the cache's lock, for one type of operation.
+type LockWaitStats struct {
+	Acquisitions uint64        // Number of times the lock was acquired.
+	Total        time.Duration // Total time spent waiting.
+	Max          time.Duration // Longest single wait.
+}
+
+// Average returns the mean time spent waiting per acquisition.
+func (s LockWaitStats) Average() time.Duration {
+	if s.Acquisitions == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Acquisitions)
+}
+
+// Stats returns a summary of the cache's current state.
+func (lru *Cache[K, V]) Stats() Stats {
+	lru.readLock(OperationOther)
+	s := Stats{
+		Capacity:   lru.capacity,
+		Limit:      lru.limit,
+		Size:       lru.size,
+		EntryCount: uint64(len(lru.cache)),
+		SizeCounts: lru.sizeCounts,
+	}
+	lru.lock.RUnlock()
+
+	queued := lru.queued.Load()
+	s.EventQueue = EventQueueStats{
+		Length:    queued - min(lru.handled.Load(), queued),
+		Capacity:  uint64(lru.opts.buffer),
+		HighWater: lru.maxQueue.Load(),
+		Dropped:   lru.dropped.Load(),
+		Coalesced: lru.coalesced.Load(),
+	}
+
+	if lru.lockWait != nil {
+		for i := range lru.lockWait {
+			s.LockWait[i] = lru.lockWait[i].snapshot()
+		}
+	}
+
+	if lru.hitPositions != nil {
+		for i := range lru.hitPositions {
+			s.HitPositions[i] = lru.hitPositions[i].Load()
+		}
+	}
+
+	if lru.doorkeeper != nil {
+		s.DoorkeeperRejections = lru.doorkeeper.rejected.Load()
+	}
+
+	s.Shadows = lru.shadowStats()
+	s.Purge = lru.purgeStats.snapshot()
+
+	if l := lru.latency; l != nil {
+		s.Latency = LatencyStats{Get: l.get.snapshot(), Set: l.set.snapshot(), Evict: l.evict.snapshot(), Purge: l.purge.snapshot()}
+	}
+
+	return s
+}
+
+//---
+
+// lockWaitCounter accumulates LockWaitStats using atomics, so recording doesn't itself contend.
+type lockWaitCounter struct {
+	acquisitions atomic.Uint64
+	total        atomic.Int64
+	max          atomic.Int64
+}
+
+func (c *lockWaitCounter) record(wait time.Duration) {
+	c.acquisitions.Add(1)
+	c.total.Add(int64(wait))
+	for {
+		m := c.max.Load()
+		if int64(wait) <= m || c.max.CompareAndSwap(m, int64(wait)) {
+			return
+		}
+	}
+}
+
+func (c *lockWaitCounter) snapshot() LockWaitStats {
+	return LockWaitStats{
+		Acquisitions: c.acquisitions.Load(),
+		Total:        time.Duration(c.total.Load()),
+		Max:          time.Duration(c.max.Load()),
+	}
+}
+
+// writeLock acquires the write lock, recording the time spent waiting if lock contention stats are enabled.
+func (lru *Cache[K, V]) writeLock(op Operation) {
+	if lru.lockWait == nil {
+		lru.lock.Lock()
+		return
+	}
+	start := time.Now()
+	lru.lock.Lock()
+	lru.lockWait[op].record(time.Since(start))
+}
+
+// readLock acquires the read lock, recording the time spent waiting if lock contention stats are enabled.
+func (lru *Cache[K, V]) readLock(op Operation) {
+	if lru.lockWait == nil {
+		lru.lock.RLock()
+		return
+	}
+	start := time.Now()
+	lru.lock.RLock()
+	lru.lockWait[op].record(time.Since(start))
+}
+
+// writeLockCtx acquires the write lock as writeLock does, unless ctx is done first.
+func (lru *Cache[K, V]) writeLockCtx(ctx context.Context, op Operation) error {
+	start := time.Now()
+	if err := lru.lock.LockContext(ctx); err != nil {
+		return err
+	}
+	if lru.lockWait != nil {
+		lru.lockWait[op].record(time.Since(start))
+	}
+	return nil
+}
+
+// readLockCtx acquires the read lock as readLock does, unless ctx is done first.
+func (lru *Cache[K, V]) readLockCtx(ctx context.Context, op Operation) error {
+	start := time.Now()
+	if err := lru.lock.RLockContext(ctx); err != nil {
+		return err
+	}
+	if lru.lockWait != nil {
+		lru.lockWait[op].record(time.Since(start))
+	}
+	return nil
+}
+
+// sizeBucket returns the index in Stats.SizeCounts for an entry of the given size, which must not be zero.
+func sizeBucket(size uint64) int {
+	return bits.Len64(size) - 1
+}
+
+// recordHitPosition records the approximate position of n in the list, for Stats.HitPositions, if enabled.
+// The position is estimated from the number of promotions since n was last promoted, relative to the length of the
+// list; as some of those promotions may have been of the same entries, it may overestimate how far back n is.
+// Called holding the list lock, before n is moved to the front.
+func (lru *Cache[K, V]) recordHitPosition(n *node[K, V]) {
+	if lru.hitPositions == nil || n.previous == nil || lru.length == 0 {
+		return
+	}
+	bucket := min((lru.sequence.Load()-n.sequence.Load())*HitPositionBuckets/uint64(lru.length), HitPositionBuckets-1)
+	lru.hitPositions[bucket].Add(1)
+}
@@ -0,0 +1,184 @@
+package lrucache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_StatsLockWait(t *testing.T) {
+	// Checks that lock acquisitions are counted per operation when lock contention stats are enabled.
+
+	cache := NewCacheWithOptions[int, int](100, WithLockContentionStats())
+	defer cache.Close()
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, cache.Set(i, i))
+			cache.Get(i)
+			cache.Delete(i)
+		}()
+	}
+	wg.Wait()
+
+	s := cache.Stats()
+	assert.Equal(t, uint64(100), s.Capacity)
+	assert.Equal(t, uint64(0), s.EntryCount)
+	assert.Equal(t, uint64(10), s.LockWait[OperationSet].Acquisitions)
+	assert.Equal(t, uint64(10), s.LockWait[OperationGet].Acquisitions)
+	assert.Equal(t, uint64(10), s.LockWait[OperationDelete].Acquisitions)
+	assert.GreaterOrEqual(t, s.LockWait[OperationSet].Max, s.LockWait[OperationSet].Average())
+
+	// Without the option, nothing is recorded.
+	plain := NewCache[int, int](100)
+	defer plain.Close()
+	require.NoError(t, plain.Set(1, 1))
+	assert.Equal(t, uint64(0), plain.Stats().LockWait[OperationSet].Acquisitions)
+	assert.Equal(t, uint64(1), plain.Stats().Size)
+}
+
+func TestCache_HitPositionStats(t *testing.T) {
+	// Checks hits on recently used entries land near the front of the histogram, and old ones near the tail.
+
+	cache := NewCacheWithOptions[int, int](10, WithHitPositionStats())
+	defer cache.Close()
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, cache.Set(i, i))
+	}
+
+	// 9 is the most recently used, 0 the least.
+	cache.Get(9)
+	cache.Get(0)
+
+	// Wait for the promotions to be processed.
+	cache.runOnEventLoop(func() {})
+
+	positions := cache.Stats().HitPositions
+	assert.Equal(t, uint64(1), positions[0])
+	assert.Equal(t, uint64(1), positions[HitPositionBuckets-1])
+
+	var total uint64
+	for _, n := range positions {
+		total += n
+	}
+	assert.Equal(t, uint64(2), total)
+
+	// Without the option, nothing is collected.
+	plain := NewCache[int, int](10)
+	defer plain.Close()
+	require.NoError(t, plain.Set(1, 1))
+	plain.Get(1)
+	assert.Equal(t, [HitPositionBuckets]uint64{}, plain.Stats().HitPositions)
+}
+
+func TestCache_SizeCounts(t *testing.T) {
+	// Checks that entries are counted by size, in powers of two, as they're added and removed.
+
+	cache := NewCache[int, string](100)
+	defer cache.Close()
+
+	assert.NoError(t, cache.SetWithSize(1, "a", 1))
+	assert.NoError(t, cache.SetWithSize(2, "b", 2))
+	assert.NoError(t, cache.SetWithSize(3, "c", 3))
+	assert.NoError(t, cache.SetWithSize(4, "d", 64))
+
+	s := cache.Stats()
+	assert.Equal(t, uint64(1), s.SizeCounts[0])
+	assert.Equal(t, uint64(2), s.SizeCounts[1])
+	assert.Equal(t, uint64(1), s.SizeCounts[6])
+
+	cache.Delete(2)
+	assert.NoError(t, cache.SetWithSize(4, "d", 4))
+
+	s = cache.Stats()
+	assert.Equal(t, uint64(1), s.SizeCounts[1])
+	assert.Equal(t, uint64(1), s.SizeCounts[2])
+	assert.Equal(t, uint64(0), s.SizeCounts[6])
+}
+
+func TestCache_EventQueueStats(t *testing.T) {
+	cache := NewCacheWithOptions[int, int](10, WithBuffer(2))
+	defer cache.Close()
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, cache.Set(i, i))
+	}
+
+	// Holding the list lock stops the promotions being applied, so they back up in the buffer.
+	cache.list.Lock()
+	for i := 0; i < 3; i++ {
+		cache.Get(i)
+	}
+	assert.Eventually(t, func() bool { return len(cache.events) == 2 }, time.Second, time.Millisecond)
+
+	// The buffer is full, so a read whose context is done skips its promotion.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, found, err := cache.GetCtx(ctx, 0)
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	s := cache.Stats().EventQueue
+	assert.Equal(t, EventQueueStats{Length: 3, Capacity: 2, HighWater: 3, Dropped: 1}, s)
+
+	cache.list.Unlock()
+	cache.Sync()
+
+	s = cache.Stats().EventQueue
+	assert.Equal(t, uint64(0), s.Length)
+	assert.Equal(t, uint64(3), s.HighWater)
+}
+
+func TestCache_LatencyStats(t *testing.T) {
+	cache := NewCacheWithOptions[int, int](2, WithLatencyStats(), WithStrictConsistency())
+	defer cache.Close()
+
+	for i := 0; i < 4; i++ {
+		require.NoError(t, cache.Set(i, i))
+	}
+	require.NoError(t, cache.SetWithExpiry(9, 9, time.Now().Add(time.Millisecond)))
+	cache.Get(3)
+	cache.Get(0)
+	time.Sleep(5 * time.Millisecond)
+	cache.DeleteExpired()
+
+	l := cache.Stats().Latency
+	assert.Equal(t, uint64(2), l.Get.Count)
+	assert.Equal(t, uint64(5), l.Set.Count)
+	assert.Equal(t, uint64(3), l.Evict.Count)
+	assert.Equal(t, uint64(1), l.Purge.Count)
+
+	var total uint64
+	for _, c := range l.Set.Counts {
+		total += c
+	}
+	assert.Equal(t, l.Set.Count, total)
+	assert.Greater(t, l.Set.Mean(), time.Duration(0))
+	assert.LessOrEqual(t, l.Set.Mean(), l.Set.Max)
+
+	plain := NewCache[int, int](2)
+	defer plain.Close()
+	assert.Zero(t, plain.Stats().Latency.Get.Count)
+}
+
+func TestLatencyHistogram_Quantile(t *testing.T) {
+	var h LatencyHistogram
+	assert.Zero(t, h.Quantile(0.5))
+
+	// 90 fast operations, in the first bucket, and 10 slow ones, in the fourth.
+	h.Counts[0], h.Counts[3] = 90, 10
+	h.Count, h.Max = 100, 900*time.Nanosecond
+
+	assert.Equal(t, LatencyBound(0), h.Quantile(0.5))
+	assert.Equal(t, LatencyBound(0), h.Quantile(0.89))
+	assert.Equal(t, 900*time.Nanosecond, h.Quantile(0.95))
+	assert.Equal(t, 900*time.Nanosecond, h.Quantile(1))
+}
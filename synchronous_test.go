@@ -0,0 +1,78 @@
+package lrucache
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_SynchronousStartsNoGoroutines(t *testing.T) {
+	// Checks no goroutines are started by the cache, through its operations, a refresh and Close. Goroutines left by
+	// other tests may exit meanwhile, so the count is only checked not to grow.
+
+	before := runtime.NumGoroutine()
+
+	calls := 0
+	loader := func(_ context.Context, k int) (int, time.Time, error) {
+		calls++
+		return calls, time.Now().Add(50 * time.Millisecond), nil
+	}
+
+	cache := NewCacheWithOptions[int, int](10, WithSynchronous(), WithPurgeInterval(time.Millisecond),
+		WithRefreshAhead(0.5), WithOnExpiredBatch(func([]int) {}, 10, time.Second))
+	assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+
+	v, err := cache.GetOrLoad(context.Background(), 1, loader)
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+
+	// Within the refresh window, the old value is returned, and the refresh has completed by the time it is.
+	time.Sleep(30 * time.Millisecond)
+	v, err = cache.GetOrLoad(context.Background(), 1, loader)
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+	v, _ = cache.Get(1)
+	assert.Equal(t, 2, v)
+	assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+
+	cache.Close()
+	assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+	assert.ErrorIs(t, cache.Set(2, 2), ErrCacheClosed)
+}
+
+func TestCache_SynchronousDeterministic(t *testing.T) {
+	// Checks the same operations always leave the cache in the same order, with promotions applied immediately.
+
+	run := func() []int {
+		cache := NewCacheWithOptions[int, int](5, WithSynchronous())
+		defer cache.Close()
+		for i := range 20 {
+			require.NoError(t, cache.Set(i%7, i))
+			cache.Get(i % 3)
+		}
+		return cache.OrderedKeys()
+	}
+
+	first := run()
+	assert.Len(t, first, 5)
+	for range 10 {
+		assert.Equal(t, first, run())
+	}
+}
+
+func TestCache_SynchronousSoftCapacity(t *testing.T) {
+	// Checks the soft capacity is enforced as entries are added, as there's no background eviction.
+
+	cache := NewCacheWithOptions[int, int](10, WithSynchronous(), WithSoftCapacity(5))
+	defer cache.Close()
+
+	require.NoError(t, cache.SetWithSize(0, 0, 4))
+	require.NoError(t, cache.SetWithSize(1, 1, 4))
+	assert.Equal(t, uint64(4), cache.Size())
+	assert.False(t, cache.Contains(0))
+	assert.True(t, cache.Contains(1))
+}
@@ -0,0 +1,166 @@
+package lrucache
+
+import "slices"
+
+// tagList is a doubly linked list of the nodes sharing a tag, ordered from the most to the least recently used.
+// Like the main list, it's only modified holding the list lock.
+type tagList[K comparable, V any] struct {
+	name  string
+	head  tagMember[K, V] // Sentinel; head.next is the most recently used member.
+	tail  tagMember[K, V] // Sentinel; tail.previous is the least recently used member.
+	count int
+	size  uint64 // Total size of the members; only maintained for tenants.
+	epoch uint64 // Members with a version up to this were invalidated by BumpTagEpoch; only maintained for tags.
+}
+
+// tagMember links a node into one of its tags' lists.
+type tagMember[K comparable, V any] struct {
+	n        *node[K, V]
+	list     *tagList[K, V]
+	previous *tagMember[K, V]
+	next     *tagMember[K, V]
+}
+
+func newTagList[K comparable, V any](name string) *tagList[K, V] {
+	l := &tagList[K, V]{name: name}
+	l.head.next = &l.tail
+	l.tail.previous = &l.head
+	return l
+}
+
+func (l *tagList[K, V]) pushFront(m *tagMember[K, V]) {
+	m.previous = &l.head
+	m.next = l.head.next
+	l.head.next.previous = m
+	l.head.next = m
+}
+
+func (l *tagList[K, V]) unlink(m *tagMember[K, V]) {
+	m.previous.next = m.next
+	m.next.previous = m.previous
+	m.previous, m.next = nil, nil
+}
+
+// addTags adds n to the list of each of its tags, first evicting the least recently used members of any tag
+// that is at its limit.
+// Assumes the lock is already acquired, and the list lock is held.
+func (lru *Cache[K, V]) addTags(n *node[K, V], tags []string) {
+	slices.Sort(tags)
+	tags = slices.Compact(tags)
+
+	for _, tag := range tags {
+		l, found := lru.tags[tag]
+		if !found {
+			l = newTagList[K, V](tag)
+			lru.tags[tag] = l
+		}
+
+		if max := lru.opts.maxEntriesPerTag; max > 0 {
+			for l.count >= max {
+				lru.removeNode(l.tail.previous.n, EvictionReasonTagLimit)
+			}
+			// The list is dropped once its last member is removed.
+			if l.count == 0 {
+				lru.tags[tag] = l
+			}
+		}
+
+		m := &tagMember[K, V]{n: n, list: l}
+		l.pushFront(m)
+		l.count++
+		n.tags = append(n.tags, m)
+	}
+}
+
+// promoteTags moves n to the front of each of its tags' lists, and its tenant's.
+// Called holding the list lock.
+func (lru *Cache[K, V]) promoteTags(n *node[K, V]) {
+	for _, m := range n.tags {
+		m.list.unlink(m)
+		m.list.pushFront(m)
+	}
+	if m := n.tenant; m != nil {
+		m.list.unlink(m)
+		m.list.pushFront(m)
+	}
+}
+
+// removeTags removes n from each of its tags' lists, and its tenant's, dropping any lists that become empty.
+// Called holding the list lock.
+func (lru *Cache[K, V]) removeTags(n *node[K, V]) {
+	for _, m := range n.tags {
+		m.list.unlink(m)
+		m.list.count--
+		if m.list.count == 0 {
+			delete(lru.tags, m.list.name)
+		}
+	}
+	n.tags = nil
+
+	if m := n.tenant; m != nil {
+		m.list.unlink(m)
+		m.list.count--
+		m.list.size -= n.size
+		if m.list.count == 0 {
+			delete(lru.tenants, m.list.name)
+		}
+		n.tenant = nil
+	}
+}
+
+// DeleteTag removes all entries with the given tag, returning the number removed.
+func (lru *Cache[K, V]) DeleteTag(tag string) int {
+	removedCount := 0
+
+	lru.writeLock(OperationDelete)
+	if lru.stopped {
+		lru.lock.Unlock()
+		return 0
+	}
+	lru.runOnEventLoop(func() {
+		l, found := lru.tags[tag]
+		if !found {
+			return
+		}
+		for l.count > 0 {
+			lru.removeNode(l.head.next.n, EvictionReasonDeleted)
+			removedCount++
+		}
+	})
+	removed := lru.takeRemovals()
+	lru.lock.Unlock()
+
+	lru.notifyRemovals(removed)
+
+	return removedCount
+}
+
+// TagCount returns the number of entries with the given tag.
+func (lru *Cache[K, V]) TagCount(tag string) int {
+	count := 0
+
+	lru.writeLock(OperationOther)
+	if lru.stopped {
+		lru.lock.Unlock()
+		return 0
+	}
+	lru.runOnEventLoop(func() {
+		if l, found := lru.tags[tag]; found {
+			count = l.count
+		}
+	})
+	lru.lock.Unlock()
+
+	return count
+}
+
+// tagNames returns the names of n's tags. Assumes the lock is already acquired.
+func (lru *Cache[K, V]) tagNames(n *node[K, V]) []string {
+	var names []string
+	lru.runOnEventLoop(func() {
+		for _, m := range n.tags {
+			names = append(names, m.list.name)
+		}
+	})
+	return names
+}
@@ -0,0 +1,90 @@
+package lrucache
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_MaxEntriesPerTag(t *testing.T) {
+	// Checks that adding an entry beyond a tag's limit evicts the least recently used entry with that tag only.
+
+	var evicted []string
+	cache := NewCacheWithOptions[string, int](100,
+		WithMaxEntriesPerTag(2),
+		WithOnEvict(func(k string, v int, reason EvictionReason) {
+			if reason == EvictionReasonTagLimit {
+				evicted = append(evicted, k)
+			}
+		}),
+	)
+	defer cache.Close()
+
+	for i := 1; i <= 2; i++ {
+		require.NoError(t, cache.SetWithOptions(fmt.Sprintf("alice-%d", i), i, WithTags("user:alice")))
+		require.NoError(t, cache.SetWithOptions(fmt.Sprintf("bob-%d", i), i, WithTags("user:bob")))
+	}
+
+	// Makes alice-1 more recent than alice-2.
+	cache.Get("alice-1")
+
+	require.NoError(t, cache.SetWithOptions("alice-3", 3, WithTags("user:alice")))
+
+	assert.Equal(t, []string{"alice-2"}, evicted)
+	assert.Equal(t, 2, cache.TagCount("user:alice"))
+	assert.Equal(t, 2, cache.TagCount("user:bob"))
+	assert.True(t, cache.Contains("alice-1"))
+	assert.True(t, cache.Contains("alice-3"))
+	assert.Equal(t, uint64(4), cache.EntryCount())
+}
+
+func TestCache_MaxEntriesPerTagOfOne(t *testing.T) {
+	// Checks a tag stays registered when its last member is evicted to make room for a new one.
+
+	cache := NewCacheWithOptions[int, int](10, WithMaxEntriesPerTag(1))
+	defer cache.Close()
+
+	require.NoError(t, cache.SetWithOptions(1, 1, WithTags("t")))
+	require.NoError(t, cache.SetWithOptions(2, 2, WithTags("t")))
+
+	assert.False(t, cache.Contains(1))
+	assert.Equal(t, 1, cache.TagCount("t"))
+	assert.Equal(t, 1, cache.DeleteTag("t"))
+	assert.False(t, cache.Contains(2))
+	assert.NoError(t, cache.CheckIntegrity())
+}
+
+func TestCache_DeleteTag(t *testing.T) {
+	// Ensures DeleteTag removes every entry with the tag, and that replacing or deleting entries keeps counts correct.
+
+	cache := NewCache[int, string](100)
+	defer cache.Close()
+
+	for i := 1; i <= 10; i++ {
+		tags := []string{"all"}
+		if i%2 == 0 {
+			tags = append(tags, "even")
+		}
+		require.NoError(t, cache.SetWithOptions(i, fmt.Sprintf("value-%d", i), WithTags(tags...), WithSize(2)))
+	}
+
+	// Replacing without tags removes it from its tags.
+	require.NoError(t, cache.Set(2, "untagged"))
+	cache.Delete(4)
+
+	assert.Equal(t, 8, cache.TagCount("all"))
+	assert.Equal(t, 3, cache.TagCount("even"))
+
+	assert.Equal(t, 3, cache.DeleteTag("even"))
+	assert.Equal(t, 0, cache.TagCount("even"))
+	assert.Equal(t, 5, cache.TagCount("all"))
+	assert.Equal(t, uint64(6), cache.EntryCount())
+	assert.Equal(t, uint64(11), cache.Size())
+
+	assert.Equal(t, 5, cache.DeleteTag("all"))
+	assert.Equal(t, 0, cache.DeleteTag("missing"))
+	assert.Equal(t, uint64(1), cache.EntryCount())
+	assert.True(t, cache.Contains(2))
+}
@@ -0,0 +1,90 @@
+package lrucache
+
+import "fmt"
+
+// tenantOf returns the tenant of the entry for k: explicit if it's not empty, otherwise the tenant given by
+// WithTenantFunc, if any.
+func (lru *Cache[K, V]) tenantOf(k K, explicit string) string {
+	if explicit == "" && lru.tenantFunc != nil {
+		return lru.tenantFunc(k)
+	}
+	return explicit
+}
+
+// hasQuota reports whether tenant has a quota, so its entries are tracked.
+func (lru *Cache[K, V]) hasQuota(tenant string) bool {
+	_, found := lru.opts.tenantQuotas[tenant]
+	return found
+}
+
+// checkQuota returns an error if an entry of the given size could never fit within tenant's quota.
+func (lru *Cache[K, V]) checkQuota(tenant string, size uint64) error {
+	quota, found := lru.opts.tenantQuotas[tenant]
+	switch {
+	case found && quota == 0:
+		return fmt.Errorf("%w: tenant %s quota = 0", ErrNoCapacity, tenant)
+	case found && size > quota:
+		return fmt.Errorf("%w: item size = %d. tenant %s quota = %d", ErrItemTooBig, size, tenant, quota)
+	}
+	return nil
+}
+
+// tenantName returns the name of n's tenant, if it has a quota. Assumes the lock is already acquired.
+func (lru *Cache[K, V]) tenantName(n *node[K, V]) string {
+	var name string
+	lru.runOnEventLoop(func() {
+		if n.tenant != nil {
+			name = n.tenant.list.name
+		}
+	})
+	return name
+}
+
+// addTenant adds n to its tenant's list, if the tenant has a quota, first evicting the tenant's least recently
+// used entries until n fits within it.
+// Assumes the lock is already acquired, and the list lock is held.
+func (lru *Cache[K, V]) addTenant(n *node[K, V], tenant string) {
+	quota, found := lru.opts.tenantQuotas[tenant]
+	if !found {
+		return
+	}
+
+	l, found := lru.tenants[tenant]
+	if !found {
+		l = newTagList[K, V](tenant)
+		lru.tenants[tenant] = l
+	}
+
+	for l.count > 0 && l.size+n.size > quota {
+		lru.removeNode(l.tail.previous.n, EvictionReasonQuota)
+	}
+	// The list is dropped once its last member is removed.
+	if l.count == 0 {
+		lru.tenants[tenant] = l
+	}
+
+	m := &tagMember[K, V]{n: n, list: l}
+	l.pushFront(m)
+	l.count++
+	l.size += n.size
+	n.tenant = m
+}
+
+// TenantSize returns the total size of the entries belonging to tenant, if it has a quota; otherwise zero.
+func (lru *Cache[K, V]) TenantSize(tenant string) uint64 {
+	var size uint64
+
+	lru.readLock(OperationOther)
+	if lru.stopped {
+		lru.lock.RUnlock()
+		return 0
+	}
+	lru.runOnEventLoop(func() {
+		if l, found := lru.tenants[tenant]; found {
+			size = l.size
+		}
+	})
+	lru.lock.RUnlock()
+
+	return size
+}
@@ -0,0 +1,54 @@
+package lrucache
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_TenantQuota(t *testing.T) {
+	// Checks that a tenant exceeding its quota evicts its own entries, rather than other tenants'.
+
+	var reasons []EvictionReason
+	cache := NewCacheWithOptions[string, int](100,
+		WithTenantQuota("noisy", 5),
+		WithTenantFunc(func(k string) string {
+			tenant, _, _ := strings.Cut(k, "/")
+			return tenant
+		}),
+		WithOnEvict(func(k string, v int, reason EvictionReason) {
+			reasons = append(reasons, reason)
+		}),
+	)
+	defer cache.Close()
+
+	assert.NoError(t, cache.Set("quiet/a", 1))
+	for i := 0; i < 20; i++ {
+		assert.NoError(t, cache.Set(fmt.Sprintf("noisy/%d", i), i))
+	}
+
+	assert.True(t, cache.Contains("quiet/a"))
+	assert.Equal(t, uint64(5), cache.TenantSize("noisy"))
+	assert.Equal(t, uint64(6), cache.EntryCount())
+	assert.False(t, cache.Contains("noisy/14"))
+	assert.True(t, cache.Contains("noisy/15"))
+	assert.Len(t, reasons, 15)
+	assert.Equal(t, EvictionReasonQuota, reasons[0])
+
+	// Reading an entry protects it from the tenant's evictions.
+	cache.Get("noisy/15")
+	assert.NoError(t, cache.SetWithSize("noisy/big", 0, 2))
+	assert.True(t, cache.Contains("noisy/15"))
+	assert.False(t, cache.Contains("noisy/16"))
+	assert.Equal(t, uint64(5), cache.TenantSize("noisy"))
+
+	// The explicit tenant overrides the key's, and entries larger than the quota are rejected.
+	assert.NoError(t, cache.SetWithOptions("quiet/b", 2, WithTenant("noisy")))
+	assert.Equal(t, uint64(5), cache.TenantSize("noisy"))
+	assert.ErrorIs(t, cache.SetWithOptions("noisy/huge", 0, WithSize(6)), ErrItemTooBig)
+
+	assert.Equal(t, uint64(0), cache.TenantSize("quiet"))
+	assert.Equal(t, "quota", EvictionReasonQuota.String())
+}
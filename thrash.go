@@ -0,0 +1,99 @@
+package lrucache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// thrashBuckets is the number of intervals the WithThrashAlert window is divided into, so it slides in steps of a
+// tenth of the window.
+const thrashBuckets = 10
+
+// ThrashAlert is passed to the WithThrashAlert callback when the cache is thrashing: evicting, or missing, more
+// than the configured thresholds over the window.
+type ThrashAlert struct {
+	Window       time.Duration // The length of the window the counts cover.
+	Evictions    uint64        // Entries evicted for capacity within the window.
+	EvictionRate float64       // Evictions per second over the window.
+	Hits         uint64        // Reads that found an entry within the window.
+	Misses       uint64        // Reads that found no entry within the window.
+	MissRatio    float64       // Misses as a fraction of all reads within the window; zero if there were none.
+}
+
+// WithThrashAlert sets a callback that's run when, over a sliding window, the cache evicts more than maxEvictionRate
+// entries per second for capacity, or more than maxMissRatio of its reads miss. This lets services log or alert on
+// cache thrash as it happens. A zero threshold disables that check. The callback is run once each time a threshold
+// is crossed, and not again until the cache has recovered and crossed it again. Reads are counted as for
+// WithStatsRecorder. The window slides in steps of a tenth of its length, on a background goroutine.
+func WithThrashAlert(fn func(a ThrashAlert), window time.Duration, maxEvictionRate, maxMissRatio float64) Option {
+	return func(o *options) {
+		o.onThrash = fn
+		o.thrashWindow = window
+		o.maxEvictionRate = maxEvictionRate
+		o.maxMissRatio = maxMissRatio
+	}
+}
+
+// thrashMonitor counts evictions and reads for WithThrashAlert. The counters are for the current step of the
+// window, and are moved into the buckets by the background goroutine.
+type thrashMonitor struct {
+	evictions atomic.Uint64
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+
+	// Only accessed by the background goroutine.
+	buckets  [thrashBuckets]thrashCounts
+	next     int
+	alerting bool
+}
+
+// thrashCounts holds the counts for one step of the window.
+type thrashCounts struct {
+	evictions, hits, misses uint64
+}
+
+// watchThrash moves the counts into the next bucket every step of the window, then checks the thresholds against
+// the whole window, until the cache is closed.
+func (lru *Cache[K, V]) watchThrash() {
+	window := lru.opts.thrashWindow
+	ticker := time.NewTicker(max(window/thrashBuckets, 1))
+	defer ticker.Stop()
+
+	m := lru.thrash
+	for {
+		select {
+		case <-lru.done:
+			return
+		case <-ticker.C:
+		}
+
+		m.buckets[m.next] = thrashCounts{
+			evictions: m.evictions.Swap(0),
+			hits:      m.hits.Swap(0),
+			misses:    m.misses.Swap(0),
+		}
+		m.next = (m.next + 1) % thrashBuckets
+
+		a := ThrashAlert{Window: window}
+		for _, b := range m.buckets {
+			a.Evictions += b.evictions
+			a.Hits += b.hits
+			a.Misses += b.misses
+		}
+		a.EvictionRate = float64(a.Evictions) / window.Seconds()
+		if reads := a.Hits + a.Misses; reads > 0 {
+			a.MissRatio = float64(a.Misses) / float64(reads)
+		}
+
+		thrashing := (lru.opts.maxEvictionRate > 0 && a.EvictionRate > lru.opts.maxEvictionRate) ||
+			(lru.opts.maxMissRatio > 0 && a.MissRatio > lru.opts.maxMissRatio)
+		if thrashing && !m.alerting {
+			lru.unattended(func() {
+				_ = lru.safely("OnThrash", func() {
+					lru.opts.onThrash(a)
+				})
+			})
+		}
+		m.alerting = thrashing
+	}
+}
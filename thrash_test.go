@@ -0,0 +1,61 @@
+package lrucache
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThrashAlert_MissRatio(t *testing.T) {
+	// Checks the callback runs once when the miss ratio crosses the threshold, and again after it has recovered.
+
+	var alerts atomic.Int32
+	var last atomic.Pointer[ThrashAlert]
+	cache := NewCacheWithOptions[int, int](10, WithThrashAlert(func(a ThrashAlert) {
+		alerts.Add(1)
+		last.Store(&a)
+	}, 50*time.Millisecond, 0, 0.5))
+	defer cache.Close()
+
+	for i := 0; i < 10; i++ {
+		cache.Get(i)
+	}
+	assert.Eventually(t, func() bool { return alerts.Load() == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, uint64(10), last.Load().Misses)
+	assert.Equal(t, 1.0, last.Load().MissRatio)
+
+	// Still thrashing within the window, so no further alerts.
+	cache.Get(0)
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int32(1), alerts.Load())
+
+	// Once the misses have left the window, the cache has recovered, so crossing again alerts again.
+	time.Sleep(100 * time.Millisecond)
+	cache.Get(0)
+	assert.Eventually(t, func() bool { return alerts.Load() == 2 }, time.Second, time.Millisecond)
+}
+
+func TestThrashAlert_EvictionRate(t *testing.T) {
+	// Checks capacity evictions are counted against the rate threshold.
+
+	alerts := make(chan ThrashAlert, 10)
+	cache := NewCacheWithOptions[int, int](1, WithThrashAlert(func(a ThrashAlert) {
+		alerts <- a
+	}, time.Second, 5, 0))
+	defer cache.Close()
+
+	for i := 0; i < 20; i++ {
+		require.NoError(t, cache.Set(i, i))
+	}
+
+	select {
+	case a := <-alerts:
+		assert.Equal(t, uint64(19), a.Evictions)
+		assert.InDelta(t, 19.0, a.EvictionRate, 0.001)
+	case <-time.After(time.Second):
+		t.Fatal("no alert")
+	}
+}
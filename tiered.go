@@ -0,0 +1,273 @@
+package lrucache
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// SecondLevel is a slower, typically remote and larger, cache consulted by a TieredCache on an L1 miss.
+type SecondLevel[K comparable, V any] interface {
+	// Get returns the value and expiry for k. found is false if the key doesn't exist.
+	Get(ctx context.Context, k K) (v V, expires time.Time, found bool, err error)
+
+	// Set stores the value for k, expiring at expires (the zero value meaning no expiry).
+	Set(ctx context.Context, k K, v V, expires time.Time) error
+
+	// Delete removes k, if it exists.
+	Delete(ctx context.Context, k K) error
+}
+
+// TieredOption configures a TieredCache. TieredOptions are passed to NewTieredCache.
+type TieredOption func(*tieredOptions)
+
+type tieredOptions struct {
+	l1         []Option
+	demote     bool
+	timeout    time.Duration
+	hedgeAfter time.Duration
+}
+
+// WithL1Options sets the Options used to create the in-memory L1 cache.
+func WithL1Options(opts ...Option) TieredOption {
+	return func(o *tieredOptions) {
+		o.l1 = append(o.l1, opts...)
+	}
+}
+
+// WithDemotion changes Set to write only to L1, with entries written to L2 when they're evicted from L1 for capacity.
+// This keeps the hot set local, while the long tail lives in L2. Without it, Set writes through to both levels.
+func WithDemotion() TieredOption {
+	return func(o *tieredOptions) {
+		o.demote = true
+	}
+}
+
+// WithDemotionTimeout sets the timeout for writing a demoted entry to L2. The default is 5 seconds.
+func WithDemotionTimeout(timeout time.Duration) TieredOption {
+	return func(o *tieredOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithHedging enables hedged loads in GetOrLoad: on an L1 miss the loader is called straight away and, if it hasn't
+// returned after the given delay, L2 is queried too. Whichever returns the value first is used, and the other is
+// canceled. This improves tail latency when the backing store is occasionally slow, at the cost of extra L2 reads.
+// Without it, L2 is queried before the loader is called. See TieredCache.HedgeStats.
+func WithHedging(after time.Duration) TieredOption {
+	return func(o *tieredOptions) {
+		o.hedgeAfter = after
+	}
+}
+
+// HedgeStats reports how often a TieredCache's hedged loads were used; see WithHedging.
+type HedgeStats struct {
+	Hedged uint64 // The number of loads that were slow enough for L2 to be queried.
+	Wins   uint64 // The number of those for which L2 returned the value first.
+}
+
+// TieredCache is a two-level cache: an in-memory LRU cache (L1) in front of a SecondLevel (L2).
+// Misses in L1 consult L2, and L2 hits are promoted into L1.
+type TieredCache[K comparable, V any] struct {
+	l1     *Cache[K, V]
+	l2     SecondLevel[K, V]
+	demote bool
+
+	hedgeAfter time.Duration
+	hedged     atomic.Uint64
+	hedgeWins  atomic.Uint64
+}
+
+// NewTieredCache creates a new TieredCache, with an L1 cache of the given capacity in front of l2.
+func NewTieredCache[K comparable, V any](capacity uint64, l2 SecondLevel[K, V], opts ...TieredOption) *TieredCache[K, V] {
+	o := tieredOptions{timeout: 5 * time.Second}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	t := &TieredCache[K, V]{l2: l2, demote: o.demote, hedgeAfter: o.hedgeAfter}
+
+	l1 := o.l1
+	if o.demote {
+		timeout := o.timeout
+		l1 = append(l1, func(lo *options) {
+			lo.evictHook = func(k K, v V, expires time.Time, reason EvictionReason) {
+				if reason != EvictionReasonCapacity && reason != EvictionReasonTagLimit {
+					return
+				}
+				if !expires.IsZero() && expires.Before(time.Now()) {
+					return
+				}
+				ctx, cancel := context.WithTimeout(context.Background(), timeout)
+				defer cancel()
+				if err := l2.Set(ctx, k, v, expires); err != nil {
+					t.l1.handleError(fmt.Errorf("unable to demote key %v to L2: %w", k, err))
+				}
+			}
+		})
+	}
+
+	t.l1 = NewCacheWithOptions[K, V](capacity, l1...)
+	return t
+}
+
+// L1 returns the in-memory L1 cache.
+func (t *TieredCache[K, V]) L1() *Cache[K, V] {
+	return t.l1
+}
+
+// Get returns the value for k from L1 or, on an L1 miss, from L2. Values found in L2 are added to L1.
+func (t *TieredCache[K, V]) Get(ctx context.Context, k K) (V, bool, error) {
+	if v, found := t.l1.Get(k); found {
+		return v, true, nil
+	}
+
+	v, expires, found, err := t.l2Get(ctx, k)
+	if err != nil || !found {
+		return t.l1.emptyV, false, err
+	}
+
+	if err := t.l1.SetWithExpiry(k, v, expires); err != nil {
+		return v, true, fmt.Errorf("unable to promote key %v to L1: %w", k, err)
+	}
+
+	return v, true, nil
+}
+
+// GetOrLoad returns the value for k from L1, or on an L1 miss from L2 or the loader, adding it to L1. Concurrent
+// calls for the same key share a single load, as with Cache.GetOrLoad. Unless WithDemotion is set, loaded values are
+// written to L2 too. With WithHedging, the loader and L2 are raced; see WithHedging.
+func (t *TieredCache[K, V]) GetOrLoad(ctx context.Context, k K, loader Loader[K, V]) (V, error) {
+	return t.l1.GetOrLoad(ctx, k, func(ctx context.Context, k K) (V, time.Time, error) {
+		if t.hedgeAfter > 0 {
+			return t.hedgedLoad(ctx, k, loader)
+		}
+		v, expires, found, err := t.l2Get(ctx, k)
+		if err != nil {
+			t.l1.handleError(fmt.Errorf("unable to get key %v from L2: %w", k, err))
+		}
+		if found {
+			return v, expires, nil
+		}
+		return t.load(ctx, k, loader)
+	})
+}
+
+// l2Get gets k from L2, treating entries that have already expired as not found.
+func (t *TieredCache[K, V]) l2Get(ctx context.Context, k K) (V, time.Time, bool, error) {
+	v, expires, found, err := t.l2.Get(ctx, k)
+	if err != nil || !found || (!expires.IsZero() && expires.Before(time.Now())) {
+		return t.l1.emptyV, time.Time{}, false, err
+	}
+	return v, expires, true, nil
+}
+
+// load calls the loader, writing the value it returns to L2 unless WithDemotion is set.
+func (t *TieredCache[K, V]) load(ctx context.Context, k K, loader Loader[K, V]) (V, time.Time, error) {
+	v, expires, err := loader(ctx, k)
+	if err == nil && !t.demote {
+		if err := t.l2.Set(ctx, k, v, expires); err != nil {
+			t.l1.handleError(fmt.Errorf("unable to write key %v to L2: %w", k, err))
+		}
+	}
+	return v, expires, err
+}
+
+// tierResult is the result of the loader or an L2 Get, raced by hedgedLoad.
+type tierResult[V any] struct {
+	v       V
+	expires time.Time
+	found   bool
+	err     error
+	l2      bool
+	panic   any // A panic recovered from the loader, re-raised by hedgedLoad.
+}
+
+// hedgedLoad calls the loader, also querying L2 if the loader hasn't returned after the hedging delay, and returns
+// the first value found. The loser is canceled. If neither finds a value, the loader's result is returned.
+func (t *TieredCache[K, V]) hedgedLoad(ctx context.Context, k K, loader Loader[K, V]) (V, time.Time, error) {
+	// Canceling on return stops whichever is still running.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Buffered, so the loser doesn't block once we've returned.
+	results := make(chan tierResult[V], 2)
+	go func() {
+		var r tierResult[V]
+		defer func() {
+			r.panic = recover()
+			results <- r
+		}()
+		r.v, r.expires, r.err = t.load(ctx, k, loader)
+		r.found = r.err == nil
+	}()
+	pending := 1
+
+	timer := time.NewTimer(t.hedgeAfter)
+	defer timer.Stop()
+	hedge := timer.C
+
+	var loaded *tierResult[V]
+	for pending > 0 {
+		select {
+		case <-hedge:
+			hedge = nil
+			t.hedged.Add(1)
+			go func() {
+				v, expires, found, err := t.l2Get(ctx, k)
+				results <- tierResult[V]{v: v, expires: expires, found: found, err: err, l2: true}
+			}()
+			pending++
+
+		case r := <-results:
+			pending--
+			if r.panic != nil {
+				panic(r.panic)
+			}
+			if r.found {
+				if r.l2 {
+					t.hedgeWins.Add(1)
+				}
+				return r.v, r.expires, nil
+			}
+			if !r.l2 {
+				loaded = &r
+			}
+		}
+	}
+
+	return t.l1.emptyV, time.Time{}, loaded.err
+}
+
+// HedgeStats returns counts of the hedged loads made since the cache was created.
+func (t *TieredCache[K, V]) HedgeStats() HedgeStats {
+	return HedgeStats{Hedged: t.hedged.Load(), Wins: t.hedgeWins.Load()}
+}
+
+// Set adds the value to L1 and, unless WithDemotion is set, L2.
+func (t *TieredCache[K, V]) Set(ctx context.Context, k K, v V) error {
+	return t.SetWithExpiry(ctx, k, v, time.Time{})
+}
+
+// SetWithExpiry adds the value to L1 and, unless WithDemotion is set, L2, expiring at expires.
+func (t *TieredCache[K, V]) SetWithExpiry(ctx context.Context, k K, v V, expires time.Time) error {
+	if err := t.l1.SetWithExpiry(k, v, expires); err != nil {
+		return err
+	}
+	if t.demote {
+		return nil
+	}
+	return t.l2.Set(ctx, k, v, expires)
+}
+
+// Delete removes k from both levels.
+func (t *TieredCache[K, V]) Delete(ctx context.Context, k K) error {
+	t.l1.Delete(k)
+	return t.l2.Delete(ctx, k)
+}
+
+// Close closes the L1 cache. L2 is owned by the caller.
+func (t *TieredCache[K, V]) Close() {
+	t.l1.Close()
+}
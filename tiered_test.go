@@ -0,0 +1,176 @@
+package lrucache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapSecondLevel is an in-memory SecondLevel, for testing.
+type mapSecondLevel[K comparable, V any] struct {
+	lock    sync.Mutex
+	entries map[K]Entry[K, V]
+	gets    int
+}
+
+func newMapSecondLevel[K comparable, V any]() *mapSecondLevel[K, V] {
+	return &mapSecondLevel[K, V]{entries: make(map[K]Entry[K, V])}
+}
+
+func (m *mapSecondLevel[K, V]) Get(ctx context.Context, k K) (V, time.Time, bool, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.gets++
+	e, found := m.entries[k]
+	return e.Value, e.Expires, found, nil
+}
+
+func (m *mapSecondLevel[K, V]) Set(ctx context.Context, k K, v V, expires time.Time) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.entries[k] = Entry[K, V]{Key: k, Value: v, Expires: expires}
+	return nil
+}
+
+func (m *mapSecondLevel[K, V]) Delete(ctx context.Context, k K) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	delete(m.entries, k)
+	return nil
+}
+
+func (m *mapSecondLevel[K, V]) len() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return len(m.entries)
+}
+
+func TestTieredCache_WriteThrough(t *testing.T) {
+	// Checks that Set writes to both levels, L1 misses are served from L2, and L2 hits are promoted.
+
+	l2 := newMapSecondLevel[int, string]()
+	cache := NewTieredCache[int, string](2, l2)
+	defer cache.Close()
+	ctx := context.Background()
+
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, cache.Set(ctx, i, "value"))
+	}
+	assert.Equal(t, 3, l2.len())
+	assert.False(t, cache.L1().Contains(1))
+
+	v, found, err := cache.Get(ctx, 1)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "value", v)
+	assert.True(t, cache.L1().Contains(1))
+
+	require.NoError(t, cache.Delete(ctx, 1))
+	_, found, _ = cache.Get(ctx, 1)
+	assert.False(t, found)
+}
+
+func TestTieredCache_Demotion(t *testing.T) {
+	// Ensures that with demotion, only entries evicted from L1 for capacity are written to L2.
+
+	l2 := newMapSecondLevel[int, string]()
+	cache := NewTieredCache[int, string](2, l2, WithDemotion())
+	defer cache.Close()
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, 1, "value-1"))
+	require.NoError(t, cache.Set(ctx, 2, "value-2"))
+	assert.Equal(t, 0, l2.len())
+
+	require.NoError(t, cache.Set(ctx, 3, "value-3"))
+	assert.Equal(t, 1, l2.len())
+	assert.Contains(t, l2.entries, 1)
+
+	// Deletes are not demoted.
+	cache.L1().Delete(2)
+	assert.Equal(t, 1, l2.len())
+
+	v, found, _ := cache.Get(ctx, 1)
+	assert.True(t, found)
+	assert.Equal(t, "value-1", v)
+}
+
+func TestTieredCache_GetOrLoad(t *testing.T) {
+	// Checks L1 misses are served from L2 before calling the loader, and loaded values are written to both levels.
+
+	l2 := newMapSecondLevel[int, string]()
+	cache := NewTieredCache[int, string](10, l2)
+	defer cache.Close()
+	ctx := context.Background()
+	require.NoError(t, l2.Set(ctx, 1, "stored", time.Time{}))
+
+	var loads atomic.Int32
+	loader := func(ctx context.Context, k int) (string, time.Time, error) {
+		loads.Add(1)
+		return "loaded", time.Time{}, nil
+	}
+
+	v, err := cache.GetOrLoad(ctx, 1, loader)
+	require.NoError(t, err)
+	assert.Equal(t, "stored", v)
+	assert.Equal(t, int32(0), loads.Load())
+
+	v, err = cache.GetOrLoad(ctx, 2, loader)
+	require.NoError(t, err)
+	assert.Equal(t, "loaded", v)
+	assert.Equal(t, int32(1), loads.Load())
+	assert.True(t, cache.L1().Contains(2))
+	assert.Equal(t, 2, l2.len())
+
+	assert.Equal(t, HedgeStats{}, cache.HedgeStats())
+}
+
+func TestTieredCache_Hedging(t *testing.T) {
+	// Checks a slow load is raced against L2, the first value found is used, and the loser is canceled.
+
+	l2 := newMapSecondLevel[int, string]()
+	cache := NewTieredCache[int, string](10, l2, WithHedging(10*time.Millisecond))
+	defer cache.Close()
+	ctx := context.Background()
+	require.NoError(t, l2.Set(ctx, 1, "stored", time.Time{}))
+
+	canceled := make(chan struct{})
+	v, err := cache.GetOrLoad(ctx, 1, func(ctx context.Context, k int) (string, time.Time, error) {
+		<-ctx.Done()
+		close(canceled)
+		return "", time.Time{}, ctx.Err()
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "stored", v)
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("the slow load was not canceled")
+	}
+	assert.Equal(t, HedgeStats{Hedged: 1, Wins: 1}, cache.HedgeStats())
+
+	// Fast loads don't query L2.
+	gets := l2.gets
+	v, err = cache.GetOrLoad(ctx, 2, func(ctx context.Context, k int) (string, time.Time, error) {
+		return "fast", time.Time{}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "fast", v)
+	assert.Equal(t, gets, l2.gets)
+	assert.Equal(t, HedgeStats{Hedged: 1, Wins: 1}, cache.HedgeStats())
+
+	// A slow load still wins when L2 doesn't have the key.
+	v, err = cache.GetOrLoad(ctx, 3, func(ctx context.Context, k int) (string, time.Time, error) {
+		time.Sleep(30 * time.Millisecond)
+		return "slow", time.Time{}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "slow", v)
+	assert.Equal(t, HedgeStats{Hedged: 2, Wins: 1}, cache.HedgeStats())
+}
@@ -0,0 +1,192 @@
+package lrucache
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// CompareAndSwap replaces the value for k with new, but only if k is in the cache with a value equal to old.
+// The entry keeps its size, expiry, metadata and tags, though WithRenewExpiryOnUpdate restarts the expiry. Values
+// are compared using ==, or the function set by WithEqual. Returns true if the value was replaced. If the comparison
+// panics, as == does for values that aren't comparable, the cache is left unchanged, and the panic is passed to the
+// error handler as a PanicError, or re-raised without one.
+func (lru *Cache[K, V]) CompareAndSwap(k K, old, new V) bool {
+	now := time.Now()
+
+	// Any in-flight load for k is superseded, even if the swap fails, as it must be done before taking the lock.
+	lru.supersedeLoad(k)
+
+	lru.writeLock(OperationSet)
+	locked := true
+	defer func() {
+		// Ensures the lock is released if the comparison's panic is re-raised.
+		if locked {
+			lru.lock.Unlock()
+		}
+	}()
+
+	if lru.stopped {
+		return false
+	}
+	existing, found := lru.cache[k]
+	if !found || existing.negative || lru.isExpired(existing, now) {
+		return false
+	}
+
+	var equal bool
+	if err := lru.safely("Equal", func() {
+		equal = lru.equal(existing.value, old)
+	}); err != nil || !equal {
+		return false
+	}
+
+	n, err := lru.replaceLocked(existing, new, now)
+	if err != nil {
+		return false
+	}
+
+	lru.dispatch(event[K, V]{a: EventActionAddToFront, n: n})
+
+	removed := lru.takeRemovals()
+	locked = false
+	lru.lock.Unlock()
+
+	lru.notifyRemovals(removed)
+
+	return true
+}
+
+// GetOrSet returns the value for k if there's an unexpired entry for it, otherwise it stores v, with a size of 1 and
+// no expiry, and returns it. loaded is true if the existing value was returned. The check and the store are atomic,
+// so of concurrent calls for the same missing key, only one stores its value, and the rest get it.
+func (lru *Cache[K, V]) GetOrSet(k K, v V) (actual V, loaded bool, err error) {
+	return lru.GetOrSetWithExpiry(k, v, time.Time{})
+}
+
+// GetOrSetWithExpiry behaves like GetOrSet, storing v, if there's no unexpired entry for k, with the given expiry.
+func (lru *Cache[K, V]) GetOrSetWithExpiry(k K, v V, expires time.Time) (actual V, loaded bool, err error) {
+	existing, stored, err := lru.swap(context.Background(), k, v, entryOptions{size: 1, expires: expires, ifAbsent: true})
+	if err != nil {
+		return lru.emptyV, false, err
+	}
+	if stored == nil && existing != nil {
+		return existing.value, true, nil
+	}
+	return v, false, nil
+}
+
+// Compute atomically updates the entry for k: fn is called with the current value, and whether it was found, and
+// returns the new value, and whether to keep it. If keep is false the entry is deleted, otherwise the new value is
+// stored. An existing entry keeps its size, expiry (unless WithRenewExpiryOnUpdate is given), metadata and tags; a
+// new one has a size of 1 and no expiry. Returns the new value, or the zero value if the entry was deleted.
+//
+// fn is called while holding the cache's lock, so must be quick, and must not call the cache.
+// If fn panics, the cache is left unchanged.
+func (lru *Cache[K, V]) Compute(k K, fn func(old V, found bool) (new V, keep bool)) (V, error) {
+	now := time.Now()
+
+	lru.supersedeLoad(k)
+
+	lru.writeLock(OperationSet)
+	locked := true
+	defer func() {
+		// Ensures the lock is released if fn's panic is re-raised.
+		if locked {
+			lru.lock.Unlock()
+		}
+	}()
+
+	if lru.stopped {
+		return lru.emptyV, fmt.Errorf("%w: unable to compute key %v", ErrCacheClosed, k)
+	}
+
+	existing, found := lru.cache[k]
+	if found && (existing.negative || lru.isExpired(existing, now)) {
+		found = false
+	}
+
+	old := lru.emptyV
+	if found {
+		old = existing.value
+	}
+
+	var v V
+	var keep bool
+	if err := lru.safely("Compute", func() {
+		v, keep = fn(old, found)
+	}); err != nil {
+		return lru.emptyV, err
+	}
+
+	var n *node[K, V]
+	switch {
+	case !keep:
+		if existing != nil {
+			lru.deleteNode(existing, EvictionReasonDeleted)
+		}
+		v = lru.emptyV
+
+	case found:
+		var err error
+		if n, err = lru.replaceLocked(existing, v, now); err != nil {
+			return lru.emptyV, err
+		}
+
+	default:
+		expires, err := lru.checkNil(v, lru.expiry.ExpireAfterWrite(k, v, time.Time{}, now))
+		if err != nil {
+			return lru.emptyV, err
+		}
+		size := lru.weigh(k, v, 1)
+		if err := lru.validate(size, expires); err != nil {
+			return lru.emptyV, err
+		}
+		n = &node[K, V]{key: k, value: v, size: size, created: now, expires: expires}
+		lru.insertLocked(n, nil, lru.tenantOf(k, ""))
+	}
+
+	if n != nil {
+		lru.dispatch(event[K, V]{a: EventActionAddToFront, n: n})
+	}
+
+	removed := lru.takeRemovals()
+	locked = false
+	lru.lock.Unlock()
+
+	lru.notifyRemovals(removed)
+
+	return v, nil
+}
+
+// replaceLocked replaces existing with a new node holding v, keeping its size (unless v is weighed by WithWeigher),
+// expiry (unless renewed by WithRenewExpiryOnUpdate), pin, metadata, tags and tenant.
+// The new node must then be sent to the front of the list. Assumes the lock is already acquired.
+func (lru *Cache[K, V]) replaceLocked(existing *node[K, V], v V, now time.Time) (*node[K, V], error) {
+	expires := existing.expires
+	if lru.opts.renewExpiryOnUpdate {
+		expires = lru.jitter(lru.expiry.ExpireAfterWrite(existing.key, v, time.Time{}, now))
+	}
+	expires, err := lru.checkNil(v, expires)
+	if err != nil {
+		return nil, err
+	}
+	size := lru.weigh(existing.key, v, existing.size)
+	if size != existing.size {
+		if err := lru.validate(size, time.Time{}); err != nil {
+			return nil, err
+		}
+	}
+
+	n := &node[K, V]{
+		key:      existing.key,
+		value:    v,
+		size:     size,
+		created:  now,
+		expires:  expires,
+		pinned:   existing.pinned,
+		metadata: existing.metadata,
+	}
+	lru.insertLocked(n, lru.tagNames(existing), lru.tenantName(existing))
+	return n, nil
+}
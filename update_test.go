@@ -0,0 +1,222 @@
+package lrucache
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_CompareAndSwap(t *testing.T) {
+	// Checks values are only replaced when they match, keeping the entry's size, expiry and tags.
+
+	cache := NewCache[string, int](10)
+	defer cache.Close()
+
+	assert.False(t, cache.CompareAndSwap("missing", 0, 1))
+
+	expires := time.Now().Add(time.Hour)
+	require.NoError(t, cache.SetWithOptions("a", 1, WithSize(3), WithExpiry(expires), WithTags("t")))
+
+	assert.False(t, cache.CompareAndSwap("a", 2, 3))
+	assert.True(t, cache.CompareAndSwap("a", 1, 2))
+
+	e, found := cache.Entry("a")
+	require.True(t, found)
+	assert.Equal(t, 2, e.Value)
+	assert.Equal(t, uint64(3), e.Size)
+	assert.Equal(t, expires, e.Expires)
+	assert.Equal(t, uint64(3), cache.Size())
+	assert.Equal(t, 1, cache.TagCount("t"))
+}
+
+func TestCache_CompareAndSwapConcurrent(t *testing.T) {
+	// Ensures concurrent optimistic increments don't lose updates.
+
+	cache := NewCache[string, int](10)
+	defer cache.Close()
+	require.NoError(t, cache.Set("counter", 0))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				for {
+					v, _ := cache.Get("counter")
+					if cache.CompareAndSwap("counter", v, v+1) {
+						break
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	v, _ := cache.Get("counter")
+	assert.Equal(t, 200, v)
+}
+
+func TestCache_CompareAndSwapWithEqual(t *testing.T) {
+	// Verifies WithEqual allows non-comparable values to be compared.
+
+	cache := NewCacheWithOptions[string, []byte](10, WithEqual(bytes.Equal))
+	defer cache.Close()
+
+	require.NoError(t, cache.Set("a", []byte("one")))
+	assert.True(t, cache.CompareAndSwap("a", []byte("one"), []byte("two")))
+
+	v, _ := cache.Get("a")
+	assert.Equal(t, []byte("two"), v)
+
+	assert.Panics(t, func() {
+		NewCacheWithOptions[string, []byte](10, WithEqual(func(a, b string) bool { return a == b }))
+	})
+}
+
+func TestCache_GetOrSet(t *testing.T) {
+	// Checks only the first of concurrent calls for a missing key stores its value, and the rest get it.
+
+	cache := NewCache[string, int](10)
+	defer cache.Close()
+
+	var wg sync.WaitGroup
+	var stored sync.Map
+	for i := 1; i <= 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, loaded, err := cache.GetOrSetWithExpiry("a", i, time.Now().Add(time.Hour))
+			assert.NoError(t, err)
+			if !loaded {
+				stored.Store(v, true)
+			}
+		}()
+	}
+	wg.Wait()
+
+	winners := 0
+	stored.Range(func(v, _ any) bool {
+		winners++
+		actual, _ := cache.Get("a")
+		assert.Equal(t, v, actual)
+		return true
+	})
+	assert.Equal(t, 1, winners)
+
+	// Expired entries are replaced.
+	require.NoError(t, cache.SetWithExpiry("b", 1, time.Now().Add(time.Millisecond)))
+	time.Sleep(5 * time.Millisecond)
+	v, loaded, err := cache.GetOrSet("b", 2)
+	require.NoError(t, err)
+	assert.False(t, loaded)
+	assert.Equal(t, 2, v)
+
+	_, _, err = cache.GetOrSetWithExpiry("c", 1, time.Now().Add(-time.Second))
+	assert.ErrorIs(t, err, ErrPastExpiry)
+}
+
+func TestCache_Compute(t *testing.T) {
+	// Checks Compute can create, update and delete entries, with concurrent updates applied atomically.
+
+	cache := NewCache[string, int](10)
+	defer cache.Close()
+
+	increment := func(old int, found bool) (int, bool) {
+		return old + 1, true
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cache.Compute("counter", increment)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	v, _ := cache.Get("counter")
+	assert.Equal(t, 50, v)
+
+	// An existing entry keeps its size.
+	require.NoError(t, cache.SetWithSize("sized", 1, 3))
+	v, err := cache.Compute("sized", increment)
+	require.NoError(t, err)
+	assert.Equal(t, 2, v)
+	e, _ := cache.Entry("sized")
+	assert.Equal(t, uint64(3), e.Size)
+
+	// Returning keep as false deletes the entry.
+	v, err = cache.Compute("counter", func(old int, found bool) (int, bool) {
+		assert.True(t, found)
+		return 0, false
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, v)
+	assert.False(t, cache.Contains("counter"))
+	assert.Equal(t, uint64(3), cache.Size())
+}
+
+func TestCache_ComputePanic(t *testing.T) {
+	// Ensures a panicking Compute function leaves the cache unchanged, and unlocked.
+
+	var handled error
+	cache := NewCacheWithOptions[string, int](10, WithErrorHandler(func(err error) {
+		handled = err
+	}))
+	defer cache.Close()
+
+	require.NoError(t, cache.Set("a", 1))
+
+	_, err := cache.Compute("a", func(old int, found bool) (int, bool) {
+		panic("boom")
+	})
+	assert.ErrorIs(t, err, ErrCallbackPanic)
+	assert.ErrorIs(t, handled, ErrCallbackPanic)
+
+	v, _ := cache.Get("a")
+	assert.Equal(t, 1, v)
+
+	// Without an error handler, the panic is re-raised, but the lock is still released.
+	plain := NewCache[string, int](10)
+	defer plain.Close()
+	assert.Panics(t, func() {
+		_, _ = plain.Compute("a", func(old int, found bool) (int, bool) {
+			panic("boom")
+		})
+	})
+	require.NoError(t, plain.Set("a", 1))
+}
+
+func TestCache_CompareAndSwapPanic(t *testing.T) {
+	// Checks a comparison that panics leaves the cache unchanged and unlocked, both with and without an error handler.
+
+	var errs []error
+	cache := NewCacheWithOptions[string, []int](10, WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	defer cache.Close()
+
+	require.NoError(t, cache.Set("a", []int{1}))
+	assert.False(t, cache.CompareAndSwap("a", []int{1}, []int{2}))
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], ErrCallbackPanic)
+
+	unhandled := NewCacheWithOptions[string, []int](10, WithEqual(func(a, b []int) bool { panic("equal") }))
+	defer unhandled.Close()
+
+	require.NoError(t, unhandled.Set("a", []int{1}))
+	assert.Panics(t, func() { unhandled.CompareAndSwap("a", []int{1}, []int{2}) })
+
+	// Neither cache is left locked.
+	v, found := cache.Get("a")
+	assert.True(t, found)
+	assert.Equal(t, []int{1}, v)
+	require.NoError(t, unhandled.Set("b", []int{3}))
+	v, _ = unhandled.Get("a")
+	assert.Equal(t, []int{1}, v)
+}
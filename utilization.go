@@ -0,0 +1,76 @@
+package lrucache
+
+import (
+	"slices"
+	"sync"
+)
+
+// UtilizationEvent is passed to the WithOnUtilization callback when the cache's utilization crosses a threshold.
+type UtilizationEvent struct {
+	Threshold float64 // The threshold crossed, as a fraction of the capacity.
+	Rising    bool    // True if utilization rose to or above the threshold; false if it fell below it.
+	Size      uint64  // The cache's size once it had crossed the threshold.
+	Capacity  uint64
+}
+
+// WithOnUtilization sets a callback that's run when the cache's size, as a fraction of its capacity, crosses any of
+// the given thresholds, in either direction: rising when it reaches a threshold, and falling when it drops below.
+// For example, 0.7, 0.8 and 0.95 report reaching 80% and 95%, and falling back below 70%, letting autoscaling or
+// admission logic react before eviction starts. If a single write crosses several thresholds, the callback is run
+// for each, in the order they were crossed. It's run after the cache's lock is released; calls are never concurrent.
+func WithOnUtilization(fn func(e UtilizationEvent), thresholds ...float64) Option {
+	return func(o *options) {
+		o.onUtilization = fn
+		o.utilizationThresholds = append(o.utilizationThresholds, thresholds...)
+	}
+}
+
+// utilizationTracker records which thresholds the cache's utilization is at or above, for WithOnUtilization.
+type utilizationTracker struct {
+	thresholds []float64 // Sorted, ascending.
+
+	lock  sync.Mutex // Held while calling the callback, so calls are never concurrent, and are ordered.
+	level int        // The number of thresholds the utilization was last at or above.
+}
+
+func newUtilizationTracker(thresholds []float64) *utilizationTracker {
+	thresholds = slices.Clone(thresholds)
+	slices.Sort(thresholds)
+	return &utilizationTracker{thresholds: slices.Compact(thresholds)}
+}
+
+// checkUtilization runs the WithOnUtilization callback for any thresholds crossed since it was last called.
+// It must be called without holding the lock.
+func (lru *Cache[K, V]) checkUtilization() {
+	t := lru.utilization
+	if t == nil || lru.capacity == 0 {
+		return
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	// Read under the tracker's lock, so the size is never older than the one the level was last set from.
+	lru.readLock(OperationOther)
+	size := lru.size
+	lru.lock.RUnlock()
+
+	u := float64(size) / float64(lru.capacity)
+	level := 0
+	for level < len(t.thresholds) && u >= t.thresholds[level] {
+		level++
+	}
+
+	for ; t.level < level; t.level++ {
+		lru.notifyUtilization(UtilizationEvent{Threshold: t.thresholds[t.level], Rising: true, Size: size, Capacity: lru.capacity})
+	}
+	for ; t.level > level; t.level-- {
+		lru.notifyUtilization(UtilizationEvent{Threshold: t.thresholds[t.level-1], Size: size, Capacity: lru.capacity})
+	}
+}
+
+func (lru *Cache[K, V]) notifyUtilization(e UtilizationEvent) {
+	_ = lru.safely("OnUtilization", func() {
+		lru.opts.onUtilization(e)
+	})
+}
@@ -0,0 +1,39 @@
+package lrucache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_OnUtilization(t *testing.T) {
+	var events []UtilizationEvent
+	cache := NewCacheWithOptions[int, int](10, WithOnUtilization(func(e UtilizationEvent) {
+		events = append(events, e)
+	}, 0.95, 0.7, 0.8))
+	defer cache.Close()
+
+	for i := 0; i < 7; i++ {
+		require.NoError(t, cache.Set(i, i))
+	}
+	require.Len(t, events, 1)
+	assert.Equal(t, UtilizationEvent{Threshold: 0.7, Rising: true, Size: 7, Capacity: 10}, events[0])
+
+	// Setting several entries at once crosses both remaining thresholds, which are reported in order.
+	require.NoError(t, cache.SetMulti(map[int]int{7: 7, 8: 8, 9: 9}))
+	require.Len(t, events, 3)
+	assert.Equal(t, 0.8, events[1].Threshold)
+	assert.Equal(t, 0.95, events[2].Threshold)
+	assert.True(t, events[2].Rising)
+
+	// Eviction keeps the cache full, so nothing further is reported.
+	require.NoError(t, cache.Set(10, 10))
+	assert.Len(t, events, 3)
+
+	assert.Equal(t, 4, cache.DeleteMulti(10, 9, 8, 7))
+	require.Len(t, events, 6)
+	assert.Equal(t, UtilizationEvent{Threshold: 0.95, Size: 6, Capacity: 10}, events[3])
+	assert.Equal(t, 0.8, events[4].Threshold)
+	assert.Equal(t, UtilizationEvent{Threshold: 0.7, Size: 6, Capacity: 10}, events[5])
+}
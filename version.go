@@ -0,0 +1,76 @@
+package lrucache
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// GetVersioned behaves like Get, also returning the entry's version. Every stored entry is given a version greater
+// than that of any entry stored before it, so a change to the value for a key between reading it and writing it
+// back can be detected; see SetIfVersion.
+func (lru *Cache[K, V]) GetVersioned(k K) (v V, version uint64, found bool) {
+	n, found, _ := lru.get(context.Background(), k)
+	if !found || n.negative {
+		return lru.emptyV, 0, false
+	}
+	return n.value, n.version, true
+}
+
+// GetIfChanged behaves like GetVersioned, but if the entry's version is sinceVersion, the value isn't returned, and
+// the error is ErrNotModified. This lets a caller that already has a copy of the value, such as an HTTP client
+// sending the version as an ETag, or a peer syncing deltas, skip copying a large value out of the cache when it
+// hasn't changed. If there's no unexpired entry for k, the error wraps ErrKeyNotFound. A read that isn't modified
+// still counts as a hit, and moves the entry to the front of the list.
+func (lru *Cache[K, V]) GetIfChanged(k K, sinceVersion uint64) (v V, version uint64, err error) {
+	n, found, err := lru.get(context.Background(), k)
+	if err != nil {
+		return lru.emptyV, 0, err
+	}
+	if !found || n.negative {
+		return lru.emptyV, 0, fmt.Errorf("%w: key %v", ErrKeyNotFound, k)
+	}
+	if n.version == sinceVersion {
+		return lru.emptyV, n.version, ErrNotModified
+	}
+	return n.value, n.version, nil
+}
+
+// SetVersioned behaves like SetWithOptions, also returning the new entry's version. The version is zero if the
+// entry wasn't stored, as it was refused by WithDoorkeeper.
+func (lru *Cache[K, V]) SetVersioned(k K, v V, opts ...EntryOption) (uint64, error) {
+	eo := entryOptions{size: 1}
+	for _, opt := range opts {
+		opt(&eo)
+	}
+	_, n, err := lru.swap(context.Background(), k, v, eo)
+	if err != nil || n == nil {
+		return 0, err
+	}
+	return n.version, nil
+}
+
+// SetIfVersion behaves like SetVersioned, but only stores the entry if the current unexpired entry for k has the
+// given version, or if version is zero and there's no such entry. Otherwise, an error wrapping ErrVersionConflict
+// is returned. The check and the store are atomic.
+func (lru *Cache[K, V]) SetIfVersion(k K, v V, version uint64, opts ...EntryOption) (uint64, error) {
+	eo := entryOptions{size: 1, checkVersion: true, version: version}
+	for _, opt := range opts {
+		opt(&eo)
+	}
+	_, n, err := lru.swap(context.Background(), k, v, eo)
+	if err != nil || n == nil {
+		return 0, err
+	}
+	return n.version, nil
+}
+
+// versionLocked returns the version of the unexpired entry for k, or zero if there isn't one.
+// Assumes the lock is already acquired.
+func (lru *Cache[K, V]) versionLocked(k K) uint64 {
+	n, found := lru.cache[k]
+	if !found || n == nil || n.negative || lru.isExpired(n, time.Now()) {
+		return 0
+	}
+	return n.version
+}
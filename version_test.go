@@ -0,0 +1,77 @@
+package lrucache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_Versions(t *testing.T) {
+	// Checks that versions increase with every store, and that SetIfVersion detects conflicting writes.
+
+	cache := NewCache[string, int](10)
+	defer cache.Close()
+
+	v1, err := cache.SetVersioned("a", 1)
+	assert.NoError(t, err)
+	assert.NotZero(t, v1)
+
+	v, version, found := cache.GetVersioned("a")
+	assert.True(t, found)
+	assert.Equal(t, 1, v)
+	assert.Equal(t, v1, version)
+
+	// A write-back based on a stale read is refused.
+	v2, err := cache.SetIfVersion("a", 2, version)
+	assert.NoError(t, err)
+	assert.Greater(t, v2, v1)
+
+	_, err = cache.SetIfVersion("a", 3, version)
+	assert.ErrorIs(t, err, ErrVersionConflict)
+	v, _, _ = cache.GetVersioned("a")
+	assert.Equal(t, 2, v)
+
+	// Zero expects no entry.
+	_, err = cache.SetIfVersion("a", 3, 0)
+	assert.ErrorIs(t, err, ErrVersionConflict)
+	v3, err := cache.SetIfVersion("b", 1, 0)
+	assert.NoError(t, err)
+	assert.Greater(t, v3, v2)
+
+	e, _ := cache.Entry("b")
+	assert.Equal(t, v3, e.Version)
+
+	_, version, found = cache.GetVersioned("missing")
+	assert.False(t, found)
+	assert.Zero(t, version)
+}
+
+func TestCache_GetIfChanged(t *testing.T) {
+	// Checks the value is only returned if the caller's version is out of date.
+
+	cache := NewCache[string, string](10)
+	defer cache.Close()
+
+	version, err := cache.SetVersioned("a", "first")
+	require.NoError(t, err)
+
+	v, current, err := cache.GetIfChanged("a", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "first", v)
+	assert.Equal(t, version, current)
+
+	v, current, err = cache.GetIfChanged("a", version)
+	assert.ErrorIs(t, err, ErrNotModified)
+	assert.Empty(t, v)
+	assert.Equal(t, version, current)
+
+	require.NoError(t, cache.Set("a", "second"))
+	v, current, err = cache.GetIfChanged("a", version)
+	require.NoError(t, err)
+	assert.Equal(t, "second", v)
+	assert.Greater(t, current, version)
+
+	_, _, err = cache.GetIfChanged("missing", 0)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
@@ -0,0 +1,88 @@
+package lrucache
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// View is a type-safe view over a cache holding values of any type. It allows values of different types to share
+// a single cache, and so a single capacity budget, while each caller deals only in its own type.
+// Views of different types share the cache's key space; callers should ensure their keys don't collide.
+type View[K comparable, V any] struct {
+	cache *Cache[K, any]
+}
+
+// NewView returns a view over cache for values of type V.
+func NewView[V any, K comparable](cache *Cache[K, any]) *View[K, V] {
+	return &View[K, V]{cache: cache}
+}
+
+// Cache returns the underlying cache.
+func (v *View[K, V]) Cache() *Cache[K, any] {
+	return v.cache
+}
+
+// Set adds a value to the cache with a size of 1 and no expiry.
+func (v *View[K, V]) Set(k K, value V) error {
+	return v.cache.Set(k, value)
+}
+
+// SetWithSize adds a value to the cache with a specific size.
+func (v *View[K, V]) SetWithSize(k K, value V, size uint64) error {
+	return v.cache.SetWithSize(k, value, size)
+}
+
+// SetWithExpiry adds a value to the cache with a specific expiry.
+func (v *View[K, V]) SetWithExpiry(k K, value V, expires time.Time) error {
+	return v.cache.SetWithExpiry(k, value, expires)
+}
+
+// SetWithOptions adds a value to the cache, configured by the given EntryOptions.
+func (v *View[K, V]) SetWithOptions(k K, value V, opts ...EntryOption) error {
+	return v.cache.SetWithOptions(k, value, opts...)
+}
+
+// Get returns the value for k. If k isn't in the cache, found is false and err is nil.
+// If the cached value is of a different type, found is false and err wraps ErrTypeMismatch.
+func (v *View[K, V]) Get(k K) (value V, found bool, err error) {
+	cached, found := v.cache.Get(k)
+	if !found {
+		return value, false, nil
+	}
+	return v.assert(k, cached)
+}
+
+// GetOrLoad returns the value for k if it's in the cache, otherwise loads it; see Cache.GetOrLoad.
+// If the cached value is of a different type, the loader isn't called and an error wrapping ErrTypeMismatch is
+// returned.
+func (v *View[K, V]) GetOrLoad(ctx context.Context, k K, loader Loader[K, V]) (V, error) {
+	cached, err := v.cache.GetOrLoad(ctx, k, func(ctx context.Context, k K) (any, time.Time, error) {
+		return loader(ctx, k)
+	})
+	if err != nil {
+		var empty V
+		return empty, err
+	}
+	value, _, err := v.assert(k, cached)
+	return value, err
+}
+
+// Contains returns true if k is in the cache, and its value is of the view's type.
+func (v *View[K, V]) Contains(k K) bool {
+	_, found, _ := v.Get(k)
+	return found
+}
+
+// Delete removes k from the cache, regardless of the type of its value.
+func (v *View[K, V]) Delete(k K) {
+	v.cache.Delete(k)
+}
+
+func (v *View[K, V]) assert(k K, cached any) (V, bool, error) {
+	value, ok := cached.(V)
+	if !ok {
+		return value, false, fmt.Errorf("%w: key %v holds %T", ErrTypeMismatch, k, cached)
+	}
+	return value, true, nil
+}
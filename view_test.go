@@ -0,0 +1,93 @@
+package lrucache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type viewUser struct {
+	Name string
+}
+
+func TestView_SharesCapacity(t *testing.T) {
+	// Checks views of different types share a single cache, and its capacity.
+
+	cache := NewCache[string, any](2)
+	defer cache.Close()
+
+	users := NewView[*viewUser](cache)
+	counts := NewView[int](cache)
+
+	require.NoError(t, users.Set("user:1", &viewUser{Name: "alice"}))
+	require.NoError(t, counts.Set("count:1", 42))
+
+	u, found, err := users.Get("user:1")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "alice", u.Name)
+
+	c, found, err := counts.Get("count:1")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 42, c)
+
+	// A third entry evicts the least recently used, whichever view it belongs to.
+	require.NoError(t, counts.Set("count:2", 7))
+	assert.False(t, users.Contains("user:1"))
+	assert.Equal(t, uint64(2), cache.Size())
+}
+
+func TestView_TypeMismatch(t *testing.T) {
+	// Ensures reading a value through a view of the wrong type is reported, rather than panicking.
+
+	cache := NewCache[string, any](10)
+	defer cache.Close()
+
+	users := NewView[*viewUser](cache)
+	counts := NewView[int](cache)
+
+	require.NoError(t, counts.Set("key", 1))
+
+	u, found, err := users.Get("key")
+	assert.ErrorIs(t, err, ErrTypeMismatch)
+	assert.False(t, found)
+	assert.Nil(t, u)
+	assert.False(t, users.Contains("key"))
+
+	_, found, err = users.Get("missing")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	// The loader isn't called, as a value exists for the key.
+	_, err = users.GetOrLoad(context.Background(), "key", func(ctx context.Context, k string) (*viewUser, time.Time, error) {
+		t.Fatal("loader should not be called")
+		return nil, time.Time{}, nil
+	})
+	assert.ErrorIs(t, err, ErrTypeMismatch)
+}
+
+func TestView_GetOrLoad(t *testing.T) {
+	// Verifies values loaded through a view are cached and returned typed.
+
+	cache := NewCache[string, any](10)
+	defer cache.Close()
+
+	counts := NewView[int](cache)
+
+	calls := 0
+	loader := func(ctx context.Context, k string) (int, time.Time, error) {
+		calls++
+		return len(k), time.Time{}, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		v, err := counts.GetOrLoad(context.Background(), "four", loader)
+		require.NoError(t, err)
+		assert.Equal(t, 4, v)
+	}
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,104 @@
+package lrucache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// keyWaiters is the registry of goroutines waiting in WaitFor for keys to be stored.
+type keyWaiters[K comparable] struct {
+	lock  sync.Mutex
+	keys  map[K]*keyWait
+	count atomic.Int32 // len(keys), so writes can cheaply skip notifying.
+}
+
+// keyWait is closed when its key is stored, waking all the goroutines waiting for it.
+type keyWait struct {
+	ch   chan struct{}
+	refs int // The number of goroutines waiting, protected by the registry's lock.
+}
+
+// WaitFor returns the value for k, waiting until it's stored, by another goroutine, if there's no unexpired entry
+// for it, so the cache can be a rendezvous point between producers and consumers without polling. It returns
+// ctx's error if it's done first, or ErrCacheClosed if the cache is closed.
+func (lru *Cache[K, V]) WaitFor(ctx context.Context, k K) (V, error) {
+	for {
+		// Registered before looking, so a store between the two isn't missed.
+		w := lru.waiters.add(k)
+		n, found, err := lru.get(ctx, k)
+		if err != nil || (found && !n.negative) {
+			lru.waiters.release(k, w)
+			if err != nil {
+				return lru.emptyV, err
+			}
+			return n.value, nil
+		}
+
+		select {
+		case <-w.ch:
+			// Stored, closed or reset; looked up again.
+			lru.waiters.release(k, w)
+		case <-ctx.Done():
+			lru.waiters.release(k, w)
+			return lru.emptyV, ctx.Err()
+		}
+	}
+}
+
+// add registers a waiter for k.
+func (w *keyWaiters[K]) add(k K) *keyWait {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.keys == nil {
+		w.keys = make(map[K]*keyWait)
+	}
+	kw, found := w.keys[k]
+	if !found {
+		kw = &keyWait{ch: make(chan struct{})}
+		w.keys[k] = kw
+		w.count.Add(1)
+	}
+	kw.refs++
+	return kw
+}
+
+// release unregisters a waiter for k, forgetting the key once no one is waiting for it.
+func (w *keyWaiters[K]) release(k K, kw *keyWait) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	kw.refs--
+	if kw.refs == 0 && w.keys[k] == kw {
+		delete(w.keys, k)
+		w.count.Add(-1)
+	}
+}
+
+// notify wakes the waiters for k, as it has been stored.
+func (w *keyWaiters[K]) notify(k K) {
+	if w.count.Load() == 0 {
+		return
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if kw, found := w.keys[k]; found {
+		close(kw.ch)
+		delete(w.keys, k)
+		w.count.Add(-1)
+	}
+}
+
+// notifyAll wakes every waiter, e.g. as the cache has been closed.
+func (w *keyWaiters[K]) notifyAll() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	for k, kw := range w.keys {
+		close(kw.ch)
+		delete(w.keys, k)
+	}
+	w.count.Store(0)
+}
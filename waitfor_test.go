@@ -0,0 +1,83 @@
+package lrucache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_WaitFor(t *testing.T) {
+	cache := NewCache[string, int](10)
+	defer cache.Close()
+
+	// An existing entry is returned straight away.
+	require.NoError(t, cache.Set("a", 1))
+	v, err := cache.WaitFor(context.Background(), "a")
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+
+	// Waiters for a missing key are all woken when it's stored.
+	var wg sync.WaitGroup
+	results := make([]int, 3)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := cache.WaitFor(context.Background(), "b")
+			assert.NoError(t, err)
+			results[i] = v
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, cache.Set("b", 2))
+	wg.Wait()
+	assert.Equal(t, []int{2, 2, 2}, results)
+
+	// Once everyone's done, nothing is left registered.
+	assert.Zero(t, cache.waiters.count.Load())
+}
+
+func TestCache_WaitForTimeout(t *testing.T) {
+	cache := NewCache[string, int](10)
+	defer cache.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := cache.WaitFor(ctx, "a")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Zero(t, cache.waiters.count.Load())
+
+	// Storing other keys doesn't wake the waiter.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		v, err := cache.WaitFor(context.Background(), "b")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, v)
+	}()
+	require.NoError(t, cache.Set("c", 3))
+	select {
+	case <-done:
+		t.Fatal("WaitFor returned before its key was stored")
+	case <-time.After(20 * time.Millisecond):
+	}
+	require.NoError(t, cache.SetMulti(map[string]int{"b": 2}))
+	<-done
+}
+
+func TestCache_WaitForClose(t *testing.T) {
+	cache := NewCache[string, int](10)
+
+	done := make(chan error)
+	go func() {
+		_, err := cache.WaitFor(context.Background(), "a")
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cache.Close()
+	assert.ErrorIs(t, <-done, ErrCacheClosed)
+}
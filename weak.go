@@ -0,0 +1,66 @@
+package lrucache
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Trim evicts entries from the tail until the cache is within its capacity, returning the number evicted. With
+// WithWeakCapacity, this is how the cache is brought back within its capacity, e.g. between batch jobs; otherwise,
+// the cache is normally already within it, so nothing is evicted.
+func (lru *Cache[K, V]) Trim() int {
+	lru.writeLock(OperationOther)
+	if lru.stopped {
+		lru.lock.Unlock()
+		return 0
+	}
+	evicted := 0
+	lru.runOnEventLoop(func() {
+		now := time.Now()
+		for lru.size > lru.limit {
+			n := lru.victim(now)
+			if n == nil {
+				break
+			}
+			lru.removeNode(n, EvictionReasonCapacity)
+			evicted++
+		}
+	})
+	removed := lru.takeRemovals()
+	lru.lock.Unlock()
+
+	lru.notifyRemovals(removed)
+
+	if evicted > 0 {
+		lru.log(slog.LevelDebug, "lrucache: trimmed entries down to the capacity", "evicted", evicted, "capacity", lru.limit)
+	}
+	return evicted
+}
+
+// touch records that the cache has just been used, for the idle trimming of WithWeakCapacity.
+func (lru *Cache[K, V]) touch() {
+	if lru.opts.weakIdle > 0 {
+		lru.lastUsed.Store(time.Now().UnixNano())
+	}
+}
+
+// trimWhenIdle calls Trim whenever the cache hasn't been read from or written to for idle, until the cache is closed.
+func (lru *Cache[K, V]) trimWhenIdle(idle time.Duration) {
+	timer := time.NewTimer(idle)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-lru.done:
+			return
+		case <-timer.C:
+		}
+
+		if since := time.Since(time.Unix(0, lru.lastUsed.Load())); since < idle {
+			timer.Reset(idle - since)
+			continue
+		}
+		lru.unattended(func() { lru.Trim() })
+		timer.Reset(idle)
+	}
+}
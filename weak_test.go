@@ -0,0 +1,69 @@
+package lrucache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_WeakCapacity(t *testing.T) {
+	// Checks sets take the cache beyond its capacity without eviction, until it's trimmed.
+
+	cache := NewCacheWithOptions[int, int](5, WithWeakCapacity(0))
+	defer cache.Close()
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, cache.Set(i, i))
+	}
+	assert.Equal(t, uint64(10), cache.Size())
+	assert.True(t, cache.Contains(0))
+
+	// Entries larger than the capacity are still rejected.
+	assert.ErrorIs(t, cache.SetWithSize(10, 10, 6), ErrItemTooBig)
+
+	cache.Get(0)
+
+	assert.Equal(t, 5, cache.Trim())
+	assert.Equal(t, uint64(5), cache.Size())
+	assert.True(t, cache.Contains(0))
+	assert.False(t, cache.Contains(1))
+	assert.True(t, cache.Contains(9))
+
+	assert.Zero(t, cache.Trim())
+}
+
+func TestCache_WeakCapacityIdle(t *testing.T) {
+	// Checks the cache is trimmed once it's gone unused for the idle period, and not while it's in use.
+
+	cache := NewCacheWithOptions[int, int](5, WithWeakCapacity(50*time.Millisecond))
+	defer cache.Close()
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, cache.Set(i, i))
+	}
+	for i := 0; i < 5; i++ {
+		time.Sleep(20 * time.Millisecond)
+		cache.Get(0)
+	}
+	assert.Equal(t, uint64(10), cache.Size())
+
+	assert.Eventually(t, func() bool {
+		return cache.Size() == 5
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestCache_TrimWithinCapacity(t *testing.T) {
+	// Checks Trim does nothing to a cache that's within its capacity, or closed.
+
+	cache := NewCache[int, int](5)
+	for i := 0; i < 10; i++ {
+		require.NoError(t, cache.Set(i, i))
+	}
+	assert.Zero(t, cache.Trim())
+	assert.Equal(t, uint64(5), cache.Size())
+
+	cache.Close()
+	assert.Zero(t, cache.Trim())
+}
@@ -0,0 +1,105 @@
+package lrucache
+
+import (
+	"log/slog"
+	"time"
+)
+
+// WithWeigher sets a function giving the size of each entry from its key and value, e.g. the approximate number of
+// bytes it holds, in place of the sizes given by WithSize, SetWithSize and Entry.Size. Sizes of zero are taken as 1.
+// If values are mutated in place once cached, their sizes can be re-evaluated by Reweigh or WithReweighInterval.
+//
+// weigh is called while holding the cache's lock when reweighing, so must be quick, and must not call the cache.
+// Its types must match the cache's.
+func WithWeigher[K comparable, V any](weigh func(k K, v V) uint64) Option {
+	return func(o *options) {
+		o.weigher = weigh
+	}
+}
+
+// WithReweighInterval calls Reweigh every interval, until the cache is closed. It requires WithWeigher.
+func WithReweighInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.reweighInterval = interval
+	}
+}
+
+// Reweigh re-evaluates the size of every entry with the function set by WithWeigher, for values that are mutated
+// in place after being cached, such as pointers to growing slices or maps, so the sizes recorded when they were
+// stored no longer reflect them. The cache's size, and its tenants', are corrected and, if the cache is now over its
+// capacity, entries are evicted from the tail. It returns the number of entries whose size changed. It makes a full
+// pass over the cache, holding the lock, and panics if there's no weigher.
+func (lru *Cache[K, V]) Reweigh() int {
+	if lru.weigher == nil {
+		panic("lrucache: Reweigh requires WithWeigher")
+	}
+
+	lru.writeLock(OperationOther)
+	if lru.stopped {
+		lru.lock.Unlock()
+		return 0
+	}
+	changed := 0
+	lru.runOnEventLoop(func() {
+		for n := lru.head.next; n != lru.tail && n != nil; n = n.next {
+			if n.negative {
+				continue
+			}
+			if size := lru.weigh(n.key, n.value, n.size); size != n.size {
+				lru.resizeNode(n, size)
+				changed++
+			}
+		}
+		lru.makeSpaceFor(0, 0)
+	})
+	removed := lru.takeRemovals()
+	lru.lock.Unlock()
+
+	lru.notifyRemovals(removed)
+
+	if changed > 0 {
+		lru.log(slog.LevelDebug, "lrucache: reweighed entries", "changed", changed, "size", lru.Size())
+	}
+	return changed
+}
+
+// weigh returns the size of the entry for k and v given by the WithWeigher function, or size if there isn't one,
+// or it panics.
+func (lru *Cache[K, V]) weigh(k K, v V, size uint64) uint64 {
+	if lru.weigher == nil {
+		return size
+	}
+	weighed := size
+	if err := lru.safely("Weigher", func() { weighed = max(lru.weigher(k, v), 1) }); err != nil {
+		return size
+	}
+	return weighed
+}
+
+// resizeNode changes the size of n, which is in the cache, correcting the cache's and its tenant's sizes.
+// Assumes the lock is already acquired.
+func (lru *Cache[K, V]) resizeNode(n *node[K, V], size uint64) {
+	lru.size = lru.size - n.size + size
+	lru.sizeCounts[sizeBucket(n.size)]--
+	lru.sizeCounts[sizeBucket(size)]++
+	if n.tenant != nil {
+		n.tenant.list.size = n.tenant.list.size - n.size + size
+	}
+	n.size = size
+	lru.updateGauges()
+}
+
+// reweighInBackground calls Reweigh every interval, until the cache is closed.
+func (lru *Cache[K, V]) reweighInBackground(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-lru.done:
+			return
+		case <-ticker.C:
+			lru.unattended(func() { lru.Reweigh() })
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package lrucache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_Weigher(t *testing.T) {
+	// Checks entries are sized by the weigher, in place of the sizes given.
+
+	cache := NewCacheWithOptions[string, []byte](10, WithWeigher(func(k string, v []byte) uint64 {
+		return uint64(len(v))
+	}))
+	defer cache.Close()
+
+	require.NoError(t, cache.Set("a", make([]byte, 4)))
+	require.NoError(t, cache.SetWithSize("b", make([]byte, 3), 1))
+	require.NoError(t, cache.Set("empty", nil))
+	assert.Equal(t, uint64(8), cache.Size())
+
+	assert.ErrorIs(t, cache.Set("big", make([]byte, 11)), ErrItemTooBig)
+
+	require.NoError(t, cache.SetAll(map[string][]byte{"c": make([]byte, 2)}))
+	assert.Equal(t, uint64(10), cache.Size())
+
+	// Evicts a, the least recently used, to make space.
+	require.NoError(t, cache.Set("d", make([]byte, 2)))
+	assert.False(t, cache.Contains("a"))
+	assert.Equal(t, uint64(8), cache.Size())
+}
+
+func TestCache_Reweigh(t *testing.T) {
+	// Checks values that grow once cached are reweighed, evicting others if the cache is now over its capacity.
+
+	type buffer struct {
+		data []byte
+	}
+
+	cache := NewCacheWithOptions[string, *buffer](10, WithWeigher(func(k string, v *buffer) uint64 {
+		return uint64(len(v.data))
+	}))
+	defer cache.Close()
+
+	a, b := &buffer{data: make([]byte, 2)}, &buffer{data: make([]byte, 2)}
+	require.NoError(t, cache.Set("a", a))
+	require.NoError(t, cache.Set("b", b))
+	assert.Equal(t, 0, cache.Reweigh())
+
+	b.data = append(b.data, make([]byte, 4)...)
+	assert.Equal(t, 1, cache.Reweigh())
+	assert.Equal(t, uint64(8), cache.Size())
+	e, _ := cache.Entry("b")
+	assert.Equal(t, uint64(6), e.Size)
+
+	b.data = append(b.data, make([]byte, 4)...)
+	assert.Equal(t, 1, cache.Reweigh())
+	assert.False(t, cache.Contains("a"))
+	assert.Equal(t, uint64(10), cache.Size())
+
+	assert.Panics(t, func() {
+		NewCache[string, int](10).Reweigh()
+	})
+}
+
+func TestCache_ReweighInterval(t *testing.T) {
+	// Checks sizes are corrected in the background.
+
+	grown := make(chan struct{})
+	cache := NewCacheWithOptions[string, int](10, WithReweighInterval(5*time.Millisecond), WithWeigher(func(k string, v int) uint64 {
+		select {
+		case <-grown:
+			return 5
+		default:
+			return 1
+		}
+	}))
+	defer cache.Close()
+
+	require.NoError(t, cache.Set("b", 1))
+	assert.Equal(t, uint64(1), cache.Size())
+	close(grown)
+
+	assert.Eventually(t, func() bool {
+		return cache.Size() == 5
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestCache_WeigherTypeMismatch(t *testing.T) {
+	// Checks a weigher for other types is rejected at construction.
+
+	assert.Panics(t, func() {
+		NewCacheWithOptions[string, int](10, WithWeigher(func(k int, v int) uint64 { return 1 }))
+	})
+}
@@ -0,0 +1,374 @@
+package lrucache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Store is the persistent store behind a WriteBehindCache.
+type Store[K comparable, V any] interface {
+	// WriteBatch persists the given entries. If it returns an error, the whole batch is retried later.
+	WriteBatch(ctx context.Context, entries []Entry[K, V]) error
+}
+
+// WriteBehindOption configures a WriteBehindCache. WriteBehindOptions are passed to NewWriteBehindCache.
+type WriteBehindOption func(*writeBehindOptions)
+
+type writeBehindOptions struct {
+	cache         []Option
+	batchSize     int
+	flushInterval time.Duration
+	maxPending    int
+	onDataLoss    any // func([]Entry[K, V], error), checked against the cache's types at construction.
+}
+
+// WithWriteCacheOptions sets the Options used to create the in-memory cache.
+func WithWriteCacheOptions(opts ...Option) WriteBehindOption {
+	return func(o *writeBehindOptions) {
+		o.cache = append(o.cache, opts...)
+	}
+}
+
+// WithWriteBatchSize sets the maximum number of entries passed to each Store.WriteBatch call. A batch is written
+// as soon as this many entries are pending, without waiting for the flush interval. The default is 100; sizes
+// below 1 leave it unchanged.
+func WithWriteBatchSize(size int) WriteBehindOption {
+	return func(o *writeBehindOptions) {
+		if size > 0 {
+			o.batchSize = size
+		}
+	}
+}
+
+// WithWriteFlushInterval sets how often pending entries are written to the Store. The default is 1 second;
+// intervals that aren't positive leave it unchanged.
+func WithWriteFlushInterval(interval time.Duration) WriteBehindOption {
+	return func(o *writeBehindOptions) {
+		if interval > 0 {
+			o.flushInterval = interval
+		}
+	}
+}
+
+// WithMaxPendingWrites sets the maximum number of entries awaiting a write to the Store. Once reached, Set blocks
+// until pending entries have been written, or its context is done. The default is 10,000; values below 1 leave it
+// unchanged.
+func WithMaxPendingWrites(max int) WriteBehindOption {
+	return func(o *writeBehindOptions) {
+		if max > 0 {
+			o.maxPending = max
+		}
+	}
+}
+
+// WithOnDataLoss sets a callback that's run with any dirty entries that are dropped without being written to the
+// Store, along with the error that prevented the write. This only happens on Close, if the final write fails;
+// dirty entries are never dropped on eviction. Its key and value types must match the cache's.
+func WithOnDataLoss[K comparable, V any](fn func(entries []Entry[K, V], err error)) WriteBehindOption {
+	return func(o *writeBehindOptions) {
+		o.onDataLoss = fn
+	}
+}
+
+// WriteBehindCache is an in-memory LRU cache in front of a Store, where Sets are written to the Store
+// asynchronously, in batches. Repeated Sets of a key before it's written are coalesced into a single write.
+// Entries are dirty from when they're Set until they've been written. A dirty entry evicted from the in-memory
+// cache, for capacity or expiry, is written to the Store before the eviction completes; if that write fails, it
+// remains pending and is retried with the next batch.
+type WriteBehindCache[K comparable, V any] struct {
+	cache      *Cache[K, V]
+	store      Store[K, V]
+	opts       writeBehindOptions
+	onDataLoss func([]Entry[K, V], error)
+
+	lock    sync.Mutex
+	pending map[K]Entry[K, V] // Entries awaiting a write, by key.
+	queue   []K               // Keys in pending, in the order they were first Set.
+	drained chan struct{}     // Closed, and replaced, whenever pending entries are written.
+
+	kick chan struct{} // Signals the flush loop that a full batch is pending.
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewWriteBehindCache creates a new WriteBehindCache, with an in-memory cache of the given capacity in front of store.
+func NewWriteBehindCache[K comparable, V any](capacity uint64, store Store[K, V], opts ...WriteBehindOption) *WriteBehindCache[K, V] {
+	o := writeBehindOptions{
+		batchSize:     100,
+		flushInterval: time.Second,
+		maxPending:    10000,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	w := &WriteBehindCache[K, V]{
+		store:   store,
+		opts:    o,
+		pending: make(map[K]Entry[K, V]),
+		drained: make(chan struct{}),
+		kick:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+
+	if o.onDataLoss != nil {
+		fn, ok := o.onDataLoss.(func([]Entry[K, V], error))
+		if !ok {
+			panic(fmt.Sprintf("lrucache: OnDataLoss callback has type %T, which does not match the cache", o.onDataLoss))
+		}
+		w.onDataLoss = fn
+	}
+
+	cacheOpts := append(o.cache, func(co *options) {
+		co.evictHook = w.flushEvicted
+	})
+	w.cache = NewCacheWithOptions[K, V](capacity, cacheOpts...)
+
+	w.wg.Add(1)
+	go w.flushLoop()
+
+	return w
+}
+
+// Cache returns the in-memory cache.
+func (w *WriteBehindCache[K, V]) Cache() *Cache[K, V] {
+	return w.cache
+}
+
+// Get returns the value for k from the in-memory cache.
+func (w *WriteBehindCache[K, V]) Get(k K) (V, bool) {
+	return w.cache.Get(k)
+}
+
+// Set adds the value to the in-memory cache, and queues it to be written to the Store.
+// If WithMaxPendingWrites entries are already pending, Set blocks until there's space, or ctx is done. The limit is
+// soft: concurrent Sets may briefly exceed it.
+func (w *WriteBehindCache[K, V]) Set(ctx context.Context, k K, v V, opts ...EntryOption) error {
+	if err := w.waitForSpace(ctx, k); err != nil {
+		return err
+	}
+
+	eo := entryOptions{size: 1}
+	for _, opt := range opts {
+		opt(&eo)
+	}
+	if err := w.cache.set(ctx, k, v, eo); err != nil {
+		return err
+	}
+
+	w.lock.Lock()
+	if _, found := w.pending[k]; !found {
+		w.queue = append(w.queue, k)
+	}
+	w.pending[k] = Entry[K, V]{Key: k, Value: v, Size: eo.size, Expires: eo.expires, Metadata: eo.metadata}
+	full := len(w.pending) >= w.opts.batchSize
+	w.lock.Unlock()
+
+	if full {
+		select {
+		case w.kick <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Delete removes k from the in-memory cache, and discards any pending write for it.
+// Deletes are not propagated to the Store.
+func (w *WriteBehindCache[K, V]) Delete(k K) {
+	w.cache.Delete(k)
+
+	w.lock.Lock()
+	delete(w.pending, k)
+	w.lock.Unlock()
+}
+
+// Dirty reports whether k has been Set, but not yet written to the Store.
+func (w *WriteBehindCache[K, V]) Dirty(k K) bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	_, found := w.pending[k]
+	return found
+}
+
+// Pending returns the number of entries awaiting a write to the Store.
+func (w *WriteBehindCache[K, V]) Pending() int {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return len(w.pending)
+}
+
+// FlushPending writes all pending entries to the Store, returning once they've been written, or the first error.
+// It's intended for use during shutdown, before Close.
+func (w *WriteBehindCache[K, V]) FlushPending(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		written, err := w.flush(ctx)
+		if err != nil {
+			return err
+		}
+		if written == 0 {
+			return nil
+		}
+	}
+}
+
+// Close stops the background flushing, writes any pending entries, and closes the in-memory cache.
+// If the pending entries couldn't be written, they're passed to the WithOnDataLoss callback, and the error returned.
+func (w *WriteBehindCache[K, V]) Close() error {
+	close(w.done)
+	w.wg.Wait()
+
+	err := w.FlushPending(context.Background())
+	if err != nil {
+		w.lock.Lock()
+		lost := make([]Entry[K, V], 0, len(w.pending))
+		for _, k := range w.queue {
+			if e, found := w.pending[k]; found {
+				lost = append(lost, e)
+				delete(w.pending, k)
+			}
+		}
+		w.queue = nil
+		w.lock.Unlock()
+
+		if w.onDataLoss != nil && len(lost) > 0 {
+			_ = w.cache.safely("OnDataLoss", func() {
+				w.onDataLoss(lost, err)
+			})
+		}
+	}
+
+	w.cache.Close()
+	return err
+}
+
+// flushEvicted is the in-memory cache's eviction hook. If the evicted entry is dirty, it's written to the Store.
+// Deleted and replaced entries are ignored, as Delete discards the pending write, and the replacement is pending.
+func (w *WriteBehindCache[K, V]) flushEvicted(k K, _ V, _ time.Time, reason EvictionReason) {
+	if reason == EvictionReasonDeleted || reason == EvictionReasonReplaced {
+		return
+	}
+
+	w.lock.Lock()
+	e, dirty := w.pending[k]
+	if dirty {
+		delete(w.pending, k)
+	}
+	w.lock.Unlock()
+
+	if !dirty {
+		return
+	}
+
+	// The key is left in the queue; flush skips keys without a pending entry.
+	if err := w.write(context.Background(), []Entry[K, V]{e}); err != nil {
+		w.cache.handleError(err)
+	}
+}
+
+// waitForSpace blocks until fewer than WithMaxPendingWrites entries are pending, or k is already pending.
+func (w *WriteBehindCache[K, V]) waitForSpace(ctx context.Context, k K) error {
+	for {
+		w.lock.Lock()
+		_, found := w.pending[k]
+		if found || len(w.pending) < w.opts.maxPending {
+			w.lock.Unlock()
+			return nil
+		}
+		drained := w.drained
+		w.lock.Unlock()
+
+		// Make sure a flush is coming.
+		select {
+		case w.kick <- struct{}{}:
+		default:
+		}
+
+		select {
+		case <-drained:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// flushLoop writes pending entries every flush interval, or as soon as a full batch is pending.
+func (w *WriteBehindCache[K, V]) flushLoop() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.opts.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		case <-w.kick:
+		}
+
+		if _, err := w.flush(context.Background()); err != nil {
+			w.cache.handleError(err)
+		}
+	}
+}
+
+// flush writes a single batch of pending entries, returning how many were written.
+func (w *WriteBehindCache[K, V]) flush(ctx context.Context) (int, error) {
+	w.lock.Lock()
+	batch := make([]Entry[K, V], 0, min(len(w.queue), w.opts.batchSize))
+	taken := 0
+	for _, k := range w.queue {
+		if len(batch) == w.opts.batchSize {
+			break
+		}
+		taken++
+		// Keys that were deleted after being queued have no pending entry.
+		if e, found := w.pending[k]; found {
+			batch = append(batch, e)
+			delete(w.pending, k)
+		}
+	}
+	w.queue = w.queue[taken:]
+	w.lock.Unlock()
+
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	if err := w.write(ctx, batch); err != nil {
+		return 0, err
+	}
+	return len(batch), nil
+}
+
+// write writes entries, which have been taken from pending, to the Store. If the write fails, the entries are
+// returned to the front of the queue, unless they've been Set again since.
+func (w *WriteBehindCache[K, V]) write(ctx context.Context, batch []Entry[K, V]) error {
+	err := w.store.WriteBatch(ctx, batch)
+
+	w.lock.Lock()
+	if err != nil {
+		requeue := make([]K, 0, len(batch)+len(w.queue))
+		for _, e := range batch {
+			if _, found := w.pending[e.Key]; !found {
+				w.pending[e.Key] = e
+				requeue = append(requeue, e.Key)
+			}
+		}
+		w.queue = append(requeue, w.queue...)
+	}
+	close(w.drained)
+	w.drained = make(chan struct{})
+	w.lock.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("unable to write %d entries to the store: %w", len(batch), err)
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package lrucache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingStore is a Store that records the batches written to it, for testing.
+type recordingStore[K comparable, V any] struct {
+	lock    sync.Mutex
+	batches [][]Entry[K, V]
+	values  map[K]V
+	fail    error
+	block   chan struct{} // If not nil, writes wait for it to be closed.
+}
+
+func newRecordingStore[K comparable, V any]() *recordingStore[K, V] {
+	return &recordingStore[K, V]{values: make(map[K]V)}
+}
+
+func (s *recordingStore[K, V]) WriteBatch(ctx context.Context, entries []Entry[K, V]) error {
+	s.lock.Lock()
+	block := s.block
+	s.lock.Unlock()
+	if block != nil {
+		select {
+		case <-block:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.fail != nil {
+		return s.fail
+	}
+	s.batches = append(s.batches, entries)
+	for _, e := range entries {
+		s.values[e.Key] = e.Value
+	}
+	return nil
+}
+
+func (s *recordingStore[K, V]) snapshot() ([][]Entry[K, V], map[K]V) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	values := make(map[K]V, len(s.values))
+	for k, v := range s.values {
+		values[k] = v
+	}
+	return append([][]Entry[K, V](nil), s.batches...), values
+}
+
+func TestWriteBehindCache_Batching(t *testing.T) {
+	// Checks writes are batched and coalesced, and a full batch is written without waiting for the interval.
+
+	store := newRecordingStore[string, int]()
+	cache := NewWriteBehindCache[string, int](100, store,
+		WithWriteBatchSize(3),
+		WithWriteFlushInterval(time.Hour),
+	)
+	defer cache.Close()
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "a", 1))
+	require.NoError(t, cache.Set(ctx, "a", 2))
+	require.NoError(t, cache.Set(ctx, "b", 1))
+	assert.Equal(t, 2, cache.Pending())
+
+	v, found := cache.Get("a")
+	assert.True(t, found)
+	assert.Equal(t, 2, v)
+
+	require.NoError(t, cache.Set(ctx, "c", 1))
+	assert.Eventually(t, func() bool {
+		return cache.Pending() == 0
+	}, time.Second, 5*time.Millisecond)
+
+	batches, values := store.snapshot()
+	require.Len(t, batches, 1)
+	assert.Len(t, batches[0], 3)
+	assert.Equal(t, map[string]int{"a": 2, "b": 1, "c": 1}, values)
+}
+
+func TestWriteBehindCache_FlushPending(t *testing.T) {
+	// Ensures FlushPending writes everything, and failed writes are kept for a retry.
+
+	store := newRecordingStore[string, int]()
+	store.fail = errors.New("store unavailable")
+
+	cache := NewWriteBehindCache[string, int](100, store,
+		WithWriteBatchSize(2),
+		WithWriteFlushInterval(time.Hour),
+	)
+	defer cache.Close()
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "a", 1))
+	require.NoError(t, cache.Set(ctx, "b", 2))
+	require.NoError(t, cache.Set(ctx, "c", 3))
+
+	assert.ErrorIs(t, cache.FlushPending(ctx), store.fail)
+	assert.Equal(t, 3, cache.Pending())
+
+	store.lock.Lock()
+	store.fail = nil
+	store.lock.Unlock()
+
+	require.NoError(t, cache.FlushPending(ctx))
+	assert.Equal(t, 0, cache.Pending())
+
+	_, values := store.snapshot()
+	assert.Equal(t, map[string]int{"a": 1, "b": 2, "c": 3}, values)
+}
+
+func TestWriteBehindCache_InvalidOptions(t *testing.T) {
+	// Checks invalid batch sizes, flush intervals and pending limits fall back to the defaults, rather than losing
+	// writes or panicking.
+
+	for _, size := range []int{0, -1} {
+		store := newRecordingStore[string, int]()
+		cache := NewWriteBehindCache[string, int](100, store,
+			WithWriteBatchSize(size),
+			WithWriteFlushInterval(time.Duration(size)),
+			WithMaxPendingWrites(size),
+		)
+		ctx := context.Background()
+
+		require.NoError(t, cache.Set(ctx, "a", 1))
+		require.NoError(t, cache.FlushPending(ctx))
+		assert.Equal(t, 0, cache.Pending())
+		cache.Close()
+
+		_, values := store.snapshot()
+		assert.Equal(t, map[string]int{"a": 1}, values)
+	}
+}
+
+func TestWriteBehindCache_Backpressure(t *testing.T) {
+	// Verifies Set blocks once the pending limit is reached, until entries are written or its context is done.
+
+	store := newRecordingStore[string, int]()
+	store.block = make(chan struct{})
+
+	cache := NewWriteBehindCache[string, int](100, store,
+		WithWriteBatchSize(10),
+		WithMaxPendingWrites(2),
+		WithWriteFlushInterval(time.Hour),
+	)
+	defer cache.Close()
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "a", 1))
+	require.NoError(t, cache.Set(ctx, "b", 1))
+
+	// Keys already pending can still be Set.
+	require.NoError(t, cache.Set(ctx, "a", 2))
+
+	timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, cache.Set(timeout, "c", 1), context.DeadlineExceeded)
+
+	result := make(chan error)
+	go func() {
+		result <- cache.Set(ctx, "c", 1)
+	}()
+
+	// Unblocking the store lets the pending entries be written, making space.
+	store.lock.Lock()
+	close(store.block)
+	store.block = nil
+	store.lock.Unlock()
+
+	select {
+	case err := <-result:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Set did not unblock")
+	}
+}
+
+func TestWriteBehindCache_FlushBeforeEvict(t *testing.T) {
+	// Checks a dirty entry evicted from memory is written to the store first, and that clean entries aren't rewritten.
+
+	store := newRecordingStore[string, int]()
+	cache := NewWriteBehindCache[string, int](2, store, WithWriteFlushInterval(time.Hour))
+	defer cache.Close()
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "a", 1))
+	require.NoError(t, cache.Set(ctx, "b", 2))
+	assert.True(t, cache.Dirty("a"))
+
+	require.NoError(t, cache.Set(ctx, "c", 3))
+	assert.False(t, cache.Dirty("a"))
+	assert.True(t, cache.Dirty("b"))
+
+	batches, values := store.snapshot()
+	assert.Len(t, batches, 1)
+	assert.Equal(t, map[string]int{"a": 1}, values)
+
+	// Once written, evicting the entry doesn't write it again.
+	require.NoError(t, cache.FlushPending(ctx))
+	require.NoError(t, cache.Set(ctx, "d", 4))
+	batches, _ = store.snapshot()
+	assert.Len(t, batches, 2)
+	assert.True(t, cache.Dirty("d"))
+}
+
+func TestWriteBehindCache_DataLoss(t *testing.T) {
+	// Ensures entries that can't be written on Close are passed to the data loss callback, and not silently dropped.
+
+	store := newRecordingStore[string, int]()
+	store.fail = errors.New("store unavailable")
+
+	var lost []Entry[string, int]
+	var lostErr error
+	cache := NewWriteBehindCache[string, int](1, store,
+		WithWriteFlushInterval(time.Hour),
+		WithOnDataLoss(func(entries []Entry[string, int], err error) {
+			lost, lostErr = entries, err
+		}),
+		WithWriteCacheOptions(WithErrorHandler(func(error) {})),
+	)
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "a", 1))
+
+	// Evicting "a" fails to write it, so it remains dirty.
+	require.NoError(t, cache.Set(ctx, "b", 2))
+	assert.True(t, cache.Dirty("a"))
+
+	assert.ErrorIs(t, cache.Close(), store.fail)
+	assert.ErrorIs(t, lostErr, store.fail)
+	assert.ElementsMatch(t, []Entry[string, int]{{Key: "a", Value: 1, Size: 1}, {Key: "b", Value: 2, Size: 1}}, lost)
+
+	assert.Panics(t, func() {
+		NewWriteBehindCache[string, int](1, store, WithOnDataLoss(func(entries []Entry[int, int], err error) {}))
+	})
+}